@@ -0,0 +1,296 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package appcfg provides an AppConfigGetter that merges the config of an app
+// from several layers, so that apps only need to set the fields that differ
+// from the rest of the organisation. From lowest to highest precedence, the
+// layers are:
+//
+//	defaults          [app_defaults] in the Chaos Monkey config
+//	account <name>    [account_overrides.<name>] in the Chaos Monkey config
+//	app               the app's own config, e.g. the chaosMonkey attribute
+//	                  of the Spinnaker application
+//
+// Each field is taken from the highest layer that sets it, except for
+// exceptions, which are concatenated across all layers.
+package appcfg
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/spinnaker"
+)
+
+// Source returns the config of an app as set by the app itself
+type Source interface {
+	// AppConfigJSON returns the config of an app in the format of the
+	// "chaosMonkey" attribute of a Spinnaker application, or nil if the app
+	// has no config. The config may be incomplete.
+	AppConfigJSON(app string) ([]byte, error)
+}
+
+// Fields are the fields of an app config that a layer sets. Fields that the
+// layer does not set are nil.
+type Fields struct {
+	Enabled                        *bool                    `json:"enabled,omitempty"`
+	Grouping                       *string                  `json:"grouping,omitempty"`
+	MeanTimeBetweenKillsInWorkDays *int                     `json:"meanTimeBetweenKillsInWorkDays,omitempty"`
+	MinTimeBetweenKillsInWorkDays  *int                     `json:"minTimeBetweenKillsInWorkDays,omitempty"`
+	RegionsAreIndependent          *bool                    `json:"regionsAreIndependent,omitempty"`
+	Exceptions                     []chaosmonkey.Exception  `json:"exceptions,omitempty"`
+	Whitelist                      *[]chaosmonkey.Exception `json:"whitelist,omitempty"`
+}
+
+// Layer is a named set of fields
+type Layer struct {
+	Name   string
+	Fields Fields
+}
+
+// Names of the layers
+const (
+	DefaultsLayer = "defaults"
+	AppLayer      = "app"
+)
+
+// AccountLayer returns the name of the override layer of an account
+func AccountLayer(account string) string {
+	return "account " + account
+}
+
+// Effective is the merged config of an app, along with the layer that each
+// field was taken from
+type Effective struct {
+	Config *chaosmonkey.AppConfig
+
+	// Sources maps the name of each field that was set, e.g. "grouping", to
+	// the name of the layer that set it
+	Sources map[string]string
+
+	// ExceptionSources is the name of the layer of each exception in
+	// Config.Exceptions
+	ExceptionSources []string
+}
+
+// appTTL is how long the config of the last app retrieved from the source is
+// reused. The scheduler gets the config of an app in each of its accounts in
+// a row, so this retrieves the app's config once rather than once per
+// account, while terminations, which happen later, see the current config.
+const appTTL = 10 * time.Second
+
+// Getter implements chaosmonkey.AccountAppConfigGetter by merging layers
+type Getter struct {
+	defaults Fields
+	accounts map[string]Fields
+	source   Source
+
+	now func() time.Time
+
+	mu   sync.Mutex
+	last *cachedApp
+}
+
+// cachedApp is the parsed config of an app, and the time at which it was
+// retrieved
+type cachedApp struct {
+	app       string
+	fields    Fields
+	retrieved time.Time
+}
+
+// New returns a Getter that merges defaults, the overrides of the account
+// and the app's config from source
+func New(defaults Fields, accounts map[string]Fields, source Source) *Getter {
+	return &Getter{defaults: defaults, accounts: accounts, source: source, now: time.Now}
+}
+
+// NewFromConfig returns a Getter with the defaults and account overrides in
+// cfg
+func NewFromConfig(cfg *config.Monkey, source Source) (*Getter, error) {
+	var defaults Fields
+	err := convert(cfg.AppDefaults(), &defaults)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", param.AppDefaults)
+	}
+
+	accounts := make(map[string]Fields)
+	for account, value := range cfg.AccountOverrides() {
+		var fields Fields
+		err := convert(value, &fields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s.%s", param.AccountOverrides, account)
+		}
+		accounts[account] = fields
+	}
+
+	return New(defaults, accounts, source), nil
+}
+
+// convert converts a value read from the config file to fields, using the
+// same JSON field names as Spinnaker
+func convert(value interface{}, fields *Fields) error {
+	js, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "json marshal failed")
+	}
+
+	err = json.Unmarshal(js, fields)
+	if err != nil {
+		return errors.Wrap(err, "json unmarshal failed")
+	}
+
+	return nil
+}
+
+// Get implements chaosmonkey.AppConfigGetter.Get
+// The config does not include account overrides
+func (g *Getter) Get(app string) (*chaosmonkey.AppConfig, error) {
+	return g.GetAccount(app, "")
+}
+
+// GetAccount implements chaosmonkey.AccountAppConfigGetter.GetAccount
+func (g *Getter) GetAccount(app, account string) (*chaosmonkey.AppConfig, error) {
+	e, err := g.Explain(app, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.Config, nil
+}
+
+// Explain returns the merged config of an app in an account, and where each
+// of its fields came from. If account is blank, no account overrides apply.
+func (g *Getter) Explain(app, account string) (*Effective, error) {
+	layers, err := g.layers(app, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return Merge(layers)
+}
+
+// layers returns the layers of an app in an account, from lowest to highest
+// precedence
+func (g *Getter) layers(app, account string) ([]Layer, error) {
+	layers := []Layer{{Name: DefaultsLayer, Fields: g.defaults}}
+
+	if fields, ok := g.accounts[account]; ok && account != "" {
+		layers = append(layers, Layer{Name: AccountLayer(account), Fields: fields})
+	}
+
+	fields, err := g.appFields(app)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(layers, Layer{Name: AppLayer, Fields: fields}), nil
+}
+
+// appFields returns the app's own config from the source, reusing the config
+// of the last app retrieved if it is the same app and was retrieved less than
+// appTTL ago. Errors are not cached.
+func (g *Getter) appFields(app string) (Fields, error) {
+	g.mu.Lock()
+	last := g.last
+	g.mu.Unlock()
+
+	if last != nil && last.app == app && g.now().Sub(last.retrieved) < appTTL {
+		return last.fields, nil
+	}
+
+	retrieved := g.now()
+	js, err := g.source.AppConfigJSON(app)
+	if err != nil {
+		return Fields{}, errors.Wrapf(err, "could not retrieve config of app %s", app)
+	}
+
+	var fields Fields
+	if js != nil {
+		err = json.Unmarshal(js, &fields)
+		if err != nil {
+			return Fields{}, errors.Wrapf(err, "could not parse config of app %s", app)
+		}
+	}
+
+	g.mu.Lock()
+	g.last = &cachedApp{app: app, fields: fields, retrieved: retrieved}
+	g.mu.Unlock()
+
+	return fields, nil
+}
+
+// Merge merges layers, which are ordered from lowest to highest precedence,
+// and validates the result. If no layer sets enabled, the app is disabled.
+func Merge(layers []Layer) (*Effective, error) {
+	var merged Fields
+	sources := make(map[string]string)
+	var exceptionSources []string
+
+	for _, l := range layers {
+		f := l.Fields
+		if f.Enabled != nil {
+			merged.Enabled = f.Enabled
+			sources["enabled"] = l.Name
+		}
+		if f.Grouping != nil {
+			merged.Grouping = f.Grouping
+			sources["grouping"] = l.Name
+		}
+		if f.MeanTimeBetweenKillsInWorkDays != nil {
+			merged.MeanTimeBetweenKillsInWorkDays = f.MeanTimeBetweenKillsInWorkDays
+			sources["meanTimeBetweenKillsInWorkDays"] = l.Name
+		}
+		if f.MinTimeBetweenKillsInWorkDays != nil {
+			merged.MinTimeBetweenKillsInWorkDays = f.MinTimeBetweenKillsInWorkDays
+			sources["minTimeBetweenKillsInWorkDays"] = l.Name
+		}
+		if f.RegionsAreIndependent != nil {
+			merged.RegionsAreIndependent = f.RegionsAreIndependent
+			sources["regionsAreIndependent"] = l.Name
+		}
+		if f.Whitelist != nil {
+			merged.Whitelist = f.Whitelist
+			sources["whitelist"] = l.Name
+		}
+		for _, e := range f.Exceptions {
+			merged.Exceptions = append(merged.Exceptions, e)
+			exceptionSources = append(exceptionSources, l.Name)
+		}
+	}
+
+	if merged.Enabled == nil {
+		disabled := false
+		merged.Enabled = &disabled
+	}
+
+	// Validate the merged config with the same rules as a Spinnaker app
+	js, err := json.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "json marshal failed")
+	}
+
+	cfg, err := spinnaker.AppConfigFromJSON(js)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid merged config")
+	}
+
+	return &Effective{Config: cfg, Sources: sources, ExceptionSources: exceptionSources}, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appcfg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+)
+
+// source returns app configs from a map
+type source map[string]string
+
+func (s source) AppConfigJSON(app string) ([]byte, error) {
+	js, ok := s[app]
+	if !ok {
+		return nil, errors.Errorf("app %s not found", app)
+	}
+	if js == "" {
+		return nil, nil
+	}
+	return []byte(js), nil
+}
+
+const testConfig = `
+[app_defaults]
+enabled = true
+grouping = "cluster"
+meanTimeBetweenKillsInWorkDays = 5
+minTimeBetweenKillsInWorkDays = 1
+
+[[app_defaults.exceptions]]
+account = "test"
+stack = "*"
+detail = "*"
+region = "*"
+
+[account_overrides.prod]
+meanTimeBetweenKillsInWorkDays = 2
+
+[[account_overrides.prod.exceptions]]
+account = "prod"
+stack = "canary"
+detail = "*"
+region = "*"
+`
+
+// countingSource counts the configs retrieved from a source
+type countingSource struct {
+	source
+	calls int
+}
+
+func (s *countingSource) AppConfigJSON(app string) ([]byte, error) {
+	s.calls++
+	return s.source.AppConfigJSON(app)
+}
+
+func newTestGetter(t *testing.T, apps source) *Getter {
+	cfg, err := config.NewFromReader(strings.NewReader(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := NewFromConfig(cfg, apps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+func TestExplain(t *testing.T) {
+	g := newTestGetter(t, source{
+		"web": `{"grouping": "stack", "exceptions": [{"account": "prod", "stack": "*", "detail": "*", "region": "eu-west-1"}]}`,
+	})
+
+	e, err := g.Explain("web", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := chaosmonkey.AppConfig{
+		Enabled:                        true,
+		Grouping:                       chaosmonkey.Stack,
+		MeanTimeBetweenKillsInWorkDays: 2,
+		MinTimeBetweenKillsInWorkDays:  1,
+		Exceptions: []chaosmonkey.Exception{
+			{Account: "test", Stack: "*", Detail: "*", Region: "*"},
+			{Account: "prod", Stack: "canary", Detail: "*", Region: "*"},
+			{Account: "prod", Stack: "*", Detail: "*", Region: "eu-west-1"},
+		},
+	}
+
+	if !reflect.DeepEqual(*e.Config, want) {
+		t.Errorf("got config %+v, want %+v", *e.Config, want)
+	}
+
+	wantSources := map[string]string{
+		"enabled":                        "defaults",
+		"grouping":                       "app",
+		"meanTimeBetweenKillsInWorkDays": "account prod",
+		"minTimeBetweenKillsInWorkDays":  "defaults",
+	}
+
+	if !reflect.DeepEqual(e.Sources, wantSources) {
+		t.Errorf("got sources %v, want %v", e.Sources, wantSources)
+	}
+
+	if got, want := e.ExceptionSources, []string{"defaults", "account prod", "app"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got exception sources %v, want %v", got, want)
+	}
+}
+
+func TestGetWithoutAccount(t *testing.T) {
+	g := newTestGetter(t, source{"web": `{"minTimeBetweenKillsInWorkDays": 3}`})
+
+	cfg, err := g.Get("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Account overrides do not apply without an account
+	if cfg.MeanTimeBetweenKillsInWorkDays != 5 || cfg.MinTimeBetweenKillsInWorkDays != 3 || len(cfg.Exceptions) != 1 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	// Accounts without overrides get the defaults
+	cfg, err = g.GetAccount("web", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.MeanTimeBetweenKillsInWorkDays != 5 {
+		t.Errorf("got mean time %d, want 5", cfg.MeanTimeBetweenKillsInWorkDays)
+	}
+}
+
+func TestAppWithoutConfig(t *testing.T) {
+	// An app without a config gets the defaults
+	g := newTestGetter(t, source{"web": ""})
+
+	cfg, err := g.GetAccount("web", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cfg.Enabled || cfg.MeanTimeBetweenKillsInWorkDays != 2 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	// Without defaults, an app without a config is disabled
+	g = New(Fields{}, nil, source{"web": ""})
+
+	cfg, err = g.Get("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Enabled {
+		t.Error("expected app without any config to be disabled")
+	}
+}
+
+func TestInvalidMerge(t *testing.T) {
+	// Enabled, but no layer sets the mean time between kills
+	g := New(Fields{}, nil, source{"web": `{"enabled": true, "grouping": "app"}`})
+
+	_, err := g.Get("web")
+	if err == nil {
+		t.Error("expected error for config without meanTimeBetweenKillsInWorkDays")
+	}
+
+	_, err = g.Get("missing")
+	if err == nil {
+		t.Error("expected error from source")
+	}
+}
+
+// TestAppConfigRetrievedOnce verifies that getting the config of an app in
+// several accounts retrieves the app's config once, and again after appTTL
+func TestAppConfigRetrievedOnce(t *testing.T) {
+	src := &countingSource{source: source{
+		"web": `{"grouping": "app"}`,
+		"api": `{"grouping": "stack"}`,
+	}}
+	g := newTestGetter(t, src.source)
+	g.source = src
+	now := time.Now()
+	g.now = func() time.Time { return now }
+
+	for _, account := range []string{"prod", "test", "staging"} {
+		cfg, err := g.GetAccount("web", account)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Grouping != chaosmonkey.App {
+			t.Errorf("%s: got grouping %v, want app", account, cfg.Grouping)
+		}
+	}
+
+	if src.calls != 1 {
+		t.Errorf("got %d retrievals for three accounts, want 1", src.calls)
+	}
+
+	cfg, err := g.GetAccount("api", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Grouping != chaosmonkey.Stack || src.calls != 2 {
+		t.Errorf("got grouping %v after %d retrievals, want stack after 2", cfg.Grouping, src.calls)
+	}
+
+	now = now.Add(appTTL)
+	_, err = g.GetAccount("api", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.calls != 3 {
+		t.Errorf("got %d retrievals after appTTL, want 3", src.calls)
+	}
+}
@@ -0,0 +1,469 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aws provides an interface to the EC2 Auto Scaling API, for
+// deployments that are not managed by Spinnaker
+//
+// Autoscaling groups are named following the same app-stack-detail-vNNN
+// convention as in Spinnaker, and the app, stack and cluster of a group are
+// parsed from its name.
+package aws
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SmartThingsOSS/frigga-go"
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	D "github.com/Netflix/chaosmonkey/deploy"
+)
+
+// cloudProvider is the cloud provider reported for AWS accounts
+const cloudProvider = "aws"
+
+// AWS implements the deploy.Deployment interface by querying the Auto Scaling
+// API, and the chaosmonkey.Terminator interface by terminating instances in
+// their autoscaling group
+type AWS struct {
+	client    *http.Client
+	endpoint  string
+	regions   []string
+	tagPrefix string
+	base      credentialsProvider
+	accounts  map[string]credentialsProvider
+	roles     map[string]string
+
+	// now is the time used for signing requests
+	now func() time.Time
+
+	mu     sync.Mutex
+	groups map[accountRegion]cachedGroups
+}
+
+// groupsTTL is how long the autoscaling groups of a region of an account are
+// cached. It covers a pass of the scheduler over every app, and is short
+// enough that a daemon does not schedule from the instances of a previous day
+const groupsTTL = 5 * time.Minute
+
+// cachedGroups are the autoscaling groups of a region of an account, and the
+// time at which they were retrieved
+type cachedGroups struct {
+	groups    []autoScalingGroup
+	retrieved time.Time
+}
+
+// accountRegion identifies the autoscaling groups in one region of an account
+type accountRegion struct {
+	account, region string
+}
+
+// autoScalingGroup is an autoscaling group as represented by the Auto Scaling
+// API
+type autoScalingGroup struct {
+	Name      string `xml:"AutoScalingGroupName"`
+	Status    string `xml:"Status"`
+	Instances []struct {
+		InstanceID     string `xml:"InstanceId"`
+		LifecycleState string `xml:"LifecycleState"`
+	} `xml:"Instances>member"`
+	Tags []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"Tags>member"`
+	SuspendedProcesses []struct {
+		ProcessName string `xml:"ProcessName"`
+	} `xml:"SuspendedProcesses>member"`
+}
+
+// disabled returns true if the group is being deleted or has been taken out
+// of service by suspending AddToLoadBalancer, which is how Spinnaker disables
+// server groups
+func (g autoScalingGroup) disabled() bool {
+	if g.Status != "" {
+		return true
+	}
+
+	for _, p := range g.SuspendedProcesses {
+		if p.ProcessName == "AddToLoadBalancer" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inService returns the ids of the instances that are in service
+func (g autoScalingGroup) inService() []D.InstanceID {
+	result := []D.InstanceID{}
+	for _, ins := range g.Instances {
+		if ins.LifecycleState == "InService" {
+			result = append(result, D.InstanceID(ins.InstanceID))
+		}
+	}
+	return result
+}
+
+// tag returns the value of a tag, and whether the group has the tag
+func (g autoScalingGroup) tag(key string) (string, bool) {
+	for _, t := range g.Tags {
+		if t.Key == key {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+// NewFromConfig returns an AWS based on config
+func NewFromConfig(cfg *config.Monkey) (*AWS, error) {
+	roles := cfg.AWSAccounts()
+	if len(roles) == 0 {
+		return nil, errors.Errorf("%s not specified", param.AWSAccounts)
+	}
+
+	regions, err := cfg.AWSRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(regions) == 0 {
+		return nil, errors.Errorf("%s not specified", param.AWSRegions)
+	}
+
+	client := &http.Client{Timeout: cfg.AWSTimeout()}
+	base, err := baseCredentials(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &AWS{
+		client:    client,
+		endpoint:  strings.TrimRight(cfg.AWSEndpoint(), "/"),
+		regions:   regions,
+		tagPrefix: cfg.AWSTagPrefix(),
+		base:      base,
+		accounts:  make(map[string]credentialsProvider),
+		roles:     roles,
+		now:       time.Now,
+		groups:    make(map[accountRegion]cachedGroups),
+	}
+
+	for account, role := range roles {
+		if role == "" {
+			a.accounts[account] = base
+		} else {
+			a.accounts[account] = a.assumeRole(base, role)
+		}
+	}
+
+	return a, nil
+}
+
+// accountNames returns the names of the configured accounts, sorted
+func (a *AWS) accountNames() []string {
+	result := make([]string, 0, len(a.accounts))
+	for name := range a.accounts {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// AccountID returns the numerical id of an AWS account
+func (a *AWS) AccountID(name string) (string, error) {
+	role, ok := a.roles[name]
+	if !ok {
+		return "", errors.Errorf("unknown account: %s", name)
+	}
+
+	// arn:aws:iam::123456789012:role/chaosmonkey
+	if role != "" {
+		parts := strings.Split(role, ":")
+		if len(parts) < 6 || parts[4] == "" {
+			return "", errors.Errorf("could not parse account id from role arn %s", role)
+		}
+		return parts[4], nil
+	}
+
+	creds, err := a.base.retrieve()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("Action", "GetCallerIdentity")
+	params.Set("Version", "2011-06-15")
+
+	var resp struct {
+		Account string `xml:"GetCallerIdentityResult>Account"`
+	}
+
+	err = a.call(creds, a.regions[0], "sts", params, &resp)
+	if err != nil {
+		return "", errors.Wrap(err, "could not retrieve caller identity")
+	}
+
+	return resp.Account, nil
+}
+
+// CloudProvider returns the cloud provider of an account, which is always
+// "aws"
+func (a *AWS) CloudProvider(account string) (string, error) {
+	return cloudProvider, nil
+}
+
+// Apps implements deploy.Deployment.Apps
+func (a *AWS) Apps(c chan<- *D.App, appNames []string) {
+	// Close the channel we're done
+	defer close(c)
+
+	for _, appName := range appNames {
+		app, err := a.getApp(appName, true)
+		if err != nil {
+			// If we have a problem with one app, we go to the next one
+			log.Printf("WARNING: GetApp failed for %s: %v", appName, err)
+			continue
+		}
+
+		c <- app
+	}
+}
+
+// GetApp implements deploy.Deployment.GetApp. The autoscaling groups are
+// always retrieved, rather than cached, since the app is about to have one of
+// its instances terminated
+func (a *AWS) GetApp(appName string) (*D.App, error) {
+	return a.getApp(appName, false)
+}
+
+// getApp retrieves an app, from the cached autoscaling groups if cached is
+// true
+func (a *AWS) getApp(appName string, cached bool) (*D.App, error) {
+	data := make(D.AppMap)
+	for _, account := range a.accountNames() {
+		for _, region := range a.regions {
+			groups, err := a.appGroups(account, region, appName, cached)
+			if err != nil {
+				log.Printf("WARNING: could not retrieve asgs for app:%s account:%s region:%s : %v", appName, account, region, err)
+				continue
+			}
+
+			for _, g := range groups {
+				if g.disabled() {
+					continue
+				}
+
+				names, _ := frigga.Parse(g.Name)
+				accountName := D.AccountName(account)
+				if _, present := data[accountName]; !present {
+					data[accountName] = D.AccountInfo{CloudProvider: cloudProvider, Clusters: make(D.ClusterMap)}
+				}
+
+				clusterName := D.ClusterName(names.Cluster)
+				if _, present := data[accountName].Clusters[clusterName]; !present {
+					data[accountName].Clusters[clusterName] = make(map[D.RegionName]map[D.ASGName][]D.InstanceID)
+				}
+
+				regionName := D.RegionName(region)
+				if _, present := data[accountName].Clusters[clusterName][regionName]; !present {
+					data[accountName].Clusters[clusterName][regionName] = make(map[D.ASGName][]D.InstanceID)
+				}
+
+				data[accountName].Clusters[clusterName][regionName][D.ASGName(g.Name)] = g.inService()
+			}
+		}
+	}
+
+	return D.NewApp(appName, data), nil
+}
+
+// AppNames returns the names of all apps that have autoscaling groups in the
+// configured accounts and regions
+func (a *AWS) AppNames() ([]string, error) {
+	set := make(map[string]bool)
+	for _, account := range a.accountNames() {
+		for _, region := range a.regions {
+			groups, err := a.autoScalingGroups(account, region, true)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, g := range groups {
+				if names, err := frigga.Parse(g.Name); err == nil {
+					set[names.App] = true
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(set))
+	for name := range set {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// appGroups returns the autoscaling groups of an app in one region of an
+// account, from the cache if cached is true
+func (a *AWS) appGroups(account, region, appName string, cached bool) ([]autoScalingGroup, error) {
+	groups, err := a.autoScalingGroups(account, region, cached)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []autoScalingGroup
+	for _, g := range groups {
+		if names, err := frigga.Parse(g.Name); err == nil && names.App == appName {
+			result = append(result, g)
+		}
+	}
+
+	return result, nil
+}
+
+// autoScalingGroups returns all autoscaling groups in one region of an
+// account. If cached is true, groups retrieved less than groupsTTL ago are
+// returned, since every app of a pass over the apps is looked up in the same
+// list. Retrieved groups are always cached
+func (a *AWS) autoScalingGroups(account, region string, cached bool) ([]autoScalingGroup, error) {
+	key := accountRegion{account, region}
+
+	if cached {
+		a.mu.Lock()
+		c, ok := a.groups[key]
+		a.mu.Unlock()
+
+		if ok && a.now().Sub(c.retrieved) < groupsTTL {
+			return c.groups, nil
+		}
+	}
+
+	retrieved := a.now()
+	groups, err := a.describeAutoScalingGroups(account, region)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.groups[key] = cachedGroups{groups: groups, retrieved: retrieved}
+	a.mu.Unlock()
+
+	return groups, nil
+}
+
+// describeAutoScalingGroups retrieves all autoscaling groups in one region of
+// an account
+func (a *AWS) describeAutoScalingGroups(account, region string) ([]autoScalingGroup, error) {
+	provider, ok := a.accounts[account]
+	if !ok {
+		return nil, errors.Errorf("unknown account: %s", account)
+	}
+
+	creds, err := provider.retrieve()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []autoScalingGroup
+	params := url.Values{}
+	params.Set("Action", "DescribeAutoScalingGroups")
+	params.Set("Version", "2011-01-01")
+	params.Set("MaxRecords", "100")
+
+	for {
+		var resp struct {
+			Groups    []autoScalingGroup `xml:"DescribeAutoScalingGroupsResult>AutoScalingGroups>member"`
+			NextToken string             `xml:"DescribeAutoScalingGroupsResult>NextToken"`
+		}
+
+		err := a.call(creds, region, "autoscaling", params, &resp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not describe autoscaling groups in account:%s region:%s", account, region)
+		}
+
+		result = append(result, resp.Groups...)
+
+		if resp.NextToken == "" {
+			break
+		}
+		params.Set("NextToken", resp.NextToken)
+	}
+
+	return result, nil
+}
+
+// call sends a Query API request to an AWS service, and decodes the XML
+// response into result
+func (a *AWS) call(creds credentials, region, service string, params url.Values, result interface{}) (err error) {
+	u := a.endpoint
+	if u == "" {
+		u = fmt.Sprintf("https://%s.%s.amazonaws.com", service, region)
+	}
+	u += "/"
+
+	payload := []byte(params.Encode())
+	req, err := http.NewRequest("POST", u, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrapf(err, "could not create request to %s", u)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	sign(req, payload, creds, region, service, a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s failed", params.Get("Action"), u)
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "failed to close response body from %s", u)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "body read failed at %s", u)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Code    string `xml:"Error>Code"`
+			Message string `xml:"Error>Message"`
+		}
+		if xml.Unmarshal(body, &failure) == nil && failure.Code != "" {
+			return errors.Errorf("%s failed: %s: %s", params.Get("Action"), failure.Code, failure.Message)
+		}
+		return errors.Errorf("unexpected response code (%d) from %s: %s", resp.StatusCode, u, body)
+	}
+
+	if err := xml.Unmarshal(body, result); err != nil {
+		return errors.Wrapf(err, "could not parse %s response", params.Get("Action"))
+	}
+
+	return nil
+}
@@ -0,0 +1,404 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	D "github.com/Netflix/chaosmonkey/deploy"
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+// group is an autoscaling group served by fakeAWS
+type group struct {
+	name      string
+	instances map[string]string // instance id -> lifecycle state
+	tags      map[string]string
+	suspended []string
+}
+
+func (g group) xml() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<member><AutoScalingGroupName>%s</AutoScalingGroupName><Instances>", g.name)
+	for id, state := range g.instances {
+		fmt.Fprintf(&b, "<member><InstanceId>%s</InstanceId><LifecycleState>%s</LifecycleState></member>", id, state)
+	}
+	b.WriteString("</Instances><Tags>")
+	for key, value := range g.tags {
+		fmt.Fprintf(&b, "<member><Key>%s</Key><Value>%s</Value></member>", key, value)
+	}
+	b.WriteString("</Tags><SuspendedProcesses>")
+	for _, p := range g.suspended {
+		fmt.Fprintf(&b, "<member><ProcessName>%s</ProcessName></member>", p)
+	}
+	b.WriteString("</SuspendedProcesses></member>")
+	return b.String()
+}
+
+// fakeAWS is a local stand-in for the Auto Scaling and STS APIs. Groups are
+// keyed by the access key id that they are visible to, and returned one
+// per page
+type fakeAWS struct {
+	t      *testing.T
+	groups map[string][]group
+
+	mu         sync.Mutex
+	terminated []string
+	describes  int
+}
+
+// describeCount returns the number of DescribeAutoScalingGroups requests
+// served
+func (f *fakeAWS) describeCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.describes
+}
+
+func (f *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=") {
+		f.t.Errorf("request not signed, Authorization=%q", auth)
+	}
+	accessKey := strings.SplitN(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 Credential="), "/", 2)[0]
+
+	if err := r.ParseForm(); err != nil {
+		f.t.Fatal(err)
+	}
+
+	switch r.PostForm.Get("Action") {
+	case "AssumeRole":
+		if got := r.PostForm.Get("RoleArn"); got != prodRole {
+			f.t.Errorf("got RoleArn=%s, want %s", got, prodRole)
+		}
+		fmt.Fprintf(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>ASIAPROD</AccessKeyId><SecretAccessKey>s</SecretAccessKey><SessionToken>t</SessionToken><Expiration>%s</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`,
+			time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	case "GetCallerIdentity":
+		fmt.Fprint(w, `<GetCallerIdentityResponse><GetCallerIdentityResult><Account>111111111111</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`)
+	case "DescribeAutoScalingGroups":
+		f.mu.Lock()
+		f.describes++
+		f.mu.Unlock()
+
+		groups := f.groups[accessKey]
+		page := 0
+		if token := r.PostForm.Get("NextToken"); token != "" {
+			fmt.Sscanf(token, "page-%d", &page)
+		}
+
+		var members, next string
+		if page < len(groups) {
+			members = groups[page].xml()
+		}
+		if page+1 < len(groups) {
+			next = fmt.Sprintf("<NextToken>page-%d</NextToken>", page+1)
+		}
+		fmt.Fprintf(w, `<DescribeAutoScalingGroupsResponse><DescribeAutoScalingGroupsResult><AutoScalingGroups>%s</AutoScalingGroups>%s</DescribeAutoScalingGroupsResult></DescribeAutoScalingGroupsResponse>`, members, next)
+	case "TerminateInstanceInAutoScalingGroup":
+		if got := r.PostForm.Get("ShouldDecrementDesiredCapacity"); got != "false" {
+			f.t.Errorf("got ShouldDecrementDesiredCapacity=%s, want false", got)
+		}
+		f.mu.Lock()
+		f.terminated = append(f.terminated, accessKey+":"+r.PostForm.Get("InstanceId"))
+		f.mu.Unlock()
+		fmt.Fprint(w, `<TerminateInstanceInAutoScalingGroupResponse><TerminateInstanceInAutoScalingGroupResult><Activity><StatusCode>InProgress</StatusCode></Activity></TerminateInstanceInAutoScalingGroupResult></TerminateInstanceInAutoScalingGroupResponse>`)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `<ErrorResponse><Error><Code>InvalidAction</Code><Message>unknown action</Message></Error></ErrorResponse>`)
+	}
+}
+
+const prodRole = "arn:aws:iam::222222222222:role/chaosmonkey"
+
+func newTestAWS(t *testing.T, f *fakeAWS) (*AWS, func()) {
+	srv := httptest.NewServer(f)
+
+	os.Setenv("AWS_ACCESS_KEY_ID", "AKIDBASE")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	cfg := config.Defaults()
+	cfg.Set(param.AWSEndpoint, srv.URL)
+	cfg.Set(param.AWSRegions, []string{"us-east-1"})
+	cfg.Set(param.AWSAccounts, map[string]string{"test": "", "prod": prodRole})
+
+	a, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a, func() {
+		srv.Close()
+		os.Unsetenv("AWS_ACCESS_KEY_ID")
+		os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	}
+}
+
+func testGroups() map[string][]group {
+	tags := map[string]string{
+		"chaosmonkey:enabled":                        "true",
+		"chaosmonkey:grouping":                       "stack",
+		"chaosmonkey:meanTimeBetweenKillsInWorkDays": "4",
+		"chaosmonkey:minTimeBetweenKillsInWorkDays":  "1",
+		"chaosmonkey:owner":                          "web-team@example.com",
+	}
+
+	return map[string][]group{
+		// prod, accessed with the credentials of prodRole
+		"ASIAPROD": {
+			{name: "web-main-v002", instances: map[string]string{"i-1": "InService", "i-2": "InService", "i-3": "Terminating"}, tags: tags},
+			{name: "web-main-v001", instances: map[string]string{"i-0": "InService"}, tags: tags, suspended: []string{"AddToLoadBalancer"}},
+			{name: "api-v000", instances: map[string]string{"i-9": "InService"}},
+		},
+		// test, accessed with the base credentials
+		"AKIDBASE": {
+			{name: "web-canary", instances: map[string]string{"i-4": "InService"}, tags: tags},
+		},
+	}
+}
+
+func TestGetApp(t *testing.T) {
+	a, cleanup := newTestAWS(t, &fakeAWS{t: t, groups: testGroups()})
+	defer cleanup()
+
+	app, err := a.GetApp("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, account := range app.Accounts() {
+		for _, cluster := range account.Clusters() {
+			for _, asg := range cluster.ASGs() {
+				var ids []string
+				for _, ins := range asg.Instances() {
+					ids = append(ids, ins.ID())
+				}
+				sort.Strings(ids)
+				got = append(got, fmt.Sprintf("%s/%s/%s/%s/%s %v", account.Name(), asg.RegionName(), cluster.StackName(), cluster.Name(), asg.Name(), ids))
+			}
+		}
+	}
+	sort.Strings(got)
+
+	// The disabled web-main-v001 and the terminating i-3 are left out
+	want := []string{
+		"prod/us-east-1/main/web-main/web-main-v002 [i-1 i-2]",
+		"test/us-east-1/canary/web-canary/web-canary [i-4]",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// instanceIDs returns the sorted ids of the instances of an app
+func instanceIDs(app *D.App) []string {
+	var result []string
+	for _, account := range app.Accounts() {
+		for _, cluster := range account.Clusters() {
+			for _, asg := range cluster.ASGs() {
+				for _, ins := range asg.Instances() {
+					result = append(result, ins.ID())
+				}
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Test that a pass over the apps retrieves the autoscaling groups of each
+// region of each account once, and that GetApp, and passes after groupsTTL,
+// retrieve them again
+func TestGroupsCache(t *testing.T) {
+	f := &fakeAWS{t: t, groups: testGroups()}
+	a, cleanup := newTestAWS(t, f)
+	defer cleanup()
+
+	now := time.Now()
+	a.now = func() time.Time { return now }
+
+	// pass returns the instances of web after a pass over web and api
+	pass := func() []string {
+		c := make(chan *D.App)
+		go a.Apps(c, []string{"web", "api"})
+		var result []string
+		for app := range c {
+			if app.Name() == "web" {
+				result = instanceIDs(app)
+			}
+		}
+		return result
+	}
+
+	// fakeAWS serves one group per page, so prod takes 3 requests and test 1
+	before := pass()
+	if got, want := f.describeCount(), 4; got != want {
+		t.Errorf("got %d describe requests after a pass, want %d", got, want)
+	}
+
+	// An instance is launched after the pass
+	f.groups["ASIAPROD"][0].instances["i-5"] = "InService"
+
+	if got := pass(); !reflect.DeepEqual(got, before) {
+		t.Errorf("got instances %v within the ttl, want cached %v", got, before)
+	}
+	if got, want := f.describeCount(), 4; got != want {
+		t.Errorf("got %d describe requests after a second pass, want %d", got, want)
+	}
+
+	app, err := a.GetApp("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := instanceIDs(app), []string{"i-1", "i-2", "i-4", "i-5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got instances %v from GetApp, want %v", got, want)
+	}
+
+	f.groups["ASIAPROD"][0].instances["i-6"] = "InService"
+	now = now.Add(groupsTTL)
+
+	if got, want := pass(), []string{"i-1", "i-2", "i-4", "i-5", "i-6"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got instances %v after the ttl, want %v", got, want)
+	}
+}
+
+func TestAppNames(t *testing.T) {
+	a, cleanup := newTestAWS(t, &fakeAWS{t: t, groups: testGroups()})
+	defer cleanup()
+
+	names, err := a.AppNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := names, []string{"api", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGetConfigFromTags(t *testing.T) {
+	a, cleanup := newTestAWS(t, &fakeAWS{t: t, groups: testGroups()})
+	defer cleanup()
+
+	cfg, err := a.Get("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cfg.Enabled || cfg.Grouping != chaosmonkey.Stack || cfg.MeanTimeBetweenKillsInWorkDays != 4 || cfg.MinTimeBetweenKillsInWorkDays != 1 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	cfg, err = a.Get("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Enabled {
+		t.Error("expected app without tags to be disabled")
+	}
+
+	owner, err := a.OwnerEmail("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := owner, "web-team@example.com"; got != want {
+		t.Errorf("got owner %q, want %q", got, want)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	f := &fakeAWS{t: t, groups: testGroups()}
+	a, cleanup := newTestAWS(t, f)
+	defer cleanup()
+
+	ins := mock.Instance{App: "web", Account: "prod", Region: "us-east-1", ASG: "web-main-v002", InstanceID: "i-1"}
+	err := a.Execute(chaosmonkey.Termination{Instance: ins, Time: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The instance must be terminated with the credentials of the prod role
+	if got, want := f.terminated, []string{"ASIAPROD:i-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got terminations %v, want %v", got, want)
+	}
+}
+
+func TestAccountID(t *testing.T) {
+	a, cleanup := newTestAWS(t, &fakeAWS{t: t})
+	defer cleanup()
+
+	tests := []struct {
+		account, id string
+	}{
+		{"prod", "222222222222"},
+		{"test", "111111111111"},
+	}
+
+	for _, tt := range tests {
+		id, err := a.AccountID(tt.account)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != tt.id {
+			t.Errorf("account=%s: got id %s, want %s", tt.account, id, tt.id)
+		}
+	}
+}
+
+func TestInstanceProfileCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/latest/api/token":
+			fmt.Fprint(w, "imds-token")
+		case r.Header.Get("X-aws-ec2-metadata-token") != "imds-token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprint(w, "chaosmonkey-role")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/chaosmonkey-role":
+			fmt.Fprint(w, `{"AccessKeyId": "ASIAPROFILE", "SecretAccessKey": "s", "Token": "t", "Expiration": "2030-01-01T00:00:00Z"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	defer func(endpoint string) { metadataEndpoint = endpoint }(metadataEndpoint)
+	metadataEndpoint = srv.URL
+
+	creds, err := instanceProfileCredentials(http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if creds.accessKeyID != "ASIAPROFILE" || creds.sessionToken != "t" {
+		t.Errorf("unexpected credentials: %+v", creds)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/spinnaker"
+)
+
+// ownerTag is the name of the tag, after the prefix, that holds the owner
+// email of an app
+const ownerTag = "owner"
+
+// Get implements chaosmonkey.AppConfigGetter.Get
+// The config is read from the tags of the app's autoscaling groups. Each tag
+// that starts with the tag prefix sets the field of the same name in the
+// "chaosMonkey" attribute of a Spinnaker application, e.g.
+//
+//	chaosmonkey:enabled                        true
+//	chaosmonkey:grouping                       cluster
+//	chaosmonkey:meanTimeBetweenKillsInWorkDays 5
+//	chaosmonkey:minTimeBetweenKillsInWorkDays  1
+//	chaosmonkey:exceptions                     [{"account": "test", "stack": "*", "detail": "*", "region": "*"}]
+//
+// An app whose autoscaling groups are not tagged is disabled. All the tagged
+// autoscaling groups of an app must have the same config.
+func (a *AWS) Get(app string) (*chaosmonkey.AppConfig, error) {
+	js, err := a.AppConfigJSON(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if js == nil {
+		cfg := chaosmonkey.NewAppConfig(nil)
+		cfg.Enabled = false
+		return &cfg, nil
+	}
+
+	return spinnaker.AppConfigFromJSON(js)
+}
+
+// AppConfigJSON implements appcfg.Source.AppConfigJSON
+// It returns the config built from the tags of the app's autoscaling groups,
+// or nil if none of them are tagged
+func (a *AWS) AppConfigJSON(app string) ([]byte, error) {
+	groups, err := a.allAppGroups(app)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	for _, g := range groups {
+		fields := make(map[string]interface{})
+		for _, t := range g.Tags {
+			if !strings.HasPrefix(t.Key, a.tagPrefix) || t.Key == a.tagPrefix+ownerTag {
+				continue
+			}
+
+			// Tag values are JSON values, or plain strings such as "cluster"
+			var value interface{}
+			if err := json.Unmarshal([]byte(t.Value), &value); err != nil {
+				value = t.Value
+			}
+			fields[strings.TrimPrefix(t.Key, a.tagPrefix)] = value
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		js, err := json.Marshal(fields)
+		if err != nil {
+			return nil, errors.Wrap(err, "json marshal failed")
+		}
+		set[string(js)] = true
+	}
+
+	switch len(set) {
+	case 0:
+		return nil, nil
+	case 1:
+		for js := range set {
+			return []byte(js), nil
+		}
+	}
+
+	return nil, errors.Errorf("autoscaling groups of app %s have conflicting %s tags", app, a.tagPrefix)
+}
+
+// OwnerEmail implements email.OwnerGetter.OwnerEmail
+// It returns the owner tags of the app's autoscaling groups, comma-separated
+func (a *AWS) OwnerEmail(app string) (string, error) {
+	groups, err := a.allAppGroups(app)
+	if err != nil {
+		return "", err
+	}
+
+	set := make(map[string]bool)
+	for _, g := range groups {
+		if owner, ok := g.tag(a.tagPrefix + ownerTag); ok && owner != "" {
+			set[owner] = true
+		}
+	}
+
+	owners := make([]string, 0, len(set))
+	for owner := range set {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	return strings.Join(owners, ","), nil
+}
+
+// allAppGroups returns the autoscaling groups of an app in every account and
+// region
+func (a *AWS) allAppGroups(app string) ([]autoScalingGroup, error) {
+	var result []autoScalingGroup
+	for _, account := range a.accountNames() {
+		for _, region := range a.regions {
+			groups, err := a.appGroups(account, region, app, true)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, groups...)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, errors.Errorf("no autoscaling groups found for app %s", app)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/deps"
+)
+
+// metadataEndpoint is the base URL of the EC2 instance metadata service
+var metadataEndpoint = "http://169.254.169.254"
+
+// refreshWindow is how long before they expire that temporary credentials
+// are refreshed
+const refreshWindow = 5 * time.Minute
+
+// credentials are AWS credentials. expiration is the zero time for long-term
+// credentials
+type credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	expiration      time.Time
+}
+
+// credentialsProvider retrieves AWS credentials
+type credentialsProvider interface {
+	retrieve() (credentials, error)
+}
+
+// staticCredentials are long-term credentials
+type staticCredentials credentials
+
+func (s staticCredentials) retrieve() (credentials, error) {
+	return credentials(s), nil
+}
+
+// cachingProvider caches temporary credentials returned by fetch until they
+// are about to expire
+type cachingProvider struct {
+	fetch func() (credentials, error)
+
+	mu     sync.Mutex
+	cached credentials
+}
+
+func (c *cachingProvider) retrieve() (credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached.accessKeyID != "" && time.Now().Add(refreshWindow).Before(c.cached.expiration) {
+		return c.cached, nil
+	}
+
+	creds, err := c.fetch()
+	if err != nil {
+		return credentials{}, err
+	}
+
+	c.cached = creds
+	return creds, nil
+}
+
+// baseCredentials returns the credentials that Chaos Monkey runs with, taken
+// from the config file, the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+// environment variables or the EC2 instance profile, in that order
+func baseCredentials(cfg *config.Monkey, client *http.Client) (credentialsProvider, error) {
+	if id := cfg.AWSAccessKeyID(); id != "" {
+		decryptor, err := deps.GetDecryptor(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		secret, err := decryptor.Decrypt(cfg.AWSEncryptedSecretAccessKey())
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decrypt aws secret access key")
+		}
+
+		return staticCredentials{accessKeyID: id, secretAccessKey: secret}, nil
+	}
+
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return staticCredentials{
+			accessKeyID:     id,
+			secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	return &cachingProvider{fetch: func() (credentials, error) {
+		return instanceProfileCredentials(client)
+	}}, nil
+}
+
+// instanceProfileCredentials retrieves the credentials of the EC2 instance
+// profile from the instance metadata service, using IMDSv2
+func instanceProfileCredentials(client *http.Client) (credentials, error) {
+	req, err := http.NewRequest("PUT", metadataEndpoint+"/latest/api/token", nil)
+	if err != nil {
+		return credentials{}, errors.Wrap(err, "could not create metadata token request")
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+
+	token, err := metadata(client, req)
+	if err != nil {
+		return credentials{}, errors.Wrap(err, "no aws credentials configured, and could not get an instance metadata token")
+	}
+
+	get := func(path string) (string, error) {
+		req, err := http.NewRequest("GET", metadataEndpoint+path, nil)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not create request for %s", path)
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+		return metadata(client, req)
+	}
+
+	roles, err := get("/latest/meta-data/iam/security-credentials/")
+	if err != nil {
+		return credentials{}, errors.Wrap(err, "could not retrieve instance profile role")
+	}
+
+	role := strings.TrimSpace(strings.Split(roles, "\n")[0])
+	if role == "" {
+		return credentials{}, errors.New("no instance profile role attached to instance")
+	}
+
+	body, err := get("/latest/meta-data/iam/security-credentials/" + url.PathEscape(role))
+	if err != nil {
+		return credentials{}, errors.Wrap(err, "could not retrieve instance profile credentials")
+	}
+
+	var parsed struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		SecretAccessKey string    `json:"SecretAccessKey"`
+		Token           string    `json:"Token"`
+		Expiration      time.Time `json:"Expiration"`
+	}
+
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return credentials{}, errors.Wrap(err, "could not parse instance profile credentials")
+	}
+
+	return credentials{
+		accessKeyID:     parsed.AccessKeyID,
+		secretAccessKey: parsed.SecretAccessKey,
+		sessionToken:    parsed.Token,
+		expiration:      parsed.Expiration,
+	}, nil
+}
+
+// metadata sends a request to the instance metadata service and returns the
+// response body
+func metadata(client *http.Client, req *http.Request) (body string, err error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "%s %s failed", req.Method, req.URL)
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "failed to close response body from %s", req.URL)
+		}
+	}()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "body read failed at %s", req.URL)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected response code (%d) from %s", resp.StatusCode, req.URL)
+	}
+
+	return string(contents), nil
+}
+
+// assumeRole returns a provider of credentials for roleARN, which are
+// obtained from STS with the base credentials
+func (a *AWS) assumeRole(base credentialsProvider, roleARN string) credentialsProvider {
+	return &cachingProvider{fetch: func() (credentials, error) {
+		creds, err := base.retrieve()
+		if err != nil {
+			return credentials{}, err
+		}
+
+		params := url.Values{}
+		params.Set("Action", "AssumeRole")
+		params.Set("Version", "2011-06-15")
+		params.Set("RoleArn", roleARN)
+		params.Set("RoleSessionName", "chaosmonkey")
+
+		var resp struct {
+			AccessKeyID     string    `xml:"AssumeRoleResult>Credentials>AccessKeyId"`
+			SecretAccessKey string    `xml:"AssumeRoleResult>Credentials>SecretAccessKey"`
+			SessionToken    string    `xml:"AssumeRoleResult>Credentials>SessionToken"`
+			Expiration      time.Time `xml:"AssumeRoleResult>Credentials>Expiration"`
+		}
+
+		err = a.call(creds, a.regions[0], "sts", params, &resp)
+		if err != nil {
+			return credentials{}, errors.Wrapf(err, "could not assume role %s", roleARN)
+		}
+
+		return credentials{
+			accessKeyID:     resp.AccessKeyID,
+			secretAccessKey: resp.SecretAccessKey,
+			sessionToken:    resp.SessionToken,
+			expiration:      resp.Expiration,
+		}, nil
+	}}
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestSignKnownAnswer checks the signature of the example request in the AWS
+// Signature Version 4 documentation
+func TestSignKnownAnswer(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	creds := credentials{accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC)
+
+	sign(req, nil, creds, "us-east-1", "iam", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("got Authorization:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSignSessionToken(t *testing.T) {
+	req, err := http.NewRequest("POST", "https://autoscaling.us-east-1.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	creds := credentials{accessKeyID: "ASIAEXAMPLE", secretAccessKey: "secret", sessionToken: "token"}
+	sign(req, []byte("Action=DescribeAutoScalingGroups"), creds, "us-east-1", "autoscaling", time.Now())
+
+	if got, want := req.Header.Get("X-Amz-Security-Token"), "token"; got != want {
+		t.Errorf("got X-Amz-Security-Token=%q, want %q", got, want)
+	}
+}
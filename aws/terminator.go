@@ -0,0 +1,57 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+)
+
+// Execute implements chaosmonkey.Terminator.Execute
+// The instance is terminated without decrementing the desired capacity of its
+// autoscaling group, so that it is replaced
+func (a *AWS) Execute(trm chaosmonkey.Termination) error {
+	ins := trm.Instance
+
+	provider, ok := a.accounts[ins.AccountName()]
+	if !ok {
+		return errors.Errorf("unknown account: %s", ins.AccountName())
+	}
+
+	creds, err := provider.retrieve()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("Action", "TerminateInstanceInAutoScalingGroup")
+	params.Set("Version", "2011-01-01")
+	params.Set("InstanceId", ins.ID())
+	params.Set("ShouldDecrementDesiredCapacity", "false")
+
+	var resp struct {
+		StatusCode string `xml:"TerminateInstanceInAutoScalingGroupResult>Activity>StatusCode"`
+	}
+
+	err = a.call(creds, ins.RegionName(), "autoscaling", params, &resp)
+	if err != nil {
+		return errors.Wrapf(err, "failed to terminate instance %s", ins.ID())
+	}
+
+	return nil
+}
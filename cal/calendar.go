@@ -0,0 +1,627 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cal
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+)
+
+// maxDaysAhead bounds the search for the next day that a kill is allowed
+const maxDaysAhead = 10 * 366
+
+// date is a day in the calendar, independent of time zone
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// dateOf returns the date of t in the location of t
+func dateOf(t time.Time) date {
+	year, month, day := t.Date()
+	return date{year, month, day}
+}
+
+// parseDate parses a date in the format 2006-01-02 or, if compact is true,
+// 20060102
+func parseDate(s string, compact bool) (date, error) {
+	layout := "2006-01-02"
+	if compact {
+		layout = "20060102"
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return date{}, err
+	}
+
+	return dateOf(t), nil
+}
+
+// addDays returns the date n days after d
+func (d date) addDays(n int) date {
+	return dateOf(time.Date(d.year, d.month, d.day+n, 12, 0, 0, 0, time.UTC))
+}
+
+// after returns true if d is after o
+func (d date) after(o date) bool {
+	if d.year != o.year {
+		return d.year > o.year
+	}
+	if d.month != o.month {
+		return d.month > o.month
+	}
+	return d.day > o.day
+}
+
+// yearly is a holiday that falls on the same day every year, from year
+// first until year last
+type yearly struct {
+	month       time.Month
+	day         int
+	first, last int
+}
+
+// Calendar is a set of holidays and the days of the work week. A day is a
+// work day if it is in the work week and is not a holiday. The zero Calendar
+// has no holidays, and a work week from Monday to Friday
+type Calendar struct {
+	holidays map[date]bool
+	yearly   []yearly
+
+	// workdays are the days of the work week. If nil, Monday to Friday
+	workdays []time.Weekday
+}
+
+// WithWorkdays returns a copy of c with a work week of the given days
+func (c Calendar) WithWorkdays(days []time.Weekday) Calendar {
+	c.workdays = days
+	return c
+}
+
+// inWorkWeek returns true if the date associated with t is a day of the
+// work week
+func (c Calendar) inWorkWeek(t time.Time) bool {
+	if c.workdays == nil {
+		return isWeekday(t)
+	}
+
+	for _, day := range c.workdays {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHoliday returns true if the date associated with t is a holiday
+// Uses the location associated with t to make this calculation
+func (c Calendar) IsHoliday(t time.Time) bool {
+	d := dateOf(t)
+	if c.holidays[d] {
+		return true
+	}
+
+	for _, y := range c.yearly {
+		if y.month == d.month && y.day == d.day && d.year >= y.first && d.year <= y.last {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsWorkday returns true if the date associated with t is a day of the work
+// week that is not a holiday
+// Uses the location associated with t to make this calculation
+func (c Calendar) IsWorkday(t time.Time) bool {
+	return c.inWorkWeek(t) && !c.IsHoliday(t)
+}
+
+// NextKillAllowed returns the earliest time at which a kill is permitted on a
+// work day, given that the previous kill was at lastKill and that there must
+// be days work days between kills. It is the inverse of NoKillsSince
+func (c Calendar) NextKillAllowed(days int, lastKill time.Time, endHour int, loc *time.Location) (time.Time, error) {
+	last := lastKill.In(loc)
+	year, month, day := last.Date()
+
+	for i := 0; i < maxDaysAhead; i++ {
+		midnight := time.Date(year, month, day+i, 0, 0, 0, 0, loc)
+		if !c.IsWorkday(midnight) {
+			continue
+		}
+
+		// The threshold only depends on the date, not on the time of day.
+		// Noon is used since stepping back a day at a time from midnight is
+		// off by one across a daylight saving time change
+		noon := time.Date(year, month, day+i, 12, 0, 0, 0, loc)
+		threshold, err := c.NoKillsSince(days, noon, endHour, loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if threshold.After(lastKill) {
+			if midnight.Before(lastKill) {
+				return lastKill, nil
+			}
+			return midnight, nil
+		}
+	}
+
+	return time.Time{}, errors.Errorf("no kill allowed within %d days of %s", maxDaysAhead, lastKill)
+}
+
+// merge returns a calendar with the holidays of both c and o, and the work
+// week of o if it has one, otherwise the work week of c
+func (c Calendar) merge(o Calendar) Calendar {
+	result := Calendar{holidays: make(map[date]bool, len(c.holidays)+len(o.holidays)), workdays: c.workdays}
+	for _, cal := range []Calendar{c, o} {
+		for d := range cal.holidays {
+			result.holidays[d] = true
+		}
+		result.yearly = append(result.yearly, cal.yearly...)
+	}
+	if o.workdays != nil {
+		result.workdays = o.workdays
+	}
+	return result
+}
+
+// Load loads a calendar from a file. The file is either in iCalendar format,
+// or a list of dates. See Parse
+func Load(path string) (Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Calendar{}, errors.Wrapf(err, "failed to open calendar %s", path)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	c, err := Parse(f)
+	if err != nil {
+		return Calendar{}, errors.Wrapf(err, "failed to parse calendar %s", path)
+	}
+
+	return c, nil
+}
+
+// Parse parses a calendar. If it starts with BEGIN:VCALENDAR, it is parsed
+// as iCalendar (RFC 5545), where every event is a holiday. Otherwise, it is a
+// list of dates, one per line, like:
+//
+//	# comments and blank lines are ignored
+//	2016-12-26 Boxing day
+//	2016-12-24..2017-01-02 company shutdown
+//
+// Text after a date is ignored
+func Parse(r io.Reader) (Calendar, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return Calendar{}, errors.Wrap(err, "failed to read calendar")
+	}
+
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+		if line == "" {
+			continue
+		}
+		if strings.EqualFold(line, "BEGIN:VCALENDAR") {
+			return parseICS(lines)
+		}
+		break
+	}
+
+	return parseDates(lines)
+}
+
+// parseDates parses a list of dates or date ranges
+func parseDates(lines []string) (Calendar, error) {
+	c := Calendar{holidays: make(map[date]bool)}
+
+	for i, line := range lines {
+		if j := strings.Index(line, "#"); j >= 0 {
+			line = line[:j]
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "\ufeff"))
+		if len(fields) == 0 {
+			continue
+		}
+
+		bounds := strings.SplitN(fields[0], "..", 2)
+		first, err := parseDate(bounds[0], false)
+		if err != nil {
+			return Calendar{}, errors.Errorf("line %d: invalid date %q, want YYYY-MM-DD", i+1, bounds[0])
+		}
+
+		last := first
+		if len(bounds) == 2 {
+			last, err = parseDate(bounds[1], false)
+			if err != nil {
+				return Calendar{}, errors.Errorf("line %d: invalid date %q, want YYYY-MM-DD", i+1, bounds[1])
+			}
+			if first.after(last) {
+				return Calendar{}, errors.Errorf("line %d: range %s ends before it starts", i+1, fields[0])
+			}
+		}
+
+		for d := first; !d.after(last); d = d.addDays(1) {
+			c.holidays[d] = true
+		}
+	}
+
+	return c, nil
+}
+
+// event is an iCalendar event
+type event struct {
+	start, end string // values of DTSTART and DTEND
+	endIsDate  bool   // true if DTEND is a date, rather than a date-time
+	duration   string
+	rrule      string
+	cancelled  bool
+	line       int // line of BEGIN:VEVENT
+	inEvent    bool
+}
+
+// parseICS parses the events of an iCalendar file as holidays. Each day that
+// an event spans is a holiday, on the date as written in the event, and
+// events that recur every year are holidays every year
+func parseICS(lines []string) (Calendar, error) {
+	c := Calendar{holidays: make(map[date]bool)}
+
+	// Long lines are folded: continuation lines start with a space or tab
+	type unfolded struct {
+		text string
+		line int
+	}
+	var props []unfolded
+	for i, line := range lines {
+		if len(props) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			props[len(props)-1].text += line[1:]
+			continue
+		}
+		props = append(props, unfolded{line, i + 1})
+	}
+
+	var ev event
+	for _, prop := range props {
+		name, params, value := splitProperty(prop.text)
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			ev = event{line: prop.line, inEvent: true}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if !ev.cancelled {
+				if err := c.addEvent(ev); err != nil {
+					return Calendar{}, errors.Wrapf(err, "event at line %d", ev.line)
+				}
+			}
+			ev = event{}
+		case !ev.inEvent:
+			// Properties of the calendar, or of components other than events
+		case name == "DTSTART":
+			ev.start = value
+		case name == "DTEND":
+			ev.end = value
+			ev.endIsDate = strings.Contains(strings.ToUpper(params), "VALUE=DATE") && !strings.Contains(strings.ToUpper(params), "VALUE=DATE-TIME")
+		case name == "DURATION":
+			ev.duration = value
+		case name == "RRULE":
+			ev.rrule = value
+		case name == "STATUS":
+			ev.cancelled = strings.EqualFold(value, "CANCELLED")
+		}
+	}
+
+	return c, nil
+}
+
+// splitProperty splits an iCalendar content line into its name, parameters
+// and value
+func splitProperty(line string) (name, params, value string) {
+	inQuotes := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ':' && !inQuotes:
+			name, value = line[:i], line[i+1:]
+			if j := strings.Index(name, ";"); j >= 0 {
+				name, params = name[:j], name[j+1:]
+			}
+			return strings.ToUpper(strings.TrimSpace(name)), params, strings.TrimSpace(value)
+		}
+	}
+
+	return strings.ToUpper(strings.TrimSpace(line)), "", ""
+}
+
+// addEvent adds the days of an event to c
+func (c *Calendar) addEvent(ev event) error {
+	if len(ev.start) < 8 {
+		return errors.Errorf("invalid DTSTART %q", ev.start)
+	}
+
+	first, err := parseDate(ev.start[:8], true)
+	if err != nil {
+		return errors.Errorf("invalid DTSTART %q", ev.start)
+	}
+
+	last, err := lastDay(ev, first)
+	if err != nil {
+		return err
+	}
+
+	var days []date
+	for d := first; !d.after(last); d = d.addDays(1) {
+		days = append(days, d)
+	}
+
+	if ev.rrule == "" {
+		for _, d := range days {
+			c.holidays[d] = true
+		}
+		return nil
+	}
+
+	lastYear, err := lastYear(ev.rrule, first.year)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range days {
+		c.yearly = append(c.yearly, yearly{month: d.month, day: d.day, first: d.year, last: lastYear + d.year - first.year})
+	}
+
+	return nil
+}
+
+// lastDay returns the last day of an event that starts on first
+func lastDay(ev event, first date) (date, error) {
+	dateOnly := len(ev.start) == 8
+
+	switch {
+	case ev.end != "":
+		if len(ev.end) < 8 {
+			return date{}, errors.Errorf("invalid DTEND %q", ev.end)
+		}
+		end, err := parseDate(ev.end[:8], true)
+		if err != nil {
+			return date{}, errors.Errorf("invalid DTEND %q", ev.end)
+		}
+
+		// The end is exclusive, so an event that ends at the start of a day
+		// does not include that day
+		if len(ev.end) == 8 || ev.endIsDate || strings.HasPrefix(ev.end[8:], "T000000") {
+			end = end.addDays(-1)
+		}
+		if first.after(end) {
+			return first, nil
+		}
+		return end, nil
+	case ev.duration != "":
+		days, err := durationDays(ev.duration)
+		if err != nil {
+			return date{}, err
+		}
+		if dateOnly && days > 0 {
+			days--
+		}
+		return first.addDays(days), nil
+	default:
+		return first, nil
+	}
+}
+
+// durationDays returns the number of whole days of an iCalendar duration,
+// like P1D or P2W
+func durationDays(duration string) (int, error) {
+	s := strings.TrimPrefix(strings.ToUpper(duration), "+")
+	if !strings.HasPrefix(s, "P") {
+		return 0, errors.Errorf("invalid DURATION %q", duration)
+	}
+	s = s[1:]
+	if i := strings.Index(s, "T"); i >= 0 {
+		// Hours, minutes and seconds do not add days
+		s = s[:i]
+	}
+
+	days := 0
+	for s != "" {
+		i := strings.IndexAny(s, "DW")
+		if i <= 0 {
+			return 0, errors.Errorf("invalid DURATION %q", duration)
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, errors.Errorf("invalid DURATION %q", duration)
+		}
+		if s[i] == 'W' {
+			n *= 7
+		}
+		days += n
+		s = s[i+1:]
+	}
+
+	return days, nil
+}
+
+// lastYear returns the last year of a yearly recurrence rule for an event
+// that starts in year first. Only yearly rules are supported, since
+// holidays on the same date every year are the only ones that can be
+// expressed without knowing the rules of the holiday
+func lastYear(rrule string, first int) (int, error) {
+	last := maxYear
+	freq := ""
+
+	for _, part := range strings.Split(rrule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return 0, errors.Errorf("invalid RRULE %q", rrule)
+		}
+
+		switch key, value := strings.ToUpper(kv[0]), kv[1]; key {
+		case "FREQ":
+			freq = strings.ToUpper(value)
+		case "INTERVAL":
+			if value != "1" {
+				return 0, errors.Errorf("unsupported RRULE %q: only yearly recurrence is supported", rrule)
+			}
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return 0, errors.Errorf("invalid RRULE %q", rrule)
+			}
+			last = first + n - 1
+		case "UNTIL":
+			if len(value) < 8 {
+				return 0, errors.Errorf("invalid RRULE %q", rrule)
+			}
+			until, err := parseDate(value[:8], true)
+			if err != nil {
+				return 0, errors.Errorf("invalid RRULE %q", rrule)
+			}
+			last = until.year
+		default:
+			return 0, errors.Errorf("unsupported RRULE %q: only yearly recurrence is supported", rrule)
+		}
+	}
+
+	if freq != "YEARLY" {
+		return 0, errors.Errorf("unsupported RRULE %q: only yearly recurrence is supported", rrule)
+	}
+
+	return last, nil
+}
+
+// maxYear is the last year of recurrences that do not end
+const maxYear = 9999
+
+// Calendars are the calendars of each account and region. The calendar of
+// an account in a region has the holidays of the default calendar, of the
+// account's calendar and of the region's calendar. Its work week is the
+// region's if set, otherwise the account's if set, otherwise the default
+type Calendars struct {
+	def      Calendar
+	accounts map[string]Calendar
+	regions  map[string]Calendar
+}
+
+// For returns the calendar of a region of an account. Either may be blank
+func (cs Calendars) For(account, region string) Calendar {
+	c := cs.def
+	if ac, ok := cs.accounts[account]; ok {
+		c = c.merge(ac)
+	}
+	if rc, ok := cs.regions[region]; ok {
+		c = c.merge(rc)
+	}
+	return c
+}
+
+// NewCalendars returns calendars from the files at the given paths. Any path
+// may be blank
+func NewCalendars(defaultPath string, accountPaths, regionPaths map[string]string) (Calendars, error) {
+	var cs Calendars
+	var err error
+
+	if defaultPath != "" {
+		cs.def, err = Load(defaultPath)
+		if err != nil {
+			return Calendars{}, err
+		}
+	}
+
+	cs.accounts, err = loadAll(accountPaths)
+	if err != nil {
+		return Calendars{}, err
+	}
+
+	cs.regions, err = loadAll(regionPaths)
+	if err != nil {
+		return Calendars{}, err
+	}
+
+	return cs, nil
+}
+
+// loadAll loads a calendar for each key of paths
+func loadAll(paths map[string]string) (map[string]Calendar, error) {
+	result := make(map[string]Calendar, len(paths))
+	for key, path := range paths {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = c
+	}
+	return result, nil
+}
+
+// NewFromConfig returns the calendars configured in cfg, with the holidays
+// of the [calendar] section and the work weeks of the [chaosmonkey] and
+// [business_hours] sections
+func NewFromConfig(cfg *config.Monkey) (Calendars, error) {
+	cs, err := NewCalendars(cfg.CalendarHolidays(), cfg.CalendarAccounts(), cfg.CalendarRegions())
+	if err != nil {
+		return Calendars{}, errors.Wrap(err, "could not load holiday calendars")
+	}
+
+	workdays, err := cfg.Workdays()
+	if err != nil {
+		return Calendars{}, err
+	}
+	cs.def = cs.def.WithWorkdays(workdays)
+
+	accounts, err := cfg.AccountHours()
+	if err != nil {
+		return Calendars{}, err
+	}
+	cs.accounts = withWorkdays(cs.accounts, accounts)
+
+	regions, err := cfg.RegionHours()
+	if err != nil {
+		return Calendars{}, err
+	}
+	cs.regions = withWorkdays(cs.regions, regions)
+
+	return cs, nil
+}
+
+// withWorkdays sets the work weeks of the calendars that have one in hours.
+// Calendars are added for keys of hours that have none
+func withWorkdays(calendars map[string]Calendar, hours map[string]config.HoursOverride) map[string]Calendar {
+	for key, o := range hours {
+		if o.Workdays != nil {
+			calendars[key] = calendars[key].WithWorkdays(o.Workdays)
+		}
+	}
+	return calendars
+}
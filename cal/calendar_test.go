@@ -0,0 +1,301 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cal_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey/cal"
+)
+
+// day returns noon on a date in UTC, in the format 2006-01-02
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s+" 12:00")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseDateList(t *testing.T) {
+	c, err := cal.Parse(strings.NewReader(`
+# US holidays
+2016-11-24 Thanksgiving
+2016-11-25
+
+2016-12-23..2016-12-27 # shutdown
+`))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	tests := []struct {
+		date    string
+		holiday bool
+		workday bool
+	}{
+		{"2016-11-23", false, true},
+		{"2016-11-24", true, false},
+		{"2016-11-25", true, false},
+		{"2016-11-26", false, false}, // Saturday
+		{"2016-12-22", false, true},
+		{"2016-12-23", true, false},
+		{"2016-12-26", true, false},
+		{"2016-12-27", true, false},
+		{"2016-12-28", false, true},
+	}
+
+	for _, tt := range tests {
+		if got, want := c.IsHoliday(day(tt.date)), tt.holiday; got != want {
+			t.Errorf("IsHoliday(%s)=%t, want %t", tt.date, got, want)
+		}
+		if got, want := c.IsWorkday(day(tt.date)), tt.workday; got != want {
+			t.Errorf("IsWorkday(%s)=%t, want %t", tt.date, got, want)
+		}
+	}
+}
+
+func TestParseDateListErrors(t *testing.T) {
+	tests := []string{
+		"11/24/2016",
+		"2016-11-24..",
+		"2016-12-27..2016-12-23",
+	}
+
+	for _, tt := range tests {
+		if _, err := cal.Parse(strings.NewReader(tt)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", tt)
+		}
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	ics := strings.Replace(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//Holidays//EN
+BEGIN:VEVENT
+UID:1
+DTSTART;VALUE=DATE:20161124
+DTEND;VALUE=DATE:20161126
+SUMMARY:Thanksgiving
+ break
+END:VEVENT
+BEGIN:VEVENT
+UID:2
+DTSTART;VALUE=DATE:20151225
+RRULE:FREQ=YEARLY
+SUMMARY:Christmas
+END:VEVENT
+BEGIN:VEVENT
+UID:3
+DTSTART;VALUE=DATE:20150101
+DURATION:P1D
+RRULE:FREQ=YEARLY;COUNT=2
+SUMMARY:New year
+END:VEVENT
+BEGIN:VEVENT
+UID:4
+DTSTART;TZID=America/Los_Angeles:20161003T090000
+DTEND;TZID=America/Los_Angeles:20161005T000000
+SUMMARY:Offsite
+END:VEVENT
+BEGIN:VEVENT
+UID:5
+DTSTART;VALUE=DATE:20161010
+STATUS:CANCELLED
+END:VEVENT
+END:VCALENDAR
+`, "\n", "\r\n", -1)
+
+	c, err := cal.Parse(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	tests := []struct {
+		date    string
+		holiday bool
+	}{
+		{"2016-11-23", false},
+		{"2016-11-24", true},
+		{"2016-11-25", true},
+		{"2016-11-26", false}, // DTEND is exclusive
+		{"2014-12-25", false}, // before the first occurrence
+		{"2015-12-25", true},
+		{"2020-12-25", true},
+		{"2015-01-01", true},
+		{"2016-01-01", true},
+		{"2017-01-01", false}, // after COUNT occurrences
+		{"2016-10-03", true},
+		{"2016-10-04", true},
+		{"2016-10-05", false}, // ends at midnight
+		{"2016-10-10", false}, // cancelled
+	}
+
+	for _, tt := range tests {
+		if got, want := c.IsHoliday(day(tt.date)), tt.holiday; got != want {
+			t.Errorf("IsHoliday(%s)=%t, want %t", tt.date, got, want)
+		}
+	}
+}
+
+func TestParseICSUnsupportedRule(t *testing.T) {
+	ics := `BEGIN:VCALENDAR
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20161124
+RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH
+END:VEVENT
+END:VCALENDAR
+`
+	if _, err := cal.Parse(strings.NewReader(ics)); err == nil {
+		t.Error("Parse succeeded, want error for rule that does not fall on the same date every year")
+	}
+}
+
+func TestCalendarNoKillsSince(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// Friday Nov 25 2016 is a holiday, so it does not count as a work day
+	c, err := cal.Parse(strings.NewReader("2016-11-25\n"))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	now := time.Date(2016, time.November, 28, 10, 0, 0, 0, loc) // Monday
+	got, err := c.NoKillsSince(1, now, 15, loc)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if want := time.Date(2016, time.November, 24, 15, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestNextKillAllowed(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	c, err := cal.Parse(strings.NewReader("2016-11-24..2016-11-25\n"))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	tests := []struct {
+		days int
+		last time.Time
+		want time.Time
+	}{
+		// Same day kills are allowed
+		{0, time.Date(2016, time.November, 21, 11, 0, 0, 0, loc), time.Date(2016, time.November, 21, 11, 0, 0, 0, loc)},
+
+		// One kill per work day
+		{1, time.Date(2016, time.November, 21, 11, 0, 0, 0, loc), time.Date(2016, time.November, 22, 0, 0, 0, 0, loc)},
+
+		// Skips the holidays and the weekend
+		{1, time.Date(2016, time.November, 23, 11, 0, 0, 0, loc), time.Date(2016, time.November, 28, 0, 0, 0, 0, loc)},
+		{2, time.Date(2016, time.November, 22, 11, 0, 0, 0, loc), time.Date(2016, time.November, 28, 0, 0, 0, 0, loc)},
+
+		// Across a daylight saving time change
+		{1, time.Date(2017, time.March, 10, 11, 0, 0, 0, loc), time.Date(2017, time.March, 13, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		got, err := c.NextKillAllowed(tt.days, tt.last, 15, loc)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("NextKillAllowed(%d, %s)=%s, want %s", tt.days, tt.last, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarsFor(t *testing.T) {
+	cs, err := cal.NewCalendars("testdata/holidays.txt",
+		map[string]string{"prod": "testdata/prod.txt"},
+		map[string]string{"eu-west-1": "testdata/eu-west-1.ics"})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	tests := []struct {
+		account, region, date string
+		holiday               bool
+	}{
+		{"", "", "2016-12-26", true},
+		{"", "", "2016-11-30", false},
+		{"test", "us-east-1", "2016-11-30", false},
+		{"prod", "us-east-1", "2016-11-30", true},
+		{"prod", "us-east-1", "2016-12-26", true},
+		{"test", "eu-west-1", "2016-12-01", true},
+		{"test", "us-east-1", "2016-12-01", false},
+	}
+
+	for _, tt := range tests {
+		if got, want := cs.For(tt.account, tt.region).IsHoliday(day(tt.date)), tt.holiday; got != want {
+			t.Errorf("For(%q, %q).IsHoliday(%s)=%t, want %t", tt.account, tt.region, tt.date, got, want)
+		}
+	}
+}
+
+func TestNewCalendarsMissingFile(t *testing.T) {
+	if _, err := cal.NewCalendars("testdata/missing.txt", nil, nil); err == nil {
+		t.Error("NewCalendars succeeded, want error")
+	}
+}
+
+func TestCalendarWorkdays(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Jerusalem")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	c := cal.Calendar{}.WithWorkdays([]time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday})
+
+	tests := []struct {
+		date    string
+		workday bool
+	}{
+		{"2016-11-18", false}, // Friday
+		{"2016-11-19", false}, // Saturday
+		{"2016-11-20", true},  // Sunday
+		{"2016-11-24", true},  // Thursday
+	}
+
+	for _, tt := range tests {
+		if got, want := c.IsWorkday(day(tt.date)), tt.workday; got != want {
+			t.Errorf("IsWorkday(%s)=%t, want %t", tt.date, got, want)
+		}
+	}
+
+	// The work day before Sunday is Thursday
+	now := time.Date(2016, time.November, 20, 10, 0, 0, 0, loc)
+	got, err := c.NoKillsSince(1, now, 15, loc)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if want := time.Date(2016, time.November, 17, 15, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -124,6 +124,15 @@ type (
 		Get(app string) (*AppConfig, error)
 	}
 
+	// AccountAppConfigGetter retrieves App configuration info that can differ
+	// between the accounts that an app is deployed in
+	AccountAppConfigGetter interface {
+		AppConfigGetter
+
+		// GetAccount returns the App config info of an app in an account
+		GetAccount(app, account string) (*AppConfig, error)
+	}
+
 	// Checker checks to see if a termination is permitted given min time between terminations
 	//
 	// if the termination is permitted, returns (true, nil)
@@ -140,12 +149,37 @@ type (
 		Check(term Termination, appCfg AppConfig, endHour int, loc *time.Location) error
 	}
 
+	// Outcome is the result of executing a termination
+	Outcome struct {
+		Succeeded bool
+		TaskID    string // id of the backend task that executed the termination, if any
+	}
+
+	// OutcomeRecorder records the outcome of terminations that were recorded
+	// by a Checker
+	OutcomeRecorder interface {
+		// RecordOutcome records the outcome of a termination after it was
+		// executed
+		RecordOutcome(term Termination, outcome Outcome) error
+	}
+
 	// Terminator provides an interface for killing instances
 	Terminator interface {
 		// Kill terminates a running instance
 		Execute(trm Termination) error
 	}
 
+	// TaskTerminator is a Terminator that terminates instances by running a
+	// task in the backend, e.g. Spinnaker
+	TaskTerminator interface {
+		Terminator
+
+		// ExecuteTask terminates a running instance, and waits for the task
+		// to complete. It returns the id of the task, even if the task
+		// failed, or blank if no task was created
+		ExecuteTask(trm Termination) (taskID string, err error)
+	}
+
 	// Outage provides an interface for checking if there is currently an outage
 	// This provides a mechanism to check if there's an ongoing outage, since
 	// Chaos Monkey doesn't run during outages
@@ -154,6 +188,15 @@ type (
 		Outage() (bool, error)
 	}
 
+	// ScopedOutage is implemented by outage checkers that can tell whether an
+	// ongoing outage affects a particular account and region. If the outage
+	// checker implements it, it is used instead of Outage when terminating
+	ScopedOutage interface {
+		// OutageIn returns true if there is an ongoing outage that affects
+		// account and region. region may be blank, meaning any region
+		OutageIn(account, region string) (bool, error)
+	}
+
 	// ErrViolatesMinTime represents an error when trying to record a termination
 	// that violates the min time between terminations for that particular app
 	ErrViolatesMinTime struct {
@@ -191,6 +234,15 @@ func NewAppConfig(exceptions []Exception) AppConfig {
 	return result
 }
 
+// GetAppConfig returns the App config info of an app in an account. If g
+// does not support per-account configs, the config of the app is returned
+func GetAppConfig(g AppConfigGetter, app, account string) (*AppConfig, error) {
+	if ag, ok := g.(AccountAppConfigGetter); ok {
+		return ag.GetAccount(app, account)
+	}
+	return g.Get(app)
+}
+
 // Matches returns true if an exception matches an ASG
 func (ex Exception) Matches(account, stack, detail, region string) bool {
 	return exFieldMatches(ex.Account, account) &&
@@ -0,0 +1,63 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/appcfg"
+	"github.com/Netflix/chaosmonkey/aws"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/deploy"
+	"github.com/Netflix/chaosmonkey/email"
+	"github.com/Netflix/chaosmonkey/file"
+	"github.com/Netflix/chaosmonkey/kubernetes"
+	"github.com/Netflix/chaosmonkey/spinnaker"
+)
+
+// backend is the platform that Chaos Monkey discovers instances on and
+// terminates them with
+type backend interface {
+	deploy.Deployment
+	chaosmonkey.Terminator
+	chaosmonkey.AppConfigGetter
+	appcfg.Source
+	email.OwnerGetter
+
+	// AccountID returns the cloud account id of an account
+	AccountID(name string) (string, error)
+
+	// CloudProvider returns the cloud provider of an account
+	CloudProvider(account string) (string, error)
+}
+
+// getBackend returns the backend configured by chaosmonkey.backend
+func getBackend(cfg *config.Monkey) (backend, error) {
+	kind := cfg.Backend()
+	switch kind {
+	case "spinnaker":
+		return spinnaker.NewFromConfig(cfg)
+	case "kubernetes":
+		return kubernetes.NewFromConfig(cfg)
+	case "aws":
+		return aws.NewFromConfig(cfg)
+	case "file":
+		return file.NewFromConfig(cfg)
+	default:
+		return nil, errors.Errorf("unsupported %s: %s", param.Backend, kind)
+	}
+}
@@ -26,14 +26,16 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/appcfg"
 	"github.com/Netflix/chaosmonkey/clock"
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/config/param"
 	"github.com/Netflix/chaosmonkey/deps"
-	"github.com/Netflix/chaosmonkey/mysql"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/killswitch"
+	"github.com/Netflix/chaosmonkey/metrics"
 	"github.com/Netflix/chaosmonkey/schedstore"
 	"github.com/Netflix/chaosmonkey/schedule"
-	"github.com/Netflix/chaosmonkey/spinnaker"
 )
 
 // Version is the version number
@@ -57,7 +59,12 @@ Chaos Monkey
 Usage:
 	chaosmonkey <command> ...
 
-command: migrate | schedule | terminate | fetch-schedule | outage | config  | email | eligible | intest
+command: migrate | schedule | terminate | fetch-schedule | daemon | stop | resume | history | simulate | outage | config | encrypt | email | eligible | intest
+
+--backend=<backend>    Optionally override chaosmonkey.backend in the config file
+                       ("spinnaker", "kubernetes", "aws" or "file"). The "file"
+                       backend reads apps from file.path instead of a network
+                       service, and can only be used leashed.
 
 Install
 -------
@@ -67,8 +74,8 @@ migrate
 -------
 Applies database migration to the database defined in the configuraton file.
 
-schedule [--max-apps=<N>] [--apps=foo,bar,baz] [--no-record-schedule]
---------------------------------------------------------------------
+schedule [--max-apps=<N>] [--apps=foo,bar,baz] [--no-record-schedule] [--seed=<N>] [--date=<date>]
+------------------------------------------------------------------------------------------------
 Generates a schedule of terminations for the day and installs the
 terminations as local cron jobs that call "chaosmonkey terminate ..."
 
@@ -81,6 +88,17 @@ terminations as local cron jobs that call "chaosmonkey terminate ..."
 --no-record-schedule   Do not record the schedule with the database.
                        This is primarily used for debugging.
 
+--seed=<N>             Generate the schedule from the given seed instead of a
+                       random one. The seed of each recorded schedule is stored
+                       in the database, so passing it along with --date
+                       reproduces that schedule, given the same deployment and
+                       configs. This is primarily used for debugging.
+
+--date=<date>          Regenerate the schedule of a date, e.g. 2016-11-16, as if
+                       the scheduler ran at the time chaosmonkey.cron_expression
+                       fires on that date, and print its terminations instead
+                       of recording and installing them.
+
 
 terminate <app> <account> [--region=<region>] [--stack=<stack>] [--cluster=<cluster>] [--leashed]
 -----------------------------------------------------------------------------------------------------------------
@@ -98,15 +116,124 @@ Queries the database to see if there is an existing schedule of
 terminations for today. If so, downloads the schedule and sets up cron jobs to
 implement the schedule.
 
-outage
+daemon [--apps=foo,bar,baz]
+--------------------------
+Runs in the foreground until it receives SIGINT or SIGTERM, as an alternative
+to the cron jobs set up by "install". At startup, installs today's schedule if
+one has already been published. Then, every time chaosmonkey.cron_expression
+fires in chaosmonkey.time_zone, reloads the config file and generates the
+schedule for the day, or fetches it if schedule_enabled is false or another
+host has already published it. Terminations are executed in-process at the
+scheduled times.
+
+On shutdown, terminations that are not due yet are dropped, and the daemon
+waits for the ones in progress.
+
+--apps=foo,bar,baz     Optionally specify an explicit list of apps to schedule.
+
+stop [--reason=<reason>] [--expires=<duration or time>]
+-------------------------------------------------------
+Activates the kill switch, which stops terminations on every host, and removes
+the terminations that are scheduled on this host.
+
+--reason=<reason>      Optionally record why terminations were stopped. The
+                       reason is logged by every terminate that is skipped.
+
+--expires=<when>       Optionally resume terminations automatically, either
+                       after a duration (e.g. 4h) or at an RFC3339 time
+                       (e.g. 2016-11-16T17:00:00-08:00).
+
+Examples:
+
+	chaosmonkey stop --reason="game day in progress" --expires=4h
+
+resume
 ------
+Deactivates the kill switch. Terminations that were removed by "stop" are not
+reinstalled until the next "schedule" or "fetch-schedule".
+
+history [<app> [<account>]] [--region=<region>] [--stack=<stack>] [--cluster=<cluster>]
+        [--since=<when>] [--until=<when>] [--leashed | --unleashed]
+        [--format=table|json|csv] [--last]
+----------------------------------------------------------------------------------------
+Print the terminations recorded in the database, oldest first. Every filter is
+optional.
+
+--since=<when>         Only terminations at or after this time, and before
+--until=<when>         --until. Either a duration before now (e.g. 12h or 7d),
+                       a date in chaosmonkey.time_zone (e.g. 2016-11-16) or an
+                       RFC3339 time. A date passed to --until includes that day.
+
+--leashed              Only leashed terminations.
+
+--unleashed            Only unleashed terminations.
+
+--format=<format>      Output format: "table" (the default), "json" or "csv".
+
+--last                 Only print the most recent termination of each instance
+                       group, as grouped by the app's config, and the time at
+                       which min_time_between_kills_in_work_days allows the next
+                       termination of the group, taking holidays into account.
+                       Only unleashed terminations are considered, unless
+                       --leashed is given.
+
+Examples:
+
+	chaosmonkey history chaosguineapig prod --since=7d
+
+	chaosmonkey history --since=2016-11-01 --until=2016-11-30 --unleashed --format=csv
+
+	chaosmonkey history chaosguineapig --last
+
+simulate [--days=<N>] [--apps=foo,bar,baz] [--seed=<N>] [--format=table|json|csv]
+---------------------------------------------------------------------------------
+Forecast the terminations of the next N runs of the scheduler, as scheduled by
+chaosmonkey.cron_expression, against the current deployment and app configs.
+Each run generates a schedule the same way the "schedule" command does, and the
+min time between kills is applied to its terminations, assuming every earlier
+termination that it allowed was executed. Nothing is recorded in the database
+and no jobs are installed.
+
+For each eligible instance group, prints the number of terminations that were
+scheduled, that the min time between kills blocked, and that were executed,
+the longest run of days without a termination, and the number of terminations
+on each day of the week.
+
+--days=<N>             Number of runs of the scheduler to simulate. Defaults
+                       to 20, about a month of weekdays.
+
+--apps=foo,bar,baz     Optionally simulate only these apps.
+
+--seed=<N>             Seed of the simulation. The same seed gives the same
+                       forecast for the same deployment, configs and start.
+
+--format=<format>      Output format: "table" (the default), "json" or "csv".
+
+Examples:
+
+	chaosmonkey simulate --days=60 --apps=chaosguineapig
+
+outage [<account>] [--region=<region>]
+--------------------------------------
 Output "true" if there is an ongoing outage, otherwise "false". Used for debugging.
 
+If an account (and optionally a region) is specified, and the outage checker
+supports it, only outages that affect that account and region are reported.
+
+Examples:
+
+	chaosmonkey outage
+
+	chaosmonkey outage prod --region=us-east-1
 
-config [<app>]
-------------
-Query Spinnaker for the config for a specific app and dump it to
-standard out. This is only used for debugging.
+
+config [<app> [<account>]]
+--------------------------
+Query the backend (e.g. Spinnaker) for the config for a specific app, merge it
+with the [app_defaults] and, if an account is specified, the
+[account_overrides.<account>] of the config file, and dump the effective config
+to standard out, along with the layer that supplied each field. This is only
+used for debugging.
 
 If no app is specified, dump the Monkey-level configuration options to standard out.
 
@@ -114,8 +241,35 @@ Examples:
 
 	chaosmonkey config chaosguineapig
 
+	chaosmonkey config chaosguineapig prod
+
 	chaosmonkey config
 
+email <app>
+-----------
+Send a test email to the owners of an app, using the SMTP settings in the
+[email] section of the config file. The owners are taken from the email
+attribute of the Spinnaker application. If the app has no owner email, the
+message goes to the configured fallback address.
+
+Example:
+
+	chaosmonkey email chaosguineapig
+
+encrypt [<plaintext>]
+---------------------
+Encrypt a secret with the configured decryptor, and print the ciphertext so
+that it can be used as an encrypted_password in the config file. If no
+plaintext is given, it is read from standard input.
+
+Only decryptors that support encryption ("aesgcm", "vault") can be used.
+
+Examples:
+
+	chaosmonkey encrypt hunter2
+
+	chaosmonkey encrypt < password.txt
+
 eligible <app> <account> [--region=<region>] [--stack=<stack>] [--cluster=<cluster>]
 -------------------------------------------------------------------------------------
 
@@ -127,6 +281,10 @@ intest
 
 Outputs "true" on standard out if running within a test environment, otherwise outputs "false"
 
+The environment is set by chaosmonkey.environment in the config file, or by the
+CHAOSMONKEY_ENVIRONMENT environment variable. It is a test environment if it is
+listed in chaosmonkey.test_environments.
+
 
 account <name>
 --------------
@@ -148,7 +306,7 @@ Example:
 	chaosmonkey provider test
 
 `
-	fmt.Printf(usage)
+	fmt.Print(usage)
 }
 
 func init() {
@@ -163,14 +321,26 @@ func Execute() {
 	clusterPtr := flag.String("cluster", "", "cluster of termination group")
 	appsPtr := flag.String("apps", "", "comma-separated list of apps to schedule for termination")
 	noRecordSchedulePtr := flag.Bool("no-record-schedule", false, "do not record schedule")
+	seedPtr := flag.Int64("seed", 0, "seed to generate the schedule from")
+	datePtr := flag.String("date", "", "date to regenerate the schedule of")
+	reasonPtr := flag.String("reason", "", "reason for stopping terminations")
+	expiresPtr := flag.String("expires", "", "duration or RFC3339 time at which stopped terminations resume")
+	sincePtr := flag.String("since", "", "only show terminations since this duration, date or RFC3339 time")
+	untilPtr := flag.String("until", "", "only show terminations until this duration, date or RFC3339 time")
+	unleashedPtr := flag.Bool("unleashed", false, "only show unleashed terminations")
+	formatPtr := flag.String("format", history.Table, "output format: table, json or csv")
+	lastPtr := flag.Bool("last", false, "only show the most recent termination of each instance group")
+	daysPtr := flag.Int("days", 20, "number of runs of the scheduler to simulate")
 	versionPtr := flag.BoolP("version", "v", false, "show version")
 	flag.Usage = Usage
 
 	// These flags, if specified, override config values
 	maxAppsFlag := "max-apps"
 	leashedFlag := "leashed"
+	backendFlag := "backend"
 	flag.Int(maxAppsFlag, math.MaxInt32, "max number of apps to examine for termination")
 	flag.Bool(leashedFlag, false, "force leashed mode")
+	flag.String(backendFlag, "", "backend to discover and terminate instances with")
 
 	flag.Parse()
 	if len(flag.Args()) == 0 {
@@ -185,49 +355,112 @@ func Execute() {
 
 	cmd := flag.Arg(0)
 
-	cfg, err := getConfig()
+	// loadConfig loads the config and associates config values with flags.
+	// The daemon calls it again every time it reloads the config
+	loadConfig := func() (*config.Monkey, error) {
+		cfg, err := getConfig()
+		if err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		log.Fatalf("FATAL: failed to load config: %v", err)
+		for name, key := range map[string]string{
+			maxAppsFlag: param.MaxApps,
+			leashedFlag: param.Leashed,
+			backendFlag: param.Backend,
+		} {
+			err = cfg.BindPFlag(key, flag.Lookup(name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to bind flag: --%s: %v", name, err)
+			}
+		}
+		return cfg, nil
 	}
 
-	// Associate config values with flags
-	err = cfg.BindPFlag(param.MaxApps, flag.Lookup(maxAppsFlag))
+	cfg, err := loadConfig()
 	if err != nil {
-		log.Fatalf("FATAL: failed to bind flag: --%s: %v", maxAppsFlag, err)
-	}
-	err = cfg.BindPFlag(param.Leashed, flag.Lookup(leashedFlag))
-	if err != nil {
-		log.Fatalf("FATAL: failed to bind flag: --%s: %v", leashedFlag, err)
+		log.Fatalf("FATAL: failed to load config: %v", err)
 	}
 
-	spin, err := spinnaker.NewFromConfig(cfg)
+	// Commands that only need the config file, and should work even if
+	// Spinnaker and the database are unreachable
+	switch cmd {
+	case "encrypt":
+		if len(flag.Args()) > 2 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		Encrypt(cfg, flag.Arg(1), os.Stdin)
+		return
+	case "daemon":
+		// The daemon creates its own backend and database connections
+		// every time it reloads the config
+		var apps []string
+		if *appsPtr != "" {
+			apps = strings.Split(*appsPtr, ",")
+		}
+		Daemon(loadConfig, apps)
+		return
+	}
 
+	platform, err := getBackend(cfg)
 	if err != nil {
-		log.Fatalf("FATAL: spinnaker.New failed: %+v", err)
+		log.Fatalf("FATAL: could not create %s backend: %+v", cfg.Backend(), err)
 	}
 
-	outage, err := deps.GetOutage(cfg)
+	appConfigs, err := appcfg.NewFromConfig(cfg, platform)
 	if err != nil {
-		log.Fatalf("FATAL: deps.GetOutage fail: %+v", err)
+		log.Fatalf("FATAL: could not create app config getter: %+v", err)
 	}
 
-	sql, err := mysql.NewFromConfig(cfg)
-	if err != nil {
-		log.Fatalf("FATAL: could not initialize mysql connection: %+v", err)
+	// The database, kill switch and outage checker are only created for the
+	// commands that use them, so that commands such as eligible also work
+	// without a database, e.g. with the file backend
+	var sql database
+	openDatabase := func() database {
+		if sql == nil {
+			sql, err = getDatabase(cfg)
+			if err != nil {
+				log.Fatalf("FATAL: could not initialize %s connection: %+v", cfg.DatabaseDriver(), err)
+			}
+		}
+		return sql
 	}
 
-	// Ensure mysql object gets closed
+	// Ensure database connection gets closed
 	defer func() {
-		_ = sql.Close()
+		if sql != nil {
+			_ = sql.Close()
+		}
 	}()
 
+	openKillSwitch := func() killswitch.KillSwitch {
+		// Only the database kill switch needs the database
+		var db killswitch.KillSwitch
+		if cfg.KillSwitch() == "database" {
+			db = openDatabase()
+		}
+
+		ks, err := getKillSwitch(cfg, db)
+		if err != nil {
+			log.Fatalf("FATAL: could not create kill switch: %+v", err)
+		}
+		return ks
+	}
+
+	getOutage := func() chaosmonkey.Outage {
+		outage, err := deps.GetOutage(cfg)
+		if err != nil {
+			log.Fatalf("FATAL: deps.GetOutage fail: %+v", err)
+		}
+		return outage
+	}
+
 	switch cmd {
 	case "install":
 		executable := ChaosmonkeyExecutable{}
-		Install(cfg, executable, sql)
+		Install(cfg, executable, openDatabase())
 	case "migrate":
-		Migrate(sql)
+		Migrate(openDatabase())
 	case "schedule":
 		log.Println("chaosmonkey schedule starting")
 		defer log.Println("chaosmonkey schedule done")
@@ -239,22 +472,41 @@ func Execute() {
 		} else {
 			// User did not explicitly specify list of apps, get 'em all
 			var err error
-			apps, err = spin.AppNames()
+			apps, err = platform.AppNames()
 			if err != nil {
 				log.Fatalf("FATAL: could not retrieve list of app names: %v", err)
 			}
 		}
 
+		seed := time.Now().UnixNano()
+		if flag.Lookup("seed").Changed {
+			seed = *seedPtr
+		}
+
+		// Replaying the schedule of a date publishes no metrics
+		if *datePtr != "" {
+			ReplaySchedule(appConfigs, cfg, platform, apps, seed, *datePtr, os.Stdout)
+			return
+		}
+
+		metrics.SetGrouping("command", cmd)
+		defer publishMetrics(cfg)
+
 		var schedStore schedstore.SchedStore
 
-		schedStore = sql
 		if *noRecordSchedulePtr {
 			schedStore = nullSchedStore{}
+		} else {
+			schedStore = openDatabase()
 		}
 
-		Schedule(spin, schedStore, cfg, spin, apps)
+		Schedule(appConfigs, schedStore, cfg, platform, apps, seed)
 	case "fetch-schedule":
-		FetchSchedule(sql, cfg)
+		FetchSchedule(openDatabase(), cfg)
+	case "stop":
+		Stop(openKillSwitch(), cfg, *reasonPtr, *expiresPtr)
+	case "resume":
+		Resume(openKillSwitch())
 	case "terminate":
 		if len(flag.Args()) != 3 {
 			flag.Usage()
@@ -262,6 +514,10 @@ func Execute() {
 		}
 		app := flag.Arg(1)
 		account := flag.Arg(2)
+
+		metrics.SetGrouping("command", cmd, "app", app, "account", account)
+		defer publishMetrics(cfg)
+
 		trackers, err := deps.GetTrackers(cfg)
 		if err != nil {
 			log.Fatalf("FATAL: could not create trackers: %+v", err)
@@ -280,26 +536,96 @@ func Execute() {
 		defer logOnPanic(errCounter) // Handler in case of panic
 		deps := deps.Deps{
 			MonkeyCfg:  cfg,
-			Checker:    sql,
-			ConfGetter: spin,
+			Checker:    openDatabase(),
+			ConfGetter: appConfigs,
 			Cl:         clock.New(),
-			Dep:        spin,
-			T:          spin,
+			Dep:        platform,
+			T:          platform,
 			Trackers:   trackers,
-			Ou:         outage,
+			Ou:         getOutage(),
 			ErrCounter: errCounter,
 			Env:        env,
+			KillSwitch: openKillSwitch(),
 		}
 		Terminate(deps, app, account, *regionPtr, *stackPtr, *clusterPtr)
+	case "history":
+		if len(flag.Args()) > 3 {
+			flag.Usage()
+			os.Exit(1)
+		}
+
+		loc, err := cfg.Location()
+		if err != nil {
+			log.Fatalf("FATAL: could not get location: %v", err)
+		}
+
+		now := time.Now()
+		since, err := parseWhen("since", *sincePtr, now, loc, false)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		until, err := parseWhen("until", *untilPtr, now, loc, true)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		leashed, err := leashedFilter(flag.Lookup(leashedFlag).Changed, *unleashedPtr)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+
+		opts := HistoryOptions{
+			Filter: history.Filter{
+				App:     flag.Arg(1),
+				Account: flag.Arg(2),
+				Region:  *regionPtr,
+				Stack:   *stackPtr,
+				Cluster: *clusterPtr,
+				Since:   since,
+				Until:   until,
+				Leashed: leashed,
+			},
+			Format: *formatPtr,
+			Last:   *lastPtr,
+		}
+		History(openDatabase(), appConfigs, cfg, opts, os.Stdout)
+	case "simulate":
+		var apps []string
+		if *appsPtr != "" {
+			apps = strings.Split(*appsPtr, ",")
+		}
+
+		seed := time.Now().UnixNano()
+		if flag.Lookup("seed").Changed {
+			seed = *seedPtr
+		}
+
+		opts := SimulateOptions{
+			Days:   *daysPtr,
+			Seed:   seed,
+			Format: *formatPtr,
+		}
+		Simulate(platform, appConfigs, cfg, apps, opts, os.Stdout)
 	case "outage":
-		Outage(outage)
+		if len(flag.Args()) > 2 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		Outage(getOutage(), flag.Arg(1), *regionPtr)
 	case "config":
-		if len(flag.Args()) != 2 {
+		if len(flag.Args()) < 2 || len(flag.Args()) > 3 {
 			DumpMonkeyConfig(cfg)
 			return
 		}
 		app := flag.Arg(1)
-		DumpConfig(spin, app)
+		account := flag.Arg(2)
+		DumpConfig(appConfigs, app, account)
+	case "email":
+		if len(flag.Args()) != 2 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		app := flag.Arg(1)
+		Email(cfg, platform, app)
 	case "eligible":
 		if len(flag.Args()) != 3 {
 			flag.Usage()
@@ -307,7 +633,7 @@ func Execute() {
 		}
 		app := flag.Arg(1)
 		account := flag.Arg(2)
-		Eligible(spin, spin, app, account, *regionPtr, *stackPtr, *clusterPtr)
+		Eligible(appConfigs, platform, app, account, *regionPtr, *stackPtr, *clusterPtr)
 	case "intest":
 		env, err := deps.GetEnv(cfg)
 		if err != nil {
@@ -321,7 +647,7 @@ func Execute() {
 		}
 
 		account := flag.Arg(1)
-		id, err := spin.AccountID(account)
+		id, err := platform.AccountID(account)
 		if err != nil {
 			fmt.Printf("ERROR: Could not retrieve id for account: %s. Reason: %v\n", account, err)
 			return
@@ -333,7 +659,7 @@ func Execute() {
 			os.Exit(1)
 		}
 		account := flag.Arg(1)
-		provider, err := spin.CloudProvider(account)
+		provider, err := platform.CloudProvider(account)
 		if err != nil {
 			fmt.Printf("ERROR: Could not retrieve provider for account: %s. Reason: %v\n", account, err)
 			return
@@ -361,6 +687,15 @@ func logOnPanic(errCounter chaosmonkey.ErrorCounter) {
 	}
 }
 
+// publishMetrics pushes metrics to the Pushgateway or textfile directory, if
+// configured
+func publishMetrics(cfg *config.Monkey) {
+	err := metrics.Publish(cfg)
+	if err != nil {
+		log.Printf("WARNING: could not publish metrics: %v", err)
+	}
+}
+
 // return configuration info
 func getConfig() (*config.Monkey, error) {
 	cfg, err := config.Load(configPaths[:])
@@ -0,0 +1,302 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/appcfg"
+	"github.com/Netflix/chaosmonkey/clock"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/cron"
+	"github.com/Netflix/chaosmonkey/daemon"
+	"github.com/Netflix/chaosmonkey/deps"
+	"github.com/Netflix/chaosmonkey/metrics"
+	"github.com/Netflix/chaosmonkey/schedstore"
+	"github.com/Netflix/chaosmonkey/schedule"
+	"github.com/Netflix/chaosmonkey/term"
+)
+
+// Daemon executes the "daemon" command. This runs until it receives SIGINT
+// or SIGTERM, generating the schedule every time chaosmonkey.cron_expression
+// fires and terminating instances in-process at the scheduled times, instead
+// of installing cron jobs.
+//
+// load is called before each schedule so that config changes are picked up
+// without a restart. If apps is not empty, only those apps are scheduled
+func Daemon(load func() (*config.Monkey, error), apps []string) {
+	log.Println("chaosmonkey daemon starting")
+	metrics.SetGrouping("command", "daemon")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Printf("received %s, waiting for terminations in progress", sig)
+		cancel()
+	}()
+
+	// Metrics are served at the address of the config the daemon starts with
+	var served sync.Once
+	d := daemon.New(func() (daemon.Run, error) {
+		cfg, err := load()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load config")
+		}
+
+		served.Do(func() {
+			if addr := cfg.PrometheusListen(); addr != "" {
+				_, err = serveMetrics(ctx, addr)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return newDaemonRun(cfg, apps)
+	})
+
+	err := d.Start(ctx)
+	if err != nil {
+		log.Fatalf("FATAL: %+v", err)
+	}
+
+	log.Println("chaosmonkey daemon done")
+}
+
+// serveMetrics serves the metrics at /metrics on addr until ctx is done, and
+// returns the address it listens on. The address is listened on before
+// serveMetrics returns, so that an address that is in use is reported
+func serveMetrics(ctx context.Context, addr string) (net.Addr, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not serve metrics")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	go func() {
+		err := srv.Serve(ln)
+		if err != http.ErrServerClosed {
+			log.Printf("ERROR: metrics server stopped: %v", err)
+		}
+	}()
+
+	log.Printf("serving metrics at http://%s/metrics", ln.Addr())
+	return ln.Addr(), nil
+}
+
+// daemonRun implements daemon.Run with the dependencies created from one
+// load of the config
+type daemonRun struct {
+	cfg  *config.Monkey
+	expr cron.Expression
+	loc  *time.Location
+	db   database
+	deps deps.Deps
+	apps []string
+}
+
+// newDaemonRun creates the dependencies that the "schedule" and "terminate"
+// commands would create from cfg
+func newDaemonRun(cfg *config.Monkey, apps []string) (r *daemonRun, err error) {
+	r = &daemonRun{cfg: cfg, apps: apps}
+
+	expr, err := cfg.CronExpression()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get cron expression")
+	}
+	r.expr, err = cron.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	r.loc, err = cfg.Location()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get location")
+	}
+
+	platform, err := getBackend(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create %s backend", cfg.Backend())
+	}
+
+	appConfigs, err := appcfg.NewFromConfig(cfg, platform)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create app config getter")
+	}
+
+	outage, err := deps.GetOutage(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "deps.GetOutage fail")
+	}
+
+	trackers, err := deps.GetTrackers(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create trackers")
+	}
+
+	errCounter, err := deps.GetErrorCounter(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create error counter")
+	}
+
+	env, err := deps.GetEnv(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not determine environment")
+	}
+
+	r.db, err = getDatabase(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not initialize %s connection", cfg.DatabaseDriver())
+	}
+	defer func() {
+		if err != nil {
+			_ = r.db.Close()
+		}
+	}()
+
+	ks, err := getKillSwitch(cfg, r.db)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create kill switch")
+	}
+
+	r.deps = deps.Deps{
+		MonkeyCfg:  cfg,
+		Checker:    r.db,
+		ConfGetter: appConfigs,
+		Cl:         clock.New(),
+		Dep:        platform,
+		T:          platform,
+		Trackers:   trackers,
+		Ou:         outage,
+		ErrCounter: errCounter,
+		Env:        env,
+		KillSwitch: ks,
+	}
+
+	return r, nil
+}
+
+// Next implements daemon.Run.Next. The cron expression is evaluated in
+// chaosmonkey.time_zone
+func (r *daemonRun) Next(t time.Time) time.Time {
+	return r.expr.Next(t.In(r.loc))
+}
+
+// Schedule implements daemon.Run.Schedule. If scheduling is disabled on
+// this host, or another host has already published today's schedule, the
+// published schedule is fetched instead
+func (r *daemonRun) Schedule() (*schedule.Schedule, error) {
+	defer publishMetrics(r.cfg)
+
+	enabled, err := r.cfg.ScheduleEnabled()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot determine if schedule is enabled")
+	}
+	if !enabled {
+		log.Println("schedule disabled, fetching today's schedule")
+		return r.Fetch()
+	}
+
+	s := schedule.New()
+	err = s.Populate(r.deps.Dep, r.deps.ConfGetter, r.cfg, r.apps)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to populate schedule")
+	}
+
+	err = r.db.Publish(time.Now().In(r.loc), s)
+	if errors.Cause(err) == schedstore.ErrAlreadyExists {
+		log.Println("today's schedule has already been published, fetching it")
+		return r.Fetch()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not publish schedule")
+	}
+
+	return s, nil
+}
+
+// Missed implements daemon.Run.Missed. The cron expression is evaluated in
+// chaosmonkey.time_zone
+func (r *daemonRun) Missed(t time.Time) bool {
+	t = t.In(r.loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.loc)
+
+	// Next returns times after the minute it is given, so start a minute
+	// before midnight to include a schedule at midnight
+	first := r.expr.Next(midnight.Add(-time.Minute))
+	return !first.IsZero() && first.Before(t)
+}
+
+// Fetch implements daemon.Run.Fetch. Stores return an empty schedule for a
+// date without one, so an empty schedule is treated as no schedule
+func (r *daemonRun) Fetch() (*schedule.Schedule, error) {
+	s, err := r.db.Retrieve(time.Now().In(r.loc))
+	if err != nil {
+		return nil, err
+	}
+
+	if s == nil || len(s.Entries()) == 0 {
+		return nil, nil
+	}
+
+	return s, nil
+}
+
+// Terminate implements daemon.Run.Terminate, as the "terminate" command
+// would for the entry's group
+func (r *daemonRun) Terminate(e schedule.Entry) error {
+	defer publishMetrics(r.cfg)
+	defer logOnPanic(r.deps.ErrCounter)
+
+	g := e.Group
+	region, _ := g.Region()
+	stack, _ := g.Stack()
+	cluster, _ := g.Cluster()
+
+	err := term.Terminate(r.deps, g.App(), g.Account(), region, stack, cluster)
+	if err != nil {
+		if cerr := r.deps.ErrCounter.Increment(); cerr != nil {
+			log.Printf("WARNING could not increment error counter: %v", cerr)
+		}
+	}
+	return err
+}
+
+// Close implements daemon.Run.Close
+func (r *daemonRun) Close() error {
+	return r.db.Close()
+}
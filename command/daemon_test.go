@@ -0,0 +1,230 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/cron"
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/metrics"
+	"github.com/Netflix/chaosmonkey/mock"
+	"github.com/Netflix/chaosmonkey/schedule"
+	"github.com/Netflix/chaosmonkey/sqlite"
+)
+
+// newTestDaemonRun returns a daemonRun with mock dependencies and a sqlite
+// database in a temporary directory
+func newTestDaemonRun(t *testing.T) (r *daemonRun, cleanup func()) {
+	dir, err := ioutil.TempDir("", "chaosmonkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sqlite.New(filepath.Join(dir, "chaosmonkey.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sqlite.Migrate(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := mock.Deps()
+	loc, err := d.MonkeyCfg.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r = &daemonRun{cfg: d.MonkeyCfg, loc: loc, db: db, deps: d}
+	return r, func() {
+		_ = db.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestDaemonRunTerminate(t *testing.T) {
+	r, cleanup := newTestDaemonRun(t)
+	defer cleanup()
+
+	err := r.Terminate(schedule.Entry{Group: grp.New("foo", "prod", "us-east-1", "", "foo-prod"), Time: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ttor := r.deps.T.(*mock.Terminator)
+	if ttor.Ncalls != 1 {
+		t.Fatalf("got %d terminations, want 1", ttor.Ncalls)
+	}
+	if got := ttor.Instance.ClusterName(); got != "foo-prod" {
+		t.Errorf("terminated instance in cluster %s, want foo-prod", got)
+	}
+}
+
+func TestDaemonRunScheduleFetchesPublishedSchedule(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		r, cleanup := newTestDaemonRun(t)
+		r.cfg.Set(param.ScheduleEnabled, enabled)
+
+		// Another host has already published today's schedule
+		published := schedule.New()
+		published.Add(time.Now().Add(time.Hour), grp.New("bar", "prod", "us-east-1", "", "bar-prod"))
+		err := r.db.Publish(time.Now().In(r.loc), published)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := r.Schedule()
+		cleanup()
+		if err != nil {
+			t.Fatalf("schedule_enabled=%t: %v", enabled, err)
+		}
+
+		if s == nil || len(s.Entries()) != 1 || s.Entries()[0].Group.App() != "bar" {
+			t.Errorf("schedule_enabled=%t: got %v, want the published schedule", enabled, s)
+		}
+	}
+}
+
+func TestDaemonRunSchedulePublishes(t *testing.T) {
+	r, cleanup := newTestDaemonRun(t)
+	defer cleanup()
+	r.cfg.Set(param.ScheduleEnabled, true)
+
+	// Stores do not record a schedule without terminations, so make sure
+	// that there are some: every day is a work day, with a kill in every group
+	r.cfg.Set(param.Workdays, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"})
+	r.deps.ConfGetter = killEveryDayGetter{}
+
+	s, err := r.Schedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Entries()) == 0 {
+		t.Fatal("generated schedule has no terminations")
+	}
+
+	fetched, err := r.Fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched == nil || len(fetched.Entries()) != len(s.Entries()) {
+		t.Errorf("generated schedule was not published: got %v, want %v", fetched, s)
+	}
+}
+
+// killEveryDayGetter returns the configs of mock.ConfigGetter, with a kill
+// every work day
+type killEveryDayGetter struct {
+	mock.ConfigGetter
+}
+
+func (g killEveryDayGetter) Get(app string) (*chaosmonkey.AppConfig, error) {
+	cfg, err := g.ConfigGetter.Get(app)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MeanTimeBetweenKillsInWorkDays = 1
+	return cfg, nil
+}
+
+func TestDaemonRunFetchWithoutSchedule(t *testing.T) {
+	r, cleanup := newTestDaemonRun(t)
+	defer cleanup()
+
+	s, err := r.Fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != nil {
+		t.Errorf("got %v before any schedule was published, want nil", s)
+	}
+}
+
+func TestDaemonRunMissed(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wednesday
+	now := time.Date(2016, time.November, 16, 10, 30, 0, 0, loc)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"0 9 * * *", true},
+		{"0 0 * * *", true},
+		{"30 10 * * *", false},
+		{"0 11 * * *", false},
+		{"0 9 * * 1,2", false},
+	}
+
+	for _, tt := range tests {
+		expr, err := cron.Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := &daemonRun{expr: expr, loc: loc}
+
+		// The time is converted to chaosmonkey.time_zone
+		if got := r.Missed(now.UTC()); got != tt.want {
+			t.Errorf("%s: got missed=%t, want %t", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := serveMetrics(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics.TerminateEvents.Inc(metrics.Picked, "foo", "prod", "us-east-1")
+
+	resp, err := http.Get("http://" + addr.String() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `chaosmonkey_terminate_events_total{stage="picked",app="foo"`) {
+		t.Errorf("got %s:\n%s", resp.Status, body)
+	}
+
+	// The address is in use
+	if _, err := serveMetrics(ctx, addr.String()); err == nil {
+		t.Error("expected error listening on an address in use")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/killswitch"
+	"github.com/Netflix/chaosmonkey/mysql"
+	"github.com/Netflix/chaosmonkey/postgres"
+	"github.com/Netflix/chaosmonkey/schedstore"
+	"github.com/Netflix/chaosmonkey/sqlite"
+)
+
+// database stores schedules, terminations and the kill switch
+type database interface {
+	schedstore.SchedStore
+	chaosmonkey.Checker
+	chaosmonkey.OutcomeRecorder
+	killswitch.KillSwitch
+	history.Store
+
+	// Close closes the connection to the database
+	Close() error
+}
+
+// getDatabase returns the database configured by database.driver
+func getDatabase(cfg *config.Monkey) (database, error) {
+	driver := cfg.DatabaseDriver()
+	switch driver {
+	case "mysql":
+		return mysql.NewFromConfig(cfg)
+	case "postgres":
+		return postgres.NewFromConfig(cfg)
+	case "sqlite":
+		return sqlite.NewFromConfig(cfg)
+	default:
+		return nil, errors.Errorf("unsupported %s: %s", param.DatabaseDriver, driver)
+	}
+}
+
+// migrate upgrades db to the latest schema version of its driver
+func migrate(db database) error {
+	switch db := db.(type) {
+	case mysql.MySQL:
+		return mysql.Migrate(db)
+	case postgres.Postgres:
+		return postgres.Migrate(db)
+	case sqlite.SQLite:
+		return sqlite.Migrate(db)
+	default:
+		return errors.Errorf("no migrations for database %T", db)
+	}
+}
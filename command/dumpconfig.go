@@ -17,18 +17,38 @@ package command
 import (
 	"fmt"
 	"os"
+	"sort"
 
-	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/appcfg"
 	"github.com/davecgh/go-spew/spew"
 )
 
-// DumpConfig dumps the config for an app to stdout
-func DumpConfig(c chaosmonkey.AppConfigGetter, app string) {
-	cfg, err := c.Get(app)
+// DumpConfig dumps the effective config for an app in an account to stdout,
+// followed by the layer that each field was taken from. If account is blank,
+// account overrides are not applied
+func DumpConfig(g *appcfg.Getter, app, account string) {
+	e, err := g.Explain(app, account)
 	if err != nil {
 		fmt.Printf("%+v", err)
 		os.Exit(1)
 	}
 
-	spew.Dump(cfg)
+	spew.Dump(e.Config)
+
+	fields := make([]string, 0, len(e.Sources))
+	for field := range e.Sources {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	fmt.Println("\nsources:")
+	if _, ok := e.Sources["enabled"]; !ok {
+		fmt.Printf("  %-32s %s\n", "enabled", "not set by any layer, app is disabled")
+	}
+	for _, field := range fields {
+		fmt.Printf("  %-32s %s\n", field, e.Sources[field])
+	}
+	for i, source := range e.ExceptionSources {
+		fmt.Printf("  %-32s %s\n", fmt.Sprintf("exceptions[%d]", i), source)
+	}
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/deps"
 )
 
 // DumpMonkeyConfig dumps the monkey-level config parameters to stdout
@@ -54,8 +55,21 @@ func DumpMonkeyConfig(cfg *config.Monkey) {
 	fmt.Printf("end hour: %d\n", cfg.EndHour())
 	loc, _ := cfg.Location()
 	fmt.Printf("location: %s\n", loc)
+	if workdays, err := cfg.Workdays(); err != nil {
+		fmt.Printf("ERROR getting workdays: %v\n", err)
+	} else {
+		fmt.Printf("workdays: %v\n", workdays)
+	}
+	fmt.Printf("job format: %s\n", cfg.JobFormat())
 	fmt.Printf("cron path: %s\n", cfg.CronPath())
 	fmt.Printf("term path: %s\n", cfg.TermPath())
 	fmt.Printf("term account: %s\n", cfg.TermAccount())
 	fmt.Printf("max apps: %d\n", cfg.MaxApps())
+	fmt.Printf("environment: %s\n", cfg.Environment())
+
+	if env, err := deps.GetEnv(cfg); err != nil {
+		fmt.Printf("ERROR getting environment: %v\n", err)
+	} else {
+		fmt.Printf("in test environment: %t\n", env.InTest())
+	}
 }
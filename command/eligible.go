@@ -27,7 +27,7 @@ import (
 // Eligible prints out a list of instance ids eligible for termination
 // It is intended only for testing
 func Eligible(g chaosmonkey.AppConfigGetter, d deploy.Deployment, app, account, region, stack, cluster string) {
-	cfg, err := g.Get(app)
+	cfg, err := chaosmonkey.GetAppConfig(g, app, account)
 	if err != nil {
 		fmt.Printf("Failed to retrieve config for app %s\n%+v", app, err)
 		os.Exit(1)
@@ -0,0 +1,41 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/email"
+)
+
+// Email sends a test notification email to the owners of app
+func Email(cfg *config.Monkey, owners email.OwnerGetter, app string) {
+	notifier, err := email.NewFromConfig(cfg, owners)
+	if err != nil {
+		fmt.Printf("ERROR: could not configure email: %v\n", err)
+		os.Exit(1)
+	}
+
+	to, err := notifier.SendTest(app)
+	if err != nil {
+		fmt.Printf("ERROR: could not send test email: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("sent test email to %s\n", strings.Join(to, ", "))
+}
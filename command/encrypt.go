@@ -0,0 +1,54 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/decryptor"
+)
+
+// Encrypt prints the ciphertext of plaintext, encrypted with the configured
+// decryptor. If plaintext is blank, it is read from r so that secrets don't
+// end up in the shell history
+func Encrypt(cfg *config.Monkey, plaintext string, r io.Reader) {
+	encrypter, err := decryptor.GetEncrypter(cfg)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	if plaintext == "" {
+		contents, err := ioutil.ReadAll(r)
+		if err != nil {
+			fmt.Printf("ERROR: could not read plaintext: %v\n", err)
+			os.Exit(1)
+		}
+		plaintext = strings.TrimRight(string(contents), "\r\n")
+	}
+
+	ciphertext, err := encrypter.Encrypt(plaintext)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(ciphertext)
+}
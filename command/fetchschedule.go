@@ -38,7 +38,7 @@ func FetchSchedule(s schedstore.SchedStore, cfg *config.Monkey) {
 		return
 	}
 
-	err = registerWithCron(sched, cfg)
+	err = writeTerminations(sched, cfg)
 	if err != nil {
 		log.Fatalf("FATAL: could not register with cron: %v", err)
 	}
@@ -0,0 +1,133 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/cal"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/history"
+)
+
+// HistoryOptions are the options of the "history" command
+type HistoryOptions struct {
+	Filter history.Filter
+
+	// Format is the output format: table, json or csv
+	Format string
+
+	// Last prints only the most recent termination of each instance group,
+	// and when the next one is allowed
+	Last bool
+}
+
+// History executes the "history" command. This prints the recorded
+// terminations that match the options to w
+func History(store history.Store, g chaosmonkey.AppConfigGetter, cfg *config.Monkey, opts HistoryOptions, w io.Writer) {
+	err := doHistory(store, g, cfg, opts, w)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+}
+
+func doHistory(store history.Store, g chaosmonkey.AppConfigGetter, cfg *config.Monkey, opts HistoryOptions, w io.Writer) error {
+	loc, err := cfg.Location()
+	if err != nil {
+		return errors.Wrap(err, "could not get location")
+	}
+
+	filter := opts.Filter
+
+	// Leashed terminations do not count against the min time between kills,
+	// so by default the last termination is the last unleashed one
+	if opts.Last && filter.Leashed == nil {
+		unleashed := false
+		filter.Leashed = &unleashed
+	}
+
+	terms, err := store.Terminations(filter)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve terminations")
+	}
+
+	if !opts.Last {
+		return history.Write(w, opts.Format, terms, loc)
+	}
+
+	calendars, err := cal.NewFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	latest, err := history.Last(terms, g, calendars, cfg.BusinessHours)
+	if err != nil {
+		return err
+	}
+
+	return history.WriteLast(w, opts.Format, latest, loc)
+}
+
+// leashedFilter returns the history filter for the --leashed and
+// --unleashed flags
+func leashedFilter(leashed, unleashed bool) (*bool, error) {
+	switch {
+	case leashed && unleashed:
+		return nil, errors.New("--leashed and --unleashed cannot be used together")
+	case leashed || unleashed:
+		return &leashed, nil
+	default:
+		return nil, nil
+	}
+}
+
+// parseWhen parses the --since and --until flags, which are either a
+// duration before now (e.g. "12h" or "7d"), a date (e.g. 2016-11-16) in loc,
+// or an RFC3339 timestamp. A blank value is returned as the zero time. If
+// endOfDay is true, a date means the end of that day rather than its start
+func parseWhen(name, s string, now time.Time, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if days, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil && strings.HasSuffix(s, "d") {
+		return now.AddDate(0, 0, -days), nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		if endOfDay {
+			return t.AddDate(0, 0, 1), nil
+		}
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("--%s must be a duration (e.g. 12h or 7d), a date (e.g. 2016-11-16) or an RFC3339 time (e.g. 2016-11-16T17:00:00-08:00): %s", name, s)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+func TestParseWhen(t *testing.T) {
+	loc := time.FixedZone("PST", -8*60*60)
+	now := time.Date(2016, time.November, 16, 9, 0, 0, 0, loc)
+
+	tests := []struct {
+		s        string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"", false, time.Time{}},
+		{"12h", false, now.Add(-12 * time.Hour)},
+		{"7d", false, time.Date(2016, time.November, 9, 9, 0, 0, 0, loc)},
+		{"2016-11-01", false, time.Date(2016, time.November, 1, 0, 0, 0, 0, loc)},
+		{"2016-11-01", true, time.Date(2016, time.November, 2, 0, 0, 0, 0, loc)},
+		{"2016-11-16T17:00:00Z", false, time.Date(2016, time.November, 16, 17, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseWhen("since", tt.s, now, loc, tt.endOfDay)
+		if err != nil {
+			t.Errorf("%q: %v", tt.s, err)
+			continue
+		}
+
+		if !got.Equal(tt.want) {
+			t.Errorf("%q: got %s, want %s", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"yesterday", "xd", "11/01/2016"} {
+		if _, err := parseWhen("since", s, now, loc, false); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestLeashedFilter(t *testing.T) {
+	if got, err := leashedFilter(false, false); err != nil || got != nil {
+		t.Errorf("no flags: got %v, %v, want nil", got, err)
+	}
+
+	if got, err := leashedFilter(true, false); err != nil || got == nil || !*got {
+		t.Errorf("--leashed: got %v, %v, want true", got, err)
+	}
+
+	if got, err := leashedFilter(false, true); err != nil || got == nil || *got {
+		t.Errorf("--unleashed: got %v, %v, want false", got, err)
+	}
+
+	if _, err := leashedFilter(true, true); err == nil {
+		t.Error("--leashed --unleashed: expected error")
+	}
+}
+
+// historyStore implements history.Store, recording the filter it was
+// queried with
+type historyStore struct {
+	terms  []history.Termination
+	filter history.Filter
+}
+
+func (s *historyStore) Terminations(f history.Filter) ([]history.Termination, error) {
+	s.filter = f
+	return s.terms, nil
+}
+
+func TestHistoryLast(t *testing.T) {
+	cfg := config.Defaults()
+	killedAt := time.Date(2016, time.November, 16, 18, 0, 0, 0, time.UTC) // 10am PST
+	store := &historyStore{terms: []history.Termination{
+		{App: "foo", Account: "prod", Region: "us-east-1", Cluster: "foo", InstanceID: "i-1", KilledAt: killedAt.Add(-24 * time.Hour)},
+		{App: "foo", Account: "prod", Region: "us-east-1", Cluster: "foo", InstanceID: "i-2", KilledAt: killedAt},
+	}}
+
+	var buf bytes.Buffer
+	err := doHistory(store, mock.ConfigGetter{}, cfg, HistoryOptions{Format: history.CSV, Last: true}, &buf)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// Only unleashed terminations count against the min time between kills
+	if store.filter.Leashed == nil || *store.filter.Leashed {
+		t.Errorf("got leashed filter %v, want unleashed only", store.filter.Leashed)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want header and one group:\n%s", len(lines), buf.String())
+	}
+
+	if !strings.Contains(lines[1], "i-2") || !strings.HasSuffix(lines[1], "2016-11-17T00:00:00-08:00") {
+		t.Errorf("unexpected row: %s", lines[1])
+	}
+}
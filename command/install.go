@@ -17,7 +17,6 @@ package command
 import (
 	"fmt"
 	"github.com/Netflix/chaosmonkey/config"
-	"github.com/Netflix/chaosmonkey/mysql"
 	"io/ioutil"
 	"log"
 	"os"
@@ -38,13 +37,15 @@ type CurrentExecutable interface {
 }
 
 // Install installs chaosmonkey and runs database migration
-func Install(cfg *config.Monkey, exec CurrentExecutable, db mysql.MySQL) {
+func Install(cfg *config.Monkey, exec CurrentExecutable, db database) {
 	InstallCron(cfg, exec)
 	Migrate(db)
 	log.Println("installation done!")
 }
 
-// InstallCron installs chaosmonkey schedule generation cron
+// InstallCron installs chaosmonkey schedule generation cron, or the
+// equivalent systemd timer or Kubernetes CronJob, depending on
+// chaosmonkey.job_format
 func InstallCron(cfg *config.Monkey, exec CurrentExecutable) {
 	executablePath, err := exec.ExecutablePath()
 	if err != nil {
@@ -55,21 +56,32 @@ func InstallCron(cfg *config.Monkey, exec CurrentExecutable) {
 		log.Fatalf("FATAL: %v", err)
 	}
 
-	err = setupCron(cfg, executablePath)
+	err = setupScheduleScript(cfg, executablePath)
 	if err != nil {
 		log.Fatalf("FATAL: %v", err)
 	}
 
-	log.Println("chaosmonkey cron is installed successfully")
-}
+	cronExpr, err := cfg.CronExpression()
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
 
-func setupCron(cfg *config.Monkey, executablePath string) error {
-	err := EnsureFileAbsent(cfg.SchedulePath())
+	w, err := getJobWriter(cfg)
 	if err != nil {
-		return err
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	err = w.WriteSchedule(cronExpr)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
 	}
 
-	err = EnsureFileAbsent(cfg.ScheduleCronPath())
+	log.Println("chaosmonkey cron is installed successfully")
+}
+
+// setupScheduleScript writes the script that runs "chaosmonkey schedule"
+func setupScheduleScript(cfg *config.Monkey, executablePath string) error {
+	err := EnsureFileAbsent(cfg.SchedulePath())
 	if err != nil {
 		return err
 	}
@@ -82,12 +94,13 @@ func setupCron(cfg *config.Monkey, executablePath string) error {
 		return err
 	}
 
-	err = ioutil.WriteFile(cfg.SchedulePath(), content, scriptPerms)
-	if err != nil {
-		return err
-	}
+	return ioutil.WriteFile(cfg.SchedulePath(), content, scriptPerms)
+}
 
-	cronExpr, err := cfg.CronExpression()
+// setupCron writes the crontab that runs the schedule script every time
+// cronExpr fires
+func setupCron(cfg *config.Monkey, cronExpr string) error {
+	err := EnsureFileAbsent(cfg.ScheduleCronPath())
 	if err != nil {
 		return err
 	}
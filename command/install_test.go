@@ -21,6 +21,8 @@ import (
 	"github.com/Netflix/chaosmonkey/mock"
 	"github.com/pkg/errors"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -116,3 +118,58 @@ func TestInstallationWithUserDefinedCron(t *testing.T) {
 		return
 	}
 }
+
+func TestInstallationWithSystemd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaosmonkey-systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scriptPath := filepath.Join(dir, "chaosmonkey-schedule.sh")
+	cronPath := filepath.Join(dir, "chaosmonkey-schedule")
+
+	cfg, err := initInstallationConfig(scriptPath, cronPath, "/var/log", filepath.Join(dir, "chaosmonkey-terminate.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set(param.JobFormat, "systemd")
+	cfg.Set(param.SystemdUnitDir, dir)
+	cfg.Set(param.SystemdSystemctl, "")
+
+	InstallCron(cfg, mock.Executable{Path: "/tmp/chaosmonkey"})
+
+	err = assertHasSameContent(filepath.Join(dir, "chaosmonkey-schedule.service"), fmt.Sprintf(`[Unit]
+Description=Chaos Monkey schedule
+
+[Service]
+Type=oneshot
+User=root
+ExecStart=%s
+`, scriptPath))
+	if err != nil {
+		t.Error(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "chaosmonkey-schedule.timer")); err != nil {
+		t.Errorf("timer not written: %v", err)
+	}
+
+	if _, err := os.Stat(cronPath); !os.IsNotExist(err) {
+		t.Errorf("expected no crontab at %s, got err=%v", cronPath, err)
+	}
+}
+
+func TestGetJobWriterUnsupported(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Set(param.JobFormat, "at")
+	if _, err := getJobWriter(cfg); err == nil {
+		t.Error("expected error for unsupported job format")
+	}
+
+	// CronJobs cannot be written without an image
+	cfg.Set(param.JobFormat, "kubernetes")
+	if _, err := getJobWriter(cfg); err == nil {
+		t.Error("expected error for kubernetes job format without image")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/jobs"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// getJobWriter returns the writer of the jobs that run Chaos Monkey on a
+// schedule, in the format configured by chaosmonkey.job_format
+func getJobWriter(cfg *config.Monkey) (jobs.Writer, error) {
+	if cfg.JobFormat() == "crontab" {
+		return crontab{cfg}, nil
+	}
+	return jobs.NewFromConfig(cfg)
+}
+
+// crontab writes jobs as crontabs in /etc/cron.d
+type crontab struct {
+	cfg *config.Monkey
+}
+
+// WriteTerminations implements jobs.Writer.WriteTerminations
+func (c crontab) WriteTerminations(s *schedule.Schedule) error {
+	return registerWithCron(s, c.cfg)
+}
+
+// RemoveTerminations implements jobs.Writer.RemoveTerminations
+func (c crontab) RemoveTerminations() error {
+	return EnsureFileAbsent(c.cfg.CronPath())
+}
+
+// WriteSchedule implements jobs.Writer.WriteSchedule
+func (c crontab) WriteSchedule(expr string) error {
+	return setupCron(c.cfg, expr)
+}
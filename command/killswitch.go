@@ -0,0 +1,109 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/killswitch"
+)
+
+// getKillSwitch returns the kill switch configured by chaosmonkey.kill_switch
+// db is used if the kill switch is stored in the database
+func getKillSwitch(cfg *config.Monkey, db killswitch.KillSwitch) (killswitch.KillSwitch, error) {
+	kind := cfg.KillSwitch()
+	switch kind {
+	case "database":
+		return db, nil
+	case "file":
+		return killswitch.NewFile(cfg.KillSwitchPath()), nil
+	default:
+		return nil, errors.Errorf("unsupported %s: %s", param.KillSwitch, kind)
+	}
+}
+
+// Stop executes the "stop" command. This activates the kill switch, which
+// stops terminations on every host, and removes the terminations that are
+// already scheduled on this host
+func Stop(ks killswitch.KillSwitch, cfg *config.Monkey, reason string, expires string) {
+	now := time.Now()
+	expiresAt, err := parseExpiry(expires, now)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	err = ks.Stop(reason, now, expiresAt)
+	if err != nil {
+		log.Fatalf("FATAL: could not stop terminations: %v", err)
+	}
+
+	if expiresAt.IsZero() {
+		log.Printf("terminations stopped until resumed")
+	} else {
+		log.Printf("terminations stopped until %s", expiresAt.Format(time.RFC3339))
+	}
+
+	w, err := getJobWriter(cfg)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	err = w.RemoveTerminations()
+	if err != nil {
+		log.Fatalf("FATAL: could not remove scheduled terminations: %v", err)
+	}
+}
+
+// Resume executes the "resume" command. This deactivates the kill switch
+func Resume(ks killswitch.KillSwitch) {
+	err := ks.Resume()
+	if err != nil {
+		log.Fatalf("FATAL: could not resume terminations: %v", err)
+	}
+
+	log.Printf("terminations resumed. Run \"chaosmonkey fetch-schedule\" to reinstall today's schedule on this host")
+}
+
+// parseExpiry parses the --expires flag, which is either a duration relative
+// to now (e.g. "4h") or an RFC3339 timestamp. A blank value means the kill
+// switch does not expire, and is returned as the zero time.
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, errors.Errorf("--expires must be in the future: %s", s)
+		}
+		return now.Add(d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("--expires must be a duration (e.g. 4h) or an RFC3339 time (e.g. 2016-11-16T17:00:00-08:00): %s", s)
+	}
+
+	if !t.After(now) {
+		return time.Time{}, errors.Errorf("--expires must be in the future: %s", s)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2016, time.November, 16, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"4h", now.Add(4 * time.Hour)},
+		{"2016-11-16T17:00:00-08:00", time.Date(2016, time.November, 17, 1, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseExpiry(tt.s, now)
+		if err != nil {
+			t.Errorf("%q: %v", tt.s, err)
+			continue
+		}
+
+		if !got.Equal(tt.want) {
+			t.Errorf("%q: got %s, want %s", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"-1h", "2016-11-16T08:00:00Z", "tomorrow"} {
+		if _, err := parseExpiry(s, now); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+// TestStopRemovesCrontab ensures stop removes the scheduled terminations
+func TestStopRemovesCrontab(t *testing.T) {
+	d := mock.Deps()
+	ks := new(mock.KillSwitch)
+
+	Stop(ks, d.MonkeyCfg, "game day", "")
+
+	if !ks.Current.Stopped || ks.Current.Reason != "game day" {
+		t.Errorf("unexpected kill switch state: %+v", ks.Current)
+	}
+
+	if _, err := os.Stat(d.MonkeyCfg.CronPath()); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got err=%v", d.MonkeyCfg.CronPath(), err)
+	}
+}
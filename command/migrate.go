@@ -15,13 +15,12 @@
 package command
 
 import (
-	"github.com/Netflix/chaosmonkey/mysql"
 	"log"
 )
 
 // Migrate executes database migration
-func Migrate(db mysql.MySQL) {
-	err := mysql.Migrate(db)
+func Migrate(db database) {
+	err := migrate(db)
 
 	if err != nil {
 		log.Fatalf("ERROR - couldn't apply database migration: %v", err)
@@ -0,0 +1,158 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// The dependencies that cmd/chaosmonkey registers
+	_ "github.com/Netflix/chaosmonkey/decryptor"
+	_ "github.com/Netflix/chaosmonkey/env"
+	_ "github.com/Netflix/chaosmonkey/errorcounter"
+	_ "github.com/Netflix/chaosmonkey/outage"
+	_ "github.com/Netflix/chaosmonkey/tracker"
+)
+
+const offlineDeployment = `
+apps:
+  web:
+    attributes:
+      chaosMonkey:
+        enabled: true
+        grouping: cluster
+        meanTimeBetweenKillsInWorkDays: 1
+        minTimeBetweenKillsInWorkDays: 1
+        exceptions: []
+    deployment:
+      prod:
+        cloudProvider: aws
+        clusters:
+          web-main:
+            us-east-1:
+              web-main-v001: [i-1, i-2]
+`
+
+// offlineConfig uses the file backend, and no database host
+const offlineConfig = `
+[chaosmonkey]
+enabled = true
+leashed = true
+schedule_enabled = true
+accounts = ["prod"]
+workdays = ["sun", "mon", "tue", "wed", "thu", "fri", "sat"]
+backend = "file"
+cron_path = "%[1]s/chaosmonkey-daily-terminations"
+
+[file]
+path = "%[1]s/deployment.yaml"
+`
+
+// TestExecuteHelper is not a test: TestOffline runs the test binary with it
+// to execute the command line after "--"
+func TestExecuteHelper(t *testing.T) {
+	if os.Getenv("CHAOSMONKEY_EXECUTE_HELPER") != "1" {
+		return
+	}
+
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	os.Args = append([]string{"chaosmonkey"}, args...)
+	Execute()
+	os.Exit(0)
+}
+
+// runCommand executes a chaosmonkey command line in dir, which has the chaosmonkey.toml
+// config file, and returns its output
+func runCommand(t *testing.T, dir string, args ...string) (string, error) {
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=^TestExecuteHelper$", "--"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CHAOSMONKEY_EXECUTE_HELPER=1")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// TestOffline verifies that schedule, eligible and leashed terminate work
+// with the file backend and without a database server
+func TestOffline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaosmonkey-offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeConfig := func(extra string) {
+		cfg := fmt.Sprintf(offlineConfig, dir) + extra
+		err := ioutil.WriteFile(filepath.Join(dir, "chaosmonkey.toml"), []byte(cfg), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte(offlineDeployment), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeConfig("")
+
+	out, err := runCommand(t, dir, "eligible", "web", "prod")
+	if err != nil {
+		t.Fatalf("eligible failed: %v\n%s", err, out)
+	}
+	if out != "i-1\ni-2\n" {
+		t.Errorf("got eligible instances %q, want i-1 and i-2", out)
+	}
+
+	out, err = runCommand(t, dir, "schedule", "--no-record-schedule", "--seed", "1")
+	if err != nil {
+		t.Fatalf("schedule failed: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chaosmonkey-daily-terminations")); err != nil {
+		t.Errorf("schedule did not write the terminations: %v", err)
+	}
+
+	// terminate checks and records terminations in the database, so it
+	// needs one. A SQLite file needs no server.
+	out, err = runCommand(t, dir, "terminate", "web", "prod")
+	if err == nil || !strings.Contains(out, "database.host not specified") {
+		t.Errorf("expected terminate to fail without a database, got %v\n%s", err, out)
+	}
+
+	writeConfig(fmt.Sprintf("\n[database]\ndriver = \"sqlite\"\npath = \"%s/chaosmonkey.db\"\n", dir))
+
+	out, err = runCommand(t, dir, "migrate")
+	if err != nil {
+		t.Fatalf("migrate failed: %v\n%s", err, out)
+	}
+
+	out, err = runCommand(t, dir, "terminate", "web", "prod", "--leashed")
+	if err != nil {
+		t.Fatalf("terminate failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "leashed=true, not killing instance") {
+		t.Errorf("expected a leashed termination, got:\n%s", out)
+	}
+}
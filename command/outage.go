@@ -22,8 +22,17 @@ import (
 )
 
 // Outage prints out "true" if an ongoing outage, else "false"
-func Outage(ou chaosmonkey.Outage) {
-	down, err := ou.Outage()
+// If account is not blank and the outage checker supports it, only outages
+// that affect account and region are considered
+func Outage(ou chaosmonkey.Outage, account, region string) {
+	var down bool
+	var err error
+	if scoped, ok := ou.(chaosmonkey.ScopedOutage); ok && account != "" {
+		down, err = scoped.OutageIn(account, region)
+	} else {
+		down, err = ou.Outage()
+	}
+
 	if err != nil {
 		fmt.Printf("ERROR: %v", err)
 		os.Exit(1)
@@ -16,21 +16,26 @@ package command
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/Netflix/chaosmonkey"
 	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/cron"
 	"github.com/Netflix/chaosmonkey/deploy"
 	"github.com/Netflix/chaosmonkey/schedstore"
 	"github.com/Netflix/chaosmonkey/schedule"
 )
 
 // Schedule executes the "schedule" command. This defines the schedule
-// of terminations for the day and records them as cron jobs
-func Schedule(g chaosmonkey.AppConfigGetter, ss schedstore.SchedStore, cfg *config.Monkey, d deploy.Deployment, apps []string) {
+// of terminations for the day and records them as cron jobs. The schedule is
+// generated from seed, so the same seed reproduces the same schedule given
+// the same deployment and configs
+func Schedule(g chaosmonkey.AppConfigGetter, ss schedstore.SchedStore, cfg *config.Monkey, d deploy.Deployment, apps []string, seed int64) {
 
 	enabled, err := cfg.ScheduleEnabled()
 	if err != nil {
@@ -47,19 +52,21 @@ func Schedule(g chaosmonkey.AppConfigGetter, ss schedstore.SchedStore, cfg *conf
 	 scheduling time but later in the day becomes enabled, it still
 	 functions correctly.
 	*/
-	err = do(d, g, ss, cfg, apps)
+	err = do(d, g, ss, cfg, apps, seed)
 
 	if err != nil {
+		// log.Fatalf exits without running deferred functions
+		publishMetrics(cfg)
 		log.Fatalf("FATAL: %v", err)
 	}
 
 }
 
 // do is the actual implementation for the Schedule function
-func do(d deploy.Deployment, g chaosmonkey.AppConfigGetter, ss schedstore.SchedStore, cfg *config.Monkey, apps []string) error {
+func do(d deploy.Deployment, g chaosmonkey.AppConfigGetter, ss schedstore.SchedStore, cfg *config.Monkey, apps []string, seed int64) error {
 
 	s := schedule.New()
-	err := s.Populate(d, g, cfg, apps)
+	err := s.PopulateWithSeed(d, g, cfg, apps, seed)
 	if err != nil {
 		return fmt.Errorf("failed to populate schedule: %v", err)
 	}
@@ -71,8 +78,69 @@ func do(d deploy.Deployment, g chaosmonkey.AppConfigGetter, ss schedstore.SchedS
 	return nil
 }
 
+// ReplaySchedule executes the "schedule" command with --date. This
+// regenerates the schedule that the scheduler generated with seed on date,
+// e.g. 2016-11-16, and prints it to w. Nothing is recorded or installed
+func ReplaySchedule(g chaosmonkey.AppConfigGetter, cfg *config.Monkey, d deploy.Deployment, apps []string, seed int64, date string, w io.Writer) {
+	err := doReplay(d, g, cfg, apps, seed, date, w)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+}
+
+func doReplay(d deploy.Deployment, g chaosmonkey.AppConfigGetter, cfg *config.Monkey, apps []string, seed int64, date string, w io.Writer) error {
+	loc, err := cfg.Location()
+	if err != nil {
+		return fmt.Errorf("could not get location: %v", err)
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return fmt.Errorf("--date must be a date, e.g. 2016-11-16: %s", date)
+	}
+
+	now, err := scheduleTime(cfg, day)
+	if err != nil {
+		return err
+	}
+
+	s := schedule.New()
+	err = s.PopulateAt(d, g, cfg, apps, now, seed)
+	if err != nil {
+		return fmt.Errorf("failed to populate schedule: %v", err)
+	}
+
+	for _, e := range s.Entries() {
+		fmt.Fprintf(w, "%s %s\n", e.Time.In(loc).Format(time.RFC3339), strings.Join(schedule.TerminateArgs(e.Group), " "))
+	}
+
+	return nil
+}
+
+// scheduleTime returns the time at which chaosmonkey.cron_expression runs
+// the scheduler on day, or the start of day if it does not run that day
+func scheduleTime(cfg *config.Monkey, day time.Time) (time.Time, error) {
+	expr, err := cfg.CronExpression()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not get cron expression: %v", err)
+	}
+
+	e, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t := e.Next(day.Add(-time.Nanosecond))
+	if t.IsZero() || !t.Before(day.AddDate(0, 0, 1)) {
+		return day, nil
+	}
+
+	return t, nil
+}
+
 // deploySchedule publishes the schedule to chaosmonkey-api
-// and registers the schedule with the local cron
+// and registers the schedule with the local cron, or writes it in the
+// configured job format
 func deploySchedule(s *schedule.Schedule, ss schedstore.SchedStore, cfg *config.Monkey) error {
 	loc, err := cfg.Location()
 	if err != nil {
@@ -87,8 +155,18 @@ func deploySchedule(s *schedule.Schedule, ss schedstore.SchedStore, cfg *config.
 		return fmt.Errorf("deploySchedule: could not publish schedule: %v", err)
 	}
 
-	err = registerWithCron(s, cfg)
-	return err
+	return writeTerminations(s, cfg)
+}
+
+// writeTerminations writes the jobs that execute the terminations of s, in
+// the format configured by chaosmonkey.job_format, replacing the jobs of the
+// previous schedule
+func writeTerminations(s *schedule.Schedule, cfg *config.Monkey) error {
+	w, err := getJobWriter(cfg)
+	if err != nil {
+		return err
+	}
+	return w.WriteTerminations(s)
 }
 
 // registerWithCron registers the schedule of terminations with cron on the local machine
@@ -45,13 +45,17 @@ func TestScheduleCommand(t *testing.T) {
 	cfg.Set(param.CronPath, cronFile)
 	cfg.Set(param.Accounts, []string{"prod", "test"})
 
+	// Every day is a work day, so that there is always a working window to
+	// schedule within a day
+	cfg.Set(param.Workdays, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"})
+
 	// Code under test
 	appNames, err := d.AppNames()
 	if err != nil {
 		t.Fatalf("%v", err)
 	}
 
-	err = do(d, a, a, cfg, appNames)
+	err = do(d, a, a, cfg, appNames, 1)
 
 	if err != nil {
 		t.Errorf("%v", err)
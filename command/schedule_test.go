@@ -15,13 +15,16 @@
 package command
 
 import (
+	"bytes"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/config/param"
 	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/mock"
 	"github.com/Netflix/chaosmonkey/schedule"
 )
 
@@ -137,3 +140,42 @@ func TestCronOutputInSortedOrder(t *testing.T) {
 		t.Errorf("\nExpected:\n%s\nActual:\n%s", expected, actual)
 	}
 }
+
+// Test that replaying a date with a seed prints the same terminations, on
+// that date
+func TestReplaySchedule(t *testing.T) {
+	replay := func(date string) string {
+		var buf bytes.Buffer
+		err := doReplay(mock.Deployment(), mock.ConfigGetter{}, config.Defaults(), nil, 7, date, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	// With seed 7, and 4 apps killed every 5 days on average, some are
+	// picked on Wednesday, Nov. 16, 2016
+	out := replay("2016-11-16")
+	if out == "" {
+		t.Fatal("got no terminations")
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if !strings.HasPrefix(line, "2016-11-16T") {
+			t.Errorf("got termination %q, want one on 2016-11-16", line)
+		}
+	}
+
+	if got := replay("2016-11-16"); got != out {
+		t.Errorf("got different replays with the same seed:\n%s\n%s", out, got)
+	}
+
+	// Nothing is scheduled on weekends
+	if got := replay("2016-11-19"); got != "" {
+		t.Errorf("got terminations on a Saturday: %s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := doReplay(mock.Deployment(), mock.ConfigGetter{}, config.Defaults(), nil, 7, "yesterday", &buf); err == nil {
+		t.Error("got no error for an invalid date")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/deploy"
+	"github.com/Netflix/chaosmonkey/simulate"
+)
+
+// SimulateOptions are the options of the "simulate" command
+type SimulateOptions struct {
+	// Days is the number of runs of the scheduler to simulate
+	Days int
+
+	// Seed is the seed of the simulation
+	Seed int64
+
+	// Format is the output format: table, json or csv
+	Format string
+}
+
+// Simulate executes the "simulate" command. This forecasts the terminations
+// of the next runs of the scheduler for apps and prints them to w, without
+// publishing a schedule or installing any jobs
+func Simulate(d deploy.Deployment, g chaosmonkey.AppConfigGetter, cfg *config.Monkey, apps []string, opts SimulateOptions, w io.Writer) {
+	err := doSimulate(d, g, cfg, apps, opts, w)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+}
+
+func doSimulate(d deploy.Deployment, g chaosmonkey.AppConfigGetter, cfg *config.Monkey, apps []string, opts SimulateOptions, w io.Writer) error {
+	loc, err := cfg.Location()
+	if err != nil {
+		return errors.Wrap(err, "could not get location")
+	}
+
+	// The scheduler logs every decision it makes, which would drown out the
+	// report over many simulated days
+	out := log.Writer()
+	log.SetOutput(ioutil.Discard)
+	report, err := simulate.Run(d, g, cfg, apps, simulate.Options{Start: time.Now(), Days: opts.Days, Seed: opts.Seed})
+	log.SetOutput(out)
+	if err != nil {
+		return errors.Wrap(err, "simulation failed")
+	}
+
+	log.Printf("simulated %d runs of the scheduler from %s to %s with seed=%d", report.Days,
+		report.Start.In(loc).Format(time.RFC3339), report.End.In(loc).Format(time.RFC3339), report.Seed)
+
+	return simulate.Write(w, opts.Format, report, loc)
+}
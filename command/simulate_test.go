@@ -0,0 +1,54 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/mock"
+	"github.com/Netflix/chaosmonkey/simulate"
+)
+
+func TestSimulate(t *testing.T) {
+	var buf bytes.Buffer
+	opts := SimulateOptions{Days: 5, Seed: 1, Format: history.JSON}
+
+	err := doSimulate(mock.Deployment(), mock.ConfigGetter{}, config.Defaults(), []string{"foo", "bar"}, opts, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report simulate.Report
+	err = json.Unmarshal(buf.Bytes(), &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := report.Days, 5; got != want {
+		t.Errorf("got days=%d, want %d", got, want)
+	}
+
+	var apps []string
+	for _, g := range report.Groups {
+		apps = append(apps, g.App)
+	}
+	if got, want := len(apps), 2; got != want || apps[0] != "bar" || apps[1] != "foo" {
+		t.Errorf("got groups of apps %v, want bar and foo", apps)
+	}
+}
@@ -32,6 +32,8 @@ func Terminate(d deps.Deps, app string, account string, region string, stack str
 		if cerr != nil {
 			log.Printf("WARNING could not increment error counter: %v", cerr)
 		}
+		// log.Fatalf exits without running deferred functions
+		publishMetrics(d.MonkeyCfg)
 		log.Fatalf("FATAL %v\n\nstack trace:\n%+v", err, err)
 	}
 }
@@ -0,0 +1,219 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+// BusinessHours are the hours of the day, and the days of the week, during
+// which Chaos Monkey terminates instances
+type BusinessHours struct {
+	// StartHour and EndHour are the hours of the day, in Location, between
+	// which terminations happen
+	StartHour int
+	EndHour   int
+	Location  *time.Location
+
+	// Workdays are the days of the week that are work days
+	Workdays []time.Weekday
+}
+
+// HoursOverride overrides the business hours of an account or a region.
+// Fields that are nil or empty are not overridden
+type HoursOverride struct {
+	StartHour *int
+	EndHour   *int
+	TimeZone  string
+	Workdays  []time.Weekday
+}
+
+// weekdays are the names of the days of the week, in lower case
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// parseWeekdays parses the names of days of the week, either in full
+// (e.g. "Sunday") or abbreviated to three letters (e.g. "sun")
+func parseWeekdays(names []string) ([]time.Weekday, error) {
+	result := make([]time.Weekday, 0, len(names))
+	for _, name := range names {
+		found := false
+		lower := strings.ToLower(strings.TrimSpace(name))
+		for full, day := range weekdays {
+			if lower == full || (len(lower) == 3 && strings.HasPrefix(full, lower)) {
+				result = append(result, day)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, errors.Errorf("invalid day of the week: %q", name)
+		}
+	}
+	return result, nil
+}
+
+// Workdays returns the days of the week that are work days, unless
+// overridden for an account or a region. Defaults to Monday to Friday
+func (m *Monkey) Workdays() ([]time.Weekday, error) {
+	names, err := m.getStringSlice(param.Workdays)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := parseWeekdays(names)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", param.Workdays)
+	}
+
+	return days, nil
+}
+
+// AccountHours returns the overrides of business hours, keyed by account
+// name
+func (m *Monkey) AccountHours() (map[string]HoursOverride, error) {
+	return m.hoursOverrides(param.BusinessHoursAccounts)
+}
+
+// RegionHours returns the overrides of business hours, keyed by region name
+func (m *Monkey) RegionHours() (map[string]HoursOverride, error) {
+	return m.hoursOverrides(param.BusinessHoursRegions)
+}
+
+// hoursOverrides returns the overrides of business hours in the sections
+// under key
+func (m *Monkey) hoursOverrides(key string) (map[string]HoursOverride, error) {
+	result := make(map[string]HoursOverride)
+	for name, value := range m.v.GetStringMap(key) {
+		var fields struct {
+			StartHour *int     `json:"start_hour"`
+			EndHour   *int     `json:"end_hour"`
+			TimeZone  string   `json:"time_zone"`
+			Workdays  []string `json:"workdays"`
+		}
+
+		js, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(js, &fields)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s.%s", key, name)
+		}
+
+		override := HoursOverride{StartHour: fields.StartHour, EndHour: fields.EndHour, TimeZone: fields.TimeZone}
+		if fields.Workdays != nil {
+			override.Workdays, err = parseWeekdays(fields.Workdays)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid %s.%s", key, name)
+			}
+		}
+
+		result[name] = override
+	}
+
+	return result, nil
+}
+
+// apply overrides the fields of hours that are set in o
+func (o HoursOverride) apply(hours *hoursFields) {
+	if o.StartHour != nil {
+		hours.startHour = *o.StartHour
+	}
+	if o.EndHour != nil {
+		hours.endHour = *o.EndHour
+	}
+	if o.TimeZone != "" {
+		hours.timeZone = o.TimeZone
+	}
+	if o.Workdays != nil {
+		hours.workdays = o.Workdays
+	}
+}
+
+// hoursFields are business hours before the time zone is loaded
+type hoursFields struct {
+	startHour, endHour int
+	timeZone           string
+	workdays           []time.Weekday
+}
+
+// BusinessHours returns the business hours of a region of an account. The
+// hours of [business_hours.regions.<region>] override those of
+// [business_hours.accounts.<account>], which override start_hour, end_hour,
+// time_zone and workdays in [chaosmonkey]. Either account or region may be
+// blank
+func (m *Monkey) BusinessHours(account, region string) (BusinessHours, error) {
+	workdays, err := m.Workdays()
+	if err != nil {
+		return BusinessHours{}, err
+	}
+
+	hours := hoursFields{
+		startHour: m.StartHour(),
+		endHour:   m.EndHour(),
+		timeZone:  m.v.GetString(param.TimeZone),
+		workdays:  workdays,
+	}
+
+	accounts, err := m.AccountHours()
+	if err != nil {
+		return BusinessHours{}, err
+	}
+	regions, err := m.RegionHours()
+	if err != nil {
+		return BusinessHours{}, err
+	}
+
+	if o, ok := accounts[account]; ok && account != "" {
+		o.apply(&hours)
+	}
+	if o, ok := regions[region]; ok && region != "" {
+		o.apply(&hours)
+	}
+
+	if hours.startHour < clockStartHour || hours.endHour > clockEndHour+1 || hours.startHour >= hours.endHour {
+		return BusinessHours{}, errors.Errorf("invalid business hours for account=%q region=%q: start hour %d must be before end hour %d, within 0-24", account, region, hours.startHour, hours.endHour)
+	}
+
+	if len(hours.workdays) == 0 {
+		return BusinessHours{}, errors.Errorf("no work days for account=%q region=%q", account, region)
+	}
+
+	loc, err := time.LoadLocation(hours.timeZone)
+	if err != nil {
+		return BusinessHours{}, errors.Wrapf(err, "invalid time zone for account=%q region=%q", account, region)
+	}
+
+	return BusinessHours{
+		StartHour: hours.startHour,
+		EndHour:   hours.endHour,
+		Location:  loc,
+		Workdays:  hours.workdays,
+	}, nil
+}
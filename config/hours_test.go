@@ -0,0 +1,105 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+func TestBusinessHoursDefaults(t *testing.T) {
+	hours, err := Defaults().BusinessHours("prod", "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	if hours.StartHour != 9 || hours.EndHour != 15 || hours.Location.String() != "America/Los_Angeles" || !reflect.DeepEqual(hours.Workdays, want) {
+		t.Errorf("unexpected default business hours: %+v", hours)
+	}
+}
+
+func TestBusinessHoursOverrides(t *testing.T) {
+	m := Defaults()
+	m.Set(param.BusinessHoursAccounts, map[string]interface{}{
+		"prod": map[string]interface{}{"start_hour": 10, "workdays": []string{"Sunday", "mon", "tue", "wed", "thu"}},
+	})
+	m.Set(param.BusinessHoursRegions, map[string]interface{}{
+		"eu-west-1": map[string]interface{}{"start_hour": 8, "end_hour": 14, "time_zone": "Europe/Dublin"},
+	})
+
+	sunToThu := []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday}
+	monToFri := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+	tests := []struct {
+		account, region string
+		start, end      int
+		zone            string
+		workdays        []time.Weekday
+	}{
+		{"test", "us-east-1", 9, 15, "America/Los_Angeles", monToFri},
+		{"prod", "us-east-1", 10, 15, "America/Los_Angeles", sunToThu},
+		{"test", "eu-west-1", 8, 14, "Europe/Dublin", monToFri},
+
+		// The region overrides the account
+		{"prod", "eu-west-1", 8, 14, "Europe/Dublin", sunToThu},
+	}
+
+	for _, tt := range tests {
+		hours, err := m.BusinessHours(tt.account, tt.region)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hours.StartHour != tt.start || hours.EndHour != tt.end || hours.Location.String() != tt.zone || !reflect.DeepEqual(hours.Workdays, tt.workdays) {
+			t.Errorf("account=%s region=%s: got %+v", tt.account, tt.region, hours)
+		}
+	}
+}
+
+func TestBusinessHoursInvalid(t *testing.T) {
+	tests := []map[string]interface{}{
+		{"start_hour": 15, "end_hour": 9},
+		{"time_zone": "Mars/Olympus_Mons"},
+		{"workdays": []string{"someday"}},
+		{"workdays": []string{}},
+	}
+
+	for _, tt := range tests {
+		m := Defaults()
+		m.Set(param.BusinessHoursRegions, map[string]interface{}{"us-east-1": tt})
+
+		if _, err := m.BusinessHours("prod", "us-east-1"); err == nil {
+			t.Errorf("%v: expected error", tt)
+		}
+	}
+}
+
+func TestDefaultCronForOtherWorkWeek(t *testing.T) {
+	m := Defaults()
+	m.Set(param.Workdays, []string{"sun", "mon", "tue", "wed", "thu"})
+
+	got, err := m.CronExpression()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "0 7 * * *"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
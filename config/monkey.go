@@ -52,6 +52,7 @@ func (m *Monkey) setDefaults() {
 	m.v.SetDefault(param.StartHour, 9)
 	m.v.SetDefault(param.EndHour, 15)
 	m.v.SetDefault(param.TimeZone, "America/Los_Angeles")
+	m.v.SetDefault(param.Workdays, []string{"monday", "tuesday", "wednesday", "thursday", "friday"})
 	m.v.SetDefault(param.CronPath, "/etc/cron.d/chaosmonkey-daily-terminations")
 	m.v.SetDefault(param.TermPath, "/apps/chaosmonkey/chaosmonkey-terminate.sh")
 	m.v.SetDefault(param.TermAccount, "root")
@@ -60,12 +61,109 @@ func (m *Monkey) setDefaults() {
 	m.v.SetDefault(param.Decryptor, "")
 	m.v.SetDefault(param.OutageChecker, "")
 
-	m.v.SetDefault(param.DatabasePort, 3306)
+	m.v.SetDefault(param.DatabaseDriver, "mysql")
+	m.v.SetDefault(param.DatabasePath, "/apps/chaosmonkey/chaosmonkey.db")
 
 	m.v.SetDefault(param.SpinnakerEndpoint, "")
 	m.v.SetDefault(param.SpinnakerCertificate, "")
 	m.v.SetDefault(param.SpinnakerEncryptedPassword, "")
 	m.v.SetDefault(param.SpinnakerUser, "")
+	m.v.SetDefault(param.SpinnakerWorkers, 8)
+	m.v.SetDefault(param.SpinnakerRequestsPerSecond, 0)
+	m.v.SetDefault(param.SpinnakerTimeout, "30s")
+	m.v.SetDefault(param.SpinnakerRetries, 3)
+	m.v.SetDefault(param.SpinnakerRetryBackoff, "500ms")
+	m.v.SetDefault(param.SpinnakerBreakerThreshold, 10)
+	m.v.SetDefault(param.SpinnakerBreakerCooldown, "30s")
+	m.v.SetDefault(param.SpinnakerTaskTimeout, "5m")
+	m.v.SetDefault(param.SpinnakerTaskPollInterval, "5s")
+	m.v.SetDefault(param.SpinnakerCertFile, "")
+	m.v.SetDefault(param.SpinnakerKeyFile, "")
+	m.v.SetDefault(param.SpinnakerCAFile, "")
+	m.v.SetDefault(param.SpinnakerTokenFile, "")
+	m.v.SetDefault(param.SpinnakerOAuth2TokenURL, "")
+	m.v.SetDefault(param.SpinnakerOAuth2ClientID, "")
+	m.v.SetDefault(param.SpinnakerOAuth2Secret, "")
+	m.v.SetDefault(param.SpinnakerOAuth2Scopes, []string{})
+	m.v.SetDefault(param.SpinnakerProxy, "")
+
+	m.v.SetDefault(param.WebhookURLs, []string{})
+	m.v.SetDefault(param.WebhookEncryptedSecret, "")
+	m.v.SetDefault(param.WebhookTimeout, "10s")
+	m.v.SetDefault(param.WebhookRetries, 2)
+	m.v.SetDefault(param.WebhookBlockOnFailure, true)
+
+	m.v.SetDefault(param.EmailHost, "")
+	m.v.SetDefault(param.EmailPort, 25)
+	m.v.SetDefault(param.EmailUser, "")
+	m.v.SetDefault(param.EmailEncryptedPassword, "")
+	m.v.SetDefault(param.EmailFrom, "")
+	m.v.SetDefault(param.EmailFallbackAddress, "")
+	m.v.SetDefault(param.EmailTemplatePath, "")
+	m.v.SetDefault(param.EmailNotifyLeashed, false)
+	m.v.SetDefault(param.EmailBlockOnFailure, true)
+
+	m.v.SetDefault(param.AESGCMKeyFile, "")
+
+	m.v.SetDefault(param.VaultAddress, "")
+	m.v.SetDefault(param.VaultNamespace, "")
+	m.v.SetDefault(param.VaultAuthMethod, "token")
+	m.v.SetDefault(param.VaultAuthMount, "")
+	m.v.SetDefault(param.VaultTokenPath, "")
+	m.v.SetDefault(param.VaultRoleID, "")
+	m.v.SetDefault(param.VaultSecretIDPath, "")
+	m.v.SetDefault(param.VaultKubernetesRole, "")
+	m.v.SetDefault(param.VaultKubernetesTokenPath, "/var/run/secrets/kubernetes.io/serviceaccount/token")
+	m.v.SetDefault(param.VaultTransitMount, "transit")
+	m.v.SetDefault(param.VaultTransitKey, "chaosmonkey")
+	m.v.SetDefault(param.VaultKVVersion, 2)
+	m.v.SetDefault(param.VaultTimeout, "10s")
+
+	m.v.SetDefault(param.HTTPOutageURL, "")
+	m.v.SetDefault(param.HTTPOutageJSONPath, "")
+	m.v.SetDefault(param.HTTPOutageOutageValues, []string{})
+	m.v.SetDefault(param.HTTPOutageRegex, "")
+	m.v.SetDefault(param.HTTPOutageTimeout, "5s")
+	m.v.SetDefault(param.HTTPOutageCacheTTL, "60s")
+	m.v.SetDefault(param.HTTPOutageCachePath, "")
+
+	m.v.SetDefault(param.AlertmanagerURL, "")
+	m.v.SetDefault(param.AlertmanagerMatchers, []string{})
+	m.v.SetDefault(param.AlertmanagerTimeout, "5s")
+
+	m.v.SetDefault(param.KubernetesEndpoint, "")
+	m.v.SetDefault(param.KubernetesTokenPath, "/var/run/secrets/kubernetes.io/serviceaccount/token")
+	m.v.SetDefault(param.KubernetesCAPath, "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt")
+	m.v.SetDefault(param.KubernetesRegion, "kubernetes")
+	m.v.SetDefault(param.KubernetesAppLabel, "app.kubernetes.io/name")
+	m.v.SetDefault(param.KubernetesAccountLabel, "")
+	m.v.SetDefault(param.KubernetesStackLabel, "chaosmonkey.netflix.com/stack")
+	m.v.SetDefault(param.KubernetesConfigAnnotation, "chaosmonkey.netflix.com/config")
+	m.v.SetDefault(param.KubernetesOwnerAnnotation, "chaosmonkey.netflix.com/owner")
+	m.v.SetDefault(param.KubernetesGracePeriod, "30s")
+	m.v.SetDefault(param.KubernetesTimeout, "10s")
+	m.v.SetDefault(param.KubernetesCronJobNamespace, "default")
+	m.v.SetDefault(param.KubernetesCronJobImage, "")
+	m.v.SetDefault(param.KubernetesCronJobCommand, []string{"chaosmonkey"})
+	m.v.SetDefault(param.KubernetesCronJobServiceAccount, "")
+
+	m.v.SetDefault(param.SystemdUnitDir, "/etc/systemd/system")
+	m.v.SetDefault(param.SystemdSystemctl, "systemctl")
+
+	m.v.SetDefault(param.AWSRegions, []string{})
+	m.v.SetDefault(param.AWSAccessKeyID, "")
+	m.v.SetDefault(param.AWSEncryptedSecretAccessKey, "")
+	m.v.SetDefault(param.AWSEndpoint, "")
+	m.v.SetDefault(param.AWSTagPrefix, "chaosmonkey:")
+	m.v.SetDefault(param.AWSTimeout, "10s")
+
+	m.v.SetDefault(param.FilePath, "/apps/chaosmonkey/deployment.yaml")
+
+	m.v.SetDefault(param.PrometheusPushgateway, "")
+	m.v.SetDefault(param.PrometheusJob, "chaosmonkey")
+	m.v.SetDefault(param.PrometheusTextfileDir, "")
+	m.v.SetDefault(param.PrometheusPushTimeout, "10s")
+	m.v.SetDefault(param.PrometheusListen, "")
 
 	m.v.SetDefault(param.DynamicProvider, "")
 	m.v.SetDefault(param.DynamicEndpoint, "")
@@ -74,6 +172,12 @@ func (m *Monkey) setDefaults() {
 	m.v.SetDefault(param.ScheduleCronPath, "/etc/cron.d/chaosmonkey-schedule")
 	m.v.SetDefault(param.SchedulePath, "/apps/chaosmonkey/chaosmonkey-schedule.sh")
 	m.v.SetDefault(param.LogPath, "/var/log")
+	m.v.SetDefault(param.KillSwitch, "database")
+	m.v.SetDefault(param.KillSwitchPath, "/apps/chaosmonkey/kill-switch.json")
+	m.v.SetDefault(param.Environment, "")
+	m.v.SetDefault(param.TestEnvironments, []string{"test"})
+	m.v.SetDefault(param.Backend, "spinnaker")
+	m.v.SetDefault(param.JobFormat, "crontab")
 }
 
 func (m *Monkey) setupEnvVarReader() {
@@ -316,12 +420,12 @@ func (m *Monkey) getStringSlice(key string) ([]string, error) {
 	// represents a list of strings, so we need to handle both cases
 	t := m.v.Get(key)
 	if t == nil {
-		return nil, fmt.Errorf("%s not specified", param.Accounts)
+		return nil, fmt.Errorf("%s not specified", key)
 	}
 
 	switch t := t.(type) {
 	default:
-		return nil, fmt.Errorf("%s: unexpected type %T", param.Accounts, t)
+		return nil, fmt.Errorf("%s: unexpected type %T", key, t)
 	case []string: // When set explicitly in code
 		return t, nil
 	case []interface{}: // When reading from config file
@@ -334,6 +438,15 @@ func (m *Monkey) getStringSlice(key string) ([]string, error) {
 	}
 }
 
+// optionalStringSlice is like getStringSlice, but returns an empty list if
+// key is not set
+func (m *Monkey) optionalStringSlice(key string) ([]string, error) {
+	if !m.v.IsSet(key) {
+		return nil, nil
+	}
+	return m.getStringSlice(key)
+}
+
 // SpinnakerEndpoint returns the spinnaker endpoint
 func (m *Monkey) SpinnakerEndpoint() string {
 	return m.v.GetString(param.SpinnakerEndpoint)
@@ -358,6 +471,118 @@ func (m *Monkey) SpinnakerUser() string {
 	return m.v.GetString(param.SpinnakerUser)
 }
 
+// SpinnakerWorkers returns the number of apps that are retrieved from
+// Spinnaker concurrently
+func (m *Monkey) SpinnakerWorkers() int {
+	return m.v.GetInt(param.SpinnakerWorkers)
+}
+
+// SpinnakerRequestsPerSecond returns the maximum rate of requests to
+// Spinnaker, across all workers. If zero, the rate is not limited
+func (m *Monkey) SpinnakerRequestsPerSecond() float64 {
+	return m.v.GetFloat64(param.SpinnakerRequestsPerSecond)
+}
+
+// SpinnakerTimeout returns the timeout of each attempt of a request to
+// Spinnaker. If zero, requests do not time out
+func (m *Monkey) SpinnakerTimeout() time.Duration {
+	return m.v.GetDuration(param.SpinnakerTimeout)
+}
+
+// SpinnakerRetries returns how many times a failed request to Spinnaker is
+// retried
+func (m *Monkey) SpinnakerRetries() int {
+	return m.v.GetInt(param.SpinnakerRetries)
+}
+
+// SpinnakerRetryBackoff returns the delay before the first retry of a
+// request to Spinnaker. The delay doubles with each retry
+func (m *Monkey) SpinnakerRetryBackoff() time.Duration {
+	return m.v.GetDuration(param.SpinnakerRetryBackoff)
+}
+
+// SpinnakerBreakerThreshold returns the number of consecutive failed requests
+// after which requests to Spinnaker are stopped for a while. If zero,
+// requests are never stopped
+func (m *Monkey) SpinnakerBreakerThreshold() int {
+	return m.v.GetInt(param.SpinnakerBreakerThreshold)
+}
+
+// SpinnakerBreakerCooldown returns how long requests to Spinnaker are stopped
+// for after repeated failures
+func (m *Monkey) SpinnakerBreakerCooldown() time.Duration {
+	return m.v.GetDuration(param.SpinnakerBreakerCooldown)
+}
+
+// SpinnakerTaskTimeout returns how long to wait for a termination task to
+// complete in Spinnaker. If zero, terminations are not verified
+func (m *Monkey) SpinnakerTaskTimeout() time.Duration {
+	return m.v.GetDuration(param.SpinnakerTaskTimeout)
+}
+
+// SpinnakerTaskPollInterval returns how often the status of a termination
+// task is checked while waiting for it to complete
+func (m *Monkey) SpinnakerTaskPollInterval() time.Duration {
+	return m.v.GetDuration(param.SpinnakerTaskPollInterval)
+}
+
+// SpinnakerCertFile returns a path to a PEM file that contains a TLS client
+// cert for authenticating against Spinnaker, as an alternative to a .p12 file
+func (m *Monkey) SpinnakerCertFile() string {
+	return m.v.GetString(param.SpinnakerCertFile)
+}
+
+// SpinnakerKeyFile returns a path to a PEM file that contains the private key
+// for the cert returned by SpinnakerCertFile
+func (m *Monkey) SpinnakerKeyFile() string {
+	return m.v.GetString(param.SpinnakerKeyFile)
+}
+
+// SpinnakerCAFile returns a path to a PEM bundle of CA certs that are trusted
+// to sign Spinnaker's cert, in addition to the system CAs
+func (m *Monkey) SpinnakerCAFile() string {
+	return m.v.GetString(param.SpinnakerCAFile)
+}
+
+// SpinnakerTokenFile returns a path to a file that contains a bearer token
+// that is sent to Spinnaker. The file is read again when it changes
+func (m *Monkey) SpinnakerTokenFile() string {
+	return m.v.GetString(param.SpinnakerTokenFile)
+}
+
+// SpinnakerOAuth2TokenURL returns the URL of the OAuth2 token endpoint that
+// bearer tokens for Spinnaker are requested from, using the client
+// credentials grant
+func (m *Monkey) SpinnakerOAuth2TokenURL() string {
+	return m.v.GetString(param.SpinnakerOAuth2TokenURL)
+}
+
+// SpinnakerOAuth2ClientID returns the OAuth2 client id used to request
+// bearer tokens for Spinnaker
+func (m *Monkey) SpinnakerOAuth2ClientID() string {
+	return m.v.GetString(param.SpinnakerOAuth2ClientID)
+}
+
+// SpinnakerOAuth2EncryptedClientSecret returns the OAuth2 client secret used
+// to request bearer tokens for Spinnaker. The encryption scheme is defined by
+// the Decryptor parameter
+func (m *Monkey) SpinnakerOAuth2EncryptedClientSecret() string {
+	return m.v.GetString(param.SpinnakerOAuth2Secret)
+}
+
+// SpinnakerOAuth2Scopes returns the scopes requested with bearer tokens for
+// Spinnaker
+func (m *Monkey) SpinnakerOAuth2Scopes() ([]string, error) {
+	return m.getStringSlice(param.SpinnakerOAuth2Scopes)
+}
+
+// SpinnakerProxy returns the URL of the HTTP proxy that requests to Spinnaker
+// are sent through. If empty, the proxy is taken from the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables
+func (m *Monkey) SpinnakerProxy() string {
+	return m.v.GetString(param.SpinnakerProxy)
+}
+
 // Decryptor returns an interface for decrypting sercrets
 func (m *Monkey) Decryptor() string {
 	return m.v.GetString(param.Decryptor)
@@ -369,14 +594,29 @@ func (m *Monkey) OutageChecker() string {
 	return m.v.GetString(param.OutageChecker)
 }
 
+// DatabaseDriver returns the kind of database that stores the Chaos Monkey
+// state. Supported values: "mysql", "postgres", "sqlite"
+func (m *Monkey) DatabaseDriver() string {
+	return m.v.GetString(param.DatabaseDriver)
+}
+
 // DatabaseHost returns the hostname the database is running on
 func (m *Monkey) DatabaseHost() string {
 	return m.v.GetString(param.DatabaseHost)
 }
 
-// DatabasePort returns the port the database is listening on
+// DatabasePort returns the port the database is listening on. If not
+// specified, it is the default port of the database driver
 func (m *Monkey) DatabasePort() int {
-	return m.v.GetInt(param.DatabasePort)
+	if m.v.IsSet(param.DatabasePort) {
+		return m.v.GetInt(param.DatabasePort)
+	}
+
+	if m.DatabaseDriver() == "postgres" {
+		return 5432
+	}
+
+	return 3306
 }
 
 // DatabaseUser returns the database user associated with the credentials
@@ -390,12 +630,466 @@ func (m *Monkey) DatabaseName() string {
 	return m.v.GetString(param.DatabaseName)
 }
 
+// DatabasePath returns the path to the database file, if the database driver
+// is "sqlite"
+func (m *Monkey) DatabasePath() string {
+	return m.v.GetString(param.DatabasePath)
+}
+
 // DatabaseEncryptedPassword returns an encrypted version of the database
 // credentials
 func (m *Monkey) DatabaseEncryptedPassword() string {
 	return m.v.GetString(param.DatabaseEncryptedPassword)
 }
 
+// CalendarHolidays returns the path to the calendar of holidays that apply to
+// all accounts and regions, in iCalendar format or as a list of dates. If
+// blank, only weekends are excluded from work days
+func (m *Monkey) CalendarHolidays() string {
+	return m.v.GetString(param.CalendarHolidays)
+}
+
+// CalendarAccounts returns the paths to calendars of additional holidays,
+// keyed by account name
+func (m *Monkey) CalendarAccounts() map[string]string {
+	return m.v.GetStringMapString(param.CalendarAccounts)
+}
+
+// CalendarRegions returns the paths to calendars of additional holidays,
+// keyed by region name
+func (m *Monkey) CalendarRegions() map[string]string {
+	return m.v.GetStringMapString(param.CalendarRegions)
+}
+
+// WebhookURLs returns the list of URLs that the webhook tracker POSTs
+// termination events to
+func (m *Monkey) WebhookURLs() ([]string, error) {
+	return m.getStringSlice(param.WebhookURLs)
+}
+
+// WebhookHeaders returns additional HTTP headers that the webhook tracker
+// sends with each request
+func (m *Monkey) WebhookHeaders() map[string]string {
+	return m.v.GetStringMapString(param.WebhookHeaders)
+}
+
+// WebhookEncryptedSecret returns an encrypted version of the secret used to
+// sign webhook requests. If blank, requests are not signed
+func (m *Monkey) WebhookEncryptedSecret() string {
+	return m.v.GetString(param.WebhookEncryptedSecret)
+}
+
+// WebhookTimeout returns the timeout for a single webhook request
+func (m *Monkey) WebhookTimeout() time.Duration {
+	return m.v.GetDuration(param.WebhookTimeout)
+}
+
+// WebhookRetries returns the number of times a failed webhook delivery is
+// retried
+func (m *Monkey) WebhookRetries() int {
+	return m.v.GetInt(param.WebhookRetries)
+}
+
+// WebhookBlockOnFailure returns true if a failed webhook delivery should
+// prevent the instance from being terminated
+func (m *Monkey) WebhookBlockOnFailure() bool {
+	return m.v.GetBool(param.WebhookBlockOnFailure)
+}
+
+// EmailHost returns the hostname of the SMTP server used to send
+// notification emails
+func (m *Monkey) EmailHost() string {
+	return m.v.GetString(param.EmailHost)
+}
+
+// EmailPort returns the port the SMTP server is listening on
+func (m *Monkey) EmailPort() int {
+	return m.v.GetInt(param.EmailPort)
+}
+
+// EmailUser returns the user for SMTP authentication. If blank, Chaos Monkey
+// does not authenticate against the SMTP server
+func (m *Monkey) EmailUser() string {
+	return m.v.GetString(param.EmailUser)
+}
+
+// EmailEncryptedPassword returns an encrypted version of the SMTP password
+func (m *Monkey) EmailEncryptedPassword() string {
+	return m.v.GetString(param.EmailEncryptedPassword)
+}
+
+// EmailFrom returns the sender address of notification emails
+func (m *Monkey) EmailFrom() string {
+	return m.v.GetString(param.EmailFrom)
+}
+
+// EmailFallbackAddress returns the address that notifications are sent to
+// when an app does not have an owner email
+func (m *Monkey) EmailFallbackAddress() string {
+	return m.v.GetString(param.EmailFallbackAddress)
+}
+
+// EmailTemplatePath returns the path to a file with text/template definitions
+// that override the default email templates
+func (m *Monkey) EmailTemplatePath() string {
+	return m.v.GetString(param.EmailTemplatePath)
+}
+
+// EmailNotifyLeashed returns true if owners should also be emailed about
+// leashed terminations
+func (m *Monkey) EmailNotifyLeashed() bool {
+	return m.v.GetBool(param.EmailNotifyLeashed)
+}
+
+// EmailBlockOnFailure returns true if a failure to send a notification email
+// should prevent the instance from being terminated
+func (m *Monkey) EmailBlockOnFailure() bool {
+	return m.v.GetBool(param.EmailBlockOnFailure)
+}
+
+// AESGCMKeyFile returns the path to the file containing the base64-encoded
+// 256-bit key used by the aesgcm decryptor
+func (m *Monkey) AESGCMKeyFile() string {
+	return m.v.GetString(param.AESGCMKeyFile)
+}
+
+// VaultAddress returns the base URL of the Vault server, e.g.
+// "https://vault.example.com:8200"
+func (m *Monkey) VaultAddress() string {
+	return m.v.GetString(param.VaultAddress)
+}
+
+// VaultNamespace returns the Vault Enterprise namespace. May be blank
+func (m *Monkey) VaultNamespace() string {
+	return m.v.GetString(param.VaultNamespace)
+}
+
+// VaultAuthMethod returns how Chaos Monkey authenticates to Vault. Valid
+// values are "token", "approle" and "kubernetes"
+func (m *Monkey) VaultAuthMethod() string {
+	return m.v.GetString(param.VaultAuthMethod)
+}
+
+// VaultAuthMount returns the path the auth method is mounted at. If blank,
+// the name of the auth method is used
+func (m *Monkey) VaultAuthMount() string {
+	return m.v.GetString(param.VaultAuthMount)
+}
+
+// VaultTokenPath returns the path to a file containing a Vault token, used by
+// the token auth method. If blank, the VAULT_TOKEN environment variable is
+// used
+func (m *Monkey) VaultTokenPath() string {
+	return m.v.GetString(param.VaultTokenPath)
+}
+
+// VaultRoleID returns the role id used by the approle auth method
+func (m *Monkey) VaultRoleID() string {
+	return m.v.GetString(param.VaultRoleID)
+}
+
+// VaultSecretIDPath returns the path to a file containing the secret id used
+// by the approle auth method
+func (m *Monkey) VaultSecretIDPath() string {
+	return m.v.GetString(param.VaultSecretIDPath)
+}
+
+// VaultKubernetesRole returns the Vault role used by the kubernetes auth
+// method
+func (m *Monkey) VaultKubernetesRole() string {
+	return m.v.GetString(param.VaultKubernetesRole)
+}
+
+// VaultKubernetesTokenPath returns the path to the service account token used
+// by the kubernetes auth method
+func (m *Monkey) VaultKubernetesTokenPath() string {
+	return m.v.GetString(param.VaultKubernetesTokenPath)
+}
+
+// VaultTransitMount returns the path the transit secrets engine is mounted at
+func (m *Monkey) VaultTransitMount() string {
+	return m.v.GetString(param.VaultTransitMount)
+}
+
+// VaultTransitKey returns the name of the transit key used to decrypt
+// "vault:" ciphertexts
+func (m *Monkey) VaultTransitKey() string {
+	return m.v.GetString(param.VaultTransitKey)
+}
+
+// VaultKVVersion returns the version (1 or 2) of the KV secrets engine used
+// to look up "kv:" references
+func (m *Monkey) VaultKVVersion() int {
+	return m.v.GetInt(param.VaultKVVersion)
+}
+
+// VaultTimeout returns the timeout for requests to Vault
+func (m *Monkey) VaultTimeout() time.Duration {
+	return m.v.GetDuration(param.VaultTimeout)
+}
+
+// HTTPOutageURL returns the URL of the status page polled by the http outage
+// checker
+func (m *Monkey) HTTPOutageURL() string {
+	return m.v.GetString(param.HTTPOutageURL)
+}
+
+// HTTPOutageHeaders returns extra HTTP headers sent to the status page
+func (m *Monkey) HTTPOutageHeaders() map[string]string {
+	return m.v.GetStringMapString(param.HTTPOutageHeaders)
+}
+
+// HTTPOutageJSONPath returns the dotted path of the field in the JSON
+// response that indicates an outage, e.g. "status.indicator"
+func (m *Monkey) HTTPOutageJSONPath() string {
+	return m.v.GetString(param.HTTPOutageJSONPath)
+}
+
+// HTTPOutageOutageValues returns the values of the field at
+// HTTPOutageJSONPath that indicate an outage
+func (m *Monkey) HTTPOutageOutageValues() ([]string, error) {
+	return m.getStringSlice(param.HTTPOutageOutageValues)
+}
+
+// HTTPOutageRegex returns a regular expression that indicates an outage if it
+// matches the response body
+func (m *Monkey) HTTPOutageRegex() string {
+	return m.v.GetString(param.HTTPOutageRegex)
+}
+
+// HTTPOutageTimeout returns the timeout for requests to the status page
+func (m *Monkey) HTTPOutageTimeout() time.Duration {
+	return m.v.GetDuration(param.HTTPOutageTimeout)
+}
+
+// HTTPOutageCacheTTL returns how long the result of a status page check is
+// reused for
+func (m *Monkey) HTTPOutageCacheTTL() time.Duration {
+	return m.v.GetDuration(param.HTTPOutageCacheTTL)
+}
+
+// HTTPOutageCachePath returns the file that the result of a status page check
+// is cached in, so that it is shared between terminate invocations. If blank,
+// a file in the chaosmonkey directory of the user's cache directory is used
+func (m *Monkey) HTTPOutageCachePath() string {
+	return m.v.GetString(param.HTTPOutageCachePath)
+}
+
+// AlertmanagerURL returns the base URL of the Alertmanager queried by the
+// alertmanager outage checker
+func (m *Monkey) AlertmanagerURL() string {
+	return m.v.GetString(param.AlertmanagerURL)
+}
+
+// AlertmanagerMatchers returns the label matchers, e.g. "severity=critical",
+// that an alert must match to indicate an outage
+func (m *Monkey) AlertmanagerMatchers() ([]string, error) {
+	return m.getStringSlice(param.AlertmanagerMatchers)
+}
+
+// AlertmanagerAccountMatchers returns additional label matchers used when
+// checking for an outage in account. Returns an empty list if none are
+// configured
+func (m *Monkey) AlertmanagerAccountMatchers(account string) ([]string, error) {
+	return m.optionalStringSlice(param.AlertmanagerAccounts + "." + account)
+}
+
+// AlertmanagerRegionMatchers returns additional label matchers used when
+// checking for an outage in region. Returns an empty list if none are
+// configured
+func (m *Monkey) AlertmanagerRegionMatchers(region string) ([]string, error) {
+	return m.optionalStringSlice(param.AlertmanagerRegions + "." + region)
+}
+
+// AlertmanagerTimeout returns the timeout for requests to Alertmanager
+func (m *Monkey) AlertmanagerTimeout() time.Duration {
+	return m.v.GetDuration(param.AlertmanagerTimeout)
+}
+
+// KubernetesEndpoint returns the URL of the Kubernetes API server. If blank,
+// the in-cluster API server is used
+func (m *Monkey) KubernetesEndpoint() string {
+	return m.v.GetString(param.KubernetesEndpoint)
+}
+
+// KubernetesTokenPath returns the path to the bearer token used to
+// authenticate against the Kubernetes API server
+func (m *Monkey) KubernetesTokenPath() string {
+	return m.v.GetString(param.KubernetesTokenPath)
+}
+
+// KubernetesCAPath returns the path to the CA certificate of the Kubernetes
+// API server
+func (m *Monkey) KubernetesCAPath() string {
+	return m.v.GetString(param.KubernetesCAPath)
+}
+
+// KubernetesNamespaces returns the namespaces Chaos Monkey looks for
+// workloads in. An empty list means all namespaces
+func (m *Monkey) KubernetesNamespaces() ([]string, error) {
+	return m.optionalStringSlice(param.KubernetesNamespaces)
+}
+
+// KubernetesRegion returns the region name that pods in the Kubernetes
+// cluster are reported in
+func (m *Monkey) KubernetesRegion() string {
+	return m.v.GetString(param.KubernetesRegion)
+}
+
+// KubernetesAppLabel returns the workload label that holds the app name
+func (m *Monkey) KubernetesAppLabel() string {
+	return m.v.GetString(param.KubernetesAppLabel)
+}
+
+// KubernetesAccountLabel returns the workload label that holds the account
+// name. If blank, the namespace is used as the account
+func (m *Monkey) KubernetesAccountLabel() string {
+	return m.v.GetString(param.KubernetesAccountLabel)
+}
+
+// KubernetesStackLabel returns the workload label that holds the stack name
+func (m *Monkey) KubernetesStackLabel() string {
+	return m.v.GetString(param.KubernetesStackLabel)
+}
+
+// KubernetesConfigAnnotation returns the workload annotation that holds the
+// Chaos Monkey config of an app
+func (m *Monkey) KubernetesConfigAnnotation() string {
+	return m.v.GetString(param.KubernetesConfigAnnotation)
+}
+
+// KubernetesOwnerAnnotation returns the workload annotation that holds the
+// owner email of an app
+func (m *Monkey) KubernetesOwnerAnnotation() string {
+	return m.v.GetString(param.KubernetesOwnerAnnotation)
+}
+
+// KubernetesGracePeriod returns the grace period given to pods when they are
+// deleted
+func (m *Monkey) KubernetesGracePeriod() time.Duration {
+	return m.v.GetDuration(param.KubernetesGracePeriod)
+}
+
+// KubernetesCronJobNamespace returns the namespace of the CronJobs
+func (m *Monkey) KubernetesCronJobNamespace() string {
+	return m.v.GetString(param.KubernetesCronJobNamespace)
+}
+
+// KubernetesCronJobImage returns the container image, with the chaosmonkey
+// binary and config file, that the CronJobs run
+func (m *Monkey) KubernetesCronJobImage() string {
+	return m.v.GetString(param.KubernetesCronJobImage)
+}
+
+// KubernetesCronJobCommand returns the command that runs chaosmonkey in the
+// container image of the CronJobs
+func (m *Monkey) KubernetesCronJobCommand() ([]string, error) {
+	return m.getStringSlice(param.KubernetesCronJobCommand)
+}
+
+// KubernetesCronJobServiceAccount returns the service account of the pods
+// of the CronJobs. If blank, the namespace's default is used
+func (m *Monkey) KubernetesCronJobServiceAccount() string {
+	return m.v.GetString(param.KubernetesCronJobServiceAccount)
+}
+
+// KubernetesTimeout returns the timeout for requests to the Kubernetes API
+// server
+func (m *Monkey) KubernetesTimeout() time.Duration {
+	return m.v.GetDuration(param.KubernetesTimeout)
+}
+
+// AWSAccounts returns a map from account name (e.g., "prod", "test") to the
+// ARN of the IAM role that Chaos Monkey assumes in that account. A blank role
+// means the account's resources are accessed with the base credentials
+func (m *Monkey) AWSAccounts() map[string]string {
+	return m.v.GetStringMapString(param.AWSAccounts)
+}
+
+// AWSRegions returns the regions that Chaos Monkey looks for autoscaling
+// groups in
+func (m *Monkey) AWSRegions() ([]string, error) {
+	return m.getStringSlice(param.AWSRegions)
+}
+
+// AWSAccessKeyID returns the access key id of the base AWS credentials. If
+// blank, credentials are taken from the environment or the instance profile
+func (m *Monkey) AWSAccessKeyID() string {
+	return m.v.GetString(param.AWSAccessKeyID)
+}
+
+// AWSEncryptedSecretAccessKey returns an encrypted version of the secret
+// access key of the base AWS credentials. The encryption scheme is defined by
+// the Decryptor parameter
+func (m *Monkey) AWSEncryptedSecretAccessKey() string {
+	return m.v.GetString(param.AWSEncryptedSecretAccessKey)
+}
+
+// AWSEndpoint returns a URL that all AWS API requests are sent to instead of
+// the regional endpoints. It is intended for local stand-ins of AWS
+func (m *Monkey) AWSEndpoint() string {
+	return m.v.GetString(param.AWSEndpoint)
+}
+
+// AWSTagPrefix returns the prefix of the autoscaling group tags that hold
+// the Chaos Monkey config of an app
+func (m *Monkey) AWSTagPrefix() string {
+	return m.v.GetString(param.AWSTagPrefix)
+}
+
+// AWSTimeout returns the timeout for requests to AWS
+func (m *Monkey) AWSTimeout() time.Duration {
+	return m.v.GetDuration(param.AWSTimeout)
+}
+
+// FilePath returns the path to the YAML or JSON file that describes apps and
+// their deployments, used by the file backend
+func (m *Monkey) FilePath() string {
+	return m.v.GetString(param.FilePath)
+}
+
+// AppDefaults returns the organisation-wide defaults of app configs, in the
+// format of the "chaosMonkey" attribute of a Spinnaker application. Fields
+// that an app does not set are taken from here
+func (m *Monkey) AppDefaults() map[string]interface{} {
+	return m.v.GetStringMap(param.AppDefaults)
+}
+
+// AccountOverrides returns app config fields by account name, in the same
+// format as AppDefaults. They override the defaults in their account, and
+// are overridden by the app
+func (m *Monkey) AccountOverrides() map[string]interface{} {
+	return m.v.GetStringMap(param.AccountOverrides)
+}
+
+// PrometheusPushgateway returns the base URL of a Pushgateway that metrics are
+// pushed to when a command finishes. If blank, metrics are not pushed
+func (m *Monkey) PrometheusPushgateway() string {
+	return m.v.GetString(param.PrometheusPushgateway)
+}
+
+// PrometheusJob returns the job name that metrics are grouped under
+func (m *Monkey) PrometheusJob() string {
+	return m.v.GetString(param.PrometheusJob)
+}
+
+// PrometheusTextfileDir returns the directory of the node_exporter textfile
+// collector that metrics are written to. If blank, no textfile is written
+func (m *Monkey) PrometheusTextfileDir() string {
+	return m.v.GetString(param.PrometheusTextfileDir)
+}
+
+// PrometheusListen returns the address, e.g. ":9090", at which the daemon
+// serves metrics for Prometheus to scrape. If blank, metrics are not served
+func (m *Monkey) PrometheusListen() string {
+	return m.v.GetString(param.PrometheusListen)
+}
+
+// PrometheusPushTimeout returns the timeout for pushing to the Pushgateway
+func (m *Monkey) PrometheusPushTimeout() time.Duration {
+	return m.v.GetDuration(param.PrometheusPushTimeout)
+}
+
 // BindPFlag binds a specific parameter to a pflag
 func (m *Monkey) BindPFlag(parameter string, flag *pflag.Flag) (err error) {
 	return m.v.BindPFlag(parameter, flag)
@@ -436,7 +1130,10 @@ func SetRemoteProvider(name string, factory RemoteConfigFactory) {
 
 // CronExpression returns the chaosmonkey main run cron expression.
 // It defaults to 2 hour before start_hour on weekdays, if no cron expression
-// is specified in the config
+// is specified in the config. If the work week is not Monday to Friday, or
+// business hours differ between accounts or regions, it defaults to every
+// day, since the schedule of each run covers the next working window of each
+// instance group
 func (m *Monkey) CronExpression() (string, error) {
 	defaultCron := "0 %d * * 1-5"
 	cron := m.v.Get(param.CronExpression)
@@ -445,6 +1142,9 @@ func (m *Monkey) CronExpression() (string, error) {
 		if err != nil {
 			return "", err
 		}
+		if !m.mondayToFriday() {
+			defaultCron = "0 %d * * *"
+		}
 		return fmt.Sprintf(defaultCron, runAtHour), nil
 	}
 	switch cron := cron.(type) {
@@ -455,6 +1155,25 @@ func (m *Monkey) CronExpression() (string, error) {
 	}
 }
 
+// mondayToFriday returns true if the work week is Monday to Friday, and
+// business hours are not overridden for any account or region
+func (m *Monkey) mondayToFriday() bool {
+	if len(m.v.GetStringMap(param.BusinessHoursAccounts)) > 0 || len(m.v.GetStringMap(param.BusinessHoursRegions)) > 0 {
+		return false
+	}
+
+	days, err := m.Workdays()
+	if err != nil {
+		return false
+	}
+
+	week := make(map[time.Weekday]bool)
+	for _, day := range days {
+		week[day] = true
+	}
+	return len(week) == 5 && !week[time.Saturday] && !week[time.Sunday]
+}
+
 // calculates the default cron run hour based on startHour.
 // The default cron starts "cronBeforeStartHour" hours
 // before "startHour"
@@ -489,3 +1208,53 @@ func (m *Monkey) SchedulePath() string {
 func (m *Monkey) LogPath() string {
 	return m.v.GetString(param.LogPath)
 }
+
+// KillSwitch returns where the kill switch that stops all terminations is
+// stored. Valid values are "database" and "file"
+func (m *Monkey) KillSwitch() string {
+	return m.v.GetString(param.KillSwitch)
+}
+
+// KillSwitchPath returns the path to the kill switch file, used if the kill
+// switch is stored in a file
+func (m *Monkey) KillSwitchPath() string {
+	return m.v.GetString(param.KillSwitchPath)
+}
+
+// Backend returns the platform that Chaos Monkey discovers and terminates
+// instances on. Valid values are "spinnaker", "kubernetes", "aws"
+// and "file"
+func (m *Monkey) Backend() string {
+	return m.v.GetString(param.Backend)
+}
+
+// JobFormat returns the format of the jobs that "install" and "schedule"
+// write. Valid values are "crontab", "systemd" and "kubernetes"
+func (m *Monkey) JobFormat() string {
+	return m.v.GetString(param.JobFormat)
+}
+
+// SystemdUnitDir returns the directory where the systemd timer and service
+// units are written
+func (m *Monkey) SystemdUnitDir() string {
+	return m.v.GetString(param.SystemdUnitDir)
+}
+
+// SystemdSystemctl returns the systemctl command used to reload and start
+// the units that are written. If blank, systemctl is not run
+func (m *Monkey) SystemdSystemctl() string {
+	return m.v.GetString(param.SystemdSystemctl)
+}
+
+// Environment returns the name of the environment Chaos Monkey is deployed
+// in, e.g. "prod" or "test". It can also be set with the
+// CHAOSMONKEY_ENVIRONMENT environment variable
+func (m *Monkey) Environment() string {
+	return m.v.GetString(param.Environment)
+}
+
+// TestEnvironments returns the names of the environments that are considered
+// test environments. Chaos Monkey may not run unleashed in these
+func (m *Monkey) TestEnvironments() ([]string, error) {
+	return m.getStringSlice(param.TestEnvironments)
+}
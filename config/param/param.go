@@ -23,6 +23,7 @@ const (
 	StartHour        = "chaosmonkey.start_hour"
 	EndHour          = "chaosmonkey.end_hour"
 	TimeZone         = "chaosmonkey.time_zone"
+	Workdays         = "chaosmonkey.workdays"
 	CronPath         = "chaosmonkey.cron_path"
 	TermPath         = "chaosmonkey.term_path"
 	TermAccount      = "chaosmonkey.term_account"
@@ -35,19 +36,155 @@ const (
 	ScheduleCronPath = "chaosmonkey.schedule_cron_path"
 	SchedulePath     = "chaosmonkey.schedule_path"
 	LogPath          = "chaosmonkey.log_path"
+	KillSwitch       = "chaosmonkey.kill_switch"
+	KillSwitchPath   = "chaosmonkey.kill_switch_path"
+	Environment      = "chaosmonkey.environment"
+	TestEnvironments = "chaosmonkey.test_environments"
+	Backend          = "chaosmonkey.backend"
+	JobFormat        = "chaosmonkey.job_format"
 
 	// spinnaker
 	SpinnakerEndpoint          = "spinnaker.endpoint"
 	SpinnakerCertificate       = "spinnaker.certificate"
 	SpinnakerEncryptedPassword = "spinnaker.encrypted_password"
 	SpinnakerUser              = "spinnaker.user"
+	SpinnakerWorkers           = "spinnaker.workers"
+	SpinnakerRequestsPerSecond = "spinnaker.requests_per_second"
+	SpinnakerTimeout           = "spinnaker.timeout"
+	SpinnakerRetries           = "spinnaker.retries"
+	SpinnakerRetryBackoff      = "spinnaker.retry_backoff"
+	SpinnakerBreakerThreshold  = "spinnaker.breaker_threshold"
+	SpinnakerBreakerCooldown   = "spinnaker.breaker_cooldown"
+	SpinnakerTaskTimeout       = "spinnaker.task_timeout"
+	SpinnakerTaskPollInterval  = "spinnaker.task_poll_interval"
+	SpinnakerCertFile          = "spinnaker.cert_file"
+	SpinnakerKeyFile           = "spinnaker.key_file"
+	SpinnakerCAFile            = "spinnaker.ca_file"
+	SpinnakerTokenFile         = "spinnaker.token_file"
+	SpinnakerOAuth2TokenURL    = "spinnaker.oauth2_token_url"
+	SpinnakerOAuth2ClientID    = "spinnaker.oauth2_client_id"
+	SpinnakerOAuth2Secret      = "spinnaker.oauth2_encrypted_client_secret"
+	SpinnakerOAuth2Scopes      = "spinnaker.oauth2_scopes"
+	SpinnakerProxy             = "spinnaker.proxy"
 
 	// database
+	DatabaseDriver            = "database.driver"
 	DatabaseHost              = "database.host"
 	DatabasePort              = "database.port"
 	DatabaseUser              = "database.user"
 	DatabaseEncryptedPassword = "database.encrypted_password"
 	DatabaseName              = "database.name"
+	DatabasePath              = "database.path"
+
+	// business hours of accounts and regions
+	BusinessHoursAccounts = "business_hours.accounts"
+	BusinessHoursRegions  = "business_hours.regions"
+
+	// holiday calendars
+	CalendarHolidays = "calendar.holidays"
+	CalendarAccounts = "calendar.accounts"
+	CalendarRegions  = "calendar.regions"
+
+	// webhook tracker
+	WebhookURLs            = "webhook.urls"
+	WebhookHeaders         = "webhook.headers"
+	WebhookEncryptedSecret = "webhook.encrypted_secret"
+	WebhookTimeout         = "webhook.timeout"
+	WebhookRetries         = "webhook.retries"
+	WebhookBlockOnFailure  = "webhook.block_on_failure"
+
+	// email tracker
+	EmailHost              = "email.host"
+	EmailPort              = "email.port"
+	EmailUser              = "email.user"
+	EmailEncryptedPassword = "email.encrypted_password"
+	EmailFrom              = "email.from"
+	EmailFallbackAddress   = "email.fallback_address"
+	EmailTemplatePath      = "email.template_path"
+	EmailNotifyLeashed     = "email.notify_leashed"
+	EmailBlockOnFailure    = "email.block_on_failure"
+
+	// aes-gcm decryptor
+	AESGCMKeyFile = "aesgcm.key_file"
+
+	// vault decryptor
+	VaultAddress             = "vault.address"
+	VaultNamespace           = "vault.namespace"
+	VaultAuthMethod          = "vault.auth_method"
+	VaultAuthMount           = "vault.auth_mount"
+	VaultTokenPath           = "vault.token_path"
+	VaultRoleID              = "vault.role_id"
+	VaultSecretIDPath        = "vault.secret_id_path"
+	VaultKubernetesRole      = "vault.kubernetes_role"
+	VaultKubernetesTokenPath = "vault.kubernetes_token_path"
+	VaultTransitMount        = "vault.transit_mount"
+	VaultTransitKey          = "vault.transit_key"
+	VaultKVVersion           = "vault.kv_version"
+	VaultTimeout             = "vault.timeout"
+
+	// http outage checker
+	HTTPOutageURL          = "http_outage.url"
+	HTTPOutageHeaders      = "http_outage.headers"
+	HTTPOutageJSONPath     = "http_outage.json_path"
+	HTTPOutageOutageValues = "http_outage.outage_values"
+	HTTPOutageRegex        = "http_outage.regex"
+	HTTPOutageTimeout      = "http_outage.timeout"
+	HTTPOutageCacheTTL     = "http_outage.cache_ttl"
+	HTTPOutageCachePath    = "http_outage.cache_path"
+
+	// alertmanager outage checker
+	AlertmanagerURL      = "alertmanager.url"
+	AlertmanagerMatchers = "alertmanager.matchers"
+	AlertmanagerAccounts = "alertmanager.accounts"
+	AlertmanagerRegions  = "alertmanager.regions"
+	AlertmanagerTimeout  = "alertmanager.timeout"
+
+	// kubernetes backend
+	KubernetesEndpoint         = "kubernetes.endpoint"
+	KubernetesTokenPath        = "kubernetes.token_path"
+	KubernetesCAPath           = "kubernetes.ca_path"
+	KubernetesNamespaces       = "kubernetes.namespaces"
+	KubernetesRegion           = "kubernetes.region"
+	KubernetesAppLabel         = "kubernetes.app_label"
+	KubernetesAccountLabel     = "kubernetes.account_label"
+	KubernetesStackLabel       = "kubernetes.stack_label"
+	KubernetesConfigAnnotation = "kubernetes.config_annotation"
+	KubernetesOwnerAnnotation  = "kubernetes.owner_annotation"
+	KubernetesGracePeriod      = "kubernetes.grace_period"
+	KubernetesTimeout          = "kubernetes.timeout"
+
+	// kubernetes CronJobs, created when job_format is "kubernetes"
+	KubernetesCronJobNamespace      = "kubernetes.cronjob_namespace"
+	KubernetesCronJobImage          = "kubernetes.cronjob_image"
+	KubernetesCronJobCommand        = "kubernetes.cronjob_command"
+	KubernetesCronJobServiceAccount = "kubernetes.cronjob_service_account"
+
+	// systemd units, written when job_format is "systemd"
+	SystemdUnitDir   = "systemd.unit_dir"
+	SystemdSystemctl = "systemd.systemctl"
+
+	// aws backend
+	AWSAccounts                 = "aws.accounts"
+	AWSRegions                  = "aws.regions"
+	AWSAccessKeyID              = "aws.access_key_id"
+	AWSEncryptedSecretAccessKey = "aws.encrypted_secret_access_key"
+	AWSEndpoint                 = "aws.endpoint"
+	AWSTagPrefix                = "aws.tag_prefix"
+	AWSTimeout                  = "aws.timeout"
+
+	// file backend
+	FilePath = "file.path"
+
+	// app config layers
+	AppDefaults      = "app_defaults"
+	AccountOverrides = "account_overrides"
+
+	// prometheus metrics
+	PrometheusPushgateway = "prometheus.pushgateway"
+	PrometheusJob         = "prometheus.job"
+	PrometheusTextfileDir = "prometheus.textfile_dir"
+	PrometheusPushTimeout = "prometheus.push_timeout"
+	PrometheusListen      = "prometheus.listen_address"
 
 	// dynamic property provider
 	DynamicProvider = "dynamic.provider"
@@ -0,0 +1,267 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cron parses the five-field cron expressions of
+// chaosmonkey.cron_expression and computes when they next fire, so that the
+// daemon can run the schedule without the host's cron
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxYears bounds the search for the next time an expression fires, so that
+// expressions that never fire (e.g. "0 0 30 2 *") do not loop forever
+const maxYears = 5
+
+// Expression is a parsed cron expression
+type Expression struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar record whether the day of month and day of week
+	// fields started with "*". Like cron, when both are restricted a day matches if
+	// either of them does
+	domStar, dowStar bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is Sunday, the same as 0
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// Parse parses a cron expression with five fields: minute, hour, day of
+// month, month and day of week. Each field is "*", a value, a range "a-b",
+// or a comma-separated list of those, each optionally followed by a step
+// "/n". Months and days of week may also be given as three-letter names
+func Parse(expr string) (Expression, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Expression{}, errors.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	var e Expression
+	var err error
+	for i, p := range []struct {
+		f    field
+		bits *uint64
+	}{
+		{minuteField, &e.minute},
+		{hourField, &e.hour},
+		{domField, &e.dom},
+		{monthField, &e.month},
+		{dowField, &e.dow},
+	} {
+		*p.bits, err = p.f.parse(fields[i])
+		if err != nil {
+			return Expression{}, errors.Wrapf(err, "cron expression %q", expr)
+		}
+	}
+
+	// Sunday may be written as 0 or 7
+	if e.dow&(1<<7) != 0 {
+		e.dow |= 1
+	}
+
+	e.domStar = strings.HasPrefix(fields[2], "*")
+	e.dowStar = strings.HasPrefix(fields[4], "*")
+	return e, nil
+}
+
+// parse returns a bit set of the values matched by s
+func (f field) parse(s string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rng = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, errors.Errorf("%s: invalid step in %q", f.name, part)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			i := strings.Index(rng, "-")
+			var err error
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, errors.Errorf("%s: invalid range %q", f.name, rng)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			hi = lo
+			// "a/n" means every n starting at a, as in "a-max/n"
+			if step != 1 {
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single value of the field
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Errorf("%s: invalid value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, errors.Errorf("%s: %d is not in range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time strictly after t at which the expression
+// fires, in the location of t. It returns the zero time if the expression
+// does not fire within the next few years
+func (e Expression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(maxYears, 0, 0)
+
+	for t.Before(end) {
+		if !has(e.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !e.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !has(e.hour, t.Hour()) {
+			next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			// An hour that is repeated when clocks go back maps to the
+			// same wall time; step in absolute time to get past it
+			if !next.After(t) {
+				next = t.Truncate(time.Hour).Add(time.Hour)
+			}
+			t = next
+			continue
+		}
+		if !has(e.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches returns true if the day of t matches the day of month and day
+// of week fields
+func (e Expression) dayMatches(t time.Time) bool {
+	dom := has(e.dom, t.Day())
+	dow := has(e.dow, int(t.Weekday()))
+	if e.domStar || e.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// OnCalendar returns the systemd calendar events, as used by the OnCalendar=
+// setting of timer units, that fire when the expression fires. zone is
+// appended to each event; if blank, systemd uses the system time zone.
+//
+// Two events are returned if both the day of month and the day of week are
+// restricted, since systemd requires both of them to match
+func (e Expression) OnCalendar(zone string) []string {
+	tm := fmt.Sprintf("%s:%s:00", values(e.hour, hourField), values(e.minute, minuteField))
+	month := values(e.month, monthField)
+	dom := values(e.dom, domField)
+
+	var dows []string
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if has(e.dow, int(d)) {
+			dows = append(dows, d.String()[:3])
+		}
+	}
+	weekdays := strings.Join(dows, ",")
+	if len(dows) == 7 {
+		weekdays = ""
+	}
+
+	event := func(weekdays, dom string) string {
+		s := fmt.Sprintf("*-%s-%s %s", month, dom, tm)
+		if weekdays != "" {
+			s = weekdays + " " + s
+		}
+		if zone != "" {
+			s += " " + zone
+		}
+		return s
+	}
+
+	if e.domStar || e.dowStar {
+		return []string{event(weekdays, dom)}
+	}
+	return []string{event(weekdays, "*"), event("", dom)}
+}
+
+// values returns the values in bits as a comma-separated list in systemd
+// calendar syntax, or "*" if every value of the field is set
+func values(bits uint64, f field) string {
+	var vals []string
+	for v := f.min; v <= f.max; v++ {
+		if has(bits, v) {
+			vals = append(vals, fmt.Sprintf("%02d", v))
+		}
+	}
+	if len(vals) == f.max-f.min+1 {
+		return "*"
+	}
+	return strings.Join(vals, ",")
+}
@@ -0,0 +1,131 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wednesday
+	now := time.Date(2016, time.November, 16, 10, 30, 0, 0, la)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2016, time.November, 16, 10, 31, 0, 0, la)},
+		{"0 7 * * 1-5", time.Date(2016, time.November, 17, 7, 0, 0, 0, la)},
+		{"0 7 * * *", time.Date(2016, time.November, 17, 7, 0, 0, 0, la)},
+		{"45 10 * * *", time.Date(2016, time.November, 16, 10, 45, 0, 0, la)},
+		{"30 10 * * *", time.Date(2016, time.November, 17, 10, 30, 0, 0, la)},
+		{"*/20 * * * *", time.Date(2016, time.November, 16, 10, 40, 0, 0, la)},
+		{"0 7 * * sat,sun", time.Date(2016, time.November, 19, 7, 0, 0, 0, la)},
+		{"0 7 * * 7", time.Date(2016, time.November, 20, 7, 0, 0, 0, la)},
+		{"0 0 1 jan *", time.Date(2017, time.January, 1, 0, 0, 0, 0, la)},
+		{"0 0 29 2 *", time.Date(2020, time.February, 29, 0, 0, 0, 0, la)},
+		// Day of month and day of week are or-ed when both are restricted
+		{"0 7 1 * fri", time.Date(2016, time.November, 18, 7, 0, 0, 0, la)},
+		{"0 7 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := e.Next(now); !got.Equal(tt.want) {
+			t.Errorf("%q: Next(%s)=%s, want %s", tt.expr, now, got, tt.want)
+		}
+	}
+}
+
+func TestNextDaylightSaving(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Parse("0 7 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clocks go back on 2016-11-06 and forward on 2017-03-12
+	for _, now := range []time.Time{
+		time.Date(2016, time.November, 5, 12, 0, 0, 0, la),
+		time.Date(2017, time.March, 11, 12, 0, 0, 0, la),
+	} {
+		want := time.Date(now.Year(), now.Month(), now.Day()+1, 7, 0, 0, 0, la)
+		if got := e.Next(now); !got.Equal(want) {
+			t.Errorf("Next(%s)=%s, want %s", now, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 7 * *",
+		"0 7 * * * *",
+		"60 7 * * *",
+		"0 24 * * *",
+		"0 7 0 * *",
+		"0 7 * 13 *",
+		"0 7 * * 8",
+		"0 7 * * 5-1",
+		"0 7 * * mon-",
+		"*/0 7 * * *",
+		"0 7 * * someday",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected error", expr)
+		}
+	}
+}
+
+func TestOnCalendar(t *testing.T) {
+	tests := []struct {
+		expr, zone string
+		want       []string
+	}{
+		{"0 7 * * 1-5", "America/Los_Angeles", []string{"Mon,Tue,Wed,Thu,Fri *-*-* 07:00:00 America/Los_Angeles"}},
+		{"0 7 * * *", "", []string{"*-*-* 07:00:00"}},
+		{"*/15 9,17 * * *", "UTC", []string{"*-*-* 09,17:00,15,30,45:00 UTC"}},
+		{"30 6 1 jan *", "", []string{"*-01-01 06:30:00"}},
+		{"0 7 * * 0", "", []string{"Sun *-*-* 07:00:00"}},
+		{"0 7 1 * fri", "", []string{"Fri *-*-* 07:00:00", "*-*-01 07:00:00"}},
+	}
+
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := e.OnCalendar(tt.zone)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%q: got %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
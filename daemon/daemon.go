@@ -0,0 +1,212 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package daemon runs Chaos Monkey as a long-running process: it generates
+// or fetches the schedule of terminations every day and terminates
+// instances in-process at the scheduled times, instead of relying on the
+// host's cron
+package daemon
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// Run holds the config and the dependencies that the daemon uses until the
+// next time the schedule is generated, when the config is reloaded
+type Run interface {
+	// Next returns the first time after t at which the schedule should be
+	// generated
+	Next(t time.Time) time.Time
+
+	// Missed returns true if the schedule should already have been
+	// generated on the day of t, before t
+	Missed(t time.Time) bool
+
+	// Schedule generates the schedule of terminations for today, or fetches
+	// it if it has already been generated. It returns nil if there are no
+	// terminations today
+	Schedule() (*schedule.Schedule, error)
+
+	// Fetch fetches the schedule of terminations for today. It returns nil
+	// if no schedule has been generated yet, or if it has no terminations
+	Fetch() (*schedule.Schedule, error)
+
+	// Terminate terminates an instance of the group of the entry
+	Terminate(e schedule.Entry) error
+
+	// Close releases the resources held by the run, e.g. database connections
+	Close() error
+}
+
+// Loader loads the config and returns a new Run
+type Loader func() (Run, error)
+
+// Daemon generates schedules and executes terminations
+type Daemon struct {
+	load Loader
+
+	// mu guards the fields below, which track the terminations of the
+	// current schedule
+	mu      sync.Mutex
+	timers  []*time.Timer
+	gen     int
+	running sync.WaitGroup
+}
+
+// New returns a daemon that calls load before every generation of the
+// schedule
+func New(load Loader) *Daemon {
+	return &Daemon{load: load}
+}
+
+// Start loads the config, installs today's schedule if one has already been
+// generated, or generates it if the cron expression already fired today, and
+// then generates the schedule every time the cron expression fires, until ctx
+// is cancelled.
+//
+// When ctx is cancelled, terminations that are not due yet are dropped, and
+// Start returns once the terminations already in progress are done. If the
+// config cannot be reloaded, the previous config is kept
+func (d *Daemon) Start(ctx context.Context) error {
+	run, err := d.load()
+	if err != nil {
+		return errors.Wrap(err, "could not load config")
+	}
+	defer func() {
+		d.cancel()
+		if err := run.Close(); err != nil {
+			log.Printf("WARNING: could not close: %v", err)
+		}
+	}()
+
+	// If the daemon was not running when the schedule should have been
+	// generated today, e.g. because it was restarted, generate it now
+	s, err := run.Fetch()
+	if err == nil && s == nil && run.Missed(time.Now()) {
+		log.Println("today's schedule was not generated at its scheduled time, generating it now")
+		s, err = run.Schedule()
+	}
+
+	if err != nil {
+		log.Printf("ERROR: could not get today's schedule: %v", err)
+	} else if s == nil {
+		log.Println("no schedule for today yet")
+	} else {
+		d.install(run, s)
+	}
+
+	for {
+		now := time.Now()
+		next := run.Next(now)
+		if next.IsZero() {
+			return errors.New("cron expression never fires")
+		}
+		log.Printf("next schedule at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("shutting down")
+			return nil
+		case <-timer.C:
+		}
+
+		reloaded, err := d.load()
+		if err != nil {
+			log.Printf("ERROR: could not reload config, keeping the previous one: %v", err)
+		} else {
+			// The terminations of the previous run use its dependencies,
+			// so they must be done before it is closed
+			d.cancel()
+			if err := run.Close(); err != nil {
+				log.Printf("WARNING: could not close: %v", err)
+			}
+			run = reloaded
+		}
+
+		s, err := run.Schedule()
+		if err != nil {
+			log.Printf("ERROR: could not generate schedule: %v", err)
+			continue
+		}
+		if s == nil {
+			log.Println("no schedule for today")
+			continue
+		}
+		d.install(run, s)
+	}
+}
+
+// install replaces the pending terminations with the entries of s that are
+// not due yet
+func (d *Daemon) install(run Run, s *schedule.Schedule) {
+	d.cancel()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	gen := d.gen
+	for _, e := range s.Entries() {
+		if !e.Time.After(now) {
+			log.Printf("skipping termination of %s at %s, which has passed", grp.String(e.Group), e.Time.Format(time.RFC3339))
+			continue
+		}
+		e := e
+		d.timers = append(d.timers, time.AfterFunc(e.Time.Sub(now), func() {
+			d.terminate(run, gen, e)
+		}))
+	}
+	log.Printf("%d terminations scheduled", len(d.timers))
+}
+
+// terminate executes the termination of an entry, unless the schedule it
+// belongs to has been replaced
+func (d *Daemon) terminate(run Run, gen int, e schedule.Entry) {
+	d.mu.Lock()
+	if gen != d.gen {
+		d.mu.Unlock()
+		return
+	}
+	d.running.Add(1)
+	d.mu.Unlock()
+	defer d.running.Done()
+
+	log.Printf("terminating %s", grp.String(e.Group))
+	if err := run.Terminate(e); err != nil {
+		log.Printf("ERROR: could not terminate %s: %v", grp.String(e.Group), err)
+	}
+}
+
+// cancel drops the pending terminations and waits for the ones in progress
+func (d *Daemon) cancel() {
+	d.mu.Lock()
+	for _, t := range d.timers {
+		t.Stop()
+	}
+	d.timers = nil
+	d.gen++
+	d.mu.Unlock()
+
+	d.running.Wait()
+}
@@ -0,0 +1,260 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// fakeRun records the calls made by the daemon
+type fakeRun struct {
+	next      func(t time.Time) time.Time
+	scheduled *schedule.Schedule
+	fetched   *schedule.Schedule
+	missed    bool
+
+	// block, if not nil, is received from before a termination returns
+	block chan struct{}
+
+	mu           sync.Mutex
+	scheduleCall int
+	terminated   []string
+	closed       bool
+	done         chan string
+}
+
+func newFakeRun(next func(t time.Time) time.Time) *fakeRun {
+	return &fakeRun{next: next, done: make(chan string, 10)}
+}
+
+func (r *fakeRun) Next(t time.Time) time.Time { return r.next(t) }
+
+func (r *fakeRun) Missed(t time.Time) bool { return r.missed }
+
+func (r *fakeRun) Schedule() (*schedule.Schedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scheduleCall++
+	return r.scheduled, nil
+}
+
+func (r *fakeRun) Fetch() (*schedule.Schedule, error) { return r.fetched, nil }
+
+func (r *fakeRun) Terminate(e schedule.Entry) error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	r.terminated = append(r.terminated, e.Group.App())
+	r.mu.Unlock()
+	r.done <- e.Group.App()
+	return nil
+}
+
+func (r *fakeRun) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *fakeRun) state() (int, []string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.scheduleCall, append([]string(nil), r.terminated...), r.closed
+}
+
+// never returns a time far enough in the future that it is not reached
+// during a test
+func never(t time.Time) time.Time { return t.Add(time.Hour) }
+
+func newSchedule(entries map[string]time.Duration) *schedule.Schedule {
+	s := schedule.New()
+	for app, d := range entries {
+		s.Add(time.Now().Add(d), grp.New(app, "prod", "", "", ""))
+	}
+	return s
+}
+
+// start runs the daemon in the background and returns a function that stops
+// it and waits for it to return
+func start(t *testing.T, load Loader) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() { result <- New(load).Start(ctx) }()
+	return func() {
+		cancel()
+		select {
+		case err := <-result:
+			if err != nil {
+				t.Errorf("Start: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("daemon did not shut down")
+		}
+	}
+}
+
+func wait(t *testing.T, done <-chan string, want string) {
+	select {
+	case app := <-done:
+		if app != want {
+			t.Errorf("got termination of %s, want %s", app, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for termination of %s", want)
+	}
+}
+
+func TestStartFetchesTodaysSchedule(t *testing.T) {
+	run := newFakeRun(never)
+	run.fetched = newSchedule(map[string]time.Duration{
+		"passed": -time.Minute,
+		"soon":   20 * time.Millisecond,
+		"later":  time.Hour,
+	})
+
+	stop := start(t, func() (Run, error) { return run, nil })
+	wait(t, run.done, "soon")
+	stop()
+
+	calls, terminated, closed := run.state()
+	if calls != 0 {
+		t.Errorf("Schedule called %d times before the cron expression fired", calls)
+	}
+	if len(terminated) != 1 {
+		t.Errorf("got terminations %v, want [soon]", terminated)
+	}
+	if !closed {
+		t.Error("run not closed on shutdown")
+	}
+}
+
+func TestStartGeneratesMissedSchedule(t *testing.T) {
+	run := newFakeRun(never)
+	run.missed = true
+	run.scheduled = newSchedule(map[string]time.Duration{"soon": 20 * time.Millisecond})
+
+	stop := start(t, func() (Run, error) { return run, nil })
+	wait(t, run.done, "soon")
+	stop()
+
+	if calls, _, _ := run.state(); calls != 1 {
+		t.Errorf("Schedule called %d times, want 1", calls)
+	}
+}
+
+func TestStartWaitsForSchedule(t *testing.T) {
+	// The cron expression has not fired today yet
+	run := newFakeRun(never)
+	run.scheduled = newSchedule(map[string]time.Duration{"soon": 20 * time.Millisecond})
+
+	stop := start(t, func() (Run, error) { return run, nil })
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	if calls, terminated, _ := run.state(); calls != 0 || len(terminated) != 0 {
+		t.Errorf("got %d Schedule calls and terminations %v before the cron expression fired, want none", calls, terminated)
+	}
+}
+
+func TestStartReloadsConfig(t *testing.T) {
+	first := newFakeRun(func(t time.Time) time.Time { return t.Add(20 * time.Millisecond) })
+	first.fetched = newSchedule(map[string]time.Duration{"old": time.Hour})
+
+	second := newFakeRun(never)
+	second.scheduled = newSchedule(map[string]time.Duration{"new": 50 * time.Millisecond})
+
+	var mu sync.Mutex
+	runs := []Run{first, second}
+	stop := start(t, func() (Run, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		r := runs[0]
+		runs = runs[1:]
+		return r, nil
+	})
+	wait(t, second.done, "new")
+	stop()
+
+	if _, terminated, closed := first.state(); len(terminated) != 0 || !closed {
+		t.Errorf("previous run: terminated=%v closed=%v, want no terminations and closed", terminated, closed)
+	}
+	if calls, _, closed := second.state(); calls != 1 || !closed {
+		t.Errorf("reloaded run: Schedule calls=%d closed=%v, want 1 call and closed", calls, closed)
+	}
+}
+
+func TestStartKeepsConfigIfReloadFails(t *testing.T) {
+	run := newFakeRun(func(t time.Time) time.Time { return t.Add(20 * time.Millisecond) })
+	run.scheduled = newSchedule(map[string]time.Duration{"foo": 50 * time.Millisecond})
+
+	// Only the first load succeeds. Loads all happen on the daemon's goroutine
+	loads := 0
+	stop := start(t, func() (Run, error) {
+		loads++
+		if loads > 1 {
+			return nil, errors.New("bad config")
+		}
+		return run, nil
+	})
+	wait(t, run.done, "foo")
+	stop()
+
+	if calls, _, _ := run.state(); calls == 0 {
+		t.Error("schedule not generated with the previous config")
+	}
+}
+
+func TestShutdownWaitsForTerminations(t *testing.T) {
+	run := newFakeRun(never)
+	run.block = make(chan struct{})
+	run.fetched = newSchedule(map[string]time.Duration{"foo": 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() { result <- New(func() (Run, error) { return run, nil }).Start(ctx) }()
+
+	// Wait for the termination to start
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-result:
+		t.Fatal("daemon returned before the termination in progress was done")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(run.block)
+	wait(t, run.done, "foo")
+	if err := <-result; err != nil {
+		t.Errorf("Start: %v", err)
+	}
+}
+
+func TestStartFailsIfConfigCannotBeLoaded(t *testing.T) {
+	err := New(func() (Run, error) { return nil, errors.New("bad config") }).Start(context.Background())
+	if err == nil {
+		t.Error("expected error")
+	}
+}
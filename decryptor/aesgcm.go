@@ -0,0 +1,130 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decryptor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+// keySize is the size of an AES-256 key, in bytes
+const keySize = 32
+
+// aesgcm decrypts secrets with AES-256-GCM using a key read from a local file.
+//
+// Ciphertexts are base64-encoded, and consist of the random nonce followed by
+// the sealed plaintext
+type aesgcm struct {
+	aead cipher.AEAD
+}
+
+// newAESGCM returns an aesgcm decryptor using the key file from cfg
+func newAESGCM(cfg *config.Monkey) (*aesgcm, error) {
+	path := cfg.AESGCMKeyFile()
+	if path == "" {
+		return nil, errors.Errorf("aesgcm decryptor: %s not specified", param.AESGCMKeyFile)
+	}
+
+	key, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAESGCMFromKey(key)
+}
+
+func newAESGCMFromKey(key []byte) (*aesgcm, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "aesgcm decryptor: invalid key")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "aesgcm decryptor: could not create cipher")
+	}
+
+	return &aesgcm{aead: aead}, nil
+}
+
+// readKeyFile reads a base64-encoded 256-bit key from path
+func readKeyFile(path string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "aesgcm decryptor: could not read key file")
+	}
+
+	if fi, err := os.Stat(path); err == nil && fi.Mode().Perm()&0077 != 0 {
+		log.Printf("WARNING: key file %s is accessible by other users (mode %s)", path, fi.Mode().Perm())
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "aesgcm decryptor: key file %s is not valid base64", path)
+	}
+
+	if len(key) != keySize {
+		return nil, errors.Errorf("aesgcm decryptor: key in %s is %d bytes, must be %d", path, len(key), keySize)
+	}
+
+	return key, nil
+}
+
+// Decrypt implements chaosmonkey.Decryptor.Decrypt
+func (a *aesgcm) Decrypt(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ciphertext))
+	if err != nil {
+		return "", errors.Wrap(err, "aesgcm decryptor: ciphertext is not valid base64")
+	}
+
+	size := a.aead.NonceSize()
+	if len(data) < size+a.aead.Overhead() {
+		return "", errors.New("aesgcm decryptor: ciphertext too short")
+	}
+
+	plaintext, err := a.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return "", errors.Wrap(err, "aesgcm decryptor: decryption failed")
+	}
+
+	return string(plaintext), nil
+}
+
+// Encrypt returns the base64-encoded ciphertext of plaintext
+func (a *aesgcm) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, a.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "aesgcm decryptor: could not generate nonce")
+	}
+
+	sealed := a.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
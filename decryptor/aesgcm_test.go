@@ -0,0 +1,138 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decryptor
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+// writeKeyFile writes contents to a temporary file and returns its path
+func writeKeyFile(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "cm-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name()
+}
+
+func aesgcmConfig(t *testing.T) (*config.Monkey, func()) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", keySize)))
+	path := writeKeyFile(t, key+"\n")
+
+	cfg := config.Defaults()
+	cfg.Set(param.Decryptor, "aesgcm")
+	cfg.Set(param.AESGCMKeyFile, path)
+	return cfg, func() { _ = os.Remove(path) }
+}
+
+func TestAESGCMRoundTrip(t *testing.T) {
+	cfg, cleanup := aesgcmConfig(t)
+	defer cleanup()
+
+	encrypter, err := GetEncrypter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := encrypter.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(ciphertext, "hunter2") {
+		t.Fatalf("ciphertext contains plaintext: %s", ciphertext)
+	}
+
+	decryptor, err := getDecryptor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := decryptor.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := plaintext, "hunter2"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestAESGCMRejectsTamperedCiphertext(t *testing.T) {
+	cfg, cleanup := aesgcmConfig(t)
+	defer cleanup()
+
+	a, err := newAESGCM(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := a.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	data[len(data)-1] ^= 0xff
+
+	for _, s := range []string{base64.StdEncoding.EncodeToString(data), "not base64!", "c2hvcnQ="} {
+		if _, err := a.Decrypt(s); err == nil {
+			t.Errorf("expected error decrypting %q", s)
+		}
+	}
+}
+
+func TestAESGCMInvalidKeyFile(t *testing.T) {
+	tests := []string{
+		"not base64!",
+		base64.StdEncoding.EncodeToString([]byte("too short")),
+	}
+
+	for _, contents := range tests {
+		path := writeKeyFile(t, contents)
+
+		cfg := config.Defaults()
+		cfg.Set(param.Decryptor, "aesgcm")
+		cfg.Set(param.AESGCMKeyFile, path)
+
+		if _, err := getDecryptor(cfg); err == nil {
+			t.Errorf("expected error for key file contents %q", contents)
+		}
+
+		_ = os.Remove(path)
+	}
+}
+
+func TestNullDecryptorCannotEncrypt(t *testing.T) {
+	if _, err := GetEncrypter(config.Defaults()); err == nil {
+		t.Error("expected error getting encrypter for null decryptor")
+	}
+}
@@ -29,15 +29,42 @@ func (n nullDecryptor) Decrypt(ciphertext string) (string, error) {
 	return ciphertext, nil
 }
 
+// Encrypter encrypts secrets so that they can be read back by the decryptor
+// that implements it
+type Encrypter interface {
+	Encrypt(plaintext string) (string, error)
+}
+
 func init() {
-	deps.GetDecryptor = getNullDecryptor
+	deps.GetDecryptor = getDecryptor
 }
 
-func getNullDecryptor(cfg *config.Monkey) (chaosmonkey.Decryptor, error) {
+func getDecryptor(cfg *config.Monkey) (chaosmonkey.Decryptor, error) {
 	kind := cfg.Decryptor()
-	if kind != "" {
+	switch kind {
+	case "":
+		return nullDecryptor{}, nil
+	case "aesgcm":
+		return newAESGCM(cfg)
+	case "vault":
+		return newVault(cfg)
+	default:
 		return nil, errors.Errorf("unsupported decryptor: %s", kind)
 	}
+}
+
+// GetEncrypter returns an Encrypter for the decryptor specified in cfg, which
+// is used to produce encrypted values for the config file
+func GetEncrypter(cfg *config.Monkey) (Encrypter, error) {
+	decryptor, err := getDecryptor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypter, ok := decryptor.(Encrypter)
+	if !ok {
+		return nil, errors.Errorf("decryptor %q does not support encryption", cfg.Decryptor())
+	}
 
-	return nullDecryptor{}, nil
+	return encrypter, nil
 }
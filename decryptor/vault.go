@@ -0,0 +1,343 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decryptor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+// Prefixes of the values that the vault decryptor resolves
+const (
+	// transitPrefix marks a ciphertext produced by the transit secrets engine,
+	// e.g. "vault:v1:8SDd3WHDOjf7mq69CyCqYjBXAiQQAVZRkFM13ok481zoCmHnSeDX9vyf7w=="
+	transitPrefix = "vault:"
+
+	// kvPrefix marks a reference to a field of a secret stored in the KV
+	// secrets engine, e.g. "kv:secret/chaosmonkey/db#password"
+	kvPrefix = "kv:"
+)
+
+// vault resolves secrets through the HashiCorp Vault HTTP API
+type vault struct {
+	address      string
+	namespace    string
+	transitMount string
+	transitKey   string
+	kvVersion    int
+	client       *http.Client
+
+	// login obtains a client token
+	login func(v *vault) (string, error)
+
+	mu    sync.Mutex
+	token string
+}
+
+// newVault returns a vault decryptor configured from cfg
+func newVault(cfg *config.Monkey) (*vault, error) {
+	address := cfg.VaultAddress()
+	if address == "" {
+		return nil, errors.Errorf("vault decryptor: %s not specified", param.VaultAddress)
+	}
+
+	login, err := vaultLogin(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vault{
+		address:      strings.TrimRight(address, "/"),
+		namespace:    cfg.VaultNamespace(),
+		transitMount: cfg.VaultTransitMount(),
+		transitKey:   cfg.VaultTransitKey(),
+		kvVersion:    cfg.VaultKVVersion(),
+		client:       &http.Client{Timeout: cfg.VaultTimeout()},
+		login:        login,
+	}, nil
+}
+
+// vaultLogin returns the login function for the configured auth method
+func vaultLogin(cfg *config.Monkey) (func(v *vault) (string, error), error) {
+	method := cfg.VaultAuthMethod()
+	mount := cfg.VaultAuthMount()
+	if mount == "" {
+		mount = method
+	}
+
+	switch method {
+	case "token":
+		path := cfg.VaultTokenPath()
+		return func(v *vault) (string, error) {
+			if path == "" {
+				token := os.Getenv("VAULT_TOKEN")
+				if token == "" {
+					return "", errors.Errorf("vault decryptor: neither %s nor VAULT_TOKEN specified", param.VaultTokenPath)
+				}
+				return token, nil
+			}
+			return readSecretFile(path)
+		}, nil
+
+	case "approle":
+		roleID := cfg.VaultRoleID()
+		if roleID == "" {
+			return nil, errors.Errorf("vault decryptor: %s not specified", param.VaultRoleID)
+		}
+		secretIDPath := cfg.VaultSecretIDPath()
+		return func(v *vault) (string, error) {
+			body := map[string]string{"role_id": roleID}
+			if secretIDPath != "" {
+				secretID, err := readSecretFile(secretIDPath)
+				if err != nil {
+					return "", err
+				}
+				body["secret_id"] = secretID
+			}
+			return v.authLogin(mount, body)
+		}, nil
+
+	case "kubernetes":
+		role := cfg.VaultKubernetesRole()
+		if role == "" {
+			return nil, errors.Errorf("vault decryptor: %s not specified", param.VaultKubernetesRole)
+		}
+		jwtPath := cfg.VaultKubernetesTokenPath()
+		return func(v *vault) (string, error) {
+			jwt, err := readSecretFile(jwtPath)
+			if err != nil {
+				return "", err
+			}
+			return v.authLogin(mount, map[string]string{"role": role, "jwt": jwt})
+		}, nil
+
+	default:
+		return nil, errors.Errorf("vault decryptor: unsupported auth method: %s", method)
+	}
+}
+
+// readSecretFile returns the contents of path, without surrounding whitespace
+func readSecretFile(path string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "vault decryptor: could not read credentials")
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
+
+// Decrypt implements chaosmonkey.Decryptor.Decrypt
+// Values starting with "vault:" are decrypted with the transit secrets
+// engine, and values of the form "kv:<path>#<field>" are looked up in the KV
+// secrets engine
+func (v *vault) Decrypt(ciphertext string) (string, error) {
+	switch {
+	case ciphertext == "":
+		return "", nil
+	case strings.HasPrefix(ciphertext, transitPrefix):
+		return v.transitDecrypt(ciphertext)
+	case strings.HasPrefix(ciphertext, kvPrefix):
+		return v.kvGet(strings.TrimPrefix(ciphertext, kvPrefix))
+	default:
+		return "", errors.Errorf("vault decryptor: value must start with %q or %q", transitPrefix, kvPrefix)
+	}
+}
+
+// Encrypt encrypts plaintext with the transit secrets engine
+func (v *vault) Encrypt(plaintext string) (string, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+
+	path := fmt.Sprintf("%s/encrypt/%s", v.transitMount, v.transitKey)
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString([]byte(plaintext))}
+	if err := v.request("POST", path, body, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.Data.Ciphertext, nil
+}
+
+func (v *vault) transitDecrypt(ciphertext string) (string, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+
+	path := fmt.Sprintf("%s/decrypt/%s", v.transitMount, v.transitKey)
+	if err := v.request("POST", path, map[string]string{"ciphertext": ciphertext}, &resp); err != nil {
+		return "", err
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return "", errors.Wrap(err, "vault decryptor: transit plaintext is not valid base64")
+	}
+
+	return string(plaintext), nil
+}
+
+// kvGet looks up a reference of the form "<mount>/<path>#<field>"
+func (v *vault) kvGet(ref string) (string, error) {
+	i := strings.LastIndex(ref, "#")
+	if i < 0 {
+		return "", errors.Errorf("vault decryptor: kv reference %q has no #field", ref)
+	}
+	secretPath, field := ref[:i], ref[i+1:]
+
+	var data map[string]interface{}
+	switch v.kvVersion {
+	case 1:
+		var resp struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := v.request("GET", secretPath, nil, &resp); err != nil {
+			return "", err
+		}
+		data = resp.Data
+	case 2:
+		// KV version 2 inserts "data" after the mount point
+		parts := strings.SplitN(secretPath, "/", 2)
+		if len(parts) != 2 {
+			return "", errors.Errorf("vault decryptor: kv reference %q must include the mount", ref)
+		}
+		var resp struct {
+			Data struct {
+				Data map[string]interface{} `json:"data"`
+			} `json:"data"`
+		}
+		if err := v.request("GET", parts[0]+"/data/"+parts[1], nil, &resp); err != nil {
+			return "", err
+		}
+		data = resp.Data.Data
+	default:
+		return "", errors.Errorf("vault decryptor: unsupported kv version: %d", v.kvVersion)
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", errors.Errorf("vault decryptor: no string field %q in %s", field, secretPath)
+	}
+
+	return value, nil
+}
+
+// authLogin logs in to the auth method mounted at mount and returns the
+// client token
+func (v *vault) authLogin(mount string, body map[string]string) (string, error) {
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+
+	if err := v.do("POST", "auth/"+mount+"/login", "", body, &resp); err != nil {
+		return "", errors.Wrap(err, "login failed")
+	}
+
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("vault decryptor: login response has no client token")
+	}
+
+	return resp.Auth.ClientToken, nil
+}
+
+// request makes an authenticated request to the Vault API
+func (v *vault) request(method, path string, body interface{}, result interface{}) error {
+	v.mu.Lock()
+	if v.token == "" {
+		token, err := v.login(v)
+		if err != nil {
+			v.mu.Unlock()
+			return err
+		}
+		v.token = token
+	}
+	token := v.token
+	v.mu.Unlock()
+
+	return v.do(method, path, token, body, result)
+}
+
+// do makes a request to /v1/<path> and decodes the JSON response into result
+func (v *vault) do(method, path, token string, body interface{}, result interface{}) (err error) {
+	var payload []byte
+	if body != nil {
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "vault decryptor: json marshal failed")
+		}
+	}
+
+	url := v.address + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "vault decryptor: could not create request")
+	}
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "vault decryptor: %s %s failed", method, url)
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "failed to close response body")
+		}
+	}()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "vault decryptor: could not read response from %s", url)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(contents, &e)
+		return errors.Errorf("vault decryptor: %s %s: code %d: %s", method, url, resp.StatusCode, strings.Join(e.Errors, "; "))
+	}
+
+	if err := json.Unmarshal(contents, result); err != nil {
+		return errors.Wrapf(err, "vault decryptor: could not parse response from %s", url)
+	}
+
+	return nil
+}
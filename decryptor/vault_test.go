@@ -0,0 +1,255 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decryptor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+// fakeVault is a minimal stand-in for the Vault HTTP API
+type fakeVault struct {
+	t      *testing.T
+	token  string // client token that is required on secret requests
+	logins int    // number of successful logins
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]string
+	if r.Method == "POST" {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			f.t.Errorf("could not decode request body: %v", err)
+		}
+	}
+
+	reply := func(v interface{}) {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+
+	switch r.URL.Path {
+	case "/v1/auth/approle/login":
+		if body["role_id"] != "my-role" || body["secret_id"] != "my-secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			reply(map[string]interface{}{"errors": []string{"invalid role or secret ID"}})
+			return
+		}
+		f.logins++
+		reply(map[string]interface{}{"auth": map[string]string{"client_token": f.token}})
+		return
+	case "/v1/auth/k8s/login":
+		if body["role"] != "chaosmonkey" || body["jwt"] != "service-account-jwt" {
+			w.WriteHeader(http.StatusBadRequest)
+			reply(map[string]interface{}{"errors": []string{"permission denied"}})
+			return
+		}
+		f.logins++
+		reply(map[string]interface{}{"auth": map[string]string{"client_token": f.token}})
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		reply(map[string]interface{}{"errors": []string{"permission denied"}})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v1/transit/decrypt/chaosmonkey":
+		plaintext := strings.TrimPrefix(body["ciphertext"], "vault:v1:")
+		reply(map[string]interface{}{"data": map[string]string{"plaintext": plaintext}})
+	case "/v1/transit/encrypt/chaosmonkey":
+		reply(map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+	case "/v1/secret/data/chaosmonkey/db":
+		reply(map[string]interface{}{"data": map[string]interface{}{"data": map[string]string{"password": "kv2-password"}}})
+	case "/v1/kv/chaosmonkey/db":
+		reply(map[string]interface{}{"data": map[string]string{"password": "kv1-password"}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		reply(map[string]interface{}{"errors": []string{}})
+	}
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server, *config.Monkey) {
+	f := &fakeVault{t: t, token: "s.client-token"}
+	srv := httptest.NewServer(f)
+
+	cfg := config.Defaults()
+	cfg.Set(param.Decryptor, "vault")
+	cfg.Set(param.VaultAddress, srv.URL)
+	return f, srv, cfg
+}
+
+func TestVaultDecrypt(t *testing.T) {
+	_, srv, cfg := newFakeVault(t)
+	defer srv.Close()
+
+	path := writeKeyFile(t, "s.client-token\n")
+	defer os.Remove(path)
+	cfg.Set(param.VaultTokenPath, path)
+
+	transit := "vault:v1:" + base64.StdEncoding.EncodeToString([]byte("transit-password"))
+
+	tests := []struct {
+		kvVersion int
+		value     string
+		want      string
+	}{
+		{2, transit, "transit-password"},
+		{2, "kv:secret/chaosmonkey/db#password", "kv2-password"},
+		{1, "kv:kv/chaosmonkey/db#password", "kv1-password"},
+		{2, "", ""},
+	}
+
+	for _, tt := range tests {
+		cfg.Set(param.VaultKVVersion, tt.kvVersion)
+		decryptor, err := getDecryptor(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := decryptor.Decrypt(tt.value)
+		if err != nil {
+			t.Errorf("%s: %v", tt.value, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	decryptor, err := getDecryptor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []string{
+		"plaintext",
+		"kv:secret/chaosmonkey/db",
+		"kv:secret/chaosmonkey/db#username",
+		"kv:secret/missing#password",
+	} {
+		if _, err := decryptor.Decrypt(value); err == nil {
+			t.Errorf("%s: expected error", value)
+		}
+	}
+}
+
+func TestVaultEncrypt(t *testing.T) {
+	_, srv, cfg := newFakeVault(t)
+	defer srv.Close()
+
+	if err := os.Setenv("VAULT_TOKEN", "s.client-token"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	encrypter, err := GetEncrypter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := encrypter.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "vault:v1:" + base64.StdEncoding.EncodeToString([]byte("hunter2")); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestVaultAuthMethods(t *testing.T) {
+	secretID := writeKeyFile(t, "my-secret")
+	defer os.Remove(secretID)
+	jwt := writeKeyFile(t, "service-account-jwt\n")
+	defer os.Remove(jwt)
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+	}{
+		{"approle", map[string]interface{}{
+			param.VaultAuthMethod:   "approle",
+			param.VaultRoleID:       "my-role",
+			param.VaultSecretIDPath: secretID,
+		}},
+		{"kubernetes", map[string]interface{}{
+			param.VaultAuthMethod:          "kubernetes",
+			param.VaultAuthMount:           "k8s",
+			param.VaultKubernetesRole:      "chaosmonkey",
+			param.VaultKubernetesTokenPath: jwt,
+		}},
+	}
+
+	for _, tt := range tests {
+		f, srv, cfg := newFakeVault(t)
+		for k, v := range tt.settings {
+			cfg.Set(k, v)
+		}
+
+		decryptor, err := getDecryptor(cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		for i := 0; i < 2; i++ {
+			got, err := decryptor.Decrypt("kv:secret/chaosmonkey/db#password")
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			} else if got != "kv2-password" {
+				t.Errorf("%s: got %q, want %q", tt.name, got, "kv2-password")
+			}
+		}
+
+		// The client token should be reused across requests
+		if got, want := f.logins, 1; got != want {
+			t.Errorf("%s: got %d logins, want %d", tt.name, got, want)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestVaultConfigErrors(t *testing.T) {
+	tests := []map[string]interface{}{
+		{param.VaultAddress: ""},
+		{param.VaultAuthMethod: "ldap"},
+		{param.VaultAuthMethod: "approle"},
+		{param.VaultAuthMethod: "kubernetes"},
+	}
+
+	for _, settings := range tests {
+		cfg := config.Defaults()
+		cfg.Set(param.Decryptor, "vault")
+		cfg.Set(param.VaultAddress, "http://localhost:8200")
+		for k, v := range settings {
+			cfg.Set(k, v)
+		}
+
+		if _, err := getDecryptor(cfg); err == nil {
+			t.Errorf("%s: expected error", fmt.Sprint(settings))
+		}
+	}
+}
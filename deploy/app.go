@@ -54,6 +54,10 @@ type (
 	AccountInfo struct {
 		CloudProvider string
 		Clusters      ClusterMap
+
+		// Stacks optionally maps cluster names to stack names. The stack of a
+		// cluster that is not in Stacks is parsed from the cluster name
+		Stacks map[ClusterName]string
 	}
 
 	// AppMap is a map that tracks info about an app
@@ -68,6 +72,9 @@ func NewApp(name string, data AppMap) *App {
 		app.accounts = append(app.accounts, &account)
 		for clusterName, clusterValue := range accountInfo.Clusters {
 			cluster := Cluster{name: string(clusterName), account: &account}
+			if stack, ok := accountInfo.Stacks[clusterName]; ok {
+				cluster.stack = &stack
+			}
 			account.clusters = append(account.clusters, &cluster)
 			for regionName, regionValue := range clusterValue {
 				for asgName, instanceIds := range regionValue {
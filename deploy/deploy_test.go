@@ -42,7 +42,7 @@ func TestASGAndClusters(t *testing.T) {
 		cloudProvider := "aws"
 
 		asg := NewASG(tc.asgName, tc.regionName, tc.ids, &cluster)
-		cluster = Cluster{tc.clusterName, []*ASG{asg}, &account}
+		cluster = Cluster{tc.clusterName, []*ASG{asg}, &account, nil}
 		account = Account{tc.accountName, []*Cluster{&cluster}, &app, cloudProvider}
 		app = App{tc.appName, []*Account{&account}}
 
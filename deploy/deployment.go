@@ -118,6 +118,9 @@ type Cluster struct {
 	name    string
 	asgs    []*ASG
 	account *Account
+
+	// stack is the stack name, if it is not derived from the cluster name
+	stack *string
 }
 
 // Name returns the name of the cluster, convention: app-stack-detail
@@ -130,8 +133,13 @@ func (c *Cluster) AppName() string {
 	return c.account.AppName()
 }
 
-// StackName returns the name of the stack, following the app-stack-detail convention
+// StackName returns the name of the stack, following the app-stack-detail
+// convention unless the stack was given explicitly
 func (c *Cluster) StackName() string {
+	if c.stack != nil {
+		return *c.stack
+	}
+
 	names, err := frigga.Parse(c.Name())
 	if err != nil {
 		panic(err)
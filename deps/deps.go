@@ -20,6 +20,7 @@ import (
 	"github.com/Netflix/chaosmonkey/clock"
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/deploy"
+	"github.com/Netflix/chaosmonkey/killswitch"
 )
 
 var (
@@ -53,4 +54,5 @@ type Deps struct {
 	Ou         chaosmonkey.Outage
 	ErrCounter chaosmonkey.ErrorCounter
 	Env        chaosmonkey.Env
+	KillSwitch killswitch.KillSwitch
 }
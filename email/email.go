@@ -0,0 +1,283 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package email notifies app owners about terminations over SMTP
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/deps"
+)
+
+// defaultTemplates contains the built-in message templates. Users may
+// override any of them by defining a template with the same name in the file
+// specified by email.template_path
+const defaultTemplates = `
+{{define "subject"}}Chaos Monkey is terminating an instance of {{.App}} ({{.Account}}, {{.Region}}){{end}}
+
+{{define "body"}}Chaos Monkey {{if .Leashed}}would terminate (leashed mode){{else}}is terminating{{end}} the following instance:
+
+  app:      {{.App}}
+  account:  {{.Account}}
+  region:   {{.Region}}
+  stack:    {{.Stack}}
+  cluster:  {{.Cluster}}
+  asg:      {{.ASG}}
+  instance: {{.InstanceID}}
+  provider: {{.CloudProvider}}
+  time:     {{.Time.Format "2006-01-02 15:04:05 MST"}}
+
+You are receiving this email because you are listed as an owner of {{.App}}.
+{{end}}
+
+{{define "test_subject"}}Chaos Monkey test email for {{.App}}{{end}}
+
+{{define "test_body"}}This is a test email from Chaos Monkey.
+
+Before Chaos Monkey terminates an instance of {{.App}}, a notification will be
+sent to: {{join .To ", "}}
+{{end}}
+`
+
+// OwnerGetter looks up the email address of the owners of an app
+type OwnerGetter interface {
+	// OwnerEmail returns the owner email of an app. It may contain several
+	// comma-separated addresses, and may be blank if the app has no owner
+	OwnerEmail(app string) (string, error)
+}
+
+// sendFunc has the same signature as smtp.SendMail
+type sendFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// Notifier sends emails to app owners. It implements chaosmonkey.Tracker
+type Notifier struct {
+	addr           string
+	auth           smtp.Auth
+	from           string
+	fallback       string
+	notifyLeashed  bool
+	blockOnFailure bool
+	owners         OwnerGetter
+	tmpl           *template.Template
+	send           sendFunc
+}
+
+// message holds the values that are available to templates
+type message struct {
+	App, Account, Region, Stack, Cluster, ASG, InstanceID, CloudProvider string
+
+	Leashed bool
+	Time    time.Time
+	From    string
+	To      []string
+}
+
+// NewFromConfig returns a Notifier configured from cfg that looks up
+// recipients with owners
+func NewFromConfig(cfg *config.Monkey, owners OwnerGetter) (*Notifier, error) {
+	if cfg.EmailHost() == "" {
+		return nil, errors.Errorf("%s not specified", param.EmailHost)
+	}
+
+	if cfg.EmailFrom() == "" {
+		return nil, errors.Errorf("%s not specified", param.EmailFrom)
+	}
+
+	tmpl, err := loadTemplates(cfg.EmailTemplatePath())
+	if err != nil {
+		return nil, err
+	}
+
+	var auth smtp.Auth
+	if user := cfg.EmailUser(); user != "" {
+		decryptor, err := deps.GetDecryptor(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		password, err := decryptor.Decrypt(cfg.EmailEncryptedPassword())
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decrypt smtp password")
+		}
+
+		auth = smtp.PlainAuth("", user, password, cfg.EmailHost())
+	}
+
+	return &Notifier{
+		addr:           fmt.Sprintf("%s:%d", cfg.EmailHost(), cfg.EmailPort()),
+		auth:           auth,
+		from:           cfg.EmailFrom(),
+		fallback:       cfg.EmailFallbackAddress(),
+		notifyLeashed:  cfg.EmailNotifyLeashed(),
+		blockOnFailure: cfg.EmailBlockOnFailure(),
+		owners:         owners,
+		tmpl:           tmpl,
+		send:           smtp.SendMail,
+	}, nil
+}
+
+// loadTemplates parses the default templates, and then the templates at path
+// if path is not blank so that they replace the defaults
+func loadTemplates(path string) (*template.Template, error) {
+	funcs := template.FuncMap{"join": strings.Join}
+	tmpl := template.Must(template.New("email").Funcs(funcs).Parse(defaultTemplates))
+
+	if path == "" {
+		return tmpl, nil
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read email templates from %s", path)
+	}
+
+	tmpl, err = tmpl.Parse(string(contents))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse email templates from %s", path)
+	}
+
+	return tmpl, nil
+}
+
+// Track implements chaosmonkey.Tracker.Track
+// It emails the owners of the app that the instance belongs to. Trackers run
+// before the instance is terminated, so that block_on_failure can prevent the
+// termination, and the message does not say whether the termination succeeded
+func (n *Notifier) Track(trm chaosmonkey.Termination) error {
+	if trm.Leashed && !n.notifyLeashed {
+		return nil
+	}
+
+	ins := trm.Instance
+	msg := message{
+		App:           ins.AppName(),
+		Account:       ins.AccountName(),
+		Region:        ins.RegionName(),
+		Stack:         ins.StackName(),
+		Cluster:       ins.ClusterName(),
+		ASG:           ins.ASGName(),
+		InstanceID:    ins.ID(),
+		CloudProvider: ins.CloudProvider(),
+		Leashed:       trm.Leashed,
+		Time:          trm.Time,
+	}
+
+	_, err := n.deliver(msg, "subject", "body")
+	if err != nil && !n.blockOnFailure {
+		log.Printf("WARNING: %v", err)
+		return nil
+	}
+
+	return err
+}
+
+// SendTest sends a test email to the owners of app
+// It returns the list of recipients
+func (n *Notifier) SendTest(app string) ([]string, error) {
+	msg := message{App: app, Time: time.Now()}
+	return n.deliver(msg, "test_subject", "test_body")
+}
+
+// deliver renders msg using the named subject and body templates and sends
+// it to the owners of msg.App. It returns the list of recipients
+func (n *Notifier) deliver(msg message, subject, body string) ([]string, error) {
+	to, err := n.recipients(msg.App)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.From = n.from
+	msg.To = to
+
+	contents, err := n.render(msg, subject, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.send(n.addr, n.auth, n.from, to, contents)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send email to %s via %s", strings.Join(to, ", "), n.addr)
+	}
+
+	return to, nil
+}
+
+// recipients returns the owner addresses of an app, or the fallback address
+// if there are none
+func (n *Notifier) recipients(app string) ([]string, error) {
+	owners, err := n.owners.OwnerEmail(app)
+	if err != nil {
+		log.Printf("WARNING: could not retrieve owner email for app=%s: %v", app, err)
+	}
+
+	result := splitAddresses(owners)
+	if len(result) > 0 {
+		return result, nil
+	}
+
+	if n.fallback == "" {
+		return nil, errors.Errorf("no owner email for app=%s and %s not specified", app, param.EmailFallbackAddress)
+	}
+
+	return []string{n.fallback}, nil
+}
+
+// splitAddresses splits a list of addresses separated by commas or semicolons
+func splitAddresses(s string) []string {
+	var result []string
+	for _, addr := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			result = append(result, addr)
+		}
+	}
+	return result
+}
+
+// render returns the full RFC 822 message, headers included
+func (n *Notifier) render(msg message, subject, body string) ([]byte, error) {
+	var subj, text bytes.Buffer
+
+	if err := n.tmpl.ExecuteTemplate(&subj, subject, msg); err != nil {
+		return nil, errors.Wrapf(err, "failed to render %s template", subject)
+	}
+
+	if err := n.tmpl.ExecuteTemplate(&text, body, msg); err != nil {
+		return nil, errors.Wrapf(err, "failed to render %s template", body)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", strings.TrimSpace(subj.String()))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprint(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprint(&buf, "Content-Type: text/plain; charset=UTF-8\r\n")
+	fmt.Fprint(&buf, "\r\n")
+	fmt.Fprint(&buf, strings.Replace(text.String(), "\n", "\r\n", -1))
+
+	return buf.Bytes(), nil
+}
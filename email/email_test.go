@@ -0,0 +1,161 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/smtp"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+// owners implements OwnerGetter
+type owners map[string]string
+
+func (o owners) OwnerEmail(app string) (string, error) {
+	email, ok := o[app]
+	if !ok {
+		return "", errors.New("no such app")
+	}
+	return email, nil
+}
+
+// sent records the messages passed to a sendFunc
+type sent struct {
+	to  []string
+	msg string
+}
+
+func newTestNotifier(t *testing.T, templatePath string, record *[]sent) *Notifier {
+	tmpl, err := loadTemplates(templatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Notifier{
+		addr:           "localhost:25",
+		from:           "chaosmonkey@example.com",
+		fallback:       "chaos-team@example.com",
+		blockOnFailure: true,
+		owners:         owners{"foo": "alice@example.com; bob@example.com", "bar": ""},
+		tmpl:           tmpl,
+		send: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			*record = append(*record, sent{to: to, msg: string(msg)})
+			return nil
+		},
+	}
+}
+
+func termination(app string, leashed bool) chaosmonkey.Termination {
+	return chaosmonkey.Termination{
+		Instance: mock.Instance{App: app, Account: "prod", Region: "us-east-1", InstanceID: "i-a96a0166"},
+		Time:     time.Date(2016, time.November, 8, 14, 3, 0, 0, time.UTC),
+		Leashed:  leashed,
+	}
+}
+
+func TestTrackSendsToOwners(t *testing.T) {
+	var record []sent
+	n := newTestNotifier(t, "", &record)
+
+	if err := n.Track(termination("foo", false)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(record), 1; got != want {
+		t.Fatalf("got %d messages, want %d", got, want)
+	}
+
+	if got, want := record[0].to, []string{"alice@example.com", "bob@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got recipients %v, want %v", got, want)
+	}
+
+	for _, s := range []string{
+		"Subject: Chaos Monkey is terminating an instance of foo (prod, us-east-1)\r\n",
+		"instance: i-a96a0166",
+	} {
+		if !strings.Contains(record[0].msg, s) {
+			t.Errorf("message does not contain %q:\n%s", s, record[0].msg)
+		}
+	}
+}
+
+func TestFallbackAddress(t *testing.T) {
+	for _, app := range []string{"bar", "unknown"} {
+		var record []sent
+		n := newTestNotifier(t, "", &record)
+
+		to, err := n.SendTest(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := to, []string{"chaos-team@example.com"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("app=%s: got recipients %v, want %v", app, got, want)
+		}
+	}
+}
+
+func TestLeashedNotSent(t *testing.T) {
+	var record []sent
+	n := newTestNotifier(t, "", &record)
+
+	if err := n.Track(termination("foo", true)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(record) != 0 {
+		t.Errorf("expected no messages for leashed termination, got %d", len(record))
+	}
+}
+
+func TestTemplateOverride(t *testing.T) {
+	f, err := ioutil.TempFile("", "cm-email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(`{{define "subject"}}[chaos] {{.InstanceID}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var record []sent
+	n := newTestNotifier(t, f.Name(), &record)
+
+	if err := n.Track(termination("foo", false)); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Subject: [chaos] i-a96a0166\r\n"; !strings.Contains(record[0].msg, want) {
+		t.Errorf("message does not contain %q:\n%s", want, record[0].msg)
+	}
+
+	// The body template was not overridden, so the default should be used
+	if want := "Chaos Monkey is terminating the following instance"; !strings.Contains(record[0].msg, want) {
+		t.Errorf("message does not contain %q:\n%s", want, record[0].msg)
+	}
+}
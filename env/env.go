@@ -12,28 +12,51 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package env contains a no-op implementation of chaosmonkey.env
-// where InTest() always returns false
+// Package env contains an implementation of chaosmonkey.Env where the
+// environment is determined by the chaosmonkey.environment config parameter
 package env
 
 import (
+	"strings"
+
 	"github.com/Netflix/chaosmonkey"
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/deps"
 )
 
-// notTestEnv is an environment that does not report as a test env
-type notTestEnv struct{}
+// configEnv is an environment named in the config file
+type configEnv struct {
+	inTest bool
+}
 
 // InTest implements chaosmonkey.Env.InTest
-func (n notTestEnv) InTest() bool {
-	return false
+func (e configEnv) InTest() bool {
+	return e.inTest
 }
 
 func init() {
-	deps.GetEnv = getNotTestEnv
+	deps.GetEnv = getEnv
 }
 
-func getNotTestEnv(cfg *config.Monkey) (chaosmonkey.Env, error) {
-	return notTestEnv{}, nil
+// getEnv returns an environment that is in test if chaosmonkey.environment is
+// one of chaosmonkey.test_environments. If no environment is configured,
+// the environment is not a test environment
+func getEnv(cfg *config.Monkey) (chaosmonkey.Env, error) {
+	name := cfg.Environment()
+	if name == "" {
+		return configEnv{inTest: false}, nil
+	}
+
+	testEnvs, err := cfg.TestEnvironments()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, testEnv := range testEnvs {
+		if strings.EqualFold(name, testEnv) {
+			return configEnv{inTest: true}, nil
+		}
+	}
+
+	return configEnv{inTest: false}, nil
 }
@@ -0,0 +1,66 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+func TestInTest(t *testing.T) {
+	tests := []struct {
+		environment string
+		testEnvs    []string
+		inTest      bool
+	}{
+		{"", []string{"test"}, false},
+		{"prod", []string{"test"}, false},
+		{"test", []string{"test"}, true},
+		{"Staging", []string{"test", "staging"}, true},
+	}
+
+	for _, tt := range tests {
+		cfg := config.Defaults()
+		cfg.Set(param.Environment, tt.environment)
+		cfg.Set(param.TestEnvironments, tt.testEnvs)
+
+		env, err := getEnv(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := env.InTest(), tt.inTest; got != want {
+			t.Errorf("environment=%q test_environments=%v: got InTest()=%t, want %t", tt.environment, tt.testEnvs, got, want)
+		}
+	}
+}
+
+// TestDefaultTestEnvironments ensures "test" is a test environment unless
+// configured otherwise
+func TestDefaultTestEnvironments(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Set(param.Environment, "test")
+
+	env, err := getEnv(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !env.InTest() {
+		t.Error("expected environment \"test\" to be a test environment by default")
+	}
+}
@@ -18,12 +18,13 @@ import (
 	"github.com/Netflix/chaosmonkey"
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/deps"
+	"github.com/Netflix/chaosmonkey/metrics"
 	"github.com/pkg/errors"
 )
 
 // Netflix uses Atlas for tracking error events.
-// In the open-source build, we currently only support a null (no-op) error
-// counter
+// In the open-source build, we support a null (no-op) error counter and a
+// Prometheus error counter
 
 type nullErrorCounter struct{}
 
@@ -31,15 +32,27 @@ func (n nullErrorCounter) Increment() error {
 	return nil
 }
 
+// prometheusErrorCounter increments the chaosmonkey_errors_total metric,
+// which is published along with the other metrics
+type prometheusErrorCounter struct{}
+
+func (p prometheusErrorCounter) Increment() error {
+	metrics.Errors.Inc()
+	return nil
+}
+
 func init() {
-	deps.GetErrorCounter = getNullErrorCounter
+	deps.GetErrorCounter = getErrorCounter
 }
 
-func getNullErrorCounter(cfg *config.Monkey) (chaosmonkey.ErrorCounter, error) {
+func getErrorCounter(cfg *config.Monkey) (chaosmonkey.ErrorCounter, error) {
 	kind := cfg.ErrorCounter()
-	if kind != "" {
+	switch kind {
+	case "":
+		return nullErrorCounter{}, nil
+	case "prometheus":
+		return prometheusErrorCounter{}, nil
+	default:
 		return nil, errors.Errorf("unsupported error counter: %s", kind)
 	}
-
-	return nullErrorCounter{}, nil
 }
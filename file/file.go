@@ -0,0 +1,261 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package file provides a backend that reads apps and their deployments from
+// a YAML or JSON file instead of discovering them, so that Chaos Monkey can
+// be run without network access, e.g. in CI or for demos.
+//
+// Example:
+//
+//	accounts:
+//	  prod: "123456789012"
+//	apps:
+//	  web:
+//	    attributes:
+//	      email: web-team@example.com
+//	      chaosMonkey:
+//	        enabled: true
+//	        grouping: cluster
+//	        meanTimeBetweenKillsInWorkDays: 2
+//	        minTimeBetweenKillsInWorkDays: 1
+//	        exceptions: []
+//	    deployment:
+//	      prod:
+//	        cloudProvider: aws
+//	        clusters:
+//	          web-main:
+//	            us-east-1:
+//	              web-main-v001: [i-0a1b2c3d, i-4e5f6a7b]
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	D "github.com/Netflix/chaosmonkey/deploy"
+	"github.com/Netflix/chaosmonkey/spinnaker"
+)
+
+// File is a backend whose apps are read from a file
+type File struct {
+	// accounts maps account names to cloud account ids
+	accounts map[string]string
+	apps     map[string]app
+}
+
+// app is an app in the file
+type app struct {
+	// Attributes has the same format as the attributes of a Spinnaker
+	// application
+	Attributes json.RawMessage `json:"attributes"`
+	Deployment D.AppMap        `json:"deployment"`
+}
+
+// NewFromConfig reads the file at the configured file.path
+func NewFromConfig(cfg *config.Monkey) (File, error) {
+	return New(cfg.FilePath())
+}
+
+// New reads the file at path. Since JSON is a subset of YAML, the file can
+// be either
+func New(path string) (File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return File{}, errors.Wrapf(err, "could not read %s", path)
+	}
+
+	f, err := parse(data)
+	if err != nil {
+		return File{}, errors.Wrapf(err, "could not parse %s", path)
+	}
+
+	return f, nil
+}
+
+// parse parses the contents of a file. The YAML is converted to JSON first,
+// so that the same field names and parsing rules apply to both formats
+func parse(data []byte) (File, error) {
+	var raw interface{}
+	err := yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return File{}, errors.Wrap(err, "yaml unmarshal failed")
+	}
+
+	js, err := json.Marshal(jsonCompatible(raw))
+	if err != nil {
+		return File{}, errors.Wrap(err, "json marshal failed")
+	}
+
+	var parsed struct {
+		Accounts map[string]string `json:"accounts"`
+		Apps     map[string]app    `json:"apps"`
+	}
+	err = json.Unmarshal(js, &parsed)
+	if err != nil {
+		return File{}, errors.Wrap(err, "json unmarshal failed")
+	}
+
+	return File{accounts: parsed.Accounts, apps: parsed.Apps}, nil
+}
+
+// jsonCompatible converts the maps that the yaml package decodes, whose keys
+// are interface{}, to maps with string keys, which can be marshaled to JSON
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[fmt.Sprint(key)] = jsonCompatible(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = jsonCompatible(value)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// Apps implements deploy.Deployment.Apps
+func (f File) Apps(c chan<- *D.App, appNames []string) {
+	defer close(c)
+
+	for _, name := range appNames {
+		app, err := f.GetApp(name)
+		if err != nil {
+			log.Printf("WARNING: %v", err)
+			continue
+		}
+		c <- app
+	}
+}
+
+// GetApp implements deploy.Deployment.GetApp
+func (f File) GetApp(name string) (*D.App, error) {
+	app, ok := f.apps[name]
+	if !ok {
+		return nil, errors.Errorf("app %s not found", name)
+	}
+
+	return D.NewApp(name, app.Deployment), nil
+}
+
+// AppNames implements deploy.Deployment.AppNames
+func (f File) AppNames() ([]string, error) {
+	result := make([]string, 0, len(f.apps))
+	for name := range f.apps {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// AccountID returns the cloud account id of an account, as listed under
+// "accounts"
+func (f File) AccountID(name string) (string, error) {
+	id, ok := f.accounts[name]
+	if !ok {
+		return "", errors.Errorf("no id for account %s", name)
+	}
+	return id, nil
+}
+
+// CloudProvider returns the cloud provider of an account, as listed in the
+// deployments of the apps
+func (f File) CloudProvider(account string) (string, error) {
+	for _, app := range f.apps {
+		if info, ok := app.Deployment[D.AccountName(account)]; ok && info.CloudProvider != "" {
+			return info.CloudProvider, nil
+		}
+	}
+
+	return "", errors.Errorf("no cloud provider for account %s", account)
+}
+
+// Get implements chaosmonkey.AppConfigGetter.Get
+// The attributes of the app are parsed in the same way as the attributes of
+// a Spinnaker application
+func (f File) Get(name string) (*chaosmonkey.AppConfig, error) {
+	js, err := f.appJSON(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return spinnaker.FromJSON(js)
+}
+
+// AppConfigJSON implements appcfg.Source.AppConfigJSON
+// It returns the "chaosMonkey" attribute of the app
+func (f File) AppConfigJSON(name string) ([]byte, error) {
+	js, err := f.appJSON(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return spinnaker.ChaosMonkeyAttribute(js)
+}
+
+// OwnerEmail implements email.OwnerGetter.OwnerEmail
+// It returns the "email" attribute of the app
+func (f File) OwnerEmail(name string) (string, error) {
+	js, err := f.appJSON(name)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Attributes struct {
+			Email string `json:"email"`
+		} `json:"attributes"`
+	}
+	err = json.Unmarshal(js, &parsed)
+	if err != nil {
+		return "", errors.Wrap(err, "json unmarshal failed")
+	}
+
+	return parsed.Attributes.Email, nil
+}
+
+// appJSON returns an app in the JSON format of a Spinnaker application
+func (f File) appJSON(name string) ([]byte, error) {
+	app, ok := f.apps[name]
+	if !ok {
+		return nil, errors.Errorf("app %s not found", name)
+	}
+
+	js, err := json.Marshal(map[string]interface{}{"name": name, "attributes": app.Attributes})
+	if err != nil {
+		return nil, errors.Wrap(err, "json marshal failed")
+	}
+
+	return js, nil
+}
+
+// Execute implements chaosmonkey.Terminator.Execute
+// Instances in a file cannot be terminated, so the file backend can only be
+// used leashed
+func (f File) Execute(trm chaosmonkey.Termination) error {
+	return errors.Errorf("cannot terminate %s: the file backend only supports leashed mode", trm.Instance.ID())
+}
@@ -0,0 +1,247 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Netflix/chaosmonkey"
+)
+
+const testYAML = `
+accounts:
+  prod: "123456789012"
+apps:
+  web:
+    attributes:
+      email: web-team@example.com
+      chaosMonkey:
+        enabled: true
+        grouping: stack
+        meanTimeBetweenKillsInWorkDays: 2
+        minTimeBetweenKillsInWorkDays: 1
+        exceptions:
+        - {account: test, stack: "*", detail: "*", region: "*"}
+    deployment:
+      prod:
+        cloudProvider: aws
+        clusters:
+          web-main:
+            us-east-1:
+              web-main-v001: [i-1, i-2]
+            us-west-2:
+              web-main-v001: [i-3]
+        stacks:
+          web-main: primary
+  api:
+    attributes:
+      chaosMonkey:
+        enabled: false
+    deployment:
+      test:
+        cloudProvider: titus
+        clusters:
+          api:
+            us-east-1:
+              api-v000: [abc]
+`
+
+const testJSON = `{
+  "apps": {
+    "web": {
+      "attributes": {"chaosMonkey": {"enabled": false}},
+      "deployment": {"prod": {"cloudProvider": "aws", "clusters": {"web": {"us-east-1": {"web-v000": ["i-1"]}}}}}
+    }
+  }
+}`
+
+// writeFile writes contents to a file in a new temporary directory
+func writeFile(t *testing.T, name, contents string) (path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "chaosmonkey-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path = filepath.Join(dir, name)
+	err = ioutil.WriteFile(path, []byte(contents), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestGetApp(t *testing.T) {
+	path, cleanup := writeFile(t, "deployment.yaml", testYAML)
+	defer cleanup()
+
+	f, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := f.GetApp("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, account := range app.Accounts() {
+		for _, cluster := range account.Clusters() {
+			for _, asg := range cluster.ASGs() {
+				for _, ins := range asg.Instances() {
+					got = append(got, fmt.Sprintf("%s %s %s %s %s %s %s", account.Name(), account.CloudProvider(), cluster.StackName(), cluster.Name(), asg.RegionName(), asg.Name(), ins.ID()))
+				}
+			}
+		}
+	}
+	sort.Strings(got)
+
+	want := []string{
+		"prod aws primary web-main us-east-1 web-main-v001 i-1",
+		"prod aws primary web-main us-east-1 web-main-v001 i-2",
+		"prod aws primary web-main us-west-2 web-main-v001 i-3",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	_, err = f.GetApp("missing")
+	if err == nil {
+		t.Error("expected error for app that is not in the file")
+	}
+}
+
+func TestAppNamesAndAccounts(t *testing.T) {
+	path, cleanup := writeFile(t, "deployment.yaml", testYAML)
+	defer cleanup()
+
+	f, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := f.AppNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := names, []string{"api", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got app names %v, want %v", got, want)
+	}
+
+	id, err := f.AccountID("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := id, "123456789012"; got != want {
+		t.Errorf("got account id %s, want %s", got, want)
+	}
+
+	provider, err := f.CloudProvider("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := provider, "titus"; got != want {
+		t.Errorf("got cloud provider %s, want %s", got, want)
+	}
+
+	_, err = f.AccountID("test")
+	if err == nil {
+		t.Error("expected error for account without an id")
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	path, cleanup := writeFile(t, "deployment.yaml", testYAML)
+	defer cleanup()
+
+	f, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := f.Get("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := chaosmonkey.AppConfig{
+		Enabled:                        true,
+		Grouping:                       chaosmonkey.Stack,
+		MeanTimeBetweenKillsInWorkDays: 2,
+		MinTimeBetweenKillsInWorkDays:  1,
+		Exceptions:                     []chaosmonkey.Exception{{Account: "test", Stack: "*", Detail: "*", Region: "*"}},
+	}
+
+	if !reflect.DeepEqual(*cfg, want) {
+		t.Errorf("got %+v, want %+v", *cfg, want)
+	}
+
+	cfg, err = f.Get("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Enabled {
+		t.Error("expected api to be disabled")
+	}
+
+	owner, err := f.OwnerEmail("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := owner, "web-team@example.com"; got != want {
+		t.Errorf("got owner %s, want %s", got, want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	path, cleanup := writeFile(t, "deployment.json", testJSON)
+	defer cleanup()
+
+	f, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := f.GetApp("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := app.Accounts()[0].Clusters()[0].ASGs()[0].Instances()[0].ID(); got != "i-1" {
+		t.Errorf("got instance %s, want i-1", got)
+	}
+
+	cfg, err := f.Get("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Enabled {
+		t.Error("expected web to be disabled")
+	}
+}
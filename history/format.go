@@ -0,0 +1,125 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Output formats
+const (
+	Table = "table"
+	JSON  = "json"
+	CSV   = "csv"
+)
+
+// columns are the columns of each termination in table and CSV output
+var columns = []string{"app", "account", "region", "stack", "cluster", "asg", "instance_id", "killed_at", "leashed", "status", "task_id"}
+
+// row returns the columns of a termination, with times in loc
+func (t Termination) row(loc *time.Location) []string {
+	return []string{t.App, t.Account, t.Region, t.Stack, t.Cluster, t.ASG, t.InstanceID,
+		t.KilledAt.In(loc).Format(time.RFC3339), strconv.FormatBool(t.Leashed), t.Status, t.TaskID}
+}
+
+// Write writes terminations to w in a format: table, json or csv. Times are
+// written in loc
+func Write(w io.Writer, format string, terms []Termination, loc *time.Location) error {
+	if format == JSON {
+		local := make([]Termination, len(terms))
+		for i, t := range terms {
+			t.KilledAt = t.KilledAt.In(loc)
+			local[i] = t
+		}
+		return WriteJSON(w, local)
+	}
+
+	rows := make([][]string, len(terms))
+	for i, t := range terms {
+		rows[i] = t.row(loc)
+	}
+
+	return WriteRows(w, format, columns, rows)
+}
+
+// WriteLast writes the most recent terminations of instance groups to w in a
+// format: table, json or csv. Times are written in loc
+func WriteLast(w io.Writer, format string, latest []Latest, loc *time.Location) error {
+	if format == JSON {
+		local := make([]Latest, len(latest))
+		for i, l := range latest {
+			l.KilledAt = l.KilledAt.In(loc)
+			l.NextAllowed = l.NextAllowed.In(loc)
+			local[i] = l
+		}
+		return WriteJSON(w, local)
+	}
+
+	header := append(append([]string{}, columns...), "next_allowed")
+	rows := make([][]string, len(latest))
+	for i, l := range latest {
+		rows[i] = append(l.row(loc), l.NextAllowed.In(loc).Format(time.RFC3339))
+	}
+
+	return WriteRows(w, format, header, rows)
+}
+
+// WriteJSON writes v as indented JSON
+func WriteJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(v), "failed to write JSON")
+}
+
+// WriteRows writes a header and rows as a table or as CSV. Blank columns are
+// written as "-" in tables
+func WriteRows(w io.Writer, format string, header []string, rows [][]string) error {
+	switch format {
+	case Table:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for _, row := range append([][]string{header}, rows...) {
+			for i, col := range row {
+				if i > 0 {
+					fmt.Fprint(tw, "\t")
+				}
+				if col == "" {
+					col = "-"
+				}
+				fmt.Fprint(tw, col)
+			}
+			fmt.Fprintln(tw)
+		}
+		return errors.Wrap(tw.Flush(), "failed to write table")
+	case CSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return errors.Wrap(err, "failed to write CSV")
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return errors.Wrap(err, "failed to write CSV")
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown format %q, must be %s, %s or %s", format, Table, JSON, CSV)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package history queries the record of past terminations
+package history
+
+import (
+	"time"
+)
+
+// Termination is a termination recorded in the database
+type Termination struct {
+	App        string    `json:"app"`
+	Account    string    `json:"account"`
+	Region     string    `json:"region"`
+	Stack      string    `json:"stack"`
+	Cluster    string    `json:"cluster"`
+	ASG        string    `json:"asg"`
+	InstanceID string    `json:"instanceId"`
+	KilledAt   time.Time `json:"killedAt"`
+	Leashed    bool      `json:"leashed"`
+
+	// Status is "succeeded" or "failed" once an unleashed termination has
+	// been executed, and blank otherwise
+	Status string `json:"status"`
+
+	// TaskID is the id of the backend task that executed the termination
+	TaskID string `json:"taskId"`
+}
+
+// Filter selects terminations. Blank fields match every termination
+type Filter struct {
+	App     string
+	Account string
+	Region  string
+	Stack   string
+	Cluster string
+
+	// Since and Until select terminations at or after Since, and before Until
+	Since time.Time
+	Until time.Time
+
+	// Leashed selects leashed terminations if true, and unleashed ones if
+	// false. If nil, both are selected
+	Leashed *bool
+}
+
+// Store retrieves recorded terminations
+type Store interface {
+	// Terminations returns the terminations that match the filter, oldest
+	// first
+	Terminations(f Filter) ([]Termination, error)
+}
+
+// Condition is a condition on a column of the terminations table
+type Condition struct {
+	Column string
+	Op     string // "=", ">=" or "<"
+	Value  interface{}
+}
+
+// Conditions returns the conditions that a termination must satisfy to match
+// the filter. Times are in UTC
+func (f Filter) Conditions() []Condition {
+	var result []Condition
+
+	for _, c := range []struct{ column, value string }{
+		{"app", f.App},
+		{"account", f.Account},
+		{"region", f.Region},
+		{"stack", f.Stack},
+		{"cluster", f.Cluster},
+	} {
+		if c.value != "" {
+			result = append(result, Condition{c.column, "=", c.value})
+		}
+	}
+
+	if !f.Since.IsZero() {
+		result = append(result, Condition{"killed_at", ">=", f.Since.UTC()})
+	}
+
+	if !f.Until.IsZero() {
+		result = append(result, Condition{"killed_at", "<", f.Until.UTC()})
+	}
+
+	if f.Leashed != nil {
+		result = append(result, Condition{"leashed", "=", *f.Leashed})
+	}
+
+	return result
+}
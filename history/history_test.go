@@ -0,0 +1,202 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/cal"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+func TestConditions(t *testing.T) {
+	leashed := false
+	since := time.Date(2016, time.November, 1, 0, 0, 0, 0, time.FixedZone("PST", -8*60*60))
+	f := Filter{App: "foo", Region: "us-east-1", Since: since, Leashed: &leashed}
+
+	want := []Condition{
+		{"app", "=", "foo"},
+		{"region", "=", "us-east-1"},
+		{"killed_at", ">=", since.UTC()},
+		{"leashed", "=", false},
+	}
+
+	if got := f.Conditions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := (Filter{}).Conditions(); len(got) != 0 {
+		t.Errorf("got %v for empty filter, want no conditions", got)
+	}
+}
+
+// sample returns terminations of two clusters of foo, at 10am PST on days of
+// November 2016
+func sample() []Termination {
+	at := func(day int) time.Time {
+		return time.Date(2016, time.November, day, 18, 0, 0, 0, time.UTC)
+	}
+
+	return []Termination{
+		{App: "foo", Account: "prod", Region: "us-east-1", Cluster: "foo-a", InstanceID: "i-1", KilledAt: at(21)},
+		{App: "foo", Account: "prod", Region: "us-east-1", Cluster: "foo-b", InstanceID: "i-2", KilledAt: at(21)},
+		{App: "foo", Account: "prod", Region: "us-east-1", Cluster: "foo-a", InstanceID: "i-3", KilledAt: at(23)},
+		{App: "foo", Account: "prod", Region: "us-west-2", Cluster: "foo-a", InstanceID: "i-4", KilledAt: at(22), Status: "failed", TaskID: "01ABC"},
+	}
+}
+
+func TestLast(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	calendars, err := cal.NewCalendars("", map[string]string{"prod": "testdata/holidays.txt"}, nil)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// mock.ConfigGetter groups by cluster, with independent regions, and
+	// allows one kill per work day
+	latest, err := Last(sample(), mock.ConfigGetter{}, calendars, hoursIn(loc))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	midnight := func(day int) time.Time {
+		return time.Date(2016, time.November, day, 0, 0, 0, 0, loc)
+	}
+
+	want := []struct {
+		id   string
+		next time.Time
+	}{
+		{"i-3", midnight(28)}, // us-east-1 foo-a, after Thanksgiving
+		{"i-2", midnight(22)}, // us-east-1 foo-b
+		{"i-4", midnight(23)}, // us-west-2 foo-a
+	}
+
+	if len(latest) != len(want) {
+		t.Fatalf("got %d groups, want %d: %+v", len(latest), len(want), latest)
+	}
+
+	for i, w := range want {
+		if got := latest[i]; got.InstanceID != w.id || !got.NextAllowed.Equal(w.next) {
+			t.Errorf("%d: got %s next allowed at %s, want %s at %s", i, got.InstanceID, got.NextAllowed, w.id, w.next)
+		}
+	}
+}
+
+// hoursIn returns business hours from 9AM to 3PM in loc, in every account
+// and region
+func hoursIn(loc *time.Location) func(account, region string) (config.BusinessHours, error) {
+	return func(account, region string) (config.BusinessHours, error) {
+		return config.BusinessHours{StartHour: 9, EndHour: 15, Location: loc}, nil
+	}
+}
+
+// appGetter returns the same config for every app
+type appGetter struct {
+	cfg chaosmonkey.AppConfig
+}
+
+func (g appGetter) Get(app string) (*chaosmonkey.AppConfig, error) {
+	cfg := g.cfg
+	return &cfg, nil
+}
+
+func TestLastAppGrouping(t *testing.T) {
+	g := appGetter{chaosmonkey.AppConfig{Grouping: chaosmonkey.App, MinTimeBetweenKillsInWorkDays: 2}}
+
+	latest, err := Last(sample(), g, cal.Calendars{}, hoursIn(time.UTC))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if len(latest) != 1 || latest[0].InstanceID != "i-3" {
+		t.Fatalf("got %+v, want the last termination i-3", latest)
+	}
+
+	// Wednesday, plus two work days
+	if want := time.Date(2016, time.November, 28, 0, 0, 0, 0, time.UTC); !latest[0].NextAllowed.Equal(want) {
+		t.Errorf("got next allowed %s, want %s", latest[0].NextAllowed, want)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Table, sample()[3:], time.UTC); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+
+	if got, want := strings.Fields(lines[0]), columns; !reflect.DeepEqual(got, want) {
+		t.Errorf("got header %v, want %v", got, want)
+	}
+
+	want := []string{"foo", "prod", "us-west-2", "-", "foo-a", "-", "i-4", "2016-11-22T18:00:00Z", "false", "failed", "01ABC"}
+	if got := strings.Fields(lines[1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("got row %v, want %v", got, want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, sample()[:1], time.FixedZone("PST", -8*60*60)); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	want := "app,account,region,stack,cluster,asg,instance_id,killed_at,leashed,status,task_id\n" +
+		"foo,prod,us-east-1,,foo-a,,i-1,2016-11-21T10:00:00-08:00,false,,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteLastJSON(t *testing.T) {
+	next := time.Date(2016, time.November, 22, 0, 0, 0, 0, time.UTC)
+	latest := []Latest{{Termination: sample()[0], NextAllowed: next}}
+
+	var buf bytes.Buffer
+	if err := WriteLast(&buf, JSON, latest, time.UTC); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+
+	if len(got) != 1 || got[0]["instanceId"] != "i-1" || got[0]["nextAllowed"] != "2016-11-22T00:00:00Z" {
+		t.Errorf("unexpected JSON: %s", buf.String())
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(new(bytes.Buffer), "yaml", sample(), time.UTC); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
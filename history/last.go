@@ -0,0 +1,128 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/cal"
+	"github.com/Netflix/chaosmonkey/config"
+)
+
+// Latest is the most recent termination of an instance group, as grouped by
+// the app's config, and the earliest time that the min time between kills
+// allows the group to be terminated again
+type Latest struct {
+	Termination
+	NextAllowed time.Time `json:"nextAllowed"`
+}
+
+// group identifies the instance group that a termination counts against
+type group struct {
+	app, account, region, stack, cluster string
+}
+
+// Last returns the most recent of terms for each instance group, ordered by
+// app, account, region, stack and cluster. The groups and the min time
+// between kills come from each app's config in g. The next allowed time is
+// computed with calendars and the business hours returned by hours, like the
+// min time between kills check
+func Last(terms []Termination, g chaosmonkey.AppConfigGetter, calendars cal.Calendars, hours func(account, region string) (config.BusinessHours, error)) ([]Latest, error) {
+	type key struct{ app, account string }
+	configs := make(map[key]*chaosmonkey.AppConfig)
+	latest := make(map[group]Termination)
+
+	for _, term := range terms {
+		k := key{term.App, term.Account}
+		cfg, ok := configs[k]
+		if !ok {
+			var err error
+			cfg, err = chaosmonkey.GetAppConfig(g, term.App, term.Account)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get config for app=%s account=%s", term.App, term.Account)
+			}
+			configs[k] = cfg
+		}
+
+		id, err := groupOf(term, *cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		if prev, ok := latest[id]; !ok || term.KilledAt.After(prev.KilledAt) {
+			latest[id] = term
+		}
+	}
+
+	result := make([]Latest, 0, len(latest))
+	for _, term := range latest {
+		cfg := configs[key{term.App, term.Account}]
+		h, err := hours(term.Account, term.Region)
+		if err != nil {
+			return nil, err
+		}
+		calendar := calendars.For(term.Account, term.Region)
+		next, err := calendar.NextKillAllowed(cfg.MinTimeBetweenKillsInWorkDays, term.KilledAt, h.EndHour, h.Location)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Latest{Termination: term, NextAllowed: next})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch {
+		case a.App != b.App:
+			return a.App < b.App
+		case a.Account != b.Account:
+			return a.Account < b.Account
+		case a.Region != b.Region:
+			return a.Region < b.Region
+		case a.Stack != b.Stack:
+			return a.Stack < b.Stack
+		default:
+			return a.Cluster < b.Cluster
+		}
+	})
+
+	return result, nil
+}
+
+// groupOf returns the group that a termination counts against for the min
+// time between kills
+func groupOf(term Termination, cfg chaosmonkey.AppConfig) (group, error) {
+	result := group{app: term.App, account: term.Account}
+
+	switch cfg.Grouping {
+	case chaosmonkey.App:
+		// nothing to do
+	case chaosmonkey.Stack:
+		result.stack = term.Stack
+	case chaosmonkey.Cluster:
+		result.cluster = term.Cluster
+	default:
+		return group{}, errors.Errorf("unknown group: %v", cfg.Grouping)
+	}
+
+	if cfg.RegionsAreIndependent {
+		result.region = term.Region
+	}
+
+	return result, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobs writes the jobs that run Chaos Monkey on a schedule as
+// systemd units or Kubernetes CronJobs, as an alternative to the crontabs
+// written by the "install" and "schedule" commands
+package jobs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/kubernetes"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+const (
+	// terminatePrefix is the prefix of the names of the jobs that terminate
+	// instances. The jobs of a schedule are numbered in order of time
+	terminatePrefix = "chaosmonkey-terminate-"
+
+	// scheduleName is the name of the job that generates the schedule
+	scheduleName = "chaosmonkey-schedule"
+)
+
+// Writer writes jobs in one format
+type Writer interface {
+	// WriteTerminations replaces the jobs of the previous schedule of
+	// terminations with jobs that execute the entries of s
+	WriteTerminations(s *schedule.Schedule) error
+
+	// RemoveTerminations removes the jobs of the current schedule of
+	// terminations
+	RemoveTerminations() error
+
+	// WriteSchedule writes the job that runs "chaosmonkey schedule" every
+	// time the cron expression fires
+	WriteSchedule(expr string) error
+}
+
+// NewFromConfig returns the writer for the systemd or kubernetes
+// chaosmonkey.job_format. Crontabs are written by the command package
+func NewFromConfig(cfg *config.Monkey) (Writer, error) {
+	loc, err := cfg.Location()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get location")
+	}
+
+	switch format := cfg.JobFormat(); format {
+	case "systemd":
+		return Systemd{
+			Dir:          cfg.SystemdUnitDir(),
+			Systemctl:    cfg.SystemdSystemctl(),
+			User:         cfg.TermAccount(),
+			TermPath:     cfg.TermPath(),
+			SchedulePath: cfg.SchedulePath(),
+			TimeZone:     loc.String(),
+		}, nil
+	case "kubernetes":
+		if cfg.KubernetesCronJobImage() == "" {
+			return nil, errors.Errorf("%s must be set when %s is kubernetes", param.KubernetesCronJobImage, param.JobFormat)
+		}
+		command, err := cfg.KubernetesCronJobCommand()
+		if err != nil {
+			return nil, err
+		}
+		client, err := kubernetes.NewFromConfig(cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create kubernetes client")
+		}
+		return Kubernetes{
+			Client:         client,
+			Namespace:      cfg.KubernetesCronJobNamespace(),
+			Image:          cfg.KubernetesCronJobImage(),
+			Command:        command,
+			ServiceAccount: cfg.KubernetesCronJobServiceAccount(),
+			TimeZone:       loc.String(),
+		}, nil
+	default:
+		return nil, errors.Errorf("unsupported %s: %s", param.JobFormat, format)
+	}
+}
+
+// glob returns the names of the files in dir that match pattern
+func glob(dir, pattern string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad pattern %s", pattern)
+	}
+
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return names, nil
+}
+
+// removeFiles removes the files in dir that match pattern
+func removeFiles(dir, pattern string) error {
+	names, err := glob(dir, pattern)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		err = os.Remove(filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "could not remove %s", name)
+		}
+	}
+	return nil
+}
+
+// writeFile writes a job file, replacing any previous one
+func writeFile(dir, name string, content []byte) error {
+	var perms os.FileMode = 0644 // -rw-r--r--
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, content, perms)
+	return errors.Wrapf(err, "could not write %s", path)
+}
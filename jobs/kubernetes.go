@@ -0,0 +1,219 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+const (
+	// managedByLabel marks the CronJobs written by Chaos Monkey
+	managedByLabel = "app.kubernetes.io/managed-by"
+
+	// jobLabel is "terminate" for the CronJobs of the schedule of
+	// terminations, and "schedule" for the CronJob that generates it
+	jobLabel = "chaosmonkey.netflix.com/job"
+
+	// groupAnnotation describes the instance group of a termination
+	groupAnnotation = "chaosmonkey.netflix.com/group"
+
+	// startingDeadlineSeconds is how late a job may start, e.g. if the
+	// CronJob controller was unavailable. Later terminations are skipped,
+	// like cron skips the jobs of a host that was down
+	startingDeadlineSeconds = 300
+
+	// jobsHistoryLimit is the number of finished Jobs of each CronJob that
+	// are kept, so that the last run of a termination can be inspected
+	jobsHistoryLimit = 1
+)
+
+// CronJobClient creates and deletes CronJobs through the API server
+type CronJobClient interface {
+	// ApplyCronJob creates the CronJob of a JSON manifest, or replaces the
+	// CronJob with the same name
+	ApplyCronJob(namespace, name string, manifest []byte) error
+
+	// DeleteCronJobs deletes the CronJobs that match a label selector, e.g.
+	// "app.kubernetes.io/managed-by=chaosmonkey". It returns an error if any
+	// of them remains
+	DeleteCronJobs(namespace, selector string) error
+}
+
+// Kubernetes creates jobs as Kubernetes CronJobs, through the API server
+type Kubernetes struct {
+	// Client creates and deletes the CronJobs
+	Client CronJobClient
+
+	// Namespace is the namespace of the CronJobs
+	Namespace string
+
+	// Image is the container image that runs chaosmonkey
+	Image string
+
+	// Command runs chaosmonkey in the image, e.g. ["chaosmonkey"]
+	Command []string
+
+	// ServiceAccount is the service account of the pods. If blank, the
+	// namespace's default is used
+	ServiceAccount string
+
+	// TimeZone is the time zone that the cron expression of the schedule
+	// is evaluated in
+	TimeZone string
+}
+
+// cronJob is a batch/v1 CronJob
+type cronJob struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   objectMeta  `json:"metadata"`
+	Spec       cronJobSpec `json:"spec"`
+}
+
+type objectMeta struct {
+	Name        string            `json:"name,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type cronJobSpec struct {
+	Schedule                   string `json:"schedule"`
+	TimeZone                   string `json:"timeZone"`
+	ConcurrencyPolicy          string `json:"concurrencyPolicy"`
+	StartingDeadlineSeconds    int    `json:"startingDeadlineSeconds"`
+	SuccessfulJobsHistoryLimit int    `json:"successfulJobsHistoryLimit"`
+	FailedJobsHistoryLimit     int    `json:"failedJobsHistoryLimit"`
+	JobTemplate                struct {
+		Metadata objectMeta `json:"metadata"`
+		Spec     struct {
+			BackoffLimit int `json:"backoffLimit"`
+			Template     struct {
+				Metadata objectMeta `json:"metadata"`
+				Spec     podSpec    `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	} `json:"jobTemplate"`
+}
+
+type podSpec struct {
+	RestartPolicy      string      `json:"restartPolicy"`
+	ServiceAccountName string      `json:"serviceAccountName,omitempty"`
+	Containers         []container `json:"containers"`
+}
+
+type container struct {
+	Name    string   `json:"name"`
+	Image   string   `json:"image"`
+	Command []string `json:"command"`
+}
+
+// WriteTerminations implements Writer.WriteTerminations
+//
+// Each entry gets a chaosmonkey-terminate-NNN CronJob that fires at the time
+// of the entry. The CronJobs of the previous schedule are deleted first.
+// Since a CronJob cannot be limited to one year, the CronJob of an entry
+// fires again on the same date of the next year, unless the next schedule or
+// "chaosmonkey stop" deletes it
+func (k Kubernetes) WriteTerminations(sched *schedule.Schedule) error {
+	err := k.RemoveTerminations()
+	if err != nil {
+		return err
+	}
+
+	entries := append([]schedule.Entry(nil), sched.Entries()...)
+	sort.Sort(schedule.ByTime(entries))
+
+	log.Printf("Creating %d CronJobs in namespace %s", len(entries), k.Namespace)
+	for i, e := range entries {
+		name := fmt.Sprintf("%s%03d", terminatePrefix, i+1)
+		t := e.Time.UTC()
+		job := k.cronJob(name, "terminate",
+			fmt.Sprintf("%d %d %d %d *", t.Minute(), t.Hour(), t.Day(), t.Month()), "Etc/UTC",
+			append([]string{"terminate"}, schedule.TerminateArgs(e.Group)...))
+		job.Metadata.Annotations = map[string]string{groupAnnotation: grp.String(e.Group)}
+
+		err = k.apply(name, job)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveTerminations implements Writer.RemoveTerminations
+// It deletes the CronJobs of the schedule of terminations from the cluster
+func (k Kubernetes) RemoveTerminations() error {
+	selector := fmt.Sprintf("%s=chaosmonkey,%s=terminate", managedByLabel, jobLabel)
+	return k.Client.DeleteCronJobs(k.Namespace, selector)
+}
+
+// WriteSchedule implements Writer.WriteSchedule
+func (k Kubernetes) WriteSchedule(expr string) error {
+	log.Printf("Creating CronJob %s in namespace %s", scheduleName, k.Namespace)
+	return k.apply(scheduleName, k.cronJob(scheduleName, "schedule", expr, k.TimeZone, []string{"schedule"}))
+}
+
+// cronJob returns a CronJob that runs chaosmonkey with args
+func (k Kubernetes) cronJob(name, job, schedule, timeZone string, args []string) cronJob {
+	labels := map[string]string{
+		managedByLabel: "chaosmonkey",
+		jobLabel:       job,
+	}
+
+	c := cronJob{
+		APIVersion: "batch/v1",
+		Kind:       "CronJob",
+		Metadata:   objectMeta{Name: name, Namespace: k.Namespace, Labels: labels},
+	}
+	c.Spec.Schedule = schedule
+	c.Spec.TimeZone = timeZone
+	c.Spec.ConcurrencyPolicy = "Forbid"
+	c.Spec.StartingDeadlineSeconds = startingDeadlineSeconds
+	c.Spec.SuccessfulJobsHistoryLimit = jobsHistoryLimit
+	c.Spec.FailedJobsHistoryLimit = jobsHistoryLimit
+	c.Spec.JobTemplate.Metadata.Labels = labels
+
+	// A failed termination is not retried, like with cron
+	c.Spec.JobTemplate.Spec.BackoffLimit = 0
+	c.Spec.JobTemplate.Spec.Template.Metadata.Labels = labels
+	c.Spec.JobTemplate.Spec.Template.Spec = podSpec{
+		RestartPolicy:      "Never",
+		ServiceAccountName: k.ServiceAccount,
+		Containers: []container{{
+			Name:    "chaosmonkey",
+			Image:   k.Image,
+			Command: append(append([]string(nil), k.Command...), args...),
+		}},
+	}
+	return c
+}
+
+// apply creates or replaces a CronJob
+func (k Kubernetes) apply(name string, job cronJob) error {
+	manifest, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrapf(err, "could not marshal CronJob %s", name)
+	}
+	return k.Client.ApplyCronJob(k.Namespace, name, manifest)
+}
@@ -0,0 +1,182 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// fakeCronJobClient keeps the CronJobs of one namespace in memory
+type fakeCronJobClient struct {
+	t    *testing.T
+	jobs map[string]cronJob
+
+	// failDelete makes DeleteCronJobs fail, like when CronJobs remain
+	failDelete bool
+}
+
+func newFakeCronJobClient(t *testing.T) *fakeCronJobClient {
+	return &fakeCronJobClient{t: t, jobs: make(map[string]cronJob)}
+}
+
+func (f *fakeCronJobClient) ApplyCronJob(namespace, name string, manifest []byte) error {
+	var job cronJob
+	if err := json.Unmarshal(manifest, &job); err != nil {
+		f.t.Fatal(err)
+	}
+	if job.Metadata.Name != name || job.Metadata.Namespace != namespace {
+		f.t.Errorf("applied %s/%s with metadata %+v", namespace, name, job.Metadata)
+	}
+	f.jobs[name] = job
+	return nil
+}
+
+func (f *fakeCronJobClient) DeleteCronJobs(namespace, selector string) error {
+	if f.failDelete {
+		return errors.New("CronJobs were not deleted")
+	}
+
+	for name, job := range f.jobs {
+		matches := true
+		for _, term := range strings.Split(selector, ",") {
+			kv := strings.SplitN(term, "=", 2)
+			if job.Metadata.Labels[kv[0]] != kv[1] {
+				matches = false
+			}
+		}
+		if matches {
+			delete(f.jobs, name)
+		}
+	}
+	return nil
+}
+
+// names returns the sorted names of the CronJobs
+func (f *fakeCronJobClient) names() []string {
+	result := []string{}
+	for name := range f.jobs {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestKubernetesTerminations(t *testing.T) {
+	client := newFakeCronJobClient(t)
+	k := Kubernetes{
+		Client:         client,
+		Namespace:      "chaos",
+		Image:          "example.com/chaosmonkey:2.0.2",
+		Command:        []string{"/chaosmonkey"},
+		ServiceAccount: "chaosmonkey",
+		TimeZone:       "America/Los_Angeles",
+	}
+
+	err := k.WriteSchedule("0 7 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = k.WriteTerminations(newSchedule(t,
+		"bar", "2016-11-16T11:23:00-08:00",
+		"foo", "2016-11-16T10:15:00-08:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"chaosmonkey-schedule", "chaosmonkey-terminate-001", "chaosmonkey-terminate-002"}
+	if got := client.names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got CronJobs %v, want %v", got, want)
+	}
+
+	job := client.jobs["chaosmonkey-terminate-001"]
+	if job.Kind != "CronJob" || job.APIVersion != "batch/v1" {
+		t.Errorf("unexpected kind: %s %s", job.APIVersion, job.Kind)
+	}
+	if job.Metadata.Labels[jobLabel] != "terminate" {
+		t.Errorf("got labels %v, want %s=terminate", job.Metadata.Labels, jobLabel)
+	}
+	if job.Spec.Schedule != "15 18 16 11 *" || job.Spec.TimeZone != "Etc/UTC" {
+		t.Errorf("got schedule %q in %s, want \"15 18 16 11 *\" in Etc/UTC", job.Spec.Schedule, job.Spec.TimeZone)
+	}
+	if job.Spec.SuccessfulJobsHistoryLimit != 1 || job.Spec.FailedJobsHistoryLimit != 1 {
+		t.Errorf("got history limits %d and %d, want 1", job.Spec.SuccessfulJobsHistoryLimit, job.Spec.FailedJobsHistoryLimit)
+	}
+
+	pod := job.Spec.JobTemplate.Spec.Template.Spec
+	wantCommand := []string{"/chaosmonkey", "terminate", "foo", "prod", "--cluster=foo-prod", "--region=us-east-1"}
+	if len(pod.Containers) != 1 || !reflect.DeepEqual(pod.Containers[0].Command, wantCommand) || pod.Containers[0].Image != k.Image {
+		t.Errorf("got containers %+v, want command %v", pod.Containers, wantCommand)
+	}
+	if pod.RestartPolicy != "Never" || pod.ServiceAccountName != "chaosmonkey" {
+		t.Errorf("unexpected pod spec: %+v", pod)
+	}
+
+	// The next day's schedule replaces the previous one
+	err = k.WriteTerminations(newSchedule(t, "baz", "2016-11-17T10:00:00-08:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.names(); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("got CronJobs %v, want %v", got, want[:2])
+	}
+
+	// Removing the terminations leaves the schedule
+	err = k.RemoveTerminations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.names(); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("got CronJobs %v after removal, want %v", got, want[:1])
+	}
+
+	// CronJobs that remain are an error, and no new ones are created
+	client.failDelete = true
+	if err := k.RemoveTerminations(); err == nil {
+		t.Error("got no error when CronJobs remain")
+	}
+	if err := k.WriteTerminations(newSchedule(t, "baz", "2016-11-18T10:00:00-08:00")); err == nil {
+		t.Error("got no error writing terminations when CronJobs remain")
+	}
+	if got := client.names(); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("got CronJobs %v, want %v", got, want[:1])
+	}
+}
+
+func TestKubernetesSchedule(t *testing.T) {
+	client := newFakeCronJobClient(t)
+	k := Kubernetes{Client: client, Namespace: "chaos", Image: "chaosmonkey", Command: []string{"chaosmonkey"}, TimeZone: "America/Los_Angeles"}
+	err := k.WriteSchedule("0 7 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := client.jobs["chaosmonkey-schedule"]
+	if job.Spec.Schedule != "0 7 * * 1-5" || job.Spec.TimeZone != "America/Los_Angeles" {
+		t.Errorf("got schedule %q in %s", job.Spec.Schedule, job.Spec.TimeZone)
+	}
+	if got := job.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command; !reflect.DeepEqual(got, []string{"chaosmonkey", "schedule"}) {
+		t.Errorf("got command %v", got)
+	}
+	if job.Metadata.Labels[jobLabel] != "schedule" {
+		t.Errorf("got labels %v, want %s=schedule", job.Metadata.Labels, jobLabel)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/cron"
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// Systemd writes jobs as pairs of systemd timer and service units
+type Systemd struct {
+	// Dir is the directory the units are written to
+	Dir string
+
+	// Systemctl is the systemctl command used to enable the timers. If
+	// blank, the units are written but systemctl is not run
+	Systemctl string
+
+	// User is the user that the services run as
+	User string
+
+	// TermPath is the script that terminates an instance
+	TermPath string
+
+	// SchedulePath is the script that generates the schedule
+	SchedulePath string
+
+	// TimeZone is the time zone that the cron expression of the schedule
+	// is evaluated in
+	TimeZone string
+
+	// run runs a command. It is replaced in tests
+	run func(name string, args ...string) error
+}
+
+// WriteTerminations implements Writer.WriteTerminations
+//
+// Each entry gets a chaosmonkey-terminate-NNN.timer that fires once, at the
+// time of the entry, and starts the matching .service. The timers of the
+// previous schedule are disabled and their units removed
+func (s Systemd) WriteTerminations(sched *schedule.Schedule) error {
+	err := s.RemoveTerminations()
+	if err != nil {
+		return err
+	}
+
+	entries := append([]schedule.Entry(nil), sched.Entries()...)
+	sort.Sort(schedule.ByTime(entries))
+
+	timers := make([]string, len(entries))
+	for i, e := range entries {
+		name := fmt.Sprintf("%s%03d", terminatePrefix, i+1)
+		description := "Chaos Monkey termination: " + grp.String(e.Group)
+		exec := append([]string{s.TermPath}, schedule.TerminateArgs(e.Group)...)
+		onCalendar := []string{e.Time.UTC().Format("2006-01-02 15:04:05") + " UTC"}
+
+		err = s.writeUnits(name, description, exec, onCalendar, false)
+		if err != nil {
+			return err
+		}
+		timers[i] = name + ".timer"
+	}
+
+	log.Printf("Writing %d timers to %s", len(timers), s.Dir)
+	return s.enable(timers...)
+}
+
+// RemoveTerminations implements Writer.RemoveTerminations
+func (s Systemd) RemoveTerminations() error {
+	timers, err := glob(s.Dir, terminatePrefix+"*.timer")
+	if err != nil {
+		return err
+	}
+
+	if len(timers) > 0 && s.Systemctl != "" {
+		err = s.systemctl(append([]string{"disable", "--now"}, timers...)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pattern := range []string{"*.timer", "*.service"} {
+		err = removeFiles(s.Dir, terminatePrefix+pattern)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSchedule implements Writer.WriteSchedule
+//
+// The timer fires every time expr fires in the time zone, and is enabled so
+// that it survives reboots
+func (s Systemd) WriteSchedule(expr string) error {
+	e, err := cron.Parse(expr)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Writing %s.timer to %s", scheduleName, s.Dir)
+	err = s.writeUnits(scheduleName, "Chaos Monkey schedule", []string{s.SchedulePath}, e.OnCalendar(s.TimeZone), true)
+	if err != nil {
+		return err
+	}
+
+	return s.enable(scheduleName + ".timer")
+}
+
+// writeUnits writes the timer and service units of a job
+func (s Systemd) writeUnits(name, description string, exec []string, onCalendar []string, install bool) error {
+	var timer bytes.Buffer
+	fmt.Fprintf(&timer, "[Unit]\nDescription=%s\n\n[Timer]\n", escapeSpecifiers(description))
+	for _, c := range onCalendar {
+		fmt.Fprintf(&timer, "OnCalendar=%s\n", c)
+	}
+	// The default accuracy of one minute would delay terminations randomly
+	timer.WriteString("AccuracySec=1s\n")
+	if install {
+		timer.WriteString("\n[Install]\nWantedBy=timers.target\n")
+	}
+
+	var service bytes.Buffer
+	fmt.Fprintf(&service, "[Unit]\nDescription=%s\n\n[Service]\nType=oneshot\n", escapeSpecifiers(description))
+	if s.User != "" {
+		fmt.Fprintf(&service, "User=%s\n", s.User)
+	}
+	fmt.Fprintf(&service, "ExecStart=%s\n", execLine(exec))
+
+	err := writeFile(s.Dir, name+".timer", timer.Bytes())
+	if err != nil {
+		return err
+	}
+	return writeFile(s.Dir, name+".service", service.Bytes())
+}
+
+// enable reloads the units and enables and starts the timers
+func (s Systemd) enable(timers ...string) error {
+	if s.Systemctl == "" {
+		return nil
+	}
+
+	err := s.systemctl("daemon-reload")
+	if err != nil {
+		return err
+	}
+
+	if len(timers) == 0 {
+		return nil
+	}
+	return s.systemctl(append([]string{"enable", "--now"}, timers...)...)
+}
+
+// systemctl runs systemctl with args
+func (s Systemd) systemctl(args ...string) error {
+	run := s.run
+	if run == nil {
+		run = func(name string, args ...string) error {
+			out, err := exec.Command(name, args...).CombinedOutput()
+			if err != nil {
+				return errors.Errorf("%v: %s", err, bytes.TrimSpace(out))
+			}
+			return nil
+		}
+	}
+
+	err := run(s.Systemctl, args...)
+	return errors.Wrapf(err, "%s %s failed", s.Systemctl, strings.Join(args, " "))
+}
+
+// execLine returns the ExecStart= command line that runs args, quoting the
+// arguments that systemd would otherwise split or expand
+func execLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		arg = escapeSpecifiers(arg)
+		if arg == "" || strings.ContainsAny(arg, " \t\"'\\;$") {
+			arg = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", "$$").Replace(arg) + `"`
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// escapeSpecifiers escapes the % specifiers that systemd expands in unit
+// files
+func escapeSpecifiers(s string) string {
+	return strings.Replace(s, "%", "%%", -1)
+}
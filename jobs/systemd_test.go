@@ -0,0 +1,210 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// newSchedule returns a schedule with a termination of each app, at times
+// in RFC3339 format
+func newSchedule(t *testing.T, entries ...string) *schedule.Schedule {
+	s := schedule.New()
+	for i := 0; i < len(entries); i += 2 {
+		tm, err := time.Parse(time.RFC3339, entries[i+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Add(tm, grp.New(entries[i], "prod", "us-east-1", "", entries[i]+"-prod"))
+	}
+	return s
+}
+
+// tempDir returns a temporary directory and a function that removes it
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "chaosmonkey-jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
+
+// files returns the names of the files in dir
+func files(t *testing.T, dir string) []string {
+	names, err := glob(dir, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func read(t *testing.T, dir, name string) string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestSystemdTerminations(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	var calls []string
+	s := Systemd{
+		Dir:       dir,
+		Systemctl: "systemctl",
+		User:      "root",
+		TermPath:  "/apps/chaosmonkey/chaosmonkey-terminate.sh",
+		run: func(name string, args ...string) error {
+			calls = append(calls, name+" "+strings.Join(args, " "))
+			return nil
+		},
+	}
+
+	// Entries are numbered in order of time
+	err := s.WriteTerminations(newSchedule(t,
+		"bar", "2016-11-16T11:23:00-08:00",
+		"foo", "2016-11-16T10:15:00-08:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"chaosmonkey-terminate-001.service",
+		"chaosmonkey-terminate-001.timer",
+		"chaosmonkey-terminate-002.service",
+		"chaosmonkey-terminate-002.timer",
+	}
+	if got := files(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %v, want %v", got, want)
+	}
+
+	wantTimer := `[Unit]
+Description=Chaos Monkey termination: app=foo account=prod region=us-east-1 cluster=foo-prod
+
+[Timer]
+OnCalendar=2016-11-16 18:15:00 UTC
+AccuracySec=1s
+`
+	if got := read(t, dir, "chaosmonkey-terminate-001.timer"); got != wantTimer {
+		t.Errorf("got timer:\n%s\nwant:\n%s", got, wantTimer)
+	}
+
+	wantService := `[Unit]
+Description=Chaos Monkey termination: app=foo account=prod region=us-east-1 cluster=foo-prod
+
+[Service]
+Type=oneshot
+User=root
+ExecStart=/apps/chaosmonkey/chaosmonkey-terminate.sh foo prod --cluster=foo-prod --region=us-east-1
+`
+	if got := read(t, dir, "chaosmonkey-terminate-001.service"); got != wantService {
+		t.Errorf("got service:\n%s\nwant:\n%s", got, wantService)
+	}
+
+	// The next day's schedule replaces the previous one
+	calls = nil
+	err = s.WriteTerminations(newSchedule(t, "baz", "2016-11-17T10:00:00-08:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = []string{"chaosmonkey-terminate-001.service", "chaosmonkey-terminate-001.timer"}
+	if got := files(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %v, want %v", got, want)
+	}
+	if got := read(t, dir, "chaosmonkey-terminate-001.timer"); !strings.Contains(got, "OnCalendar=2016-11-17 18:00:00 UTC") {
+		t.Errorf("timer not replaced:\n%s", got)
+	}
+
+	wantCalls := []string{
+		"systemctl disable --now chaosmonkey-terminate-001.timer chaosmonkey-terminate-002.timer",
+		"systemctl daemon-reload",
+		"systemctl enable --now chaosmonkey-terminate-001.timer",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("got systemctl calls %q, want %q", calls, wantCalls)
+	}
+
+	err = s.RemoveTerminations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := files(t, dir); len(got) != 0 {
+		t.Errorf("got files %v after removal, want none", got)
+	}
+}
+
+func TestSystemdSchedule(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// Without systemctl, the units are only written
+	s := Systemd{
+		Dir:          dir,
+		User:         "root",
+		SchedulePath: "/apps/chaosmonkey/chaosmonkey-schedule.sh",
+		TimeZone:     "America/Los_Angeles",
+		run: func(name string, args ...string) error {
+			t.Errorf("unexpected call: %s %v", name, args)
+			return nil
+		},
+	}
+
+	err := s.WriteSchedule("0 7 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTimer := `[Unit]
+Description=Chaos Monkey schedule
+
+[Timer]
+OnCalendar=Mon,Tue,Wed,Thu,Fri *-*-* 07:00:00 America/Los_Angeles
+AccuracySec=1s
+
+[Install]
+WantedBy=timers.target
+`
+	if got := read(t, dir, "chaosmonkey-schedule.timer"); got != wantTimer {
+		t.Errorf("got timer:\n%s\nwant:\n%s", got, wantTimer)
+	}
+
+	if got := read(t, dir, "chaosmonkey-schedule.service"); !strings.Contains(got, "ExecStart=/apps/chaosmonkey/chaosmonkey-schedule.sh\n") {
+		t.Errorf("unexpected service:\n%s", got)
+	}
+
+	if err := s.WriteSchedule("0 7 * *"); err == nil {
+		t.Error("expected error for bad cron expression")
+	}
+}
+
+func TestExecLine(t *testing.T) {
+	got := execLine([]string{"/bin/terminate", "foo", "my app", `a"b`, "100%", "$HOME"})
+	want := `/bin/terminate foo "my app" "a\"b" 100%% "$$HOME"`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package killswitch
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// File is a kill switch stored in a local file. Terminations are stopped if
+// the file exists. To share it between hosts, put it on a shared filesystem
+type File struct {
+	Path string
+}
+
+// fileContents is the JSON document stored in the kill switch file
+type fileContents struct {
+	Reason    string     `json:"reason"`
+	StoppedAt time.Time  `json:"stoppedAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// NewFile returns a kill switch stored at path
+func NewFile(path string) File {
+	return File{Path: path}
+}
+
+// Stop implements KillSwitch.Stop
+func (f File) Stop(reason string, stoppedAt time.Time, expiresAt time.Time) error {
+	contents := fileContents{Reason: reason, StoppedAt: stoppedAt.UTC()}
+	if !expiresAt.IsZero() {
+		utc := expiresAt.UTC()
+		contents.ExpiresAt = &utc
+	}
+
+	data, err := json.MarshalIndent(contents, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "json marshal failed")
+	}
+
+	// Write to a temporary file and rename, so that a terminate that runs at
+	// the same time never sees a partial file
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Path), filepath.Base(f.Path))
+	if err != nil {
+		return errors.Wrap(err, "could not create kill switch file")
+	}
+
+	_, err = tmp.Write(append(data, '\n'))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.Path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrapf(err, "could not write kill switch file %s", f.Path)
+	}
+
+	return nil
+}
+
+// Resume implements KillSwitch.Resume
+func (f File) Resume() error {
+	err := os.Remove(f.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "could not remove kill switch file %s", f.Path)
+	}
+
+	return nil
+}
+
+// State implements KillSwitch.State
+// If the file exists but cannot be parsed, terminations are considered
+// stopped
+func (f File) State() (State, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, errors.Wrapf(err, "could not read kill switch file %s", f.Path)
+	}
+
+	var contents fileContents
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return State{Stopped: true, Reason: "kill switch file " + f.Path + " exists but could not be parsed"}, nil
+	}
+
+	state := State{Stopped: true, Reason: contents.Reason, StoppedAt: contents.StoppedAt}
+	if contents.ExpiresAt != nil {
+		state.ExpiresAt = *contents.ExpiresAt
+	}
+
+	return state, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package killswitch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStopResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaosmonkey-killswitch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := NewFile(filepath.Join(dir, "kill-switch.json"))
+
+	state, err := f.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Stopped {
+		t.Fatal("expected kill switch to be inactive when file is absent")
+	}
+
+	stoppedAt := time.Date(2016, time.November, 16, 9, 0, 0, 0, time.UTC)
+	expiresAt := stoppedAt.Add(4 * time.Hour)
+
+	err = f.Stop("game day", stoppedAt, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = f.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.Stopped || state.Reason != "game day" || !state.StoppedAt.Equal(stoppedAt) || !state.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("unexpected state after stop: %+v", state)
+	}
+
+	if !state.Active(stoppedAt.Add(time.Hour)) {
+		t.Error("expected kill switch to be active before it expires")
+	}
+
+	if state.Active(expiresAt) {
+		t.Error("expected kill switch to be inactive once it expires")
+	}
+
+	err = f.Resume()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = f.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Stopped {
+		t.Error("expected kill switch to be inactive after resume")
+	}
+}
+
+// TestFileUnparseable ensures that creating the file by hand, e.g. with touch,
+// stops terminations
+func TestFileUnparseable(t *testing.T) {
+	f, err := ioutil.TempFile("", "chaosmonkey-killswitch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_ = f.Close()
+
+	state, err := NewFile(f.Name()).State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.Active(time.Now()) {
+		t.Error("expected kill switch to be active when file exists but is empty")
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package killswitch provides a global switch that stops all terminations
+package killswitch
+
+import (
+	"time"
+)
+
+// State is the state of the kill switch
+type State struct {
+	// Stopped is true if terminations were stopped
+	Stopped bool
+
+	// Reason is the reason given for stopping terminations. May be blank
+	Reason string
+
+	// StoppedAt is when terminations were stopped
+	StoppedAt time.Time
+
+	// ExpiresAt is when terminations resume automatically. It is the zero
+	// time if the kill switch does not expire
+	ExpiresAt time.Time
+}
+
+// Active returns true if terminations are stopped at time now
+func (s State) Active(now time.Time) bool {
+	return s.Stopped && (s.ExpiresAt.IsZero() || now.Before(s.ExpiresAt))
+}
+
+// KillSwitch stops and resumes terminations on every host that shares it
+type KillSwitch interface {
+	// Stop stops terminations until Resume is called or until expiresAt. If
+	// expiresAt is the zero time, the kill switch does not expire
+	Stop(reason string, stoppedAt time.Time, expiresAt time.Time) error
+
+	// Resume resumes terminations
+	Resume() error
+
+	// State returns the current state of the kill switch
+	State() (State, error)
+}
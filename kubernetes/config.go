@@ -0,0 +1,108 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/spinnaker"
+)
+
+// Get implements chaosmonkey.AppConfigGetter.Get
+// The config is read from the config annotation of the app's workloads, in the
+// same format as the "chaosMonkey" attribute of a Spinnaker application, e.g.
+//
+//	chaosmonkey.netflix.com/config: '{"enabled": true, "grouping": "cluster",
+//	  "meanTimeBetweenKillsInWorkDays": 5, "minTimeBetweenKillsInWorkDays": 1,
+//	  "exceptions": []}'
+//
+// An app whose workloads are not annotated is disabled. All the annotated
+// workloads of an app must have the same config.
+func (k Kubernetes) Get(app string) (*chaosmonkey.AppConfig, error) {
+	js, err := k.AppConfigJSON(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if js == nil {
+		cfg := chaosmonkey.NewAppConfig(nil)
+		cfg.Enabled = false
+		return &cfg, nil
+	}
+
+	return spinnaker.AppConfigFromJSON(js)
+}
+
+// AppConfigJSON implements appcfg.Source.AppConfigJSON
+// It returns the config annotation of the app's workloads, or nil if none of
+// them are annotated
+func (k Kubernetes) AppConfigJSON(app string) ([]byte, error) {
+	values, err := k.annotations(app, k.configAnnotation)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(values) {
+	case 0:
+		return nil, nil
+	case 1:
+		return []byte(values[0]), nil
+	default:
+		return nil, errors.Errorf("workloads of app %s have conflicting %s annotations", app, k.configAnnotation)
+	}
+}
+
+// OwnerEmail implements email.OwnerGetter.OwnerEmail
+// It returns the owner annotations of the app's workloads, comma-separated
+func (k Kubernetes) OwnerEmail(app string) (string, error) {
+	values, err := k.annotations(app, k.ownerAnnotation)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+// annotations returns the distinct non-blank values of an annotation on the
+// workloads of an app
+func (k Kubernetes) annotations(app, key string) ([]string, error) {
+	workloads, err := k.workloads(k.appLabel + "=" + app)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(workloads) == 0 {
+		return nil, errors.Errorf("no deployments or statefulsets found with label %s=%s", k.appLabel, app)
+	}
+
+	set := make(map[string]bool)
+	for _, w := range workloads {
+		if value := strings.TrimSpace(w.Metadata.Annotations[key]); value != "" {
+			set[value] = true
+		}
+	}
+
+	result := make([]string, 0, len(set))
+	for value := range set {
+		result = append(result, value)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ApplyCronJob implements jobs.CronJobClient.ApplyCronJob
+// The CronJob is created, or replaced if it already exists
+func (k Kubernetes) ApplyCronJob(ns, name string, manifest []byte) error {
+	collection := k.path("apis/batch/v1", ns, "cronjobs")
+
+	_, err := k.do("POST", collection, manifest)
+	if hasStatus(err, http.StatusConflict) {
+		_, err = k.do("PUT", collection+"/"+url.PathEscape(name), manifest)
+	}
+
+	return errors.Wrapf(err, "failed to apply CronJob %s/%s", ns, name)
+}
+
+// DeleteCronJobs implements jobs.CronJobClient.DeleteCronJobs
+// The Jobs and pods of the CronJobs are deleted in the background. Returns an
+// error if any CronJob that matches the selector is not being deleted
+// afterwards
+func (k Kubernetes) DeleteCronJobs(ns, selector string) error {
+	collection := k.path("apis/batch/v1", ns, "cronjobs")
+
+	query := url.Values{}
+	query.Set("labelSelector", selector)
+	query.Set("propagationPolicy", "Background")
+	_, err := k.do("DELETE", collection+"?"+query.Encode(), nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete CronJobs %s in namespace %s", selector, ns)
+	}
+
+	items, err := k.list(collection, selector)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list CronJobs %s in namespace %s", selector, ns)
+	}
+
+	var remaining []string
+	for _, item := range items {
+		var job struct {
+			Metadata objectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(item, &job); err != nil {
+			return errors.Wrap(err, "could not parse cronjobs")
+		}
+		if job.Metadata.DeletionTimestamp == nil {
+			remaining = append(remaining, job.Metadata.Name)
+		}
+	}
+
+	if len(remaining) > 0 {
+		return errors.Errorf("CronJobs %s in namespace %s were not deleted", strings.Join(remaining, ", "), ns)
+	}
+
+	return nil
+}
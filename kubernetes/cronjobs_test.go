@@ -0,0 +1,82 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCronJobs(t *testing.T) {
+	// A CronJob of another app is left alone
+	other := object{resource: "cronjobs", namespace: "chaos", name: "backup", labels: map[string]string{"app": "backup"},
+		json: `{"metadata": {"name": "backup", "namespace": "chaos", "labels": {"app": "backup"}}}`}
+	k, f, cleanup := newTestKubernetes(t, other)
+	defer cleanup()
+
+	labels := `{"app.kubernetes.io/managed-by": "chaosmonkey", "chaosmonkey.netflix.com/job": "terminate"}`
+	job := func(name, schedule string) []byte {
+		return []byte(`{"kind": "CronJob", "metadata": {"name": "` + name + `", "namespace": "chaos", "labels": ` + labels + `}, "spec": {"schedule": "` + schedule + `"}}`)
+	}
+
+	for _, step := range []struct{ name, schedule string }{
+		{"chaosmonkey-terminate-001", "15 18 16 11 *"},
+		{"chaosmonkey-terminate-002", "23 19 16 11 *"},
+		{"chaosmonkey-terminate-001", "0 18 17 11 *"},
+	} {
+		if err := k.ApplyCronJob("chaos", step.name, job(step.name, step.schedule)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The CronJob that already exists is replaced
+	want := []string{
+		"POST /apis/batch/v1/namespaces/chaos/cronjobs",
+		"POST /apis/batch/v1/namespaces/chaos/cronjobs",
+		"PUT /apis/batch/v1/namespaces/chaos/cronjobs/chaosmonkey-terminate-001",
+	}
+	if !reflect.DeepEqual(f.applied, want) {
+		t.Errorf("got requests %v, want %v", f.applied, want)
+	}
+	if got, want := len(f.objects), 3; got != want {
+		t.Errorf("got %d objects, want %d", got, want)
+	}
+
+	err := k.DeleteCronJobs("chaos", "app.kubernetes.io/managed-by=chaosmonkey,chaosmonkey.netflix.com/job=terminate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := f.deleted["/apis/batch/v1/namespaces/chaos/cronjobs?labelSelector=app.kubernetes.io%2Fmanaged-by%3Dchaosmonkey%2Cchaosmonkey.netflix.com%2Fjob%3Dterminate&propagationPolicy=Background"]; !ok {
+		t.Errorf("CronJobs not deleted, got deletions %v", f.deleted)
+	}
+	if got, want := len(f.objects), 1; got != want || f.objects[0].name != "backup" {
+		t.Errorf("got objects %+v, want only backup", f.objects)
+	}
+}
+
+// Test that CronJobs that are not deleted, e.g. because the service account
+// may only delete some of them, are an error
+func TestDeleteCronJobsRemaining(t *testing.T) {
+	labels := map[string]string{"app.kubernetes.io/managed-by": "chaosmonkey"}
+	k, _, cleanup := newTestKubernetes(t, object{resource: "cronjobs", namespace: "chaos", name: "chaosmonkey-terminate-001", labels: labels, undeletable: true,
+		json: `{"metadata": {"name": "chaosmonkey-terminate-001", "namespace": "chaos"}}`})
+	defer cleanup()
+
+	err := k.DeleteCronJobs("chaos", "app.kubernetes.io/managed-by=chaosmonkey")
+	if err == nil {
+		t.Error("got no error with a remaining CronJob")
+	}
+}
@@ -0,0 +1,527 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kubernetes provides an interface to the Kubernetes API
+//
+// Workloads are mapped onto the Chaos Monkey deployment model as follows:
+//
+//	app      the value of the app label of a Deployment or StatefulSet
+//	account  the value of the account label, or the namespace if no account
+//	         label is configured
+//	stack    the value of the stack label
+//	region   the configured region name for the Kubernetes cluster
+//	cluster  the Deployment or StatefulSet
+//	asg      a ReplicaSet of a Deployment, or the StatefulSet itself
+//	instance a running pod, identified by namespace/name
+package kubernetes
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	D "github.com/Netflix/chaosmonkey/deploy"
+)
+
+// cloudProvider is the cloud provider reported for Kubernetes accounts
+const cloudProvider = "kubernetes"
+
+// Kubernetes implements the deploy.Deployment interface by querying the
+// Kubernetes API server, and the chaosmonkey.Terminator interface by deleting
+// pods
+type Kubernetes struct {
+	endpoint    string
+	token       string
+	client      *http.Client
+	namespaces  []string
+	region      string
+	gracePeriod time.Duration
+
+	appLabel         string
+	accountLabel     string
+	stackLabel       string
+	configAnnotation string
+	ownerAnnotation  string
+}
+
+// objectMeta is the metadata of a Kubernetes object
+type objectMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	UID               string            `json:"uid"`
+	Labels            map[string]string `json:"labels"`
+	Annotations       map[string]string `json:"annotations"`
+	OwnerReferences   []ownerReference  `json:"ownerReferences"`
+	DeletionTimestamp *string           `json:"deletionTimestamp"`
+}
+
+// ownerReference identifies the object that owns another object, e.g. the
+// ReplicaSet that owns a pod
+type ownerReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	UID  string `json:"uid"`
+}
+
+// ownedBy returns true if the object is owned by the object with uid
+func (m objectMeta) ownedBy(uid string) bool {
+	for _, ref := range m.OwnerReferences {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// workload is a Deployment, StatefulSet or ReplicaSet
+type workload struct {
+	kind     string
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		Replicas *int `json:"replicas"`
+		Selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		} `json:"selector"`
+	} `json:"spec"`
+}
+
+// pod is a Kubernetes pod
+type pod struct {
+	Metadata objectMeta `json:"metadata"`
+	Status   struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+// running returns true if the pod is running and is not being deleted
+func (p pod) running() bool {
+	return p.Status.Phase == "Running" && p.Metadata.DeletionTimestamp == nil
+}
+
+// id returns the instance id of the pod
+func (p pod) id() D.InstanceID {
+	return D.InstanceID(p.Metadata.Namespace + "/" + p.Metadata.Name)
+}
+
+// NewFromConfig returns a Kubernetes based on config
+func NewFromConfig(cfg *config.Monkey) (Kubernetes, error) {
+	endpoint := cfg.KubernetesEndpoint()
+	if endpoint == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return Kubernetes{}, errors.New("no kubernetes endpoint specified in config, and not running in a Kubernetes cluster")
+		}
+		endpoint = "https://" + net.JoinHostPort(host, port)
+	}
+
+	namespaces, err := cfg.KubernetesNamespaces()
+	if err != nil {
+		return Kubernetes{}, err
+	}
+
+	token, err := readOptionalFile(cfg.KubernetesTokenPath())
+	if err != nil {
+		return Kubernetes{}, err
+	}
+
+	transport := &http.Transport{}
+	ca, err := readOptionalFile(cfg.KubernetesCAPath())
+	if err != nil {
+		return Kubernetes{}, err
+	}
+
+	if ca != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(ca)) {
+			return Kubernetes{}, errors.Errorf("no certificates found in %s", cfg.KubernetesCAPath())
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return Kubernetes{
+		endpoint:         strings.TrimRight(endpoint, "/"),
+		token:            strings.TrimSpace(token),
+		client:           &http.Client{Transport: transport, Timeout: cfg.KubernetesTimeout()},
+		namespaces:       namespaces,
+		region:           cfg.KubernetesRegion(),
+		gracePeriod:      cfg.KubernetesGracePeriod(),
+		appLabel:         cfg.KubernetesAppLabel(),
+		accountLabel:     cfg.KubernetesAccountLabel(),
+		stackLabel:       cfg.KubernetesStackLabel(),
+		configAnnotation: cfg.KubernetesConfigAnnotation(),
+		ownerAnnotation:  cfg.KubernetesOwnerAnnotation(),
+	}, nil
+}
+
+// readOptionalFile returns the contents of the file at path, or a blank
+// string if path is blank or the file does not exist
+func readOptionalFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read file %s", path)
+	}
+
+	return string(contents), nil
+}
+
+// AccountID implements the "account" command. Kubernetes accounts do not have
+// ids
+func (k Kubernetes) AccountID(name string) (string, error) {
+	return "", errors.New("kubernetes accounts do not have ids")
+}
+
+// CloudProvider returns the cloud provider of an account, which is always
+// "kubernetes"
+func (k Kubernetes) CloudProvider(account string) (string, error) {
+	return cloudProvider, nil
+}
+
+// Apps implements deploy.Deployment.Apps
+func (k Kubernetes) Apps(c chan<- *D.App, appNames []string) {
+	// Close the channel we're done
+	defer close(c)
+
+	for _, appName := range appNames {
+		app, err := k.GetApp(appName)
+		if err != nil {
+			// If we have a problem with one app, we go to the next one
+			log.Printf("WARNING: GetApp failed for %s: %v", appName, err)
+			continue
+		}
+
+		c <- app
+	}
+}
+
+// GetApp implements deploy.Deployment.GetApp
+func (k Kubernetes) GetApp(appName string) (*D.App, error) {
+	workloads, err := k.workloads(k.appLabel + "=" + appName)
+	if err != nil {
+		return nil, err
+	}
+
+	region := D.RegionName(k.region)
+	data := make(D.AppMap)
+	for _, w := range workloads {
+		account := D.AccountName(k.account(w.Metadata))
+		if _, present := data[account]; !present {
+			data[account] = D.AccountInfo{
+				CloudProvider: cloudProvider,
+				Clusters:      make(D.ClusterMap),
+				Stacks:        make(map[D.ClusterName]string),
+			}
+		}
+
+		asgs, err := k.asgs(w)
+		if err != nil {
+			log.Printf("WARNING: could not retrieve asgs for app:%s account:%s cluster:%s : %v", appName, account, w.Metadata.Name, err)
+			continue
+		}
+
+		// Workloads with the same name in namespaces that map to the same
+		// account are one cluster, like a Spinnaker cluster that spans
+		// regions. Pod ids include the namespace, so they stay distinct
+		clusterName := D.ClusterName(w.Metadata.Name)
+		stack := w.Metadata.Labels[k.stackLabel]
+		regions, present := data[account].Clusters[clusterName]
+		if !present {
+			regions = map[D.RegionName]map[D.ASGName][]D.InstanceID{region: make(map[D.ASGName][]D.InstanceID)}
+			data[account].Clusters[clusterName] = regions
+			data[account].Stacks[clusterName] = stack
+		} else if prev := data[account].Stacks[clusterName]; prev != stack {
+			log.Printf("WARNING: cluster %s of app:%s account:%s has stacks %q and %q, using %q", clusterName, appName, account, prev, stack, prev)
+		}
+
+		for name, ids := range asgs {
+			regions[region][name] = append(regions[region][name], ids...)
+		}
+	}
+
+	return D.NewApp(appName, data), nil
+}
+
+// AppNames returns the names of all apps, which are the values of the app
+// label on Deployments and StatefulSets
+func (k Kubernetes) AppNames() ([]string, error) {
+	workloads, err := k.workloads(k.appLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	for _, w := range workloads {
+		set[w.Metadata.Labels[k.appLabel]] = true
+	}
+
+	result := make([]string, 0, len(set))
+	for name := range set {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// account returns the account name of a workload
+func (k Kubernetes) account(m objectMeta) string {
+	if k.accountLabel != "" {
+		if account, ok := m.Labels[k.accountLabel]; ok {
+			return account
+		}
+	}
+
+	return m.Namespace
+}
+
+// workloads returns the Deployments and StatefulSets that match selector in
+// the configured namespaces
+func (k Kubernetes) workloads(selector string) ([]workload, error) {
+	var result []workload
+	for _, kind := range []string{"deployments", "statefulsets"} {
+		for _, ns := range k.namespacesOrAll() {
+			items, err := k.list(k.path("apis/apps/v1", ns, kind), selector)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, item := range items {
+				var w workload
+				if err := json.Unmarshal(item, &w); err != nil {
+					return nil, errors.Wrapf(err, "could not parse %s", kind)
+				}
+				w.kind = kind
+				result = append(result, w)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// asgs returns the running pods of a workload, grouped by ASG name
+func (k Kubernetes) asgs(w workload) (map[D.ASGName][]D.InstanceID, error) {
+	ns := w.Metadata.Namespace
+	selector := matchLabels(w.Spec.Selector.MatchLabels)
+
+	pods, err := k.pods(ns, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[D.ASGName][]D.InstanceID)
+
+	if w.kind == "statefulsets" {
+		result[D.ASGName(w.Metadata.Name)] = owned(pods, w.Metadata.UID)
+		return result, nil
+	}
+
+	items, err := k.list(k.path("apis/apps/v1", ns, "replicasets"), selector)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		var rs workload
+		if err := json.Unmarshal(item, &rs); err != nil {
+			return nil, errors.Wrap(err, "could not parse replicasets")
+		}
+
+		// ReplicaSets of previous rollouts are scaled down to zero, like
+		// disabled ASGs
+		if !rs.Metadata.ownedBy(w.Metadata.UID) || (rs.Spec.Replicas != nil && *rs.Spec.Replicas == 0) {
+			continue
+		}
+
+		result[D.ASGName(rs.Metadata.Name)] = owned(pods, rs.Metadata.UID)
+	}
+
+	return result, nil
+}
+
+// owned returns the ids of the running pods owned by the object with uid
+func owned(pods []pod, uid string) []D.InstanceID {
+	result := []D.InstanceID{}
+	for _, p := range pods {
+		if p.running() && p.Metadata.ownedBy(uid) {
+			result = append(result, p.id())
+		}
+	}
+	return result
+}
+
+// pods returns the pods in namespace ns that match selector
+func (k Kubernetes) pods(ns, selector string) ([]pod, error) {
+	items, err := k.list(k.path("api/v1", ns, "pods"), selector)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]pod, len(items))
+	for i, item := range items {
+		if err := json.Unmarshal(item, &result[i]); err != nil {
+			return nil, errors.Wrap(err, "could not parse pods")
+		}
+	}
+
+	return result, nil
+}
+
+// matchLabels converts the matchLabels of a label selector to the string
+// representation used in queries
+func matchLabels(labels map[string]string) string {
+	terms := make([]string, 0, len(labels))
+	for key, value := range labels {
+		terms = append(terms, key+"="+value)
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, ",")
+}
+
+// namespacesOrAll returns the configured namespaces, or a single blank
+// namespace, which means all namespaces
+func (k Kubernetes) namespacesOrAll() []string {
+	if len(k.namespaces) == 0 {
+		return []string{""}
+	}
+	return k.namespaces
+}
+
+// path returns the path of a collection of resources in namespace ns, or in
+// all namespaces if ns is blank
+func (k Kubernetes) path(group, ns, resource string) string {
+	if ns == "" {
+		return fmt.Sprintf("/%s/%s", group, resource)
+	}
+	return fmt.Sprintf("/%s/namespaces/%s/%s", group, url.PathEscape(ns), resource)
+}
+
+// list returns the items of a collection of resources that match selector,
+// following continuation tokens
+func (k Kubernetes) list(path, selector string) ([]json.RawMessage, error) {
+	var result []json.RawMessage
+	query := url.Values{}
+	query.Set("limit", "500")
+	if selector != "" {
+		query.Set("labelSelector", selector)
+	}
+
+	for {
+		body, err := k.do("GET", path+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Items    []json.RawMessage `json:"items"`
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+		}
+
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, errors.Wrapf(err, "could not parse response from %s", path)
+		}
+
+		result = append(result, page.Items...)
+
+		if page.Metadata.Continue == "" {
+			return result, nil
+		}
+		query.Set("continue", page.Metadata.Continue)
+	}
+}
+
+// do sends a request to the API server and returns the response body. It
+// returns an error unless the response code is 2xx
+func (k Kubernetes) do(method, path string, payload []byte) (body []byte, err error) {
+	u := k.endpoint + path
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create request to %s", u)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if k.token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s failed", method, u)
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "failed to close response body from %s", u)
+		}
+	}()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "body read failed at %s", u)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return nil, statusError{method: method, url: u, code: resp.StatusCode, body: body}
+	}
+
+	return body, nil
+}
+
+// statusError is the error returned by do for responses that are not 2xx
+type statusError struct {
+	method, url string
+	code        int
+	body        []byte
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected response code (%d) from %s %s: %s", e.code, e.method, e.url, e.body)
+}
+
+// hasStatus returns true if err is the error of a response with status code
+func hasStatus(err error, code int) bool {
+	e, ok := errors.Cause(err).(statusError)
+	return ok && e.code == code
+}
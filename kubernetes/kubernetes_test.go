@@ -0,0 +1,421 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	D "github.com/Netflix/chaosmonkey/deploy"
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+// object is a Kubernetes object served by fakeAPIServer
+type object struct {
+	resource  string // e.g., "pods"
+	namespace string
+	name      string // only set for objects created through the server
+
+	// undeletable objects are kept when their collection is deleted, like
+	// objects that the caller may not delete
+	undeletable bool
+	labels      map[string]string
+	json        string
+}
+
+// fakeAPIServer serves list and delete requests for objects, with support for
+// namespaces and simple label selectors
+type fakeAPIServer struct {
+	t       *testing.T
+	objects []object
+
+	mu      sync.Mutex
+	deleted map[string]string // URL -> request body
+	applied []string          // method and path of creations and replacements
+}
+
+// find returns the index of an object, or -1
+func (f *fakeAPIServer) find(resource, ns, name string) int {
+	for i, o := range f.objects {
+		if o.resource == resource && o.namespace == ns && o.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got, want := r.Header.Get("Authorization"), "Bearer s3cr3t"; got != want {
+		f.t.Errorf("got Authorization=%q, want %q", got, want)
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] == "apis" {
+		parts = parts[1:]
+	}
+	parts = parts[2:] // group and version
+
+	var ns string
+	if parts[0] == "namespaces" {
+		ns = parts[1]
+		parts = parts[2:]
+	}
+
+	switch r.Method {
+	case "DELETE":
+		body, _ := ioutil.ReadAll(r.Body)
+		f.mu.Lock()
+		f.deleted[r.URL.String()] = string(body)
+		if len(parts) == 1 {
+			// Delete a collection
+			var kept []object
+			for _, o := range f.objects {
+				if o.undeletable || o.resource != parts[0] || o.namespace != ns || !matches(o.labels, r.URL.Query().Get("labelSelector")) {
+					kept = append(kept, o)
+				}
+			}
+			f.objects = kept
+		}
+		f.mu.Unlock()
+		fmt.Fprint(w, `{"kind": "Status"}`)
+		return
+	case "POST", "PUT":
+		body, _ := ioutil.ReadAll(r.Body)
+		var obj struct {
+			Metadata objectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(body, &obj); err != nil {
+			f.t.Fatal(err)
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		i := f.find(parts[0], ns, obj.Metadata.Name)
+		switch {
+		case r.Method == "POST" && i >= 0:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"kind": "Status", "reason": "AlreadyExists"}`)
+			return
+		case r.Method == "PUT" && i < 0:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind": "Status", "reason": "NotFound"}`)
+			return
+		}
+
+		o := object{resource: parts[0], namespace: ns, name: obj.Metadata.Name, labels: obj.Metadata.Labels, json: string(body)}
+		if i >= 0 {
+			f.objects[i] = o
+		} else {
+			f.objects = append(f.objects, o)
+		}
+		f.applied = append(f.applied, r.Method+" "+r.URL.Path)
+		fmt.Fprint(w, string(body))
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var items []string
+	for _, o := range f.objects {
+		if o.resource == parts[0] && (ns == "" || o.namespace == ns) && matches(o.labels, r.URL.Query().Get("labelSelector")) {
+			items = append(items, o.json)
+		}
+	}
+
+	fmt.Fprintf(w, `{"items": [%s], "metadata": {}}`, strings.Join(items, ","))
+}
+
+// matches returns true if labels match a selector of the form "k1=v1,k2"
+func matches(labels map[string]string, selector string) bool {
+	if selector == "" {
+		return true
+	}
+
+	for _, term := range strings.Split(selector, ",") {
+		kv := strings.SplitN(term, "=", 2)
+		value, ok := labels[kv[0]]
+		if !ok || (len(kv) == 2 && value != kv[1]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// workloadObject returns a Deployment, StatefulSet or ReplicaSet
+func workloadObject(resource, ns, name, uid, owner string, replicas int, labels map[string]string, annotations map[string]string) object {
+	meta := map[string]interface{}{"name": name, "namespace": ns, "uid": uid, "labels": labels, "annotations": annotations}
+	if owner != "" {
+		meta["ownerReferences"] = []map[string]string{{"uid": owner}}
+	}
+
+	js, _ := json.Marshal(map[string]interface{}{
+		"metadata": meta,
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"selector": map[string]interface{}{"matchLabels": map[string]string{"run": name}},
+		},
+	})
+
+	return object{resource: resource, namespace: ns, labels: labels, json: string(js)}
+}
+
+// podObject returns a pod
+func podObject(ns, name, owner, workload, phase string) object {
+	labels := map[string]string{"run": workload}
+	js, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "namespace": ns, "labels": labels, "ownerReferences": []map[string]string{{"uid": owner}}},
+		"status":   map[string]string{"phase": phase},
+	})
+
+	return object{resource: "pods", namespace: ns, labels: labels, json: string(js)}
+}
+
+func newTestKubernetes(t *testing.T, objects ...object) (Kubernetes, *fakeAPIServer, func()) {
+	f := &fakeAPIServer{t: t, objects: objects, deleted: make(map[string]string)}
+	srv := httptest.NewServer(f)
+
+	token, err := ioutil.TempFile("", "chaosmonkey-kubernetes-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = token.WriteString("s3cr3t\n")
+	_ = token.Close()
+
+	cfg := config.Defaults()
+	cfg.Set(param.KubernetesEndpoint, srv.URL)
+	cfg.Set(param.KubernetesTokenPath, token.Name())
+	cfg.Set(param.KubernetesCAPath, "")
+	cfg.Set(param.KubernetesRegion, "k8s-east")
+	cfg.Set(param.KubernetesAppLabel, "app")
+	cfg.Set(param.KubernetesStackLabel, "stack")
+	cfg.Set(param.KubernetesGracePeriod, "10s")
+
+	k, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k, f, func() {
+		srv.Close()
+		_ = os.Remove(token.Name())
+	}
+}
+
+// testObjects returns a "web" app with a Deployment in namespace prod, which
+// has a current and an old ReplicaSet, and a StatefulSet in namespace test
+func testObjects() []object {
+	// The stacks differ from what would be parsed from the workload names
+	webLabels := map[string]string{"app": "web", "stack": "primary"}
+	cacheLabels := map[string]string{"app": "web"}
+	cfg := map[string]string{"chaosmonkey.netflix.com/config": `{"enabled": true, "grouping": "cluster", "meanTimeBetweenKillsInWorkDays": 3, "minTimeBetweenKillsInWorkDays": 1}`}
+
+	return []object{
+		workloadObject("deployments", "prod", "web-main", "d1", "", 2, webLabels, cfg),
+		workloadObject("replicasets", "prod", "web-main-7d9f8", "rs1", "d1", 2, map[string]string{"run": "web-main"}, nil),
+		workloadObject("replicasets", "prod", "web-main-5c4b3", "rs0", "d1", 0, map[string]string{"run": "web-main"}, nil),
+		podObject("prod", "web-main-7d9f8-abcde", "rs1", "web-main", "Running"),
+		podObject("prod", "web-main-7d9f8-fghij", "rs1", "web-main", "Running"),
+		podObject("prod", "web-main-7d9f8-klmno", "rs1", "web-main", "Pending"),
+		workloadObject("statefulsets", "test", "web-cache", "s1", "", 1, cacheLabels, cfg),
+		podObject("test", "web-cache-0", "s1", "web-cache", "Running"),
+		workloadObject("deployments", "prod", "api", "d2", "", 1, map[string]string{"app": "api"}, nil),
+	}
+}
+
+func TestGetApp(t *testing.T) {
+	k, _, cleanup := newTestKubernetes(t, testObjects()...)
+	defer cleanup()
+
+	app, err := k.GetApp("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type asg struct {
+		account, stack, region, cluster, name string
+		ids                                   []string
+	}
+
+	var got []asg
+	for _, account := range app.Accounts() {
+		for _, cluster := range account.Clusters() {
+			for _, a := range cluster.ASGs() {
+				var ids []string
+				for _, ins := range a.Instances() {
+					ids = append(ids, ins.ID())
+				}
+				sort.Strings(ids)
+				got = append(got, asg{account.Name(), cluster.StackName(), a.RegionName(), cluster.Name(), a.Name(), ids})
+			}
+		}
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].name < got[j].name })
+
+	want := []asg{
+		{"test", "", "k8s-east", "web-cache", "web-cache", []string{"test/web-cache-0"}},
+		{"prod", "primary", "k8s-east", "web-main", "web-main-7d9f8", []string{"prod/web-main-7d9f8-abcde", "prod/web-main-7d9f8-fghij"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, account := range app.Accounts() {
+		if got, want := account.CloudProvider(), "kubernetes"; got != want {
+			t.Errorf("got CloudProvider()=%s, want %s", got, want)
+		}
+	}
+}
+
+func TestAppNames(t *testing.T) {
+	k, _, cleanup := newTestKubernetes(t, testObjects()...)
+	defer cleanup()
+
+	names, err := k.AppNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := names, []string{"api", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// Test that workloads with the same name in two namespaces that map to the
+// same account are merged into one cluster
+func TestGetAppSameNameInNamespaces(t *testing.T) {
+	labels := map[string]string{"app": "web", "env": "prod"}
+	k, _, cleanup := newTestKubernetes(t,
+		workloadObject("statefulsets", "east", "web", "s1", "", 1, labels, nil),
+		podObject("east", "web-0", "s1", "web", "Running"),
+		workloadObject("statefulsets", "west", "web", "s2", "", 1, labels, nil),
+		podObject("west", "web-0", "s2", "web", "Running"),
+	)
+	defer cleanup()
+	k.accountLabel = "env"
+
+	app, err := k.GetApp("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, account := range app.Accounts() {
+		for _, cluster := range account.Clusters() {
+			for _, a := range cluster.ASGs() {
+				for _, ins := range a.Instances() {
+					got = append(got, fmt.Sprintf("%s/%s/%s %s", account.Name(), cluster.Name(), a.Name(), ins.ID()))
+				}
+			}
+		}
+	}
+	sort.Strings(got)
+
+	want := []string{"prod/web/web east/web-0", "prod/web/web west/web-0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestAccountLabel(t *testing.T) {
+	labels := map[string]string{"app": "web", "env": "staging"}
+	k, _, cleanup := newTestKubernetes(t, workloadObject("deployments", "web", "web", "d1", "", 1, labels, nil))
+	defer cleanup()
+	k.accountLabel = "env"
+
+	app, err := k.GetApp("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := app.Accounts()[0].Name(), "staging"; got != want {
+		t.Errorf("got account %s, want %s", got, want)
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	k, _, cleanup := newTestKubernetes(t, testObjects()...)
+	defer cleanup()
+
+	cfg, err := k.Get("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cfg.Enabled || cfg.MeanTimeBetweenKillsInWorkDays != 3 || cfg.Grouping != chaosmonkey.Cluster {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	// Workloads without the annotation are not opted in
+	cfg, err = k.Get("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Enabled {
+		t.Error("expected app without config annotation to be disabled")
+	}
+
+	if _, err := k.Get("missing"); err == nil {
+		t.Error("expected error for app without workloads")
+	}
+}
+
+func TestExecute(t *testing.T) {
+	k, f, cleanup := newTestKubernetes(t)
+	defer cleanup()
+
+	ins := mock.Instance{App: "web", Account: "prod", Region: "k8s-east", InstanceID: "prod/web-main-7d9f8-abcde"}
+	err := k.Execute(chaosmonkey.Termination{Instance: ins, Time: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, ok := f.deleted["/api/v1/namespaces/prod/pods/web-main-7d9f8-abcde"]
+	if !ok {
+		t.Fatalf("pod not deleted, got deletions %v", f.deleted)
+	}
+
+	var opts deleteOptions
+	if err := json.Unmarshal([]byte(body), &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := opts.GracePeriodSeconds, int64(10); got != want {
+		t.Errorf("got gracePeriodSeconds=%d, want %d", got, want)
+	}
+}
+
+// Kubernetes must implement the interfaces used by the commands
+var (
+	_ D.Deployment                = Kubernetes{}
+	_ chaosmonkey.Terminator      = Kubernetes{}
+	_ chaosmonkey.AppConfigGetter = Kubernetes{}
+)
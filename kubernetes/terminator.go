@@ -0,0 +1,69 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+)
+
+// deleteOptions is the request body for pod deletions
+type deleteOptions struct {
+	Kind               string `json:"kind"`
+	APIVersion         string `json:"apiVersion"`
+	GracePeriodSeconds int64  `json:"gracePeriodSeconds"`
+}
+
+// Execute implements chaosmonkey.Terminator.Execute
+// The pod is deleted with the configured grace period
+func (k Kubernetes) Execute(trm chaosmonkey.Termination) error {
+	ns, name, err := splitID(trm.Instance.ID())
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(deleteOptions{
+		Kind:               "DeleteOptions",
+		APIVersion:         "v1",
+		GracePeriodSeconds: int64(k.gracePeriod.Seconds()),
+	})
+	if err != nil {
+		return errors.Wrap(err, "json marshal failed")
+	}
+
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", url.PathEscape(ns), url.PathEscape(name))
+	_, err = k.do("DELETE", path, payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete pod %s", trm.Instance.ID())
+	}
+
+	return nil
+}
+
+// splitID splits an instance id into the namespace and name of the pod
+func splitID(id string) (ns, name string, err error) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid pod id %q, expected namespace/name", id)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,312 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics records Chaos Monkey activity as Prometheus metrics
+//
+// Metrics are kept in memory and rendered in the Prometheus text exposition
+// format. Since most Chaos Monkey invocations are short-lived processes
+// launched by cron, the metrics can be pushed to a Pushgateway or written to a
+// node_exporter textfile when the process finishes.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Stages of a terminate or schedule run that are counted
+const (
+	Picked   = "picked"   // an instance (terminate) or group (schedule) was selected
+	Disabled = "disabled" // skipped because Chaos Monkey, the account or the app is disabled
+	Outage   = "outage"   // blocked because of an ongoing outage
+	MinTime  = "min_time" // blocked by the min time between kills check
+	Holiday  = "holiday"  // not scheduled because it is not a work day
+	Executed = "executed" // the termination was carried out
+	Failed   = "failed"   // the run failed with an error
+)
+
+var (
+	// TerminateEvents counts each stage of the terminate command
+	TerminateEvents = NewCounterVec("chaosmonkey_terminate_events_total",
+		"Number of terminate runs that reached a stage.",
+		"stage", "app", "account", "region")
+
+	// ScheduleEvents counts each stage of the schedule command
+	ScheduleEvents = NewCounterVec("chaosmonkey_schedule_events_total",
+		"Number of instance groups that reached a stage of scheduling.",
+		"stage", "app", "account", "region")
+
+	// TerminateDuration observes how long it takes to terminate an instance
+	TerminateDuration = NewHistogramVec("chaosmonkey_terminate_duration_seconds",
+		"Time taken to execute a termination.",
+		DefaultBuckets, "app", "account", "region")
+
+	// ScheduleDuration observes how long it takes to schedule an app
+	ScheduleDuration = NewHistogramVec("chaosmonkey_schedule_duration_seconds",
+		"Time taken to compute the termination schedule of an app.",
+		DefaultBuckets, "app")
+
+	// Errors counts errors reported through the ErrorCounter
+	Errors = NewCounterVec("chaosmonkey_errors_total",
+		"Number of errors reported by Chaos Monkey.")
+)
+
+// DefaultBuckets are the default histogram buckets, in seconds
+var DefaultBuckets = []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// collector is a metric family that can render itself
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+var (
+	mu         sync.Mutex
+	collectors []collector
+)
+
+func register(c collector) {
+	mu.Lock()
+	defer mu.Unlock()
+	collectors = append(collectors, c)
+}
+
+// Write renders all metrics in the Prometheus text exposition format
+func Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	mu.Lock()
+	cs := collectors
+	mu.Unlock()
+
+	for _, c := range cs {
+		c.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+// Handler returns an http.Handler that serves the metrics
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = Write(w)
+	})
+}
+
+// vec holds the series of a metric family, keyed by label values
+type vec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string][]string // key -> label values
+}
+
+func newVec(name, help string, labels []string) vec {
+	return vec{name: name, help: help, labels: labels, series: make(map[string][]string)}
+}
+
+// key returns the map key for a set of label values, registering the series
+// if it has not been seen before
+// Must be called with v.mu held
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("%s: got %d label values, want %d", v.name, len(values), len(v.labels)))
+	}
+
+	k := strings.Join(values, "\xff")
+	if _, ok := v.series[k]; !ok {
+		v.series[k] = append([]string(nil), values...)
+	}
+	return k
+}
+
+// sortedKeys returns the series keys in a deterministic order
+// Must be called with v.mu held
+func (v *vec) sortedKeys() []string {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelString renders label values as {a="x",b="y"}, with extra appended
+func (v *vec) labelString(values []string, extra ...string) string {
+	var pairs []string
+	for i, name := range v.labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escape(values[i])))
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], escape(extra[i+1])))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (v *vec) writeHeader(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, kind)
+}
+
+// escape escapes a label value
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+// CounterVec is a family of counters partitioned by label values
+type CounterVec struct {
+	vec
+	values map[string]float64
+}
+
+// NewCounterVec creates and registers a family of counters
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec: newVec(name, help, labels), values: make(map[string]float64)}
+	register(c)
+	return c
+}
+
+// Inc increments the counter with the given label values by one
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta to the counter with the given label values
+func (c *CounterVec) Add(delta float64, values ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[c.key(values)] += delta
+}
+
+// Value returns the current value of the counter with the given label values
+func (c *CounterVec) Value(values ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[strings.Join(values, "\xff")]
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.series) == 0 {
+		return
+	}
+
+	c.writeHeader(w, "counter")
+	for _, k := range c.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelString(c.series[k]), formatFloat(c.values[k]))
+	}
+}
+
+// HistogramVec is a family of histograms partitioned by label values
+type HistogramVec struct {
+	vec
+	buckets []float64
+	counts  map[string][]uint64 // cumulative count per bucket
+	sums    map[string]float64
+	totals  map[string]uint64
+}
+
+// NewHistogramVec creates and registers a family of histograms with the given
+// bucket upper bounds, which must be sorted in increasing order
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		vec:     newVec(name, help, labels),
+		buckets: buckets,
+		counts:  make(map[string][]uint64),
+		sums:    make(map[string]float64),
+		totals:  make(map[string]uint64),
+	}
+	register(h)
+	return h
+}
+
+// Observe adds an observation to the histogram with the given label values
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	k := h.key(values)
+	counts, ok := h.counts[k]
+	if !ok {
+		counts = make([]uint64, len(h.buckets))
+		h.counts[k] = counts
+	}
+
+	for i, upper := range h.buckets {
+		if value <= upper {
+			counts[i]++
+		}
+	}
+	h.sums[k] += value
+	h.totals[k]++
+}
+
+// Count returns the number of observations of the histogram with the given
+// label values
+func (h *HistogramVec) Count(values ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.totals[strings.Join(values, "\xff")]
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.series) == 0 {
+		return
+	}
+
+	h.writeHeader(w, "histogram")
+	for _, k := range h.sortedKeys() {
+		values := h.series[k]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelString(values, "le", formatFloat(upper)), h.counts[k][i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelString(values, "le", "+Inf"), h.totals[k])
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelString(values), formatFloat(h.sums[k]))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelString(values), h.totals[k])
+	}
+}
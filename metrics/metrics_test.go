@@ -0,0 +1,129 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+func render(c collector) string {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	c.write(w)
+	_ = w.Flush()
+	return buf.String()
+}
+
+func TestCounterFormat(t *testing.T) {
+	c := &CounterVec{vec: newVec("test_total", "A test counter.", []string{"app", "region"}), values: make(map[string]float64)}
+	c.Inc("foo", "us-east-1")
+	c.Inc("foo", "us-east-1")
+	c.Add(0.5, "bar", `quo"te`)
+
+	want := `# HELP test_total A test counter.
+# TYPE test_total counter
+test_total{app="bar",region="quo\"te"} 0.5
+test_total{app="foo",region="us-east-1"} 2
+`
+	if got := render(c); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogramFormat(t *testing.T) {
+	h := &HistogramVec{
+		vec:     newVec("test_seconds", "A test histogram.", []string{"app"}),
+		buckets: []float64{1, 5},
+		counts:  make(map[string][]uint64),
+		sums:    make(map[string]float64),
+		totals:  make(map[string]uint64),
+	}
+	h.Observe(0.5, "foo")
+	h.Observe(2, "foo")
+	h.Observe(10, "foo")
+
+	want := `# HELP test_seconds A test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{app="foo",le="1"} 1
+test_seconds_bucket{app="foo",le="5"} 2
+test_seconds_bucket{app="foo",le="+Inf"} 3
+test_seconds_sum{app="foo"} 12.5
+test_seconds_count{app="foo"} 3
+`
+	if got := render(h); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	var method, path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		contents, _ := ioutil.ReadAll(r.Body)
+		body = string(contents)
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "cm-metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := config.Defaults()
+	cfg.Set(param.PrometheusPushgateway, srv.URL)
+	cfg.Set(param.PrometheusTextfileDir, dir)
+
+	SetGrouping("command", "terminate", "app", "foo")
+	defer SetGrouping()
+	TerminateEvents.Inc(Picked, "foo", "prod", "us-east-1")
+
+	if err := Publish(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := method, "PUT"; got != want {
+		t.Errorf("got method %s, want %s", got, want)
+	}
+
+	if got, want := path, "/metrics/job/chaosmonkey/command/terminate/app/foo"; got != want {
+		t.Errorf("got path %s, want %s", got, want)
+	}
+
+	series := `chaosmonkey_terminate_events_total{stage="picked",app="foo",account="prod",region="us-east-1"}`
+	if !strings.Contains(body, series) {
+		t.Errorf("pushed body does not contain %s:\n%s", series, body)
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "chaosmonkey_terminate_foo.prom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(contents), body; got != want {
+		t.Errorf("textfile contents differ from pushed body:\n%s", got)
+	}
+}
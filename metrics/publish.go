@@ -0,0 +1,149 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+)
+
+// grouping holds label name/value pairs that identify the metrics of this
+// process when they are published
+var grouping []string
+
+// SetGrouping sets the label name/value pairs that identify the metrics of
+// this process, e.g. SetGrouping("command", "terminate", "app", "foo").
+// Runs with different groupings do not overwrite each other's metrics
+func SetGrouping(pairs ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	grouping = append([]string(nil), pairs...)
+}
+
+func currentGrouping() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	return grouping
+}
+
+// Publish pushes the metrics to the Pushgateway and writes them to the
+// textfile directory, if either has been configured
+func Publish(cfg *config.Monkey) error {
+	var buf bytes.Buffer
+	if err := Write(&buf); err != nil {
+		return errors.Wrap(err, "failed to render metrics")
+	}
+
+	pairs := currentGrouping()
+
+	if gw := cfg.PrometheusPushgateway(); gw != "" {
+		client := &http.Client{Timeout: cfg.PrometheusPushTimeout()}
+		if err := push(client, gw, cfg.PrometheusJob(), pairs, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if dir := cfg.PrometheusTextfileDir(); dir != "" {
+		if err := writeTextfile(dir, cfg.PrometheusJob(), pairs, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// push PUTs the metrics to the Pushgateway at base, replacing any metrics
+// previously pushed with the same grouping key
+func push(client *http.Client, base, job string, pairs []string, body []byte) (err error) {
+	u := strings.TrimRight(base, "/") + "/metrics/job/" + url.PathEscape(job)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		u += "/" + url.PathEscape(pairs[i]) + "/" + url.PathEscape(pairs[i+1])
+	}
+
+	req, err := http.NewRequest("PUT", u, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create pushgateway request")
+	}
+	req.Header.Set("Content-Type", ContentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "push to %s failed", u)
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "failed to close response body")
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		contents, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("push to %s failed, code: %d, body: %s", u, resp.StatusCode, contents)
+	}
+
+	return nil
+}
+
+// writeTextfile atomically writes the metrics to a .prom file in dir, named
+// after the job and the grouping values
+func writeTextfile(dir, job string, pairs []string, body []byte) error {
+	name := job
+	for i := 1; i < len(pairs); i += 2 {
+		name += "_" + pairs[i]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, name) + ".prom"
+
+	// node_exporter only reads files ending in .prom, so writing to a temporary
+	// file and renaming ensures it never sees a partial file
+	tmp, err := ioutil.TempFile(dir, "."+name)
+	if err != nil {
+		return errors.Wrapf(err, "could not create temporary file in %s", dir)
+	}
+
+	_, err = tmp.Write(body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrapf(err, "could not write %s", tmp.Name())
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrapf(err, "could not write %s", path)
+	}
+
+	return nil
+}
@@ -1,6 +1,20 @@
 // Code generated by go-bindata.
 // sources:
 // migration/mysql/1.0.0_initial_schema.sql
+// migration/mysql/1.1.0_kill_switch.sql
+// migration/mysql/1.2.0_wider_instance_id.sql
+// migration/mysql/1.3.0_termination_outcome.sql
+// migration/mysql/1.4.0_schedule_seed.sql
+// migration/postgres/1.0.0_initial_schema.sql
+// migration/postgres/1.1.0_kill_switch.sql
+// migration/postgres/1.2.0_wider_instance_id.sql
+// migration/postgres/1.3.0_termination_outcome.sql
+// migration/postgres/1.4.0_schedule_seed.sql
+// migration/sqlite/1.0.0_initial_schema.sql
+// migration/sqlite/1.1.0_kill_switch.sql
+// migration/sqlite/1.2.0_wider_instance_id.sql
+// migration/sqlite/1.3.0_termination_outcome.sql
+// migration/sqlite/1.4.0_schedule_seed.sql
 // DO NOT EDIT!
 
 package migration
@@ -88,6 +102,286 @@ func migrationMysql100_initial_schemaSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrationMysql110_kill_switchSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x51\x4d\x53\x83\x30\x10\xbd\xe7\x57\xec\xad\x76\x84\x19\xeb\x78\xeb\x78\x48\x4b\xaa\x8c\x29\x54\x08\x8e\x3d\x75\x10\xd6\x36\x53\x1a\x18\x12\x87\xfa\xef\x0d\xd0\x0f\xf4\xe4\xbb\xed\x66\xdf\xdb\x7d\x79\xae\x0b\xb7\x07\xb9\xad\x53\x83\x90\x54\xc4\x75\x21\x7e\xe5\x20\x15\x68\xcc\x8c\x2c\x15\x8c\x92\x6a\x04\x52\x03\x1e\x31\xfb\x32\x98\x43\xb3\x43\x05\x66\x67\x5b\x3d\xaf\x1d\xb2\x45\x5a\x55\x85\xc4\x9c\xcc\x23\x46\x05\x03\x41\x67\x9c\x81\xbf\x80\x20\x14\xc0\xde\xfd\x58\xc4\xb0\x97\x45\xb1\xd1\x8d\x34\xd9\x0e\x6e\x08\x58\xc8\x1c\xae\xf0\x03\xd1\x4d\x07\x09\xe7\xb0\x8a\xfc\x25\x8d\xd6\xf0\xc2\xd6\x0e\xd8\xab\xd2\xa2\x49\xbf\x35\x4c\x1c\xbb\x1a\x6b\xec\x36\x82\x96\x6a\x5b\x60\xa7\x0b\xbd\x6e\xa7\xaa\x4d\x59\x55\x78\x92\x9e\x85\x21\x67\x34\xb8\x2a\x7b\x6c\x41\x13\x2e\x60\x41\x79\xcc\x9c\x8e\x50\x63\xaa\xad\x8b\x1e\x6f\x34\x9a\x3f\xd3\xe8\x66\x72\x77\xff\x30\xbe\xd0\x9c\xa1\xf2\x26\x35\xb6\xf0\xac\x4f\xe1\x2f\xd9\x75\xe6\xec\xc4\xde\x6b\xe4\x01\xdb\x6f\x4c\xc4\xbc\x63\xe2\xb1\x92\x35\xea\xbf\xcc\xf6\xa0\x21\x7e\x33\x9d\x7e\x40\x7e\xb6\xa6\x87\x36\x21\x2f\x51\x83\x2a\xcd\x49\xb7\x5b\x31\x26\x2c\x78\xf2\x03\xf6\xe8\x2b\x55\x7a\xb3\x29\x21\x6d\x9c\x97\x74\xbd\xb2\x51\xe7\x7c\x2f\xe1\xb6\xcd\x7f\xc5\x5b\x97\x45\x61\x5f\x3f\xd2\x6c\x4f\xbc\x28\x5c\x9d\x02\x1e\x44\x3a\x25\x3f\x21\xf0\x12\x58\x4c\x02\x00\x00")
+
+func migrationMysql110_kill_switchSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationMysql110_kill_switchSql,
+		"migration/mysql/1.1.0_kill_switch.sql",
+	)
+}
+
+func migrationMysql110_kill_switchSql() (*asset, error) {
+	bytes, err := migrationMysql110_kill_switchSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/mysql/1.1.0_kill_switch.sql", size: 588, mode: os.FileMode(420), modTime: time.Unix(1792320236, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationMysql120_wider_instance_idSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x9d\x8f\x41\x4b\x03\x31\x10\x85\xef\xfb\x2b\xde\xad\x8a\x2e\xa2\x54\x28\x78\x5a\x6d\x45\x71\x6d\x71\xdd\x15\x3c\x49\x9a\x4c\xdd\xc1\xdd\x24\x24\x53\xaa\xff\xde\x44\xb1\x78\x14\x6f\x33\x6f\xe6\xcd\xbc\xaf\x2c\x71\x34\xf2\x6b\x50\x42\xe8\x7c\x51\x96\x78\x7c\xa8\xc1\x16\x91\xb4\xb0\xb3\x98\x74\x7e\x02\x8e\xa0\x77\xd2\x5b\x21\x83\x5d\x4f\x16\xd2\x27\xe9\xdb\x97\x97\x52\xa3\xbc\x1f\x98\x4c\xbe\x70\xb7\x5d\x53\xb0\x24\x14\xe1\x9d\x49\xa3\x40\x60\x43\x56\x78\x93\x36\xb0\xfe\x80\x55\x23\x45\xaf\x34\x9d\xe4\xea\x38\xdd\x64\xdd\xc3\xb8\xe4\xb0\x4e\xb0\x61\xc9\x11\xa6\x33\xe8\x5e\x05\xa5\x85\x42\x2c\xaa\xba\x5d\x34\x68\xab\xcb\x7a\x81\x24\x8c\x6c\xbf\x7e\x47\xdc\xaf\xe6\xb7\xd7\xcf\xc9\x10\x45\x59\x4d\x2f\x6c\xf0\x54\x35\x57\x37\x55\x73\x70\x7e\x7a\x76\x88\xe5\xaa\xc5\xb2\xab\xeb\x8b\xa2\xc8\xe9\xf6\xb8\x73\xb7\xb3\x3f\xc0\x7b\xda\x2c\xfe\x89\x37\xb8\x61\xc8\x30\x4a\xbf\xfd\x2b\xda\x74\xf6\x3b\xd9\x27\x49\x6d\xee\xe7\x87\x01\x00\x00")
+
+func migrationMysql120_wider_instance_idSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationMysql120_wider_instance_idSql,
+		"migration/mysql/1.2.0_wider_instance_id.sql",
+	)
+}
+
+func migrationMysql120_wider_instance_idSql() (*asset, error) {
+	bytes, err := migrationMysql120_wider_instance_idSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/mysql/1.2.0_wider_instance_id.sql", size: 391, mode: os.FileMode(420), modTime: time.Unix(1792320850, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationMysql130_termination_outcomeSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x51\x3d\x6f\x83\x30\x14\xdc\xf9\x15\x27\x16\x82\x0a\x51\x55\x29\x53\x26\x1a\x52\x75\x70\x93\x96\x40\xd6\xca\x81\x97\x60\x85\x18\x84\x8d\xd2\x9f\x5f\x9b\x04\xfa\xa9\xaa\x9e\x9e\x4f\x77\xf7\x7c\xbe\x30\xc4\xcd\x49\x1c\x5a\xae\x09\x59\xe3\x84\x21\x36\x2f\x0c\x42\x42\x51\xae\x45\x2d\xe1\x65\x8d\x07\xa1\x40\x6f\x94\x77\x9a\x0a\x9c\x4b\x92\xd0\xa5\x81\x2e\x3a\x4b\x32\x17\xde\x34\x95\xa0\xc2\x3a\x28\xcd\x75\xa7\x2c\xe8\xaa\x2e\xcf\x89\x0a\x2a\x5c\xd4\x2d\xdc\x3d\x17\x55\x3f\xcb\x9c\xc0\x25\x3a\x59\x11\x57\xa5\x71\xd5\xd4\x9e\x84\xbc\xb8\x95\x5c\x61\x47\x66\xcb\xb0\x33\xb0\xae\x5c\x1a\x16\x57\xc7\x57\x51\x58\x6b\x5d\x12\xcc\x54\xef\xfb\x69\xc7\xf3\x23\x5d\x09\x98\xd0\xf4\x30\xc5\xa6\x11\x52\xf2\x23\xb5\xbe\x61\x70\xfd\x11\x40\x68\x27\x62\xe9\x32\x41\x1a\xdd\xb3\xe5\xe7\xcd\xca\x81\x39\x51\x1c\x63\xb1\x66\xd9\xd3\x6a\x48\x82\x6d\x94\x2c\x1e\xa3\x64\x72\x77\xeb\x03\xab\x75\x8a\x55\xc6\x18\xe2\xe5\x43\x94\xb1\x14\x9e\x17\x7c\x17\x0e\x0f\x1d\x85\xb3\x99\xff\x9b\x70\xee\x38\x36\xdb\x58\x41\x5c\x9f\xe5\x50\xc2\xd8\x80\x05\xff\xd5\x41\x5b\x57\xe6\x7b\xfb\xcf\xf8\x3b\x62\x9c\xac\x9f\xbf\x66\x0c\x7e\xe0\xd7\x08\x73\xe7\x1d\xed\xd5\xaa\x7e\x23\x02\x00\x00")
+
+func migrationMysql130_termination_outcomeSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationMysql130_termination_outcomeSql,
+		"migration/mysql/1.3.0_termination_outcome.sql",
+	)
+}
+
+func migrationMysql130_termination_outcomeSql() (*asset, error) {
+	bytes, err := migrationMysql130_termination_outcomeSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/mysql/1.3.0_termination_outcome.sql", size: 547, mode: os.FileMode(420), modTime: time.Unix(1792321930, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationMysql140_schedule_seedSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x50\xcb\x4e\xc3\x30\x10\xbc\xe7\x2b\xe6\xd6\x03\x84\x1f\xe8\x29\x25\x15\x8a\x64\xd2\x07\xc9\x07\xa4\xf6\xb6\xb6\x08\x71\x64\x3b\x18\xfe\x9e\x75\x22\x02\x27\xc4\x9e\x76\x67\x77\x67\x46\x93\xe7\xb8\x7b\x33\x37\xd7\x05\x42\x3b\x66\x79\x8e\x97\x93\x80\x19\xe0\x49\x06\x63\x07\x6c\xda\x71\x03\xe3\x41\x1f\x24\xa7\x40\x0a\x51\xd3\x80\xa0\x19\x5a\xfe\xd2\x11\x0f\xdd\x38\xf6\x86\x54\x62\xf0\xc4\x67\x0c\x05\x4d\x4b\x3f\x37\x52\x93\x9a\x7a\x42\xec\x3c\x6e\x34\x50\x92\x64\x36\x13\xf4\x3d\x98\x82\xde\xc9\x7d\xc2\xd9\x08\x7b\x9d\x1f\x14\xef\x1f\x12\x5d\x15\x12\x59\xdd\x0a\x81\xab\x75\x2b\x91\xc7\x38\x5d\x7a\xe3\x79\xc2\x85\x78\xb3\x88\x79\x44\xe2\xd6\x91\xb4\x4e\xb1\x9f\x42\x34\xfb\x33\x9a\x62\x27\xf6\x3f\xaf\x19\xb8\x8a\xb2\xc4\xe3\x41\xb4\xcf\xf5\xe2\x72\x57\x3d\x55\x75\x33\x0b\x6d\xb3\x2c\x29\xaf\xc9\x94\x36\x0e\xdf\xd9\xac\xc1\x24\xf0\x5f\xd1\x38\xdb\xf7\xc9\x64\x27\x5f\xff\xb0\x53\x9e\x0f\xc7\xdf\x7e\xb6\xd9\x17\xf6\x83\xce\x64\x9c\x01\x00\x00")
+
+func migrationMysql140_schedule_seedSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationMysql140_schedule_seedSql,
+		"migration/mysql/1.4.0_schedule_seed.sql",
+	)
+}
+
+func migrationMysql140_schedule_seedSql() (*asset, error) {
+	bytes, err := migrationMysql140_schedule_seedSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/mysql/1.4.0_schedule_seed.sql", size: 412, mode: os.FileMode(420), modTime: time.Unix(1792324342, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationPostgres100_initial_schemaSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xad\x94\x5f\x6f\xda\x30\x14\xc5\xdf\xf9\x14\xf7\xad\x45\x83\xa9\xad\xd6\x6d\x12\x4f\x29\x18\x2d\x5a\x08\x2c\x09\x53\xbb\x97\xc8\x24\x17\xb0\x48\x1c\x14\x9b\xb6\xf4\xd3\xef\x3a\x69\xfe\x00\xab\x46\xb5\xf9\xcd\xce\xf1\xcf\xc7\xf7\x9e\xb8\xdf\x87\x0f\xa9\x58\xe5\x5c\x23\xcc\xb7\x9d\x7e\x1f\xfc\x1f\x0e\x08\x09\x0a\x23\x2d\x32\x09\x17\xf3\xed\x05\x08\x05\xf8\x8c\xd1\x4e\x63\x0c\x4f\x6b\x94\xa0\xd7\xb4\x54\xee\x33\x22\x9a\xf0\xed\x36\x11\x18\x1b\xc2\xa4\x5a\x57\xb0\xe6\x8f\x48\x62\x04\xc5\x53\x04\x11\x93\x4e\x15\xf3\xc9\xde\x9c\x53\x13\x54\x0f\x54\x46\x1f\xb8\x06\x0e\x2a\x5a\x63\xca\x0d\xe9\x11\x73\x65\xf8\x29\x72\xa9\x1a\x4e\x29\x30\x2e\x91\x14\x7b\x88\xb9\xe6\x0b\xae\xf0\x63\x67\xe8\x31\x2b\x60\x10\x58\x77\x0e\x03\x7b\x0c\xee\x34\x00\x76\x6f\xfb\x81\x5f\x6c\x8a\x77\x09\x2a\xb8\xec\x00\x0d\x11\x43\x33\x7c\xe6\xd9\x96\x03\x33\xcf\x9e\x58\xde\x03\x7c\x67\x0f\xbd\x42\x14\x9b\xba\x54\x63\x64\xd0\x86\xe8\xce\x1d\xa7\x57\x2f\x93\xcf\x42\x96\x2d\x41\x63\x9e\x0a\x59\xd6\xa4\x3a\xaf\x67\x7c\x26\x59\xc4\x13\xd0\x82\xcc\xbf\x64\x12\x0b\x76\x31\xab\x46\x60\x4f\x98\x1f\x58\x93\x59\xf0\xab\x7d\x04\xb1\x17\x18\xf1\x9d\x2a\xf1\x66\x47\x2c\x96\x4b\xcc\x51\x46\x44\x4e\xf9\xfe\x75\x0e\xcb\x3c\x4b\x0b\x1f\x05\x9b\xba\xd1\x5c\xee\xa7\xe5\x0d\xbf\x59\xde\xe5\xed\xf5\x4d\xb7\x81\x97\xba\x28\xca\x76\x52\x1f\xea\xae\xaf\xae\x8e\x75\x39\xae\xcc\x9d\x8e\x78\x24\x6b\x99\x25\xaf\xc6\xe7\x22\xe1\x72\x03\x4a\xe7\x42\xae\x40\x67\x74\xfb\x58\x44\xa6\x3e\x32\xd3\xb0\xcd\x51\xa1\xd4\x05\x53\x69\x1e\x6d\x8e\x3d\xde\xdc\xde\x76\xff\x81\x19\x25\x3b\x45\x4d\x38\x64\x7e\xf9\xfc\xb5\x61\xc2\xbb\x99\xdd\x41\xa7\xca\x95\xed\x8e\xd8\xfd\x5b\xb9\x0a\x4d\xf5\x43\xc2\xe0\x33\x4c\xdd\x76\xde\xcc\x87\x16\xe5\x4f\xe9\x6c\x25\xe7\x5d\x01\xfd\xdf\x8d\x3e\xa3\x29\x67\x16\xfa\x2f\xc1\x39\xb2\xa7\x56\xa7\xd7\x20\x7b\x27\x42\x21\xc9\x21\x65\x3f\x34\xd5\xa9\x84\x9f\x4e\x8e\xdd\x88\x24\xc1\x38\xe4\xfa\xad\x7f\x0b\x46\x6c\x6c\xcd\x9d\x00\x86\x73\xcf\x63\x6e\x10\xd6\xa2\x12\x90\x20\x57\xd4\xbf\xd2\xd0\xdd\x74\xea\x30\xcb\x3d\xdd\x3c\xb6\x1c\x9f\x9d\x13\x92\x76\x7b\x43\xea\x59\x58\x1b\x6c\x02\x73\x18\x01\x12\xf5\x9a\x6b\x18\xbc\x79\x10\xeb\xb7\x7a\x94\x3d\xc9\xea\xb5\xae\x9f\x6a\xb3\x78\xd6\x63\x9d\x67\x86\x0b\x0b\xea\x75\x67\xe4\x4d\x67\xaf\x91\xac\x23\x3b\x68\xaf\xb6\x7d\x0d\x3a\xbf\x01\x91\x07\x90\x39\x31\x06\x00\x00")
+
+func migrationPostgres100_initial_schemaSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationPostgres100_initial_schemaSql,
+		"migration/postgres/1.0.0_initial_schema.sql",
+	)
+}
+
+func migrationPostgres100_initial_schemaSql() (*asset, error) {
+	bytes, err := migrationPostgres100_initial_schemaSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/postgres/1.0.0_initial_schema.sql", size: 1585, mode: os.FileMode(420), modTime: time.Unix(1792326136, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationPostgres110_kill_switchSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x91\x31\x4f\xc3\x30\x10\x85\x77\xff\x8a\xdb\xda\x8a\x44\xa2\x88\xad\x93\xdb\xba\x22\xc2\x4d\x4a\xe2\x20\xca\x52\x85\xe4\x68\xad\x9a\x24\x8a\x8d\x52\xfe\x3d\x8e\x1b\xd2\x0a\x31\x70\x9b\xcf\xef\xbe\xbb\xa7\xe7\xfb\x70\xf3\x21\xf7\x4d\x66\x10\xd2\x9a\xf8\x3e\x24\x4f\x1c\x64\x09\x1a\x73\x23\xab\x12\x46\x69\x3d\x02\xa9\x01\x4f\x98\x7f\x1a\x2c\xa0\x3d\x60\x09\xe6\x60\x5b\xe7\xb9\x4e\x64\x1f\x59\x5d\x2b\x89\x05\x59\xc4\x8c\x0a\x06\x82\xce\x39\x83\x60\x05\x61\x24\x80\xbd\x04\x89\x48\xe0\x28\x95\xda\xe9\x56\x9a\xfc\x00\x63\x02\xb6\x64\x01\x97\x0a\x42\xe1\xd4\x61\xca\x39\x6c\xe2\x60\x4d\xe3\x2d\x3c\xb2\xad\x07\xf6\xaa\x4c\xb5\xd9\x97\x86\xa9\x67\x57\x63\x83\x6e\x23\x68\x59\xee\x15\x3a\x2e\x9c\xb9\x8e\xaa\x4d\x55\xd7\xd8\xa3\xe7\x51\xc4\x19\x0d\x2f\xe4\x25\x5b\xd1\x94\x0b\x58\x51\x9e\x30\xcf\x0d\x34\x98\x69\xeb\xe2\x5c\xcf\x34\x5e\x3c\xd0\x78\x3c\xbd\xbd\xbb\x9f\x0c\x63\xde\x35\x79\x97\x19\xfb\x10\xc1\x9a\x25\x82\xae\x37\xe2\xf5\x97\x0c\x4f\xb5\x6c\x50\xff\x21\xeb\x0e\x18\xca\xda\x72\x0d\xf9\xde\x99\xba\xb6\x01\x45\x85\x1a\xca\xca\xf4\x28\x47\x9d\xcc\x08\xe9\x02\x1a\xf2\x5a\x56\x6d\xf9\x93\xd8\x10\x57\xd7\xfc\x57\x60\x4d\xa5\x94\xfd\x7d\xcb\xf2\x23\x59\xc6\xd1\xa6\x8f\xec\x2a\xa4\x19\xf9\x06\x7a\x5b\xa0\xe4\x1e\x02\x00\x00")
+
+func migrationPostgres110_kill_switchSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationPostgres110_kill_switchSql,
+		"migration/postgres/1.1.0_kill_switch.sql",
+	)
+}
+
+func migrationPostgres110_kill_switchSql() (*asset, error) {
+	bytes, err := migrationPostgres110_kill_switchSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/postgres/1.1.0_kill_switch.sql", size: 542, mode: os.FileMode(420), modTime: time.Unix(1792326136, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationPostgres120_wider_instance_idSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xa5\x8f\x4f\x4b\xc3\x40\x10\xc5\xef\xf9\x14\xef\x56\x45\x83\x28\x15\x0a\x9e\x62\x0d\x08\xc6\x7f\x31\x11\x3c\xc9\x76\x77\x6a\x06\x93\xdd\x65\x77\x4a\xf5\xdb\xbb\x6b\xb1\xe7\x82\xb7\x99\x37\xf3\x66\xde\xaf\x2c\x71\x32\xf1\x47\x50\x42\xe8\x7d\x51\x96\x78\x79\x6e\xc0\x16\x91\xb4\xb0\xb3\x98\xf5\x7e\x06\x8e\xa0\x2f\xd2\x1b\x21\x83\xed\x40\x16\x32\x24\x69\xe7\xcb\x4b\xa9\x51\xde\x8f\x4c\x26\x5f\xb8\xdb\xac\x28\x58\x12\x8a\xf0\xce\xa4\x51\x20\xb0\x21\x2b\xbc\x4e\x1b\x58\x7d\xc3\xaa\x89\xa2\x57\x9a\xce\x72\x75\x9a\x6e\xb2\x1e\x60\x5c\x72\x58\x27\x58\xb3\xe4\x08\xf3\x05\xf4\xa0\x82\xd2\x42\x21\x16\x55\xd3\xd5\x2d\xba\xea\xba\xa9\x91\x84\x89\xed\xef\xef\x88\xdd\x60\xf9\xd8\xf4\xf7\x0f\xc9\x16\x45\x59\x4d\xef\x6c\xd0\xbd\x3d\xd5\x78\xad\xda\xe5\x6d\xd5\x1e\x5d\x9e\x5f\x1c\x5f\x15\x45\xce\xb7\x07\xbe\x71\x5b\xfb\x87\xbc\xe7\xcd\xe2\x41\xc4\xc1\x8d\x63\xc6\x51\xfa\xf3\xbf\xe1\xe6\x8b\x94\xed\x07\xa6\xd4\x92\x23\x8b\x01\x00\x00")
+
+func migrationPostgres120_wider_instance_idSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationPostgres120_wider_instance_idSql,
+		"migration/postgres/1.2.0_wider_instance_id.sql",
+	)
+}
+
+func migrationPostgres120_wider_instance_idSql() (*asset, error) {
+	bytes, err := migrationPostgres120_wider_instance_idSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/postgres/1.2.0_wider_instance_id.sql", size: 395, mode: os.FileMode(420), modTime: time.Unix(1792326136, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationPostgres130_termination_outcomeSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x51\x3d\x6f\x83\x30\x14\xdc\xf9\x15\x27\x16\x82\x0a\x51\x55\x29\x53\x26\x1a\x52\x75\x70\x93\x96\x40\xd6\xca\x81\x97\x60\x85\x18\x84\x8d\xd2\x9f\x5f\x9b\x04\xfa\xa9\xaa\x9e\x9e\x4f\x77\xf7\x7c\xbe\x30\xc4\xcd\x49\x1c\x5a\xae\x09\x59\xe3\x84\x21\x36\x2f\x0c\x42\x42\x51\xae\x45\x2d\xe1\x65\x8d\x07\xa1\x40\x6f\x94\x77\x9a\x0a\x9c\x4b\x92\xd0\xa5\x81\x2e\x3a\x4b\x32\x17\xde\x34\x95\xa0\xc2\x3a\x28\xcd\x75\xa7\x2c\xe8\xaa\x2e\xcf\x89\x0a\x2a\x5c\xd4\x2d\xdc\x3d\x17\x55\x3f\xcb\x9c\xc0\x25\x3a\x59\x11\x57\xa5\x71\xd5\xd4\x9e\x84\xbc\xb8\x95\x5c\x61\x47\x66\xcb\xb0\x33\xb0\xae\x5c\x1a\x16\x57\xc7\x57\x51\x58\x6b\x5d\x12\xcc\x54\xef\xfb\x69\xc7\xf3\x23\x5d\x09\x98\xd0\xf4\x30\xc5\xa6\x11\x52\xf2\x23\xb5\xbe\x61\x70\xfd\x11\x40\x68\x27\x62\xe9\x32\x41\x1a\xdd\xb3\xe5\xe7\xcd\xca\x81\x39\x51\x1c\x63\xb1\x66\xd9\xd3\x6a\x48\x82\x6d\x94\x2c\x1e\xa3\x64\x72\x77\xeb\x03\xab\x75\x8a\x55\xc6\x18\xe2\xe5\x43\x94\xb1\x14\x9e\x17\x7c\x17\x0e\x0f\x1d\x85\xb3\x99\xff\x9b\x70\xee\x38\x36\xdb\x58\x41\x5c\x9f\xe5\x50\xc2\xd8\x80\x05\xff\xd5\x41\x5b\x57\xe6\x7b\xfb\xcf\xf8\x3b\x62\x9c\xac\x9f\xbf\x66\x0c\x7e\xe0\xd7\x08\x73\xe7\x1d\xed\xd5\xaa\x7e\x23\x02\x00\x00")
+
+func migrationPostgres130_termination_outcomeSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationPostgres130_termination_outcomeSql,
+		"migration/postgres/1.3.0_termination_outcome.sql",
+	)
+}
+
+func migrationPostgres130_termination_outcomeSql() (*asset, error) {
+	bytes, err := migrationPostgres130_termination_outcomeSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/postgres/1.3.0_termination_outcome.sql", size: 547, mode: os.FileMode(420), modTime: time.Unix(1792326136, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationPostgres140_schedule_seedSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x50\xcb\x4e\xc3\x30\x10\xbc\xe7\x2b\xe6\xd6\x03\x84\x1f\xe8\x29\x25\x15\x8a\x64\xd2\x07\xc9\x07\xa4\xf6\xb6\xb6\x08\x71\x64\x3b\x18\xfe\x9e\x75\x22\x02\x27\xc4\x9e\x76\x67\x77\x67\x46\x93\xe7\xb8\x7b\x33\x37\xd7\x05\x42\x3b\x66\x79\x8e\x97\x93\x80\x19\xe0\x49\x06\x63\x07\x6c\xda\x71\x03\xe3\x41\x1f\x24\xa7\x40\x0a\x51\xd3\x80\xa0\x19\x5a\xfe\xd2\x11\x0f\xdd\x38\xf6\x86\x54\x62\xf0\xc4\x67\x0c\x05\x4d\x4b\x3f\x37\x52\x93\x9a\x7a\x42\xec\x3c\x6e\x34\x50\x92\x64\x36\x13\xf4\x3d\x98\x82\xde\xc9\x7d\xc2\xd9\x08\x7b\x9d\x1f\x14\xef\x1f\x12\x5d\x15\x12\x59\xdd\x0a\x81\xab\x75\x2b\x91\xc7\x38\x5d\x7a\xe3\x79\xc2\x85\x78\xb3\x88\x79\x44\xe2\xd6\x91\xb4\x4e\xb1\x9f\x42\x34\xfb\x33\x9a\x62\x27\xf6\x3f\xaf\x19\xb8\x8a\xb2\xc4\xe3\x41\xb4\xcf\xf5\xe2\x72\x57\x3d\x55\x75\x33\x0b\x6d\xb3\x2c\x29\xaf\xc9\x94\x36\x0e\xdf\xd9\xac\xc1\x24\xf0\x5f\xd1\x38\xdb\xf7\xc9\x64\x27\x5f\xff\xb0\x53\x9e\x0f\xc7\xdf\x7e\xb6\xd9\x17\xf6\x83\xce\x64\x9c\x01\x00\x00")
+
+func migrationPostgres140_schedule_seedSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationPostgres140_schedule_seedSql,
+		"migration/postgres/1.4.0_schedule_seed.sql",
+	)
+}
+
+func migrationPostgres140_schedule_seedSql() (*asset, error) {
+	bytes, err := migrationPostgres140_schedule_seedSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/postgres/1.4.0_schedule_seed.sql", size: 412, mode: os.FileMode(420), modTime: time.Unix(1792324342, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationSqlite100_initial_schemaSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xad\x55\xd1\x6e\xda\x30\x14\x7d\xe7\x2b\xae\xfa\xd2\x56\x03\x14\x50\xe9\xa6\xf6\x29\x2d\xee\x16\x2d\x84\x2e\x84\xa9\x7d\x42\x6e\x62\xc0\x6a\x62\x23\xdb\xb4\xa5\x5f\xbf\x6b\xa7\x21\x29\x6c\x1a\x53\xe7\x17\x94\x9b\xe3\x73\x8e\xef\x3d\xc1\x9d\x0e\x7c\x2a\xf8\x42\x51\xc3\x60\xba\x6a\x75\x3a\x30\xf9\x11\x02\x17\xa0\x59\x6a\xb8\x14\x70\x3c\x5d\x1d\x03\xd7\xc0\x5e\x58\xba\x36\x2c\x83\xe7\x25\x13\x60\x96\x58\x2a\xf7\x59\x10\x3e\xd0\xd5\x2a\xe7\x2c\xb3\x0c\xa3\xaa\xae\x61\x49\x9f\x18\x82\x19\x68\x5a\x30\xe0\x19\xe2\xb4\x7b\x1e\x6d\xac\xce\x96\x41\xb7\x41\x4b\x7c\x41\x0d\x50\xd0\xe9\x92\x15\xd4\x32\x3d\x31\xa5\x2d\x7f\xc1\xa8\xd0\x35\x4f\x09\xb0\x2e\x19\x22\x36\x90\x51\x43\x1f\xa8\x66\x5d\xbb\x27\xe1\x05\x43\x19\x85\x30\x23\x15\x1a\xb6\x8a\xec\xc5\x58\xf8\x34\xb9\x6e\xdb\x5f\xcb\x34\x97\xaa\x40\xb9\xa3\xbe\xe7\x9d\x77\xbc\x5e\xc7\xeb\x43\x6f\x70\xe1\x9d\x5d\x78\x83\xae\xe7\xd6\x51\xdb\xf2\x55\xbe\x70\xcf\x06\x52\x59\xac\x2c\x35\x72\xa4\x4b\x25\x85\xcc\xe5\x82\xa7\x34\x07\xa9\x32\xa6\x5a\xd7\x31\xf1\x13\x02\x89\x7f\x15\x12\x08\x6e\x20\x1a\x27\x40\xee\x82\x49\x32\x71\x9e\xb3\x75\x8e\xd6\x4e\x5a\x80\x8b\x67\x50\xaf\x20\x4a\xc8\x57\x12\xc3\x6d\x1c\x8c\xfc\xf8\x1e\xbe\x93\x7b\xf0\xa7\xc9\x38\x88\x90\x71\x44\xa2\xa4\xed\xf6\x64\x76\x4a\xd5\x4a\xc8\x5d\xe2\x04\xa2\x69\x18\xb6\xb7\x65\x74\xec\x60\x72\x8e\xa7\x56\x05\x17\xe5\x84\x2a\x79\x77\xfc\x5c\x5a\xcb\x06\x1b\x05\xaf\x52\x60\x0d\x5b\x54\xb7\xc1\x49\xb9\x97\xd5\x1a\xe2\xa1\x92\x60\x44\x76\xe4\x50\xca\xc1\xca\xc6\x76\xe1\x8a\xa5\x74\xad\x4b\x69\x5b\xcf\xf8\x7c\xce\x14\x13\x29\x2a\x14\x74\xf3\xf6\x0c\x73\x25\x0b\xe7\xd1\x09\x61\x6e\xea\x3e\xfc\xf4\xe3\xeb\x6f\x7e\x7c\x32\xe8\xf5\x4f\x6b\xb1\x12\x97\xa6\x72\x2d\xcc\x7b\x5c\xcf\xf3\x76\x71\x8a\x2d\xec\x79\x77\xf8\x10\xd6\x30\x8f\xc6\xad\xcf\x87\x9c\x8a\x47\x4c\x89\xe2\x62\x01\x46\xe2\x39\x32\x9c\x25\xf6\x4e\x48\x03\x2b\xc5\x34\x13\xc6\x71\x6a\x43\xd3\xc7\x5d\x8f\xfd\xc1\xe0\xf4\x03\x9c\x69\xbe\xd6\x38\xa0\xf7\x9c\x9f\xcf\xbf\xd4\x9c\xf0\xcf\x9c\xa7\x97\xad\x2a\x82\x41\x34\x24\x77\x7f\x8a\xe0\xcc\x76\x7f\x86\x34\xec\x05\xc6\x51\x33\x9a\xf6\x45\x83\xe5\x77\x41\x6e\xa4\xea\x23\x59\xfe\xdf\x73\x3f\x60\x46\x07\xf6\xfd\x2f\x39\xda\xb1\xa7\x17\xfb\xc7\x40\x7b\x7b\x40\x2e\xd0\x21\x7e\x0a\x33\xdb\xac\x0a\x78\xb6\x27\xfb\xc8\xf3\x9c\x65\x33\x6a\x0e\xfe\xee\xdc\xb6\x9c\x51\x8d\x43\x2c\x6d\x5c\x8d\xc7\x21\xf1\xa3\x3a\x48\x43\x72\xe3\x4f\xc3\x04\x6e\xfc\x70\x42\x0e\x49\x4a\x73\xc6\x33\x9c\xd4\x6c\x6b\xab\x4e\xcd\xfb\x1c\x20\xa8\x5d\x9b\xb7\xf4\xf6\xbf\x73\x7b\xb5\x0c\xe5\xb3\xa8\x2e\x97\xed\xcd\x62\x8b\x07\xdd\x2d\x4a\x5a\x5e\x78\xc0\x09\xb7\x86\xf1\xf8\xf6\x2d\x97\xdb\xdc\x5e\x36\xab\x4d\x5f\x97\xad\x5f\xbf\x58\x8d\x74\xe0\x06\x00\x00")
+
+func migrationSqlite100_initial_schemaSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationSqlite100_initial_schemaSql,
+		"migration/sqlite/1.0.0_initial_schema.sql",
+	)
+}
+
+func migrationSqlite100_initial_schemaSql() (*asset, error) {
+	bytes, err := migrationSqlite100_initial_schemaSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/sqlite/1.0.0_initial_schema.sql", size: 1760, mode: os.FileMode(420), modTime: time.Unix(1792326075, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationSqlite110_kill_switchSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x51\xc1\x72\x82\x30\x10\xbd\xf3\x15\x7b\x53\xa7\x30\x53\x3b\xbd\x79\x8a\x12\x5b\xa6\x11\x2c\x84\x4e\x3d\x39\x14\xb6\x9a\x11\x21\x43\xd2\xc1\xfe\x7d\x43\xb0\x4a\x3d\xf5\xdd\x76\xb3\xef\xed\xbe\x3c\xcf\x83\xbb\xa3\xd8\x35\x99\x46\x48\xa5\xe3\x79\x90\xbc\x32\x10\x15\x28\xcc\xb5\xa8\x2b\x18\xa5\x72\x04\x42\x01\x9e\x30\xff\xd2\x58\x40\xbb\xc7\x0a\xf4\xde\xb4\x7a\x5e\x37\x64\x8a\x4c\xca\x52\x60\xe1\x2c\x62\x4a\x38\x05\x4e\xe6\x8c\x42\xb0\x84\x30\xe2\x40\xdf\x83\x84\x27\x70\x10\x65\xb9\x55\xad\xd0\xf9\x1e\xc6\x0e\x18\x88\x02\xae\x08\x42\x4e\x9f\x68\x6c\x19\x61\xca\x18\xac\xe3\x60\x45\xe2\x0d\xbc\xd0\x8d\x0b\xe6\xb2\xac\x6c\xb3\x6f\x05\x53\xd7\xac\xc7\x06\xed\x56\x50\xa2\xda\x95\x68\xb5\xa1\xd7\xb6\xca\x4a\xd7\x52\xe2\x59\x7e\x1e\x45\x8c\x92\xf0\xaa\xec\xd3\x25\x49\x19\x87\x25\x61\x09\x75\x2d\xa1\xc1\x4c\x19\x27\x3d\xde\x48\xbc\x78\x26\xf1\x78\x7a\xff\xf0\x38\xb9\xd0\xdc\xa1\xf2\x36\xd3\xa6\xf0\x8d\x57\x1e\xac\xe8\x75\x66\x60\xa8\xbb\x59\x8b\x23\x76\xdf\x99\xf2\x85\x65\xe3\x49\x8a\x06\xd5\x2d\xbb\x3b\xea\x16\x7f\xd9\x6e\x3f\x24\x3e\x3b\xf3\x43\xbb\x50\xd4\xa8\xa0\xaa\xf5\x59\xdb\xae\x99\xcc\x1c\xa7\x0b\xf3\x92\xad\x5f\xb7\xd5\x6f\xba\x97\x68\xbb\xe6\xbf\xc2\x6d\xea\xb2\x34\xaf\x1f\x59\x7e\x70\xfc\x38\x5a\x9f\xe3\x1d\x04\x3a\x73\x7e\x00\xb0\x16\xf1\x40\x4a\x02\x00\x00")
+
+func migrationSqlite110_kill_switchSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationSqlite110_kill_switchSql,
+		"migration/sqlite/1.1.0_kill_switch.sql",
+	)
+}
+
+func migrationSqlite110_kill_switchSql() (*asset, error) {
+	bytes, err := migrationSqlite110_kill_switchSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/sqlite/1.1.0_kill_switch.sql", size: 586, mode: os.FileMode(420), modTime: time.Unix(1792326075, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationSqlite120_wider_instance_idSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x90\xb1\x4e\x03\x31\x10\x44\xfb\xfb\x8a\xe9\x52\x90\x0b\x0d\x05\x6d\x04\x05\x12\x50\x10\x08\x2d\x72\xec\xbd\xb3\x15\xdf\xda\xb2\x1d\x85\xfb\x7b\x76\x03\x9c\x44\x47\xb7\x5e\xcf\x8c\xde\x4e\xdf\xe3\x6a\x0a\x63\x31\x8d\xb0\xcf\x5d\xdf\xe3\xf5\xe5\x09\x81\x51\xc9\xb6\x90\x18\xab\x7d\x5e\x21\x54\xd0\x27\xd9\x53\x23\x87\xb3\x27\x46\xf3\xb2\xfa\xf6\xa9\x48\x1e\x26\xe7\x18\xc8\x69\xc2\xe3\xe9\x40\x85\xa9\x51\x45\x4e\x4e\xbe\x0a\x21\x38\xe2\x16\x06\x51\xe0\x30\x83\xcd\x44\x35\x1b\x4b\xd7\x3a\xad\x25\x33\x58\x0f\x97\xc4\xc1\xa9\x61\x08\x4d\x11\x6e\x6e\x61\xbd\x29\xc6\x36\x2a\x75\xf3\xc3\x16\x04\x74\x11\x12\x0f\xa9\x58\x12\x1c\x42\x24\x1e\x9b\x47\x1a\xf0\xbe\xdd\xdd\x3d\x6c\x77\xb0\x29\x9e\x26\xae\x6b\xd4\x24\x71\xb5\x19\xb6\xf4\x11\x1c\x4c\x2c\x64\xdc\xac\x81\x3e\x45\x01\x14\xfb\xb4\x86\x61\xa7\x93\xc2\x5e\xd2\x7d\xe0\x11\x2d\x29\x03\x8f\xb4\xc1\xdb\xdf\x9b\x8f\x44\xf9\x62\x95\xdb\xaa\xe4\x6b\x5c\x6d\x94\x71\x0e\x82\xa1\xfb\xe7\x59\xbb\x5c\x1c\x72\x42\xa7\xa2\xa5\xef\xfb\x74\xe6\xdf\xc6\x97\xba\x75\xf9\xaf\xc2\x4b\x8a\x51\xdb\x34\xf6\xd8\x7d\x01\xc6\xc6\xbb\xfe\xc6\x01\x00\x00")
+
+func migrationSqlite120_wider_instance_idSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationSqlite120_wider_instance_idSql,
+		"migration/sqlite/1.2.0_wider_instance_id.sql",
+	)
+}
+
+func migrationSqlite120_wider_instance_idSql() (*asset, error) {
+	bytes, err := migrationSqlite120_wider_instance_idSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/sqlite/1.2.0_wider_instance_id.sql", size: 454, mode: os.FileMode(420), modTime: time.Unix(1792326075, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationSqlite130_termination_outcomeSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x91\xc1\x6e\x83\x30\x10\x44\xef\x7c\xc5\x28\x17\x82\x5a\xa2\xaa\x52\x4e\x9c\x68\x48\xd5\x83\x0b\x2d\x81\x5c\x2b\x07\x36\xc1\x82\x18\x84\x8d\xd2\xcf\xaf\x4d\x42\xda\x4a\x55\x15\x9f\xd6\xab\xf1\x5b\xcf\x8e\xef\xe3\xee\x28\x0e\x3d\xd7\x84\xbc\x73\x7c\x1f\x9b\x77\x06\x21\xa1\xa8\xd0\xa2\x95\x70\xf3\xce\x85\x50\xa0\x4f\x2a\x06\x4d\x25\x4e\x15\x49\xe8\xca\xb4\xce\xef\xac\xc8\x5c\x78\xd7\x35\x82\x4a\x4b\x50\x9a\xeb\x41\xd9\xe6\x4c\x0d\x45\x41\x54\x52\x39\x43\xdb\x63\xb6\xe7\xa2\x19\x6b\x59\x10\xb8\xc4\x20\x1b\xe2\xaa\x32\x54\x4d\xfd\x51\xc8\x33\xad\xe2\x0a\x3b\x32\x53\xa6\x99\xf7\x96\xca\xa5\x51\x71\x55\x7f\x88\xd2\xa2\x75\x45\x30\x55\xbb\x1f\xab\x1d\x2f\x6a\xba\x08\x30\xa7\xc5\x61\x81\x4d\x27\xa4\xe4\x35\xf5\x9e\x51\x70\xfd\x6d\x40\x68\x27\x64\xd9\x3a\x45\x16\x3e\xb1\xf5\xcf\xc9\xca\x81\x39\x61\x14\x61\x95\xb0\xfc\x35\x9e\x9c\x60\x1b\xa6\xab\x97\x30\x9d\x3f\x3e\x78\x40\x9c\x64\x88\x73\xc6\x10\xad\x9f\xc3\x9c\x65\x70\xdd\xe0\x66\xe2\xe4\xe0\x4a\x5c\x2e\xbd\xbf\x89\x8e\x35\x7d\xcd\x26\x6a\x4f\x72\x4a\xe7\x1a\x8d\x6d\xde\x14\x4e\xdf\x36\x66\xef\xe3\x96\xfe\xff\x69\x94\x26\x6f\xbf\xcd\x07\xb7\x3f\xb8\x78\x0b\x9c\x2f\x90\x12\xeb\x13\x55\x02\x00\x00")
+
+func migrationSqlite130_termination_outcomeSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationSqlite130_termination_outcomeSql,
+		"migration/sqlite/1.3.0_termination_outcome.sql",
+	)
+}
+
+func migrationSqlite130_termination_outcomeSql() (*asset, error) {
+	bytes, err := migrationSqlite130_termination_outcomeSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/sqlite/1.3.0_termination_outcome.sql", size: 597, mode: os.FileMode(420), modTime: time.Unix(1792326075, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrationSqlite140_schedule_seedSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x50\x41\x4e\xc3\x30\x10\xbc\xe7\x15\x73\xeb\x01\xc2\x07\x7a\x0a\x24\x42\x95\x4c\x0a\x25\x79\x40\x6a\x6f\x6b\xab\xc1\xb6\x6c\x87\xc0\xef\x59\x37\x22\x70\x42\xec\x69\x77\x76\x77\x66\x34\x65\x89\x9b\x37\x73\x0e\x43\x22\xf4\xbe\x28\x4b\xbc\xbe\x08\x18\x8b\x48\x32\x19\x67\xb1\xe9\xfd\x06\x26\x82\x3e\x48\x4e\x89\x14\x66\x4d\x16\x49\x33\xb4\xfc\xe5\x23\x1e\x06\xef\x47\x43\x2a\x33\x44\xe2\x33\x86\x92\xa6\xa5\xbf\x36\x52\x93\x9a\x46\xc2\x3c\x44\x9c\xc9\x52\x96\x64\x36\x93\xf4\x2d\x98\x82\xde\x29\x7c\x22\xb8\x19\xee\x74\x7d\x50\xbc\xbf\xcb\x74\xbb\x94\xc9\xda\x5e\x08\x9c\x5c\x58\x89\x22\xfc\x74\x1c\x4d\xe4\x09\x47\xe2\xcd\x22\x16\x31\x13\xb7\x81\xa4\x0b\x8a\xfd\x54\xa2\x6b\x0e\xe8\xaa\x7b\xd1\xfc\xbc\x16\xe0\xaa\xea\x1a\x0f\x7b\xd1\x3f\xb5\x8b\xcb\x5d\xdb\x35\x8f\x7c\x9b\x95\xb6\x45\x91\xa5\xd7\x68\x6a\x37\xdb\xef\x70\xd6\x64\x32\xf8\xaf\x6c\x82\x1b\xc7\xec\x72\x90\x97\x3f\xfc\xd4\x87\xfd\xf3\x6f\x43\xdb\xe2\x0b\x8e\x3b\x55\xe2\x9d\x01\x00\x00")
+
+func migrationSqlite140_schedule_seedSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrationSqlite140_schedule_seedSql,
+		"migration/sqlite/1.4.0_schedule_seed.sql",
+	)
+}
+
+func migrationSqlite140_schedule_seedSql() (*asset, error) {
+	bytes, err := migrationSqlite140_schedule_seedSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migration/sqlite/1.4.0_schedule_seed.sql", size: 413, mode: os.FileMode(420), modTime: time.Unix(1792326075, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -140,7 +434,21 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"migration/mysql/1.0.0_initial_schema.sql": migrationMysql100_initial_schemaSql,
+	"migration/mysql/1.0.0_initial_schema.sql":         migrationMysql100_initial_schemaSql,
+	"migration/mysql/1.1.0_kill_switch.sql":            migrationMysql110_kill_switchSql,
+	"migration/mysql/1.2.0_wider_instance_id.sql":      migrationMysql120_wider_instance_idSql,
+	"migration/mysql/1.3.0_termination_outcome.sql":    migrationMysql130_termination_outcomeSql,
+	"migration/mysql/1.4.0_schedule_seed.sql":          migrationMysql140_schedule_seedSql,
+	"migration/postgres/1.0.0_initial_schema.sql":      migrationPostgres100_initial_schemaSql,
+	"migration/postgres/1.1.0_kill_switch.sql":         migrationPostgres110_kill_switchSql,
+	"migration/postgres/1.2.0_wider_instance_id.sql":   migrationPostgres120_wider_instance_idSql,
+	"migration/postgres/1.3.0_termination_outcome.sql": migrationPostgres130_termination_outcomeSql,
+	"migration/postgres/1.4.0_schedule_seed.sql":       migrationPostgres140_schedule_seedSql,
+	"migration/sqlite/1.0.0_initial_schema.sql":        migrationSqlite100_initial_schemaSql,
+	"migration/sqlite/1.1.0_kill_switch.sql":           migrationSqlite110_kill_switchSql,
+	"migration/sqlite/1.2.0_wider_instance_id.sql":     migrationSqlite120_wider_instance_idSql,
+	"migration/sqlite/1.3.0_termination_outcome.sql":   migrationSqlite130_termination_outcomeSql,
+	"migration/sqlite/1.4.0_schedule_seed.sql":         migrationSqlite140_schedule_seedSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -185,7 +493,25 @@ type bintree struct {
 var _bintree = &bintree{nil, map[string]*bintree{
 	"migration": &bintree{nil, map[string]*bintree{
 		"mysql": &bintree{nil, map[string]*bintree{
-			"1.0.0_initial_schema.sql": &bintree{migrationMysql100_initial_schemaSql, map[string]*bintree{}},
+			"1.0.0_initial_schema.sql":      &bintree{migrationMysql100_initial_schemaSql, map[string]*bintree{}},
+			"1.1.0_kill_switch.sql":         &bintree{migrationMysql110_kill_switchSql, map[string]*bintree{}},
+			"1.2.0_wider_instance_id.sql":   &bintree{migrationMysql120_wider_instance_idSql, map[string]*bintree{}},
+			"1.3.0_termination_outcome.sql": &bintree{migrationMysql130_termination_outcomeSql, map[string]*bintree{}},
+			"1.4.0_schedule_seed.sql":       &bintree{migrationMysql140_schedule_seedSql, map[string]*bintree{}},
+		}},
+		"postgres": &bintree{nil, map[string]*bintree{
+			"1.0.0_initial_schema.sql":      &bintree{migrationPostgres100_initial_schemaSql, map[string]*bintree{}},
+			"1.1.0_kill_switch.sql":         &bintree{migrationPostgres110_kill_switchSql, map[string]*bintree{}},
+			"1.2.0_wider_instance_id.sql":   &bintree{migrationPostgres120_wider_instance_idSql, map[string]*bintree{}},
+			"1.3.0_termination_outcome.sql": &bintree{migrationPostgres130_termination_outcomeSql, map[string]*bintree{}},
+			"1.4.0_schedule_seed.sql":       &bintree{migrationPostgres140_schedule_seedSql, map[string]*bintree{}},
+		}},
+		"sqlite": &bintree{nil, map[string]*bintree{
+			"1.0.0_initial_schema.sql":      &bintree{migrationSqlite100_initial_schemaSql, map[string]*bintree{}},
+			"1.1.0_kill_switch.sql":         &bintree{migrationSqlite110_kill_switchSql, map[string]*bintree{}},
+			"1.2.0_wider_instance_id.sql":   &bintree{migrationSqlite120_wider_instance_idSql, map[string]*bintree{}},
+			"1.3.0_termination_outcome.sql": &bintree{migrationSqlite130_termination_outcomeSql, map[string]*bintree{}},
+			"1.4.0_schedule_seed.sql":       &bintree{migrationSqlite140_schedule_seedSql, map[string]*bintree{}},
 		}},
 	}},
 }}
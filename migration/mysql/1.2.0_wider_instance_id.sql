@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Kubernetes pods are identified by namespace/name, which does not fit in 48 characters
+ALTER TABLE terminations MODIFY instance_id VARCHAR(512) NOT NULL;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE terminations MODIFY instance_id VARCHAR(48) NOT NULL;
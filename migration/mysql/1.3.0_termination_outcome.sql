@@ -0,0 +1,14 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- status is "succeeded" or "failed" once an unleashed termination has been executed,
+-- and task_id is the id of the backend task (e.g. Spinnaker) that executed it
+ALTER TABLE terminations
+    ADD COLUMN status  VARCHAR(20)  NOT NULL DEFAULT '',
+    ADD COLUMN task_id VARCHAR(255) NOT NULL DEFAULT '';
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE terminations
+    DROP COLUMN status,
+    DROP COLUMN task_id;
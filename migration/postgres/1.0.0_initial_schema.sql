@@ -0,0 +1,37 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Migrations have the same ids as the MySQL migrations, so that a schema
+-- version means the same schema in every database.
+CREATE TABLE IF NOT EXISTS schedules (
+    id           SERIAL PRIMARY KEY,
+    date         DATE NOT NULL,         -- date of termination schedule, in local time zone
+    time         TIMESTAMPTZ NOT NULL,  -- because of time difference, may differ from date
+    app          VARCHAR(512) NOT NULL,
+    account      VARCHAR(100) NOT NULL,
+    region       VARCHAR(50)  NOT NULL, -- use blank string to indicate not present
+    stack        VARCHAR(255) NOT NULL, -- use blank string to indicate not present
+    cluster      VARCHAR(768) NOT NULL  -- use blank string to indicate not present
+    );
+
+CREATE INDEX IF NOT EXISTS schedules_date_index ON schedules (date);
+
+CREATE TABLE IF NOT EXISTS terminations (
+    id           SERIAL PRIMARY KEY,
+    app          VARCHAR(512) NOT NULL,
+    account      VARCHAR(100) NOT NULL,
+    stack        VARCHAR(255) NOT NULL,
+    cluster      VARCHAR(768) NOT NULL,
+    region       VARCHAR(50) NOT NULL,
+    asg          VARCHAR(1000) NOT NULL,
+    instance_id  VARCHAR(48) NOT NULL,
+    killed_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    leashed      BOOLEAN NOT NULL DEFAULT FALSE
+    );
+
+CREATE INDEX IF NOT EXISTS terminations_app_killed_at_index ON terminations (app, killed_at);
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE schedules;
+DROP TABLE terminations;
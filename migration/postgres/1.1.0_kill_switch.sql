@@ -0,0 +1,14 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS kill_switch (
+    id           INT NOT NULL PRIMARY KEY, -- always 1, there is a single kill switch
+    stopped      BOOLEAN NOT NULL DEFAULT FALSE,
+    reason       VARCHAR(1024) NOT NULL,
+    stopped_at   TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NULL          -- NULL if the kill switch does not expire
+    );
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE kill_switch;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Kubernetes pods are identified by namespace/name, which does not fit in 48 characters
+ALTER TABLE terminations ALTER COLUMN instance_id TYPE VARCHAR(512);
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE terminations ALTER COLUMN instance_id TYPE VARCHAR(48);
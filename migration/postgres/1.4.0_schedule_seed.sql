@@ -0,0 +1,12 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- seed is the seed the schedule was generated with, on every row of the date.
+-- It is NULL for schedules published before seeds were recorded
+ALTER TABLE schedules
+    ADD COLUMN seed BIGINT NULL;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE schedules
+    DROP COLUMN seed;
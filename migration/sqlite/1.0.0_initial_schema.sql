@@ -0,0 +1,39 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Migrations have the same ids as the MySQL migrations, so that a schema
+-- version means the same schema in every database.
+-- Times are stored as text in UTC, in the format "2006-01-02 15:04:05.000000",
+-- so that they compare in chronological order
+CREATE TABLE IF NOT EXISTS schedules (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    date         TEXT NOT NULL,         -- date of termination schedule, in local time zone, as 2006-01-02
+    time         DATETIME NOT NULL,     -- time in UTC. Because of time difference, may differ from date
+    app          VARCHAR(512) NOT NULL,
+    account      VARCHAR(100) NOT NULL,
+    region       VARCHAR(50)  NOT NULL, -- use blank string to indicate not present
+    stack        VARCHAR(255) NOT NULL, -- use blank string to indicate not present
+    cluster      VARCHAR(768) NOT NULL  -- use blank string to indicate not present
+    );
+
+CREATE INDEX IF NOT EXISTS schedules_date_index ON schedules (date);
+
+CREATE TABLE IF NOT EXISTS terminations (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    app          VARCHAR(512) NOT NULL,
+    account      VARCHAR(100) NOT NULL,
+    stack        VARCHAR(255) NOT NULL,
+    cluster      VARCHAR(768) NOT NULL,
+    region       VARCHAR(50) NOT NULL,
+    asg          VARCHAR(1000) NOT NULL,
+    instance_id  VARCHAR(48) NOT NULL,
+    killed_at    DATETIME NOT NULL,     -- time in UTC
+    leashed      BOOLEAN NOT NULL DEFAULT FALSE
+    );
+
+CREATE INDEX IF NOT EXISTS terminations_app_killed_at_index ON terminations (app, killed_at);
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE schedules;
+DROP TABLE terminations;
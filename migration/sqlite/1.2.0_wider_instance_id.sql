@@ -0,0 +1,10 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Kubernetes pods are identified by namespace/name, which does not fit in 48 characters.
+-- SQLite does not enforce the length of VARCHAR columns, so instance_id already
+-- holds them, and there is nothing to change. This migration keeps the ids in
+-- step with the MySQL migrations.
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
@@ -23,6 +23,7 @@ import (
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/config/param"
 	"github.com/Netflix/chaosmonkey/deps"
+	"github.com/Netflix/chaosmonkey/killswitch"
 )
 
 type (
@@ -48,6 +49,11 @@ type (
 	Env struct {
 		IsInTest bool
 	}
+
+	// KillSwitch implements killswitch.KillSwitch
+	KillSwitch struct {
+		Current killswitch.State
+	}
 )
 
 // Check implements deps.Checker.Check
@@ -76,6 +82,23 @@ func (e Env) InTest() bool {
 	return e.IsInTest
 }
 
+// Stop implements killswitch.KillSwitch.Stop
+func (k *KillSwitch) Stop(reason string, stoppedAt time.Time, expiresAt time.Time) error {
+	k.Current = killswitch.State{Stopped: true, Reason: reason, StoppedAt: stoppedAt, ExpiresAt: expiresAt}
+	return nil
+}
+
+// Resume implements killswitch.KillSwitch.Resume
+func (k *KillSwitch) Resume() error {
+	k.Current.Stopped = false
+	return nil
+}
+
+// State implements killswitch.KillSwitch.State
+func (k *KillSwitch) State() (killswitch.State, error) {
+	return k.Current, nil
+}
+
 // Deps returns a deps.Deps object that contains mocks.
 // The mocks implement their interfaces by performing no-ops.
 func Deps() deps.Deps {
@@ -110,5 +133,6 @@ func Deps() deps.Deps {
 		Ou:         Outage{},
 		ErrCounter: ErrorCounter{},
 		Env:        Env{false},
+		KillSwitch: new(KillSwitch),
 	}
 }
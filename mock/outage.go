@@ -15,9 +15,11 @@
 package mock
 
 // Outage is a mock implementation of outage.Outage
-type Outage struct{}
+type Outage struct {
+	IsOutage bool
+}
 
 // Outage implemnets outage.Outage.Outage
 func (o Outage) Outage() (bool, error) {
-	return false, nil
+	return o.IsOutage, nil
 }
@@ -21,10 +21,12 @@
 package mysql_test
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
 	c "github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/history"
 	"github.com/Netflix/chaosmonkey/mock"
 	"github.com/Netflix/chaosmonkey/mysql"
 )
@@ -381,3 +383,99 @@ func TestCheckMinTimeEnforced(t *testing.T) {
 		}
 	}
 }
+
+// TestRecordOutcome verifies that the outcome is stored with the termination
+// that Check recorded, even if the termination time has fractional seconds
+func TestRecordOutcome(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mysql.New("localhost", port, "root", password, "chaosmonkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ins, loc, appCfg := testSetup(t)
+
+	trm := c.Termination{Instance: ins, Time: time.Date(2016, time.November, 16, 17, 0, 0, 700000000, time.UTC)}
+
+	err = m.Check(trm, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.RecordOutcome(trm, c.Outcome{Succeeded: true, TaskID: "01BXQ5TDV8ZR2K7SZ5RJGQ1NKG"})
+	if err != nil {
+		t.Fatalf("failed to record outcome: %v", err)
+	}
+
+	other := trm
+	other.Time = trm.Time.Add(time.Hour)
+	err = m.RecordOutcome(other, c.Outcome{Succeeded: false})
+	if err == nil {
+		t.Error("expected error when recording the outcome of a termination that was not checked")
+	}
+}
+
+// Test that terminations can be retrieved with filters
+func TestTerminations(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mysql.New("localhost", port, "root", password, "chaosmonkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ins, loc, appCfg := testSetup(t)
+	appCfg.MinTimeBetweenKillsInWorkDays = 0
+
+	west := ins.(mock.Instance)
+	west.Region = "us-west-2"
+	west.InstanceID = "i-b96a0166"
+
+	day := func(d int) time.Time {
+		return time.Date(2016, time.November, d, 11, 0, 0, 0, loc)
+	}
+
+	for _, trm := range []c.Termination{
+		{Instance: ins, Time: day(14), Leashed: true},
+		{Instance: ins, Time: day(15)},
+		{Instance: west, Time: day(16)},
+	} {
+		if err := m.Check(trm, appCfg, endHour, loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	unleashed := false
+	tests := []struct {
+		filter history.Filter
+		want   []string
+	}{
+		{history.Filter{}, []string{ins.ID(), ins.ID(), "i-b96a0166"}},
+		{history.Filter{App: ins.AppName(), Region: "us-west-2"}, []string{"i-b96a0166"}},
+		{history.Filter{Since: day(15), Until: day(16)}, []string{ins.ID()}},
+		{history.Filter{Leashed: &unleashed}, []string{ins.ID(), "i-b96a0166"}},
+	}
+
+	for _, tt := range tests {
+		terms, err := m.Terminations(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, term := range terms {
+			got = append(got, term.InstanceID)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/killswitch"
+)
+
+// killSwitchID is the id of the single row of the kill_switch table
+const killSwitchID = 1
+
+// Stop implements killswitch.KillSwitch.Stop
+func (m MySQL) Stop(reason string, stoppedAt time.Time, expiresAt time.Time) error {
+	var expires interface{}
+	if !expiresAt.IsZero() {
+		expires = expiresAt.UTC()
+	}
+
+	_, err := m.db.Exec(`INSERT INTO kill_switch (id, stopped, reason, stopped_at, expires_at) VALUES (?, TRUE, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE stopped=TRUE, reason=VALUES(reason), stopped_at=VALUES(stopped_at), expires_at=VALUES(expires_at)`,
+		killSwitchID, reason, stoppedAt.UTC(), expires)
+	if err != nil {
+		return errors.Wrap(err, "failed to stop kill switch")
+	}
+
+	return nil
+}
+
+// Resume implements killswitch.KillSwitch.Resume
+func (m MySQL) Resume() error {
+	_, err := m.db.Exec("UPDATE kill_switch SET stopped=FALSE WHERE id=?", killSwitchID)
+	if err != nil {
+		return errors.Wrap(err, "failed to resume kill switch")
+	}
+
+	return nil
+}
+
+// State implements killswitch.KillSwitch.State
+func (m MySQL) State() (killswitch.State, error) {
+	var state killswitch.State
+	var expiresAt mysql.NullTime
+
+	err := m.db.QueryRow("SELECT stopped, reason, stopped_at, expires_at FROM kill_switch WHERE id=?", killSwitchID).
+		Scan(&state.Stopped, &state.Reason, &state.StoppedAt, &expiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return killswitch.State{}, nil
+	case err != nil:
+		return killswitch.State{}, errors.Wrap(err, "failed to retrieve kill switch state")
+	}
+
+	if expiresAt.Valid {
+		state.ExpiresAt = expiresAt.Time
+	}
+
+	return state, nil
+}
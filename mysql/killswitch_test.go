@@ -0,0 +1,99 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build docker
+
+// The tests in this package use docker to test against a mysql:5.6 database
+// By default, the tests are off unless you pass the "-tags docker" flag
+// when running the test.
+
+package mysql_test
+
+import (
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/Netflix/chaosmonkey/mysql"
+)
+
+// Test we can stop, retrieve and resume the kill switch
+func TestKillSwitch(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mysql.New("localhost", port, "root", password, "chaosmonkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := m.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Stopped {
+		t.Fatal("expected kill switch to be inactive before it was ever stopped")
+	}
+
+	stoppedAt := time.Date(2016, time.June, 20, 11, 40, 0, 0, time.UTC)
+	expiresAt := stoppedAt.Add(4 * time.Hour)
+
+	// Code under test:
+	err = m.Stop("game day", stoppedAt, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = m.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.Stopped || state.Reason != "game day" || !state.StoppedAt.Equal(stoppedAt) || !state.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("unexpected state after stop: %+v", state)
+	}
+
+	// Stopping again without an expiry replaces the previous one
+	err = m.Stop("", stoppedAt, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = m.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.ExpiresAt.IsZero() {
+		t.Errorf("got ExpiresAt=%s, want zero time", state.ExpiresAt)
+	}
+
+	err = m.Resume()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = m.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Stopped {
+		t.Error("expected kill switch to be inactive after resume")
+	}
+}
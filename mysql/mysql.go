@@ -29,6 +29,7 @@ import (
 	"github.com/Netflix/chaosmonkey/config/param"
 	"github.com/Netflix/chaosmonkey/deps"
 	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/history"
 	"github.com/Netflix/chaosmonkey/migration"
 	"github.com/Netflix/chaosmonkey/schedstore"
 	"github.com/Netflix/chaosmonkey/schedule"
@@ -38,7 +39,8 @@ import (
 
 // MySQL represents a MySQL-backed store for schedules and terminations
 type MySQL struct {
-	db *sql.DB
+	db        *sql.DB
+	calendars cal.Calendars
 }
 
 // TxDeadlock returns true if the error is because of a transaction deadlock
@@ -79,7 +81,18 @@ func NewFromConfig(cfg *config.Monkey) (MySQL, error) {
 		return MySQL{}, err
 	}
 
-	return New(cfg.DatabaseHost(), cfg.DatabasePort(), cfg.DatabaseUser(), password, cfg.DatabaseName())
+	calendars, err := cal.NewFromConfig(cfg)
+	if err != nil {
+		return MySQL{}, err
+	}
+
+	m, err := New(cfg.DatabaseHost(), cfg.DatabasePort(), cfg.DatabaseUser(), password, cfg.DatabaseName())
+	if err != nil {
+		return MySQL{}, err
+	}
+
+	m.calendars = calendars
+	return m, nil
 }
 
 // New creates a new MySQL
@@ -89,7 +102,7 @@ func New(host string, port int, user string, password string, dbname string) (My
 		return MySQL{}, errors.Wrap(err, "sql.Open failed")
 	}
 
-	return MySQL{db}, nil
+	return MySQL{db: db}, nil
 }
 
 // Close closes the underlying sql.DB
@@ -108,7 +121,7 @@ func utcDate(date time.Time) time.Time {
 
 // Retrieve  retrieves the schedule for the given date
 func (m MySQL) Retrieve(date time.Time) (sched *schedule.Schedule, err error) {
-	rows, err := m.db.Query("SELECT time, app, account, region, stack, cluster FROM schedules WHERE date = DATE(?)", utcDate(date))
+	rows, err := m.db.Query("SELECT time, app, account, region, stack, cluster, seed FROM schedules WHERE date = DATE(?)", utcDate(date))
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to retrieve schedule for %s", date)
 	}
@@ -124,12 +137,17 @@ func (m MySQL) Retrieve(date time.Time) (sched *schedule.Schedule, err error) {
 	for rows.Next() {
 		var tm time.Time
 		var app, account, region, stack, cluster string
+		var seed sql.NullInt64
 
-		err = rows.Scan(&tm, &app, &account, &region, &stack, &cluster)
+		err = rows.Scan(&tm, &app, &account, &region, &stack, &cluster, &seed)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
 		}
 
+		if seed.Valid {
+			sched.SetSeed(seed.Int64)
+		}
+
 		sched.Add(tm, grp.New(app, account, region, stack, cluster))
 	}
 
@@ -182,12 +200,16 @@ func (m MySQL) PublishWithDelay(date time.Time, sched *schedule.Schedule, delay
 	if delay > 0 {
 		time.Sleep(delay)
 	}
-	query := "INSERT INTO schedules (date, time, app, account, region, stack, cluster) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	query := "INSERT INTO schedules (date, time, app, account, region, stack, cluster, seed) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
 	stmt, err := tx.Prepare(query)
 	if err != nil {
 		return errors.Wrapf(err, "failed to prepare sql statement: %s", query)
 	}
 
+	// Schedules published without a seed record it as NULL
+	var seed sql.NullInt64
+	seed.Int64, seed.Valid = sched.Seed()
+
 	for _, entry := range sched.Entries() {
 		var app, account, region, stack, cluster string
 		app = entry.Group.App()
@@ -202,7 +224,7 @@ func (m MySQL) PublishWithDelay(date time.Time, sched *schedule.Schedule, delay
 			cluster = val
 		}
 
-		_, err = stmt.Exec(utcDate(date), entry.Time.In(time.UTC), app, account, region, stack, cluster)
+		_, err = stmt.Exec(utcDate(date), entry.Time.In(time.UTC), app, account, region, stack, cluster, seed)
 		if err != nil {
 			return errors.Wrapf(err, "failed to execute prepared query")
 		}
@@ -282,7 +304,7 @@ func (m MySQL) CheckWithDelay(term chaosmonkey.Termination, appCfg chaosmonkey.A
 		}
 	}()
 
-	err = respectsMinTimeBetweenKills(tx, term.Time, term, appCfg, endHour, loc)
+	err = respectsMinTimeBetweenKills(tx, term.Time, term, appCfg, m.calendars, endHour, loc)
 	if err != nil {
 		return err
 	}
@@ -300,10 +322,11 @@ func (m MySQL) CheckWithDelay(term chaosmonkey.Termination, appCfg chaosmonkey.A
 // violate the min time between kills value. If this termination is too close
 // to the most recent one, this will return an error.
 // If this termination would violate the min time, returns an ErrViolatesMinTime
-func respectsMinTimeBetweenKills(tx *sql.Tx, now time.Time, term chaosmonkey.Termination, appCfg chaosmonkey.AppConfig, endHour int, loc *time.Location) (err error) {
+func respectsMinTimeBetweenKills(tx *sql.Tx, now time.Time, term chaosmonkey.Termination, appCfg chaosmonkey.AppConfig, calendars cal.Calendars, endHour int, loc *time.Location) (err error) {
 	app := term.Instance.AppName()
 	account := term.Instance.AccountName()
-	threshold, err := noKillsSince(appCfg.MinTimeBetweenKillsInWorkDays, now, endHour, loc)
+	calendar := calendars.For(term.Instance.AccountName(), term.Instance.RegionName())
+	threshold, err := calendar.NoKillsSince(appCfg.MinTimeBetweenKillsInWorkDays, now, endHour, loc)
 	if err != nil {
 		return err
 	}
@@ -365,69 +388,6 @@ func respectsMinTimeBetweenKills(tx *sql.Tx, now time.Time, term chaosmonkey.Ter
 	return nil
 }
 
-// noKillsSince computes the date of the most recent kill
-// that conforms to the min time between kills specified
-// by days
-//
-// Note that the calculation is min time in work days, so it does not count weekends.
-//
-// chrono is an interface for returning the current time
-// endHour is the hour of the end of a workday in 24-hour time. For example, if
-// workday ends at 5PM, this would be 17
-// loc is the location that corresponds to endHour, e.g. America/Los_Angeles for PST
-//
-// The returned time will be in UTC
-//
-// If days=1, then we allow
-// kills each day, so the most recent kill will be at the
-// end of the previous workday. For example:
-//
-//  days: 1
-//  endHour: 17 (i.e. work day ends at 5PM local time)
-//  loc:  America/Los_Angeles (PST)
-//  chrono.Now(): Wed, Dec. 16, 2015 2:30 PM PST
-//  Output: Tue, Dec. 15, 2015 5:00 PM PST
-//
-//
-// If days=0, returns the the current date, with
-// the time set to endHour. For example:
-//
-//  days: 0
-//  endHour: 17 (i.e. work day ends at 5PM local time)
-//  loc:  America/Los_Angeles (PST)
-//  chrono.Now(): Wed, Dec. 16, 2015 2:30 PM PST
-//  Output: Wed, Dec. 16, 2015 5:00 PM PST
-//
-// noKillsSince returns the a datetime that is the last allowed time that a kill
-// is permitted to have happened.
-func noKillsSince(days int, now time.Time, endHour int, loc *time.Location) (time.Time, error) {
-	if days < 0 {
-		return time.Time{}, errors.Errorf("noKillsSince passed illegal input: days=%d", days)
-	}
-
-	oneDay := time.Hour * 24
-
-	// Tail-recursive helper function reads clearer than writing a
-	// traditional loop
-	//
-	// It expects a time localized to the zone associated with endHour because
-	// workday and year-month-day values depend on the local timezone
-	var helper func(N int, tInLoc time.Time) time.Time
-
-	helper = func(N int, tInLoc time.Time) time.Time {
-		switch {
-		case !cal.IsWorkday(tInLoc):
-			return helper(N, tInLoc.Add(-oneDay))
-		case N == 0:
-			return time.Date(tInLoc.Year(), tInLoc.Month(), tInLoc.Day(), endHour, 0, 0, 0, loc).UTC()
-		default:
-			return helper(N-1, tInLoc.Add(-oneDay))
-		}
-	}
-
-	return helper(days, now.In(loc)), nil
-}
-
 func recordTermination(tx *sql.Tx, term chaosmonkey.Termination, loc *time.Location) (err error) {
 
 	i := term.Instance
@@ -438,6 +398,82 @@ func recordTermination(tx *sql.Tx, term chaosmonkey.Termination, loc *time.Locat
 	return err
 }
 
+// Termination statuses recorded by RecordOutcome
+const (
+	statusSucceeded = "succeeded"
+	statusFailed    = "failed"
+)
+
+// RecordOutcome implements chaosmonkey.OutcomeRecorder.RecordOutcome
+// It stores the status and task id with the termination recorded by Check
+func (m MySQL) RecordOutcome(term chaosmonkey.Termination, outcome chaosmonkey.Outcome) error {
+	status := statusFailed
+	if outcome.Succeeded {
+		status = statusSucceeded
+	}
+
+	i := term.Instance
+	result, err := m.db.Exec("UPDATE terminations SET status = ?, task_id = ? WHERE app = ? AND account = ? AND instance_id = ? AND killed_at = CAST(? AS DATETIME)",
+		status, outcome.TaskID, i.AppName(), i.AccountName(), i.ID(), term.Time.In(time.UTC))
+	if err != nil {
+		return errors.Wrap(err, "failed to record termination outcome")
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to record termination outcome")
+	}
+
+	if n == 0 {
+		return errors.Errorf("no termination of %s at %s to record outcome of", i.ID(), term.Time.In(time.UTC))
+	}
+
+	return nil
+}
+
+// Terminations implements history.Store.Terminations
+func (m MySQL) Terminations(f history.Filter) (terms []history.Termination, err error) {
+	query := "SELECT app, account, region, stack, cluster, asg, instance_id, killed_at, leashed, status, task_id FROM terminations"
+
+	var clauses []string
+	var args []interface{}
+	for _, c := range f.Conditions() {
+		clauses = append(clauses, c.Column+" "+c.Op+" ?")
+		args = append(args, c.Value)
+	}
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY killed_at"
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve terminations")
+	}
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "rows.Close() failed")
+		}
+	}()
+
+	for rows.Next() {
+		var t history.Termination
+		err = rows.Scan(&t.App, &t.Account, &t.Region, &t.Stack, &t.Cluster, &t.ASG, &t.InstanceID, &t.KilledAt, &t.Leashed, &t.Status, &t.TaskID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		terms = append(terms, t)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "rows.Err() errored")
+	}
+
+	return terms, nil
+}
+
 var migrationSource = &migrate.AssetMigrationSource{
 	Asset:    migration.Asset,
 	AssetDir: migration.AssetDir,
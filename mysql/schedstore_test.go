@@ -50,6 +50,7 @@ func TestPublishRetrieve(t *testing.T) {
 	}
 
 	sched := schedule.New()
+	sched.SetSeed(42)
 
 	t1 := time.Date(2016, time.June, 20, 11, 40, 0, 0, loc)
 	sched.Add(t1, grp.New("chaosguineapig", "test", "us-east-1", "", "chaosguineapig-test"))
@@ -76,6 +77,10 @@ func TestPublishRetrieve(t *testing.T) {
 	if !t1.Equal(entry.Time) {
 		t.Errorf("%s != %s", t1, entry.Time)
 	}
+
+	if seed, ok := sched.Seed(); !ok || seed != 42 {
+		t.Errorf("got seed=%d, ok=%t, want seed=42, ok=true", seed, ok)
+	}
 }
 
 func NewMySQL() (mysql.MySQL, error) {
@@ -0,0 +1,155 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+// Alertmanager is an outage checker that reports an outage if any active
+// alert in Alertmanager matches the configured label matchers
+//
+// It implements chaosmonkey.ScopedOutage: matchers configured for an account
+// or a region are added to the global matchers when checking for an outage in
+// that account or region.
+type Alertmanager struct {
+	url      string
+	matchers []string
+	cfg      *config.Monkey
+	client   *http.Client
+}
+
+// alert is the subset of an Alertmanager v2 alert that Chaos Monkey uses
+type alert struct {
+	Labels map[string]string `json:"labels"`
+	Status struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+// NewAlertmanager returns an alertmanager outage checker configured from cfg
+func NewAlertmanager(cfg *config.Monkey) (*Alertmanager, error) {
+	base := cfg.AlertmanagerURL()
+	if base == "" {
+		return nil, errors.Errorf("alertmanager outage checker: %s not specified", param.AlertmanagerURL)
+	}
+
+	matchers, err := cfg.AlertmanagerMatchers()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Alertmanager{
+		url:      strings.TrimRight(base, "/"),
+		matchers: matchers,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.AlertmanagerTimeout()},
+	}, nil
+}
+
+// Outage implements chaosmonkey.Outage.Outage
+// Only the global matchers are used
+func (a *Alertmanager) Outage() (bool, error) {
+	return a.firing(a.matchers)
+}
+
+// OutageIn implements chaosmonkey.ScopedOutage.OutageIn
+func (a *Alertmanager) OutageIn(account, region string) (bool, error) {
+	matchers := append([]string(nil), a.matchers...)
+
+	accountMatchers, err := a.cfg.AlertmanagerAccountMatchers(account)
+	if err != nil {
+		return false, err
+	}
+	matchers = append(matchers, accountMatchers...)
+
+	if region != "" {
+		regionMatchers, err := a.cfg.AlertmanagerRegionMatchers(region)
+		if err != nil {
+			return false, err
+		}
+		matchers = append(matchers, regionMatchers...)
+	}
+
+	return a.firing(matchers)
+}
+
+// firing returns true if any active alert matches all of the matchers
+func (a *Alertmanager) firing(matchers []string) (bool, error) {
+	alerts, err := a.alerts(matchers)
+	if err != nil {
+		return false, err
+	}
+
+	firing := false
+	for _, al := range alerts {
+		if al.Status.State != "active" {
+			continue
+		}
+		log.Printf("alert firing: alertname=%s labels=%v", al.Labels["alertname"], al.Labels)
+		firing = true
+	}
+
+	return firing, nil
+}
+
+// alerts queries the Alertmanager v2 API for unsilenced, uninhibited alerts
+// that match the matchers
+func (a *Alertmanager) alerts(matchers []string) (alerts []alert, err error) {
+	query := url.Values{}
+	query.Set("active", "true")
+	query.Set("silenced", "false")
+	query.Set("inhibited", "false")
+	for _, m := range matchers {
+		query.Add("filter", m)
+	}
+
+	u := a.url + "/api/v2/alerts?" + query.Encode()
+	resp, err := a.client.Get(u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "alertmanager outage checker: could not query %s", a.url)
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "failed to close response body")
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "alertmanager outage checker: could not read response from %s", a.url)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("alertmanager outage checker: %s returned code %d: %s", u, resp.StatusCode, body)
+	}
+
+	if err := json.Unmarshal(body, &alerts); err != nil {
+		return nil, errors.Wrapf(err, "alertmanager outage checker: could not parse response from %s", a.url)
+	}
+
+	return alerts, nil
+}
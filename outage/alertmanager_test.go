@@ -0,0 +1,137 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outage
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+// fakeAlertmanager serves alerts, filtering them on exact label matchers
+// the same way as the Alertmanager v2 API
+func fakeAlertmanager(t *testing.T, filters *[]string, alerts ...map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/alerts" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		if got := r.URL.Query().Get("silenced"); got != "false" {
+			t.Errorf("got silenced=%s, want false", got)
+		}
+
+		*filters = r.URL.Query()["filter"]
+
+		var matching []string
+		for _, labels := range alerts {
+			match := true
+			for _, f := range *filters {
+				kv := strings.SplitN(f, "=", 2)
+				if labels[kv[0]] != kv[1] {
+					match = false
+				}
+			}
+			if match {
+				matching = append(matching, fmt.Sprintf(`{"labels": {"alertname": %q}, "status": {"state": "active"}}`, labels["alertname"]))
+			}
+		}
+
+		fmt.Fprintf(w, "[%s]", strings.Join(matching, ","))
+	}))
+}
+
+func TestAlertmanagerScopes(t *testing.T) {
+	var filters []string
+	srv := fakeAlertmanager(t, &filters,
+		map[string]string{"alertname": "HighErrorRate", "severity": "critical", "env": "test", "region": "us-east-1"},
+	)
+	defer srv.Close()
+
+	cfg := config.Defaults()
+	cfg.Set(param.OutageChecker, "alertmanager")
+	cfg.Set(param.AlertmanagerURL, srv.URL)
+	cfg.Set(param.AlertmanagerMatchers, []string{"severity=critical"})
+	cfg.Set(param.AlertmanagerAccounts+".prod", []string{"env=prod"})
+	cfg.Set(param.AlertmanagerAccounts+".test", []string{"env=test"})
+	cfg.Set(param.AlertmanagerRegions+".us-east-1", []string{"region=us-east-1"})
+	cfg.Set(param.AlertmanagerRegions+".us-west-2", []string{"region=us-west-2"})
+
+	a, err := NewAlertmanager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		account, region string
+		filters         []string
+		outage          bool
+	}{
+		{"prod", "us-east-1", []string{"severity=critical", "env=prod", "region=us-east-1"}, false},
+		{"test", "us-east-1", []string{"severity=critical", "env=test", "region=us-east-1"}, true},
+		{"test", "us-west-2", []string{"severity=critical", "env=test", "region=us-west-2"}, false},
+		{"test", "", []string{"severity=critical", "env=test"}, true},
+		{"staging", "", []string{"severity=critical"}, true},
+	}
+
+	for _, tt := range tests {
+		outage, err := a.OutageIn(tt.account, tt.region)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if outage != tt.outage {
+			t.Errorf("account=%s region=%s: got outage=%t, want %t", tt.account, tt.region, outage, tt.outage)
+		}
+
+		if !reflect.DeepEqual(filters, tt.filters) {
+			t.Errorf("account=%s region=%s: got filters %v, want %v", tt.account, tt.region, filters, tt.filters)
+		}
+	}
+
+	outage, err := a.Outage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !outage {
+		t.Error("expected outage with global matchers only")
+	}
+}
+
+func TestAlertmanagerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `"bad matcher"`)
+	}))
+	defer srv.Close()
+
+	cfg := config.Defaults()
+	cfg.Set(param.AlertmanagerURL, srv.URL)
+
+	a, err := NewAlertmanager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.OutageIn("prod", "us-east-1"); err == nil {
+		t.Error("expected error when alertmanager returns an error")
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outage
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+// maxBodySize is the largest status page response that is read
+const maxBodySize = 1 << 20
+
+// HTTP is an outage checker that polls a status page over HTTP
+//
+// The response is checked with either a JSON path or a regular expression. If
+// the status page cannot be reached, or responds with an error, it is treated
+// as an outage so that Chaos Monkey errs on the safe side.
+type HTTP struct {
+	url          string
+	headers      map[string]string
+	jsonPath     []string
+	outageValues []string
+	regex        *regexp.Regexp
+	client       *http.Client
+	cacheTTL     time.Duration
+	cachePath    string
+	now          func() time.Time
+
+	// settings is a hash of the settings that determine the result of a
+	// check, so that checkers with different settings do not share results
+	settings string
+}
+
+// httpCacheEntry is the result of a check, as stored in the cache file
+type httpCacheEntry struct {
+	URL       string    `json:"url"`
+	Settings  string    `json:"settings"`
+	Outage    bool      `json:"outage"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// NewHTTP returns an http outage checker configured from cfg
+func NewHTTP(cfg *config.Monkey) (*HTTP, error) {
+	url := cfg.HTTPOutageURL()
+	if url == "" {
+		return nil, errors.Errorf("http outage checker: %s not specified", param.HTTPOutageURL)
+	}
+
+	path := cfg.HTTPOutageJSONPath()
+	expr := cfg.HTTPOutageRegex()
+	if (path == "") == (expr == "") {
+		return nil, errors.Errorf("http outage checker: exactly one of %s and %s must be specified", param.HTTPOutageJSONPath, param.HTTPOutageRegex)
+	}
+
+	values, err := cfg.HTTPOutageOutageValues()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &HTTP{
+		url:          url,
+		headers:      cfg.HTTPOutageHeaders(),
+		outageValues: values,
+		client:       &http.Client{Timeout: cfg.HTTPOutageTimeout()},
+		cacheTTL:     cfg.HTTPOutageCacheTTL(),
+		cachePath:    cfg.HTTPOutageCachePath(),
+		now:          time.Now,
+	}
+
+	if path != "" {
+		h.jsonPath = strings.Split(path, ".")
+	}
+
+	if expr != "" {
+		h.regex, err = regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "http outage checker: invalid %s", param.HTTPOutageRegex)
+		}
+	}
+
+	h.settings, err = settingsHash(url, h.headers, path, expr, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.cachePath == "" && h.cacheTTL > 0 {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			log.Printf("WARNING: not caching outage checks, %s not specified: %v", param.HTTPOutageCachePath, err)
+			h.cacheTTL = 0
+		} else {
+			h.cachePath = filepath.Join(dir, "chaosmonkey", fmt.Sprintf("outage-%s.json", h.settings))
+		}
+	}
+
+	return h, nil
+}
+
+// settingsHash returns a hash of the settings that determine the result of a
+// check. Only the hash is stored in the cache, since headers may hold
+// credentials.
+func settingsHash(url string, headers map[string]string, jsonPath, regex string, outageValues []string) (string, error) {
+	// Maps are marshalled with sorted keys, so the hash is stable
+	js, err := json.Marshal(struct {
+		URL          string            `json:"url"`
+		Headers      map[string]string `json:"headers"`
+		JSONPath     string            `json:"jsonPath"`
+		Regex        string            `json:"regex"`
+		OutageValues []string          `json:"outageValues"`
+	}{url, headers, jsonPath, regex, outageValues})
+	if err != nil {
+		return "", errors.Wrap(err, "http outage checker: could not hash settings")
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write(js)
+	return fmt.Sprintf("%016x", hash.Sum64()), nil
+}
+
+// Outage implements chaosmonkey.Outage.Outage
+func (h *HTTP) Outage() (bool, error) {
+	if outage, ok := h.cached(); ok {
+		return outage, nil
+	}
+
+	outage, err := h.check()
+	if err != nil {
+		log.Printf("WARNING: assuming outage: %v", err)
+		outage = true
+	}
+
+	h.store(outage)
+	return outage, nil
+}
+
+// check polls the status page
+func (h *HTTP) check() (outage bool, err error) {
+	req, err := http.NewRequest("GET", h.url, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "could not create request")
+	}
+
+	for k, v := range h.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not reach status page %s", h.url)
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "failed to close response body")
+		}
+	}()
+
+	body, err := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: maxBodySize})
+	if err != nil {
+		return false, errors.Wrapf(err, "could not read response from %s", h.url)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return false, errors.Errorf("status page %s returned code %d", h.url, resp.StatusCode)
+	}
+
+	if h.regex != nil {
+		return h.regex.Match(body), nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return false, errors.Wrapf(err, "status page %s did not return valid JSON", h.url)
+	}
+
+	value, err := lookup(doc, h.jsonPath)
+	if err != nil {
+		return false, errors.Wrapf(err, "status page %s", h.url)
+	}
+
+	return h.isOutageValue(value), nil
+}
+
+// isOutageValue returns true if value indicates an outage. If no outage
+// values are configured, only a boolean true indicates an outage
+func (h *HTTP) isOutageValue(value interface{}) bool {
+	if len(h.outageValues) == 0 {
+		b, ok := value.(bool)
+		return ok && b
+	}
+
+	s := fmt.Sprint(value)
+	for _, v := range h.outageValues {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookup returns the value at path in a decoded JSON document. Path elements
+// that are integers index into arrays
+func lookup(doc interface{}, path []string) (interface{}, error) {
+	current := doc
+	for i, elem := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[elem]
+			if !ok {
+				return nil, errors.Errorf("no field at %s", strings.Join(path[:i+1], "."))
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(elem)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, errors.Errorf("no element at %s", strings.Join(path[:i+1], "."))
+			}
+			current = node[index]
+		default:
+			return nil, errors.Errorf("no field at %s", strings.Join(path[:i+1], "."))
+		}
+	}
+
+	return current, nil
+}
+
+// cached returns the cached result, if there is one that has not expired
+func (h *HTTP) cached() (outage bool, ok bool) {
+	if h.cacheTTL <= 0 {
+		return false, false
+	}
+
+	f, err := os.Open(h.cachePath)
+	if err != nil {
+		return false, false
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	// A result that another user could have written would let them turn off
+	// the outage check
+	info, err := f.Stat()
+	if err != nil || !private(info) {
+		log.Printf("WARNING: ignoring outage cache %s, which is not private to the current user", h.cachePath)
+		return false, false
+	}
+
+	contents, err := ioutil.ReadAll(io.LimitReader(f, maxBodySize))
+	if err != nil {
+		return false, false
+	}
+
+	var entry httpCacheEntry
+	if err := json.Unmarshal(contents, &entry); err != nil {
+		return false, false
+	}
+
+	age := h.now().Sub(entry.CheckedAt)
+	if entry.URL != h.url || entry.Settings != h.settings || age < 0 || age >= h.cacheTTL {
+		return false, false
+	}
+
+	return entry.Outage, true
+}
+
+// store writes the result to the cache file. Failures are only logged, since
+// they only mean that the next invocation checks the status page again
+func (h *HTTP) store(outage bool) {
+	if h.cacheTTL <= 0 {
+		return
+	}
+
+	contents, err := json.Marshal(httpCacheEntry{URL: h.url, Settings: h.settings, Outage: outage, CheckedAt: h.now()})
+	if err != nil {
+		log.Printf("WARNING: could not encode outage cache: %v", err)
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(h.cachePath), 0700)
+	if err != nil {
+		log.Printf("WARNING: could not write outage cache: %v", err)
+		return
+	}
+
+	// Write to a temporary file and rename, so that concurrent invocations
+	// never read a partial file
+	tmp, err := ioutil.TempFile(filepath.Dir(h.cachePath), filepath.Base(h.cachePath))
+	if err != nil {
+		log.Printf("WARNING: could not write outage cache: %v", err)
+		return
+	}
+
+	_, err = tmp.Write(contents)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), h.cachePath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		log.Printf("WARNING: could not write outage cache: %v", err)
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+)
+
+func TestHTTPOutage(t *testing.T) {
+	tests := []struct {
+		body     string
+		status   int
+		settings map[string]interface{}
+		outage   bool
+	}{
+		// statuspage.io style indicator
+		{`{"status": {"indicator": "major"}}`, 200, map[string]interface{}{
+			param.HTTPOutageJSONPath:     "status.indicator",
+			param.HTTPOutageOutageValues: []string{"major", "critical"},
+		}, true},
+		{`{"status": {"indicator": "none"}}`, 200, map[string]interface{}{
+			param.HTTPOutageJSONPath:     "status.indicator",
+			param.HTTPOutageOutageValues: []string{"major", "critical"},
+		}, false},
+
+		// boolean field, with array indexing
+		{`{"incidents": [{"active": true}]}`, 200, map[string]interface{}{
+			param.HTTPOutageJSONPath: "incidents.0.active",
+		}, true},
+		{`{"incidents": [{"active": false}]}`, 200, map[string]interface{}{
+			param.HTTPOutageJSONPath: "incidents.0.active",
+		}, false},
+
+		// regex
+		{"All Systems Operational", 200, map[string]interface{}{
+			param.HTTPOutageRegex: "(?i)outage|degraded",
+		}, false},
+		{"Partial Outage", 200, map[string]interface{}{
+			param.HTTPOutageRegex: "(?i)outage|degraded",
+		}, true},
+
+		// failures are treated as an outage
+		{`{"status": {"indicator": "none"}}`, 503, map[string]interface{}{
+			param.HTTPOutageJSONPath:     "status.indicator",
+			param.HTTPOutageOutageValues: []string{"major"},
+		}, true},
+		{`not json`, 200, map[string]interface{}{
+			param.HTTPOutageJSONPath:     "status.indicator",
+			param.HTTPOutageOutageValues: []string{"major"},
+		}, true},
+		{`{"status": {}}`, 200, map[string]interface{}{
+			param.HTTPOutageJSONPath:     "status.indicator",
+			param.HTTPOutageOutageValues: []string{"major"},
+		}, true},
+	}
+
+	for i, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			fmt.Fprint(w, tt.body)
+		}))
+
+		cfg := config.Defaults()
+		cfg.Set(param.HTTPOutageURL, srv.URL)
+		cfg.Set(param.HTTPOutageCacheTTL, "0s")
+		for k, v := range tt.settings {
+			cfg.Set(k, v)
+		}
+
+		h, err := NewHTTP(cfg)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+
+		outage, err := h.Outage()
+		srv.Close()
+		if err != nil {
+			t.Errorf("test %d: %v", i, err)
+		}
+
+		if outage != tt.outage {
+			t.Errorf("test %d: body=%s status=%d: got outage=%t, want %t", i, tt.body, tt.status, outage, tt.outage)
+		}
+	}
+}
+
+func TestHTTPOutageUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	cfg := config.Defaults()
+	cfg.Set(param.HTTPOutageURL, srv.URL)
+	cfg.Set(param.HTTPOutageRegex, "outage")
+	cfg.Set(param.HTTPOutageTimeout, "10ms")
+	cfg.Set(param.HTTPOutageCacheTTL, "0s")
+
+	h, err := NewHTTP(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outage, err := h.Outage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !outage {
+		t.Error("expected a timeout to be treated as an outage")
+	}
+}
+
+func TestHTTPOutageCache(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, "Major Outage")
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "cm-outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := config.Defaults()
+	cfg.Set(param.HTTPOutageURL, srv.URL)
+	cfg.Set(param.HTTPOutageRegex, "Outage")
+	cfg.Set(param.HTTPOutageCacheTTL, "60s")
+	cfg.Set(param.HTTPOutageCachePath, filepath.Join(dir, "cache.json"))
+
+	now := time.Date(2016, time.November, 8, 14, 3, 0, 0, time.UTC)
+
+	// Each checker simulates a separate terminate invocation
+	check := func(at time.Time) bool {
+		h, err := NewHTTP(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.now = func() time.Time { return at }
+
+		outage, err := h.Outage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return outage
+	}
+
+	for _, offset := range []time.Duration{0, 10 * time.Second, 59 * time.Second} {
+		if !check(now.Add(offset)) {
+			t.Errorf("offset=%s: expected outage", offset)
+		}
+	}
+
+	if got, want := calls, 1; got != want {
+		t.Errorf("got %d calls within cache ttl, want %d", got, want)
+	}
+
+	check(now.Add(61 * time.Second))
+	if got, want := calls, 2; got != want {
+		t.Errorf("got %d calls after cache ttl, want %d", got, want)
+	}
+}
+
+// TestHTTPOutageCacheSettings verifies that checkers of the same status page
+// with different settings do not share cached results
+func TestHTTPOutageCacheSettings(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"status": "Major Outage"}`)
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "cm-outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newChecker := func(settings map[string]interface{}) *HTTP {
+		cfg := config.Defaults()
+		cfg.Set(param.HTTPOutageURL, srv.URL)
+		cfg.Set(param.HTTPOutageCacheTTL, "60s")
+		for k, v := range settings {
+			cfg.Set(k, v)
+		}
+
+		h, err := NewHTTP(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	byRegex := map[string]interface{}{param.HTTPOutageRegex: "Outage"}
+	byOtherRegex := map[string]interface{}{param.HTTPOutageRegex: "Degraded"}
+	byJSONPath := map[string]interface{}{param.HTTPOutageJSONPath: "status", param.HTTPOutageOutageValues: []string{"Degraded"}}
+
+	// The default cache files differ
+	paths := make(map[string]bool)
+	for _, settings := range []map[string]interface{}{byRegex, byOtherRegex, byJSONPath} {
+		paths[newChecker(settings).cachePath] = true
+	}
+	if len(paths) != 3 {
+		t.Errorf("got %d default cache paths for three settings, want 3: %v", len(paths), paths)
+	}
+
+	// Checkers that share a cache file do not use each other's results
+	cachePath := filepath.Join(dir, "cache.json")
+	for _, tt := range []struct {
+		settings map[string]interface{}
+		want     bool
+	}{
+		{byRegex, true},
+		{byOtherRegex, false},
+		{byJSONPath, false},
+		{byJSONPath, false},
+	} {
+		tt.settings[param.HTTPOutageCachePath] = cachePath
+		outage, err := newChecker(tt.settings).Outage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if outage != tt.want {
+			t.Errorf("%v: got outage=%t, want %t", tt.settings, outage, tt.want)
+		}
+	}
+
+	// Only the last check was cached
+	if got, want := calls, 3; got != want {
+		t.Errorf("got %d calls, want %d", got, want)
+	}
+}
+
+// TestHTTPOutageCacheDefaultPath verifies that the cache is in a private
+// directory of the user's cache directory by default
+func TestHTTPOutageCacheDefaultPath(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the cache directory is only set by XDG_CACHE_HOME on linux")
+	}
+
+	dir, err := ioutil.TempDir("", "cm-outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "All Systems Operational")
+	}))
+	defer srv.Close()
+
+	cfg := config.Defaults()
+	cfg.Set(param.HTTPOutageURL, srv.URL)
+	cfg.Set(param.HTTPOutageRegex, "Outage")
+
+	h, err := NewHTTP(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := filepath.Dir(h.cachePath), filepath.Join(dir, "chaosmonkey"); got != want {
+		t.Errorf("got cache in %s, want %s", got, want)
+	}
+
+	if _, err := h.Outage(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Dir(h.cachePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("got cache directory mode %o, want 700", perm)
+	}
+
+	if _, ok := h.cached(); !ok {
+		t.Error("result was not cached")
+	}
+}
+
+// TestHTTPOutageCacheNotPrivate verifies that a cache file that other users
+// could have written is ignored
+func TestHTTPOutageCacheNotPrivate(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, "Major Outage")
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "cm-outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := config.Defaults()
+	cfg.Set(param.HTTPOutageURL, srv.URL)
+	cfg.Set(param.HTTPOutageRegex, "Outage")
+	cfg.Set(param.HTTPOutageCachePath, filepath.Join(dir, "cache.json"))
+
+	h, err := NewHTTP(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A planted result that says there is no outage
+	h.store(false)
+	if _, ok := h.cached(); !ok {
+		t.Fatal("result was not cached")
+	}
+
+	err = os.Chmod(h.cachePath, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outage, err := h.Outage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !outage || calls != 1 {
+		t.Errorf("got outage=%t after %d calls, want the status page to be checked", outage, calls)
+	}
+
+	// The file of another user, which only root can create
+	if os.Getuid() != 0 {
+		return
+	}
+
+	h.store(false)
+	err = os.Chown(h.cachePath, 65534, 65534)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := h.cached(); ok {
+		t.Error("cache file of another user was used")
+	}
+}
+
+func TestHTTPOutageConfigErrors(t *testing.T) {
+	tests := []map[string]interface{}{
+		{param.HTTPOutageURL: ""},
+		{},
+		{param.HTTPOutageJSONPath: "status", param.HTTPOutageRegex: "outage"},
+		{param.HTTPOutageRegex: "("},
+	}
+
+	for _, settings := range tests {
+		cfg := config.Defaults()
+		cfg.Set(param.OutageChecker, "http")
+		cfg.Set(param.HTTPOutageURL, "http://status.example.com")
+		for k, v := range settings {
+			cfg.Set(k, v)
+		}
+
+		if _, err := GetOutage(cfg); err == nil {
+			t.Errorf("%v: expected error", settings)
+		}
+	}
+}
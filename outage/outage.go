@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package outage provides a default no-op outage implementation
+// Package outage provides outage checker implementations
 package outage
 
 import (
@@ -34,11 +34,18 @@ func init() {
 	deps.GetOutage = GetOutage
 }
 
-// GetOutage returns a do-nothing outage checker
+// GetOutage returns the outage checker specified in the config, or a
+// do-nothing outage checker if none is specified
 func GetOutage(cfg *config.Monkey) (chaosmonkey.Outage, error) {
 	checker := cfg.OutageChecker()
-	if checker != "" {
+	switch checker {
+	case "":
+		return NullOutage{}, nil
+	case "http":
+		return NewHTTP(cfg)
+	case "alertmanager":
+		return NewAlertmanager(cfg)
+	default:
 		return nil, errors.Errorf("unknown outage provider: %s", checker)
 	}
-	return NullOutage{}, nil
 }
@@ -0,0 +1,321 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build docker
+// +build docker
+
+package postgres_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	c "github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/mock"
+	"github.com/Netflix/chaosmonkey/postgres"
+)
+
+var endHour = 15 // 3PM
+
+// testSetup returns some values useful for test setup
+func testSetup(t *testing.T) (ins c.Instance, loc *time.Location, appCfg c.AppConfig) {
+	ins = mock.Instance{
+		App:        "myapp",
+		Account:    "prod",
+		Stack:      "mystack",
+		Cluster:    "mycluster",
+		Region:     "us-east-1",
+		ASG:        "myapp-mystack-mycluster-V123",
+		InstanceID: "i-a96a0166",
+	}
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appCfg = c.AppConfig{
+		Enabled:                        true,
+		RegionsAreIndependent:          true,
+		MeanTimeBetweenKillsInWorkDays: 5,
+		MinTimeBetweenKillsInWorkDays:  1,
+		Grouping:                       c.Cluster,
+	}
+
+	return
+}
+
+// TestCheckForbidden verifies check succeeds when there are no previous
+// terminations, and fails if the previous termination is too recent
+func TestCheckForbidden(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ins, loc, appCfg := testSetup(t)
+
+	trm := c.Termination{Instance: ins, Time: time.Now(), Leashed: false}
+
+	// First check should succeed
+	err = p.Check(trm, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Second check should fail
+	err = p.Check(trm, appCfg, endHour, loc)
+	if !postgres.ViolatesMinTime(err) {
+		t.Fatalf("Expected Err.ViolatesMinTime, got %v", err)
+	}
+}
+
+// When we are going to commit an unleashed termination, we only care
+// about unleashed previous terminations
+func TestCheckLeashed(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ins, loc, appCfg := testSetup(t)
+
+	err = p.Check(c.Termination{Instance: ins, Time: time.Now(), Leashed: true}, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.Check(c.Termination{Instance: ins, Time: time.Now(), Leashed: false}, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatalf("Should have allowed an unleashed termination after leashed: %v", err)
+	}
+}
+
+// Check that only one termination is permitted on concurrent attempts
+func TestConcurrentChecks(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ins, loc, appCfg := testSetup(t)
+
+	trm := c.Termination{Instance: ins, Time: time.Now()}
+
+	// Try to check twice. At least one should return an error
+	ch := make(chan error, 2)
+
+	go func() {
+		// We use the "Postgres.CheckWithDelay" method which adds a delay
+		// between reading from the database and writing to it, to increase
+		// the likelihood that the two requests overlap
+		ch <- p.CheckWithDelay(trm, appCfg, endHour, loc, 1*time.Second)
+	}()
+
+	go func() {
+		ch <- p.Check(trm, appCfg, endHour, loc)
+	}()
+
+	var success int
+	for i := 0; i < 2; i++ {
+		err := <-ch
+		switch {
+		case err == nil:
+			success++
+		case postgres.TxDeadlock(err), postgres.ViolatesMinTime(err):
+			// expected for the check that lost
+		default:
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}
+
+	if got, want := success, 1; got != want {
+		t.Errorf("got %d succeses, want: %d", got, want)
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	ins, loc, _ := testSetup(t)
+
+	tests := []struct {
+		desc    string
+		grp     c.Group
+		reg     bool // regions are independent
+		ins     c.Instance
+		allowed bool // true if we can kill this instance after previous
+	}{
+		{"same cluster, should fail", c.Cluster, true, mock.Instance{App: "myapp", Account: "prod", Stack: "mystack", Cluster: "mycluster", Region: "us-east-1"}, false},
+		{"different cluster, should succeed", c.Cluster, true, mock.Instance{App: "myapp", Account: "prod", Stack: "mystack", Cluster: "othercluster", Region: "us-east-1"}, true},
+		{"same stack should fail", c.Stack, true, mock.Instance{App: "myapp", Account: "prod", Stack: "mystack", Cluster: "othercluster", Region: "us-east-1"}, false},
+		{"different stack, should succeed", c.Stack, true, mock.Instance{App: "myapp", Account: "prod", Stack: "otherstack", Cluster: "othercluster", Region: "us-east-1"}, true},
+		{"same app, should fail", c.App, true, mock.Instance{App: "myapp", Account: "prod", Stack: "mystack", Cluster: "othercluster", Region: "us-east-1"}, false},
+		{"different region, should succeed", c.Cluster, true, mock.Instance{App: "myapp", Account: "prod", Stack: "mystack", Cluster: "mycluster", Region: "us-west-2"}, true},
+		{"different region where regions are not independent, should fail", c.Cluster, false, mock.Instance{App: "myapp", Account: "prod", Stack: "mystack", Cluster: "mycluster", Region: "us-west-2"}, false},
+	}
+
+	for _, tt := range tests {
+		err := initDB()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := newPostgres()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := c.AppConfig{
+			Enabled:                        true,
+			RegionsAreIndependent:          tt.reg,
+			MeanTimeBetweenKillsInWorkDays: 1,
+			MinTimeBetweenKillsInWorkDays:  1,
+			Grouping:                       tt.grp,
+		}
+
+		err = p.Check(c.Termination{Instance: ins, Time: time.Now()}, cfg, endHour, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		term := c.Termination{Instance: tt.ins, Time: time.Now()}
+
+		err = p.Check(term, cfg, endHour, loc)
+		if tt.allowed && err != nil {
+			t.Errorf("%s: got p.Check(%#v, %#v) = %+v, expected nil", tt.desc, term, cfg, err)
+		}
+
+		if !tt.allowed && err == nil {
+			t.Errorf("%s: got p.Check(%#v, %#v) = nil, expected error", tt.desc, term, cfg)
+		}
+
+		p.Close()
+	}
+}
+
+// TestRecordOutcome verifies that the outcome is stored with the termination
+// that Check recorded, even if the termination time has sub-microsecond
+// precision
+func TestRecordOutcome(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ins, loc, appCfg := testSetup(t)
+
+	trm := c.Termination{Instance: ins, Time: time.Date(2016, time.November, 16, 17, 0, 0, 700000123, time.UTC)}
+
+	err = p.Check(trm, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.RecordOutcome(trm, c.Outcome{Succeeded: true, TaskID: "01BXQ5TD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := c.Termination{Instance: ins, Time: trm.Time.Add(time.Hour)}
+	if err = p.RecordOutcome(other, c.Outcome{Succeeded: true}); err == nil {
+		t.Error("got nil error recording the outcome of a termination that was never checked")
+	}
+}
+
+// Test that terminations can be retrieved with filters
+func TestTerminations(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ins, loc, appCfg := testSetup(t)
+	appCfg.MinTimeBetweenKillsInWorkDays = 0
+
+	west := ins.(mock.Instance)
+	west.Region = "us-west-2"
+	west.InstanceID = "i-b96a0166"
+
+	day := func(d int) time.Time {
+		return time.Date(2016, time.November, d, 11, 0, 0, 0, loc)
+	}
+
+	for _, trm := range []c.Termination{
+		{Instance: ins, Time: day(14), Leashed: true},
+		{Instance: ins, Time: day(15)},
+		{Instance: west, Time: day(16)},
+	} {
+		if err := p.Check(trm, appCfg, endHour, loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	unleashed := false
+	tests := []struct {
+		filter history.Filter
+		want   []string
+	}{
+		{history.Filter{}, []string{ins.ID(), ins.ID(), "i-b96a0166"}},
+		{history.Filter{App: ins.AppName(), Region: "us-west-2"}, []string{"i-b96a0166"}},
+		{history.Filter{Since: day(15), Until: day(16)}, []string{ins.ID()}},
+		{history.Filter{Leashed: &unleashed}, []string{ins.ID(), "i-b96a0166"}},
+	}
+
+	for _, tt := range tests {
+		terms, err := p.Terminations(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, term := range terms {
+			got = append(got, term.InstanceID)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/migration"
+)
+
+func TestDSN(t *testing.T) {
+	got := dsn("db.example.com", 5432, "chaosmonkey", `it's a s\cret`, "chaosmonkey")
+	want := `host='db.example.com' port='5432' user='chaosmonkey' password='it\'s a s\\cret' dbname='chaosmonkey' timezone='UTC'`
+	if got != want {
+		t.Errorf("got dsn %s, want %s", got, want)
+	}
+
+	// Parameters that are not specified are left to the environment
+	got = dsn("db.example.com", 5432, "chaosmonkey", "", "chaosmonkey")
+	want = `host='db.example.com' port='5432' user='chaosmonkey' dbname='chaosmonkey' timezone='UTC'`
+	if got != want {
+		t.Errorf("got dsn %s, want %s", got, want)
+	}
+}
+
+func TestTxDeadlock(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "40P01"}, true},                               // deadlock_detected
+		{errors.Wrap(&pq.Error{Code: "40001"}, "commit failed"), true}, // serialization_failure
+		{&pq.Error{Code: "23505"}, false},                              // unique_violation
+		{errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		if got := TxDeadlock(tt.err); got != tt.want {
+			t.Errorf("TxDeadlock(%v)=%t, want %t", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestMigrationIDs verifies that the PostgreSQL migrations have the same ids
+// as the MySQL migrations, so that a schema version means the same schema
+func TestMigrationIDs(t *testing.T) {
+	want, err := migration.AssetDir("migration/mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := migration.AssetDir(migrationSource.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(want)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got migrations %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,480 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postgres provides a PostgreSQL-backed store for schedules and
+// terminations
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/rubenv/sql-migrate"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/cal"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/deps"
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/migration"
+	"github.com/Netflix/chaosmonkey/schedstore"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// Postgres represents a PostgreSQL-backed store for schedules and
+// terminations
+type Postgres struct {
+	db        *sql.DB
+	calendars cal.Calendars
+}
+
+// txOptions are the options of every transaction. Like the MySQL store, we
+// need serializable transactions for atomic test & set behavior
+var txOptions = &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+// TxDeadlock returns true if the error is because of a transaction deadlock
+// or a serialization failure. Either way, the transaction was rolled back
+// because it conflicted with a concurrent one, and may be retried
+func TxDeadlock(err error) bool {
+	switch err := errors.Cause(err).(type) {
+	case *pq.Error:
+		// See: https://www.postgresql.org/docs/current/errcodes-appendix.html
+		return err.Code == "40P01" || err.Code == "40001"
+	default:
+		return false
+	}
+}
+
+// ViolatesMinTime returns true if the error violates min time between
+// terminations
+func ViolatesMinTime(err error) bool {
+	_, ok := errors.Cause(err).(chaosmonkey.ErrViolatesMinTime)
+	return ok
+}
+
+// NewFromConfig creates a new Postgres taking config parameters from cfg
+func NewFromConfig(cfg *config.Monkey) (Postgres, error) {
+	if cfg.DatabaseHost() == "" {
+		return Postgres{}, errors.Errorf("%s not specified", param.DatabaseHost)
+	}
+
+	decryptor, err := deps.GetDecryptor(cfg)
+	if err != nil {
+		return Postgres{}, err
+	}
+
+	password, err := decryptor.Decrypt(cfg.DatabaseEncryptedPassword())
+	if err != nil {
+		return Postgres{}, err
+	}
+
+	calendars, err := cal.NewFromConfig(cfg)
+	if err != nil {
+		return Postgres{}, err
+	}
+
+	p, err := New(cfg.DatabaseHost(), cfg.DatabasePort(), cfg.DatabaseUser(), password, cfg.DatabaseName())
+	if err != nil {
+		return Postgres{}, err
+	}
+
+	p.calendars = calendars
+	return p, nil
+}
+
+// New creates a new Postgres
+func New(host string, port int, user string, password string, dbname string) (Postgres, error) {
+	db, err := sql.Open("postgres", dsn(host, port, user, password, dbname))
+	if err != nil {
+		return Postgres{}, errors.Wrap(err, "sql.Open failed")
+	}
+
+	return Postgres{db: db}, nil
+}
+
+// Close closes the underlying sql.DB
+func (p Postgres) Close() error {
+	return p.db.Close()
+}
+
+// dsn returns a PostgreSQL connection string (data source name). Settings
+// that are not specified here, like sslmode, are taken from the standard
+// PG* environment variables
+// See: https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING
+func dsn(host string, port int, user string, password string, dbname string) string {
+	params := []struct{ key, value string }{
+		{"host", host},
+		{"port", fmt.Sprint(port)},
+		{"user", user},
+		{"password", password},
+		{"dbname", dbname},
+		{"timezone", "UTC"}, // Scan'd time.Times should be in UTC time zone
+	}
+
+	var ss []string
+	for _, p := range params {
+		if p.value != "" {
+			ss = append(ss, fmt.Sprintf("%s=%s", p.key, quote(p.value)))
+		}
+	}
+
+	return strings.Join(ss, " ")
+}
+
+// quote quotes a value in a connection string
+func quote(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `'`, `\'`, -1)
+	return "'" + value + "'"
+}
+
+// dateOf returns the date of t in the location of t, in the format of a
+// Postgres DATE
+func dateOf(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// Retrieve retrieves the schedule for the given date
+func (p Postgres) Retrieve(date time.Time) (sched *schedule.Schedule, err error) {
+	rows, err := p.db.Query("SELECT time, app, account, region, stack, cluster, seed FROM schedules WHERE date = $1 ORDER BY id", dateOf(date))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve schedule for %s", date)
+	}
+
+	sched = schedule.New()
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "rows.Close() failed")
+		}
+	}()
+
+	for rows.Next() {
+		var tm time.Time
+		var app, account, region, stack, cluster string
+		var seed sql.NullInt64
+
+		err = rows.Scan(&tm, &app, &account, &region, &stack, &cluster, &seed)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		if seed.Valid {
+			sched.SetSeed(seed.Int64)
+		}
+
+		sched.Add(tm.UTC(), grp.New(app, account, region, stack, cluster))
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "rows.Err() errored")
+	}
+
+	return sched, nil
+}
+
+// Publish publishes the schedule for the given date
+func (p Postgres) Publish(date time.Time, sched *schedule.Schedule) error {
+	return p.PublishWithDelay(date, sched, 0)
+}
+
+// PublishWithDelay publishes the schedule with a delay between checking the schedule
+// exists and writing it. The delay is used only for testing race conditions
+func (p Postgres) PublishWithDelay(date time.Time, sched *schedule.Schedule, delay time.Duration) (err error) {
+	// First, we check to see if there is a schedule present
+	tx, err := p.db.BeginTx(context.Background(), txOptions)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	// We must either commit or rollback at the end. Postgres may only detect
+	// a conflict with a concurrent transaction on commit
+	defer func() {
+		switch err {
+		case nil:
+			err = tx.Commit()
+		case schedstore.ErrAlreadyExists:
+			// We want to return ErrAlreadyExists even if the transaction commit
+			// fails
+			_ = tx.Commit()
+		default:
+			_ = tx.Rollback()
+		}
+	}()
+
+	exists, err := schedExists(tx, date)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return schedstore.ErrAlreadyExists
+	}
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	query := "INSERT INTO schedules (date, time, app, account, region, stack, cluster, seed) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return errors.Wrapf(err, "failed to prepare sql statement: %s", query)
+	}
+
+	// Schedules published without a seed record it as NULL
+	var seed sql.NullInt64
+	seed.Int64, seed.Valid = sched.Seed()
+
+	for _, entry := range sched.Entries() {
+		var app, account, region, stack, cluster string
+		app = entry.Group.App()
+		account = entry.Group.Account()
+		if val, ok := entry.Group.Region(); ok {
+			region = val
+		}
+		if val, ok := entry.Group.Stack(); ok {
+			stack = val
+		}
+		if val, ok := entry.Group.Cluster(); ok {
+			cluster = val
+		}
+
+		_, err = stmt.Exec(dateOf(date), entry.Time.In(time.UTC), app, account, region, stack, cluster, seed)
+		if err != nil {
+			return errors.Wrapf(err, "failed to execute prepared query")
+		}
+	}
+
+	return nil
+}
+
+// schedExists returns true if a schedule has previously been
+// published for this date
+func schedExists(tx *sql.Tx, date time.Time) (bool, error) {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM schedules WHERE date = $1", dateOf(date)).Scan(&count)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check if schedule exists for %s", date)
+	}
+
+	return count > 0, nil
+}
+
+// Check checks if a termination is permitted and, if so, records the
+// termination time on the server
+func (p Postgres) Check(term chaosmonkey.Termination, appCfg chaosmonkey.AppConfig, endHour int, loc *time.Location) error {
+	return p.CheckWithDelay(term, appCfg, endHour, loc, 0)
+}
+
+// CheckWithDelay is the same as Check, but adds a delay between reading and
+// writing to the database (used for testing only)
+func (p Postgres) CheckWithDelay(term chaosmonkey.Termination, appCfg chaosmonkey.AppConfig, endHour int, loc *time.Location, delay time.Duration) (err error) {
+	tx, err := p.db.BeginTx(context.Background(), txOptions)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	defer func() {
+		switch err {
+		case nil:
+			err = tx.Commit()
+		default:
+			_ = tx.Rollback()
+		}
+	}()
+
+	err = respectsMinTimeBetweenKills(tx, term.Time, term, appCfg, p.calendars, endHour, loc)
+	if err != nil {
+		return err
+	}
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	return recordTermination(tx, term)
+}
+
+// respectsMinTimeBetweenKills checks if this termination will respect or
+// violate the min time between kills value. If this termination is too close
+// to the most recent one, this will return an error.
+// If this termination would violate the min time, returns an ErrViolatesMinTime
+func respectsMinTimeBetweenKills(tx *sql.Tx, now time.Time, term chaosmonkey.Termination, appCfg chaosmonkey.AppConfig, calendars cal.Calendars, endHour int, loc *time.Location) (err error) {
+	calendar := calendars.For(term.Instance.AccountName(), term.Instance.RegionName())
+	threshold, err := calendar.NoKillsSince(appCfg.MinTimeBetweenKillsInWorkDays, now, endHour, loc)
+	if err != nil {
+		return err
+	}
+
+	query := "SELECT instance_id, killed_at FROM terminations WHERE app = $1 AND account = $2 AND killed_at >= $3"
+	args := []interface{}{term.Instance.AppName(), term.Instance.AccountName(), threshold.In(time.UTC)}
+
+	// and adds a condition on column to the query
+	and := func(column string, value interface{}) {
+		args = append(args, value)
+		query += fmt.Sprintf(" AND %s = $%d", column, len(args))
+	}
+
+	switch appCfg.Grouping {
+	case chaosmonkey.App:
+		// nothing to do
+	case chaosmonkey.Stack:
+		and("stack", term.Instance.StackName())
+	case chaosmonkey.Cluster:
+		and("cluster", term.Instance.ClusterName())
+	default:
+		return errors.Errorf("unknown group: %v", appCfg.Grouping)
+	}
+
+	if appCfg.RegionsAreIndependent {
+		and("region", term.Instance.RegionName())
+	}
+
+	// For unleashed (real) terminations, we only care about previous
+	// terminations that were also unleashed. That's because a previous
+	// leashed termination wasn't a real one, so that wouldn't violate
+	// the min time between terminations
+	if !term.Leashed {
+		query += " AND leashed = FALSE"
+	}
+
+	// We need at most one entry
+	query += " LIMIT 1"
+
+	var instanceID string
+	var killedAt time.Time
+	err = tx.QueryRow(query, args...).Scan(&instanceID, &killedAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil
+	case err != nil:
+		return errors.Wrap(err, "failed to retrieve previous terminations")
+	}
+
+	return chaosmonkey.ErrViolatesMinTime{InstanceID: instanceID, KilledAt: killedAt, Loc: loc}
+}
+
+func recordTermination(tx *sql.Tx, term chaosmonkey.Termination) error {
+	i := term.Instance
+
+	_, err := tx.Exec("INSERT INTO terminations (app, account, stack, cluster, region, asg, instance_id, killed_at, leashed) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		i.AppName(), i.AccountName(), i.StackName(), i.ClusterName(), i.RegionName(), i.ASGName(), i.ID(), term.Time.In(time.UTC), term.Leashed)
+
+	return err
+}
+
+// Termination statuses recorded by RecordOutcome
+const (
+	statusSucceeded = "succeeded"
+	statusFailed    = "failed"
+)
+
+// RecordOutcome implements chaosmonkey.OutcomeRecorder.RecordOutcome
+// It stores the status and task id with the termination recorded by Check
+func (p Postgres) RecordOutcome(term chaosmonkey.Termination, outcome chaosmonkey.Outcome) error {
+	status := statusFailed
+	if outcome.Succeeded {
+		status = statusSucceeded
+	}
+
+	// killed_at has microsecond precision, and the time is rounded the same
+	// way here as it was when the termination was recorded
+	i := term.Instance
+	result, err := p.db.Exec("UPDATE terminations SET status = $1, task_id = $2 WHERE app = $3 AND account = $4 AND instance_id = $5 AND killed_at = $6",
+		status, outcome.TaskID, i.AppName(), i.AccountName(), i.ID(), term.Time.In(time.UTC))
+	if err != nil {
+		return errors.Wrap(err, "failed to record termination outcome")
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to record termination outcome")
+	}
+
+	if n == 0 {
+		return errors.Errorf("no termination of %s at %s to record outcome of", i.ID(), term.Time.In(time.UTC))
+	}
+
+	return nil
+}
+
+// Terminations implements history.Store.Terminations
+func (p Postgres) Terminations(f history.Filter) (terms []history.Termination, err error) {
+	query := "SELECT app, account, region, stack, cluster, asg, instance_id, killed_at, leashed, status, task_id FROM terminations"
+
+	var clauses []string
+	var args []interface{}
+	for i, c := range f.Conditions() {
+		clauses = append(clauses, fmt.Sprintf("%s %s $%d", c.Column, c.Op, i+1))
+		args = append(args, c.Value)
+	}
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY killed_at"
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve terminations")
+	}
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "rows.Close() failed")
+		}
+	}()
+
+	for rows.Next() {
+		var t history.Termination
+		err = rows.Scan(&t.App, &t.Account, &t.Region, &t.Stack, &t.Cluster, &t.ASG, &t.InstanceID, &t.KilledAt, &t.Leashed, &t.Status, &t.TaskID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		terms = append(terms, t)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "rows.Err() errored")
+	}
+
+	return terms, nil
+}
+
+var migrationSource = &migrate.AssetMigrationSource{
+	Asset:    migration.Asset,
+	AssetDir: migration.AssetDir,
+	Dir:      "migration/postgres",
+}
+
+var databaseDialect = "postgres"
+
+// Migrate upgrades a database to the latest database schema version.
+func Migrate(db Postgres) error {
+	migrationCount, err := migrate.Exec(db.db, databaseDialect, migrationSource, migrate.Up)
+	if err != nil {
+		return errors.Wrap(err, "database migration failed")
+	}
+	log.Println("Successfully applied database migrations. Number of migrations applied: ", migrationCount)
+
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build docker
+// +build docker
+
+// The tests in this package use docker to test against a postgres:13 database
+// By default, the tests are off unless you pass the "-tags docker" flag
+// when running the test.
+//
+// By default, TestMain starts up a new postgres Docker container. However, if
+// you already have a postgres docker container running, you can skip this by
+// also passing the "dockerup" flag: -tags "docker dockerup"
+
+package postgres_test
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/postgres"
+)
+
+var (
+	dbName   = "chaosmonkey"
+	password = "password"
+	port     = 5432
+)
+
+// inUse returns true if port accepts connections on localhost
+func inUse(port int) bool {
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return false
+	}
+
+	conn.Close()
+	return true
+}
+
+func TestMain(m *testing.M) {
+	var alwaysUp bool
+	flag.BoolVar(&alwaysUp, "dockerup", false, "if true, won't start docker")
+	flag.Parse()
+
+	// The container does not use SSL
+	os.Setenv("PGSSLMODE", "disable")
+
+	var cmd *exec.Cmd
+	var err error
+
+	if !alwaysUp {
+		// Check to make sure the port isn't already in use
+		if inUse(port) {
+			panic(fmt.Sprintf("can't start postgres container: port %d currently in use", port))
+		}
+		cmd, err = startPostgresContainer()
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err = createDB()
+	if err != nil {
+		panic(err)
+	}
+
+	r := m.Run()
+
+	if !alwaysUp {
+		// Send a SIGTERM once we're done so postgres container shuts down
+		cmd.Process.Signal(syscall.SIGTERM)
+
+		// Wait for container to finish shutting down
+		cmd.Wait()
+	}
+
+	os.Exit(r)
+}
+
+// startPostgresContainer starts a Postgres docker container, and waits until
+// it accepts connections
+// Returns the Cmd object associated with the process
+func startPostgresContainer() (*exec.Cmd, error) {
+	cmd := exec.Command("docker", "run", "-e", "POSTGRES_PASSWORD="+password, fmt.Sprintf("-p%d:5432", port), "postgres:13")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("postgres", fmt.Sprintf("host=127.0.0.1 port=%d user=postgres password=%s dbname=postgres", port, password))
+	if err != nil {
+		return nil, errors.Wrap(err, "sql.Open failed")
+	}
+	defer db.Close()
+
+	deadline := time.Now().Add(30 * time.Second)
+	for db.Ping() != nil {
+		if time.Now().After(deadline) {
+			return nil, errors.New("postgres never accepted connections (postgres container needs manual cleanup)")
+		}
+		time.Sleep(time.Second)
+	}
+
+	return cmd, nil
+}
+
+// createDB creates the "chaosmonkey" database, if it does not exist
+func createDB() error {
+	db, err := sql.Open("postgres", fmt.Sprintf("host=127.0.0.1 port=%d user=postgres password=%s dbname=postgres", port, password))
+	if err != nil {
+		return errors.Wrap(err, "sql.Open failed")
+	}
+	defer db.Close()
+
+	var exists bool
+	err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", dbName).Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if database exists")
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err = db.Exec("CREATE DATABASE " + dbName)
+	return errors.Wrap(err, "create database failed")
+}
+
+// initDB initializes the "chaosmonkey" database with the chaosmonkey schemas
+// It wipes out any existing tables. Unlike MySQL, Postgres does not drop
+// databases that have open connections, so the schema is dropped instead
+func initDB() error {
+	db, err := sql.Open("postgres", fmt.Sprintf("host=127.0.0.1 port=%d user=postgres password=%s dbname=%s", port, password, dbName))
+	if err != nil {
+		return errors.Wrap(err, "sql.Open failed")
+	}
+	defer db.Close()
+
+	_, err = db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public")
+	if err != nil {
+		return errors.Wrap(err, "recreate schema failed")
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		return errors.Wrap(err, "postgres.New failed")
+	}
+	defer p.Close()
+
+	err = postgres.Migrate(p)
+	if err != nil {
+		return errors.Wrap(err, "database migration failed")
+	}
+
+	return nil
+}
+
+func newPostgres() (postgres.Postgres, error) {
+	return postgres.New("localhost", port, "postgres", password, dbName)
+}
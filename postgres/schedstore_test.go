@@ -0,0 +1,208 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build docker
+// +build docker
+
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/postgres"
+	"github.com/Netflix/chaosmonkey/schedstore"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// testEntries returns the entries of a schedule on June 20, 2016
+func testEntries(loc *time.Location) []schedule.Entry {
+	return []schedule.Entry{
+		{Time: time.Date(2016, time.June, 20, 11, 40, 0, 0, loc), Group: grp.New("doesnotexist", "test", "us-east-1", "", "doesnotexist-foo-bar")},
+		{Time: time.Date(2016, time.June, 20, 12, 35, 0, 0, loc), Group: grp.New("foobar", "other", "us-west-2", "", "foobar-baz-quux")},
+		{Time: time.Date(2016, time.June, 20, 9, 7, 0, 0, loc), Group: grp.New("chaosguineapig", "prod", "us-east-1", "", "chaosguineapig-prod")},
+	}
+}
+
+// Test we can publish and then retrieve a schedule, and that only the
+// schedule of the requested day is retrieved
+func TestPublishRetrieve(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	// Dates are in a time zone where the UTC date differs in the evening
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pEntries := testEntries(loc)
+	psched := schedule.New()
+	psched.SetSeed(42)
+	for _, v := range pEntries {
+		psched.Add(v.Time, v.Group)
+	}
+
+	date := time.Date(2016, time.June, 20, 0, 0, 0, 0, loc)
+
+	next := schedule.New()
+	next.Add(time.Date(2016, time.June, 21, 17, 0, 0, 0, loc), grp.New("imaginaryproject", "test", "us-west-2", "", ""))
+
+	// Code under test:
+	err = p.Publish(date, psched)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.Publish(date.AddDate(0, 0, 1), next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsched, err := p.Retrieve(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seed, ok := rsched.Seed(); !ok || seed != 42 {
+		t.Errorf("got seed=%d, ok=%t, want seed=42, ok=true", seed, ok)
+	}
+
+	rEntries := rsched.Entries()
+	if got, want := len(rEntries), len(pEntries); got != want {
+		t.Fatalf("got len(entries)=%d, want %d", got, want)
+	}
+
+	for i := range pEntries {
+		if got, want := rEntries[i], pEntries[i]; !got.Equal(&want) {
+			t.Errorf("got entry[%d]=%v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestScheduleAlreadyExists(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	psched1 := schedule.New()
+	psched1.Add(time.Date(2016, time.June, 20, 11, 40, 0, 0, loc), grp.New("imaginaryproject", "test", "us-west-2", "", ""))
+
+	date := time.Date(2016, time.June, 20, 0, 0, 0, 0, loc)
+
+	// Create an initial schedule with one entry
+	err = p.Publish(date, psched1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Try to publish a new schedule
+	psched2 := schedule.New()
+	for _, v := range testEntries(loc) {
+		psched2.Add(v.Time, v.Group)
+	}
+
+	err = p.Publish(date, psched2)
+
+	// This should return an error
+	if got, want := err, schedstore.ErrAlreadyExists; got != want {
+		t.Fatalf(`got p.Publish()="%v" want "%v"`, got, want)
+	}
+}
+
+func TestScheduleAlreadyExistsConcurrency(t *testing.T) {
+	err := initDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPostgres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	psched1 := schedule.New()
+	psched1.Add(time.Date(2016, time.June, 20, 11, 40, 0, 0, loc), grp.New("imaginaryproject", "test", "us-west-2", "", ""))
+
+	psched2 := schedule.New()
+	for _, v := range testEntries(loc) {
+		psched2.Add(v.Time, v.Group)
+	}
+
+	// Try to publish the schedule twice. At least one schedule should return an
+	// error
+	ch := make(chan error, 2)
+
+	date := time.Date(2016, time.June, 20, 0, 0, 0, 0, loc)
+
+	go func() {
+		ch <- p.PublishWithDelay(date, psched1, 3*time.Second)
+	}()
+
+	go func() {
+		ch <- p.PublishWithDelay(date, psched2, 0)
+	}()
+
+	// Retrieve the two error values from the two calls
+
+	var success int
+	var txDeadlock int
+	for i := 0; i < 2; i++ {
+		err := <-ch
+		switch {
+		case err == nil:
+			success++
+		case postgres.TxDeadlock(err):
+			txDeadlock++
+		default:
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}
+
+	if got, want := success, 1; got != want {
+		t.Errorf("got %d succeses, want: %d", got, want)
+	}
+
+	// Should cause a serialization failure
+	if got, want := txDeadlock, 1; got != want {
+		t.Errorf("got %d txDeadlock, want: %d", got, want)
+	}
+}
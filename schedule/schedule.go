@@ -19,21 +19,53 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"math/rand"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/cal"
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/deploy"
 	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/metrics"
 )
 
 // Populate populates the termination schedule with the random
 // terminations for a list of apps. If the specified list of apps is empty,
 // then it will
+//
+// The schedule is generated with a random seed, which is recorded in the
+// schedule
 func (s *Schedule) Populate(d deploy.Deployment, getter chaosmonkey.AppConfigGetter, chaosConfig *config.Monkey, apps []string) error {
+	return s.PopulateWithSeed(d, getter, chaosConfig, apps, time.Now().UnixNano())
+}
+
+// PopulateWithSeed is like Populate, but generates the schedule with seed.
+// Given the same deployment, configs and day, the same seed generates the
+// same schedule
+func (s *Schedule) PopulateWithSeed(d deploy.Deployment, getter chaosmonkey.AppConfigGetter, chaosConfig *config.Monkey, apps []string, seed int64) error {
+	return s.populate(d, getter, chaosConfig, apps, time.Now(), seed)
+}
+
+// PopulateAt is like PopulateWithSeed, but generates the schedule that the
+// scheduler would generate if it ran at now. It is used to simulate the
+// schedules of future days
+func (s *Schedule) PopulateAt(d deploy.Deployment, getter chaosmonkey.AppConfigGetter, chaosConfig *config.Monkey, apps []string, now time.Time, seed int64) error {
+	return s.populate(d, getter, chaosConfig, apps, now, seed)
+}
+
+// populate implements PopulateWithSeed, scheduling the working window of
+// each instance group that follows now
+//
+// now is passed as an argument to simplify testing
+func (s *Schedule) populate(d deploy.Deployment, getter chaosmonkey.AppConfigGetter, chaosConfig *config.Monkey, apps []string, now time.Time, seed int64) error {
+	log.Printf("generating schedule with seed=%d", seed)
+	s.SetSeed(seed)
+
 	c := make(chan *deploy.App)
 
 	// If the caller explicitly a set of apps, use those
@@ -46,24 +78,64 @@ func (s *Schedule) Populate(d deploy.Deployment, getter chaosmonkey.AppConfigGet
 		}
 	}
 
+	calendars, err := cal.NewFromConfig(chaosConfig)
+	if err != nil {
+		return err
+	}
+
+	// Apps are retrieved concurrently, so the apps that make the cut are
+	// chosen by name rather than in the order in which they arrive. That
+	// way, the same seed picks the same apps
+	var limited map[string]bool
+	if max := chaosConfig.MaxApps(); len(apps) > max {
+		if max < 0 {
+			max = 0
+		}
+		sorted := append([]string(nil), apps...)
+		sort.Strings(sorted)
+		apps = sorted[:max]
+
+		limited = make(map[string]bool)
+		for _, name := range apps {
+			limited[name] = true
+		}
+	}
+
 	go d.Apps(c, apps)
-	i := 0 // number of apps already processed
 	for app := range c {
-		if i >= chaosConfig.MaxApps() {
-			break
+		if limited != nil && !limited[app.Name()] {
+			continue
 		}
 
-		i++
+		// If configs can differ between accounts, schedule each account of
+		// the app with its own config
+		if ag, ok := getter.(chaosmonkey.AccountAppConfigGetter); ok {
+			for _, account := range app.Accounts() {
+				cfg, err := ag.GetAccount(app.Name(), account.Name())
+				if err != nil {
+					log.Printf("WARNING: Could not retrieve config for app=%s account=%s. %s", app.Name(), account.Name(), err)
+					metrics.ScheduleEvents.Inc(metrics.Failed, app.Name(), account.Name(), "")
+					continue
+				}
+				doScheduleApp(s, app, account.Name(), *cfg, chaosConfig, calendars, now, seed)
+			}
+			continue
+		}
 
 		cfg, err := getter.Get(app.Name())
 
 		if err != nil {
 			log.Printf("WARNING: Could not retrieve config for app=%s. %s", app.Name(), err)
+			metrics.ScheduleEvents.Inc(metrics.Failed, app.Name(), "", "")
 			continue
 		}
-		doScheduleApp(s, app, *cfg, chaosConfig)
+		doScheduleApp(s, app, "", *cfg, chaosConfig, calendars, now, seed)
 	}
 
+	// Apps are retrieved concurrently, so the order of the entries is made
+	// independent of the order in which apps were scheduled
+	sort.Sort(byTimeAndGroup(s.entries))
+
 	return nil
 }
 
@@ -77,67 +149,168 @@ func (s *Schedule) Entries() []Entry {
 	return s.entries
 }
 
-// doScheduleApp populates the termination schedule for one app
-func doScheduleApp(schedule *Schedule, app *deploy.App, cfg chaosmonkey.AppConfig, chaosConfig *config.Monkey) {
+// Seed returns the seed that the schedule was generated with. ok is false if
+// the seed is not known, e.g. for a schedule that was published before seeds
+// were recorded
+func (s *Schedule) Seed() (seed int64, ok bool) {
+	return s.seed, s.seeded
+}
+
+// SetSeed records the seed that the schedule was generated with
+func (s *Schedule) SetSeed(seed int64) {
+	s.seed = seed
+	s.seeded = true
+}
+
+// doScheduleApp populates the termination schedule for one app. If account
+// is not blank, only the instance groups in that account are scheduled.
+// Each group is scheduled within the rest of its next working window, in the
+// business hours of its account and region. Groups whose next working window
+// is on a holiday, or does not start within a day of now, are not scheduled
+//
+// The random decisions for each group are drawn from a source derived from
+// seed and the group, so that they do not depend on the other apps
+func doScheduleApp(schedule *Schedule, app *deploy.App, account string, cfg chaosmonkey.AppConfig, chaosConfig *config.Monkey, calendars cal.Calendars, now time.Time, seed int64) {
 
 	if !cfg.Enabled {
-		log.Printf("app=%s disabled\n", app.Name())
+		if account == "" {
+			log.Printf("app=%s disabled\n", app.Name())
+		} else {
+			log.Printf("app=%s account=%s disabled\n", app.Name(), account)
+		}
+		metrics.ScheduleEvents.Inc(metrics.Disabled, app.Name(), account, "")
 		return
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	startHour := chaosConfig.StartHour()
-	endHour := chaosConfig.EndHour()
-	location, err := chaosConfig.Location()
-
-	if err != nil {
-		panic(fmt.Sprintf("Could not get Location for time zone calculation: %s", err.Error()))
-	}
+	start := time.Now()
+	defer func() {
+		metrics.ScheduleDuration.Observe(time.Since(start).Seconds(), app.Name())
+	}()
 
 	groups := app.EligibleInstanceGroups(cfg)
+	if account != "" {
+		groups = inAccount(groups, account)
+	}
 
 	if len(groups) == 0 {
 		log.Printf("app=%s no eligible instance groups", app.Name())
 	}
 
 	for _, group := range groups {
+		region, _ := group.Region()
+		hours, err := chaosConfig.BusinessHours(group.Account(), region)
+		if err != nil {
+			log.Printf("WARNING: %s not scheduled: %v", grp.String(group), err)
+			metrics.ScheduleEvents.Inc(metrics.Failed, app.Name(), group.Account(), region)
+			continue
+		}
+
+		windowStart, windowEnd, ok := nextWorkingWindow(now, hours, calendars.For(group.Account(), region))
+		if !ok {
+			log.Printf("%s not scheduled, not a work day\n", grp.String(group))
+			metrics.ScheduleEvents.Inc(metrics.Holiday, app.Name(), group.Account(), region)
+			continue
+		}
+
+		r := groupRand(seed, group)
 		kill := shouldKillInstance(cfg.MeanTimeBetweenKillsInWorkDays, r)
 		log.Printf("%s mtbk=%d kill=%t\n", grp.String(group), cfg.MeanTimeBetweenKillsInWorkDays, kill)
 		if kill {
-			time := chooseTerminationTime(time.Now(), startHour, endHour, location)
+			time := chooseTerminationTime(windowStart, windowEnd, r)
 			schedule.Add(time, group)
+
+			metrics.ScheduleEvents.Inc(metrics.Picked, app.Name(), group.Account(), region)
 		}
 	}
 }
 
-// chooseTerminationTime Randomly selects a time to terminate an instance
-// on the same date as now, between startHour:00 and endHour:00 in the same
-// timezone as location
-// Panics if endHour <= startHour
-//
-// Note that there is no guarantee that the selected termination time will be in
-// the future
+// groupRand returns the source of the random decisions for group in the
+// schedule generated with seed
+func groupRand(seed int64, group grp.InstanceGroup) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(grp.String(group)))
+	return rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+}
+
+// nextWorkingWindow returns the part after now of the first working window,
+// in the time zone of hours, that ends after now. If now is within the
+// window, the part starts at the next whole minute. Returns false if the
+// window is not on a work day of calendar, or does not start within a day of
+// now, since the next run of the scheduler schedules it.
 //
-// now is passed as an argument to simplify testing
-func chooseTerminationTime(now time.Time, startHour int, endHour int, location *time.Location) time.Time {
-	if endHour <= startHour {
-		panic(fmt.Sprintf("ChooseTermination called with startHour <= endHour, startHour: %d. endHour: %d", startHour, endHour))
+// Only the first window that ends after now is considered, so that a
+// scheduler that runs once a day schedules each window once: a holiday is
+// not replaced by the next work day, which the next run schedules
+func nextWorkingWindow(now time.Time, hours config.BusinessHours, calendar cal.Calendar) (start, end time.Time, ok bool) {
+	year, month, day := now.In(hours.Location).Date()
+
+	// Today's window, or tomorrow's if today's has ended
+	start = time.Date(year, month, day, hours.StartHour, 0, 0, 0, hours.Location)
+	end = time.Date(year, month, day, hours.EndHour, 0, 0, 0, hours.Location)
+	if !end.After(now) {
+		day++
+		start = time.Date(year, month, day, hours.StartHour, 0, 0, 0, hours.Location)
+		end = time.Date(year, month, day, hours.EndHour, 0, 0, 0, hours.Location)
+	}
+
+	if !start.Before(now.Add(24 * time.Hour)) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	noon := time.Date(year, month, day, 12, 0, 0, 0, hours.Location)
+	if !calendar.IsWorkday(noon) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	if start.Before(now) {
+		start = now.Truncate(time.Minute)
+		if start.Before(now) {
+			start = start.Add(time.Minute)
+		}
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, false
 	}
 
+	return start, end, true
+}
+
+// inAccount returns the groups that are in an account
+func inAccount(groups []grp.InstanceGroup, account string) []grp.InstanceGroup {
+	var result []grp.InstanceGroup
+	for _, group := range groups {
+		if group.Account() == account {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// chooseTerminationTime Randomly selects a time to terminate an instance
+// in [start, end), on a whole number of minutes after start
+// Panics if end is less than a minute after start
+func chooseTerminationTime(start, end time.Time, r intRand) time.Time {
 	// Compute the number of minutes in the interval between start and end,
 	// pick a random one in there, and then add it to the start time as an
 	// offset
-	minutesInTimeInterval := (endHour - startHour) * 60
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	minutesInTimeInterval := int(end.Sub(start) / time.Minute)
+	if minutesInTimeInterval <= 0 {
+		panic(fmt.Sprintf("chooseTerminationTime called with less than a minute between start: %s and end: %s", start, end))
+	}
 	sample := r.Intn(minutesInTimeInterval)
 
 	// Convert the sample to duration in minutes
 	offset := time.Duration(sample) * time.Minute
 
-	year, month, day := now.Date()
-	startTime := time.Date(year, month, day, startHour, 0, 0, 0, location)
+	return start.Add(offset)
+}
+
+// intRand generates random ints
+type intRand interface {
 
-	return startTime.Add(offset)
+	// Return a random int on [0, n)
+	Intn(n int) int
 }
 
 // float64Rand generates random floats on [0, 1)
@@ -227,20 +400,27 @@ func (e *Entry) Crontab(termPath, account string) string {
 // terminateCommand returns the string for terminating an instance
 // given the path to the chaosmonkey termination executable and an instance to terminate
 func terminateCommand(termPath string, group grp.InstanceGroup) string {
-	cmd := fmt.Sprintf("%s %s %s", termPath, group.App(), group.Account())
+	return strings.Join(append([]string{termPath}, TerminateArgs(group)...), " ")
+}
+
+// TerminateArgs returns the arguments of the "terminate" command that
+// terminates an instance of group, e.g.
+// ["foo", "prod", "--cluster=foo-prod", "--region=us-east-1"]
+func TerminateArgs(group grp.InstanceGroup) []string {
+	args := []string{group.App(), group.Account()}
 	if cluster, ok := group.Cluster(); ok {
-		cmd = fmt.Sprintf("%s --cluster=%s", cmd, cluster)
+		args = append(args, "--cluster="+cluster)
 	}
 
 	if stack, ok := group.Stack(); ok {
-		cmd = fmt.Sprintf("%s --stack=%s", cmd, stack)
+		args = append(args, "--stack="+stack)
 	}
 
 	if region, ok := group.Region(); ok {
-		cmd = fmt.Sprintf("%s --region=%s", cmd, region)
+		args = append(args, "--region="+region)
 	}
 
-	return cmd
+	return args
 }
 
 // logRedirect returns a string to append to a shell command so it redirects
@@ -253,6 +433,10 @@ func logRedirect(logPath string) string {
 // Schedule is a collection of termination entries.
 type Schedule struct {
 	entries []Entry
+
+	// seed is the seed the schedule was generated with, if seeded is true
+	seed   int64
+	seeded bool
 }
 
 // New returns a new Schedule
@@ -260,7 +444,7 @@ func New() *Schedule {
 	return &Schedule{
 		// We need a zero-element slice instead of a nil slice so that
 		// it will JSON-marshall into '[ ]' instead of 'null'
-		make([]Entry, 0),
+		entries: make([]Entry, 0),
 	}
 }
 
@@ -271,6 +455,18 @@ func (t ByTime) Len() int           { return len(t) }
 func (t ByTime) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
 func (t ByTime) Less(i, j int) bool { return t[i].Time.Before(t[j].Time) }
 
+// byTimeAndGroup sorts entries by time, and entries at the same time by group
+type byTimeAndGroup []Entry
+
+func (t byTimeAndGroup) Len() int      { return len(t) }
+func (t byTimeAndGroup) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t byTimeAndGroup) Less(i, j int) bool {
+	if !t[i].Time.Equal(t[j].Time) {
+		return t[i].Time.Before(t[j].Time)
+	}
+	return grp.String(t[i].Group) < grp.String(t[j].Group)
+}
+
 // Crontab returns a schedule of termination commands in crontab format
 // It takes as arguments:
 //  - the path to the executable that terminates an instance
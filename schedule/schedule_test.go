@@ -16,11 +16,22 @@ package schedule
 
 import (
 	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/cal"
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/deploy"
 	"github.com/Netflix/chaosmonkey/mock"
 )
 
@@ -37,7 +48,7 @@ func TestPopulate(t *testing.T) {
 	cfg.Set(param.ScheduleEnabled, true)
 
 	// Code under test
-	err := s.Populate(d, getter, cfg, nil)
+	err := s.populate(d, getter, cfg, nil, wednesday(t), 1)
 
 	if err != nil {
 		t.Fatalf("%v", err)
@@ -55,19 +66,406 @@ func TestPopulate(t *testing.T) {
 
 }
 
+func TestPopulateAccountConfigs(t *testing.T) {
+	s := New()
+	// mock deployment returns 4 single-cluster apps, 3 in prod and one in test
+	d := mock.Deployment()
+
+	cfg := config.Defaults()
+	cfg.Set(param.ScheduleEnabled, true)
+
+	// The test account is disabled, so only the prod apps are scheduled
+	err := s.populate(d, mockAccountConfigGetter{disabled: "test"}, cfg, nil, wednesday(t), 1)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, entry := range s.Entries() {
+		if entry.Group.Account() != "prod" {
+			t.Errorf("unexpected entry in account %s", entry.Group.Account())
+		}
+	}
+
+	if got, want := len(s.Entries()), 3; got != want {
+		t.Errorf("got %d entries, want %d", got, want)
+	}
+}
+
+func TestPopulateSkipsHolidays(t *testing.T) {
+	s := New()
+	// mock deployment returns 4 single-cluster apps, 3 in prod and one in test
+	d := mock.Deployment()
+
+	cfg := config.Defaults()
+	cfg.Set(param.ScheduleEnabled, true)
+
+	// Today is a holiday in the test account only
+	dir, err := ioutil.TempDir("", "schedule")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	path := filepath.Join(dir, "holidays.txt")
+	if err := ioutil.WriteFile(path, []byte("2016-11-16 company holiday\n"), 0644); err != nil {
+		t.Fatalf("%v", err)
+	}
+	cfg.Set(param.CalendarAccounts, map[string]string{"test": path})
+
+	err = s.populate(d, new(mockConfigGetter), cfg, nil, wednesday(t), 1)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, entry := range s.Entries() {
+		if entry.Group.Account() != "prod" {
+			t.Errorf("unexpected entry in account %s", entry.Group.Account())
+		}
+	}
+
+	if got, want := len(s.Entries()), 3; got != want {
+		t.Errorf("got %d entries, want %d", got, want)
+	}
+}
+
+func TestPopulateRegionHours(t *testing.T) {
+	// mock deployment returns 4 single-cluster apps in us-east-1
+	d := mock.Deployment()
+
+	cfg := config.Defaults()
+	cfg.Set(param.ScheduleEnabled, true)
+	cfg.Set(param.BusinessHoursRegions, map[string]interface{}{
+		"us-east-1": map[string]interface{}{
+			"start_hour": 10,
+			"end_hour":   16,
+			"time_zone":  "Asia/Jerusalem",
+			"workdays":   []string{"sun", "mon", "tue", "wed", "thu"},
+		},
+	})
+
+	jerusalem, err := time.LoadLocation("Asia/Jerusalem")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	tests := []struct {
+		now  time.Time
+		want int // number of entries
+		day  int // day of November 2016 in Jerusalem that entries are on
+	}{
+		// Wednesday 7AM in Los Angeles is 5PM in Jerusalem, so the window
+		// of Thursday is scheduled
+		{wednesday(t), 4, 17},
+
+		// Thursday 7AM in Los Angeles is Thursday 5PM in Jerusalem, and
+		// Friday is not a work day
+		{wednesday(t).AddDate(0, 0, 1), 0, 0},
+
+		// Saturday 7AM in Los Angeles is 5PM in Jerusalem, so the window
+		// of Sunday is scheduled
+		{wednesday(t).AddDate(0, 0, 3), 4, 20},
+	}
+
+	for _, tt := range tests {
+		s := New()
+		err := s.populate(d, new(mockConfigGetter), cfg, nil, tt.now, 1)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		if got := len(s.Entries()); got != tt.want {
+			t.Errorf("%s: got %d entries, want %d", tt.now, got, tt.want)
+			continue
+		}
+
+		for _, entry := range s.Entries() {
+			local := entry.Time.In(jerusalem)
+			if local.Day() != tt.day || local.Hour() < 10 || local.Hour() >= 16 {
+				t.Errorf("%s: entry at %s, want between 10:00 and 16:00 on November %d in Jerusalem", tt.now, local, tt.day)
+			}
+		}
+	}
+}
+
+// seededDeployment returns a deployment of n single-cluster apps in prod
+func seededDeployment(n int) deploy.Deployment {
+	apps := make(map[string]deploy.AppMap)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("app%02d", i)
+		apps[name] = deploy.AppMap{"prod": deploy.AccountInfo{CloudProvider: "aws", Clusters: deploy.ClusterMap{
+			deploy.ClusterName(name + "-prod"): {"us-east-1": {deploy.ASGName(name + "-prod-v001"): []deploy.InstanceID{deploy.InstanceID("i-" + name)}}},
+		}}}
+	}
+	return mock.NewDeployment(apps)
+}
+
+// populateWithSeed returns the schedule generated with seed for apps, where
+// each app is killed every other day on average
+func populateWithSeed(t *testing.T, d deploy.Deployment, apps []string, seed int64) *Schedule {
+	cfg := config.Defaults()
+	cfg.Set(param.ScheduleEnabled, true)
+
+	s := New()
+	err := s.populate(d, mockConfigGetter{mtbk: 2}, cfg, apps, wednesday(t), seed)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return s
+}
+
+// sameEntries returns true if the schedules have the same entries, in the
+// same order
+func sameEntries(s1, s2 *Schedule) bool {
+	if len(s1.Entries()) != len(s2.Entries()) {
+		return false
+	}
+	for i := range s1.Entries() {
+		if !s1.Entries()[i].Equal(&s2.Entries()[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPopulateWithSeed(t *testing.T) {
+	d := seededDeployment(20)
+
+	// The mock deployment returns apps in map order, which differs between
+	// calls, so this also checks that the order of the apps does not matter
+	s1 := populateWithSeed(t, d, nil, 42)
+	s2 := populateWithSeed(t, d, nil, 42)
+	if !sameEntries(s1, s2) {
+		t.Errorf("same seed generated different schedules:\n%s\n%s", s1.Crontab("x", "x"), s2.Crontab("x", "x"))
+	}
+
+	if seed, ok := s1.Seed(); !ok || seed != 42 {
+		t.Errorf("got seed %d, %t, want 42", seed, ok)
+	}
+
+	// With mtbk=2, about half of the apps are picked
+	if n := len(s1.Entries()); n < 5 || n > 15 {
+		t.Errorf("got %d of 20 apps picked, want about 10", n)
+	}
+
+	if s3 := populateWithSeed(t, d, nil, 43); sameEntries(s1, s3) {
+		t.Error("different seeds generated the same schedule")
+	}
+}
+
+// Test that max_apps picks the same apps, whatever the order in which the
+// deployment returns them
+func TestPopulateMaxApps(t *testing.T) {
+	d := seededDeployment(20)
+	cfg := config.Defaults()
+	cfg.Set(param.ScheduleEnabled, true)
+	cfg.Set(param.MaxApps, 3)
+
+	for i := 0; i < 5; i++ {
+		s := New()
+		err := s.populate(d, mockConfigGetter{mtbk: 1}, cfg, nil, wednesday(t), 1)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		var got []string
+		for _, e := range s.Entries() {
+			got = append(got, e.Group.App())
+		}
+		sort.Strings(got)
+
+		if want := []string{"app00", "app01", "app02"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got apps %v, want %v", got, want)
+		}
+	}
+}
+
+func TestChooseTerminationTime(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	start := time.Date(2016, time.November, 16, 9, 0, 0, 0, loc)
+	end := time.Date(2016, time.November, 16, 15, 0, 0, 0, loc)
+
+	r := rand.New(rand.NewSource(1))
+	first, last := end, start
+	for i := 0; i < 1000; i++ {
+		tm := chooseTerminationTime(start, end, r)
+		if tm.Before(start) || !tm.Before(end) || tm.Sub(start)%time.Minute != 0 {
+			t.Fatalf("got %s, want a time in [%s, %s)", tm, start, end)
+		}
+		if tm.Before(first) {
+			first = tm
+		}
+		if tm.After(last) {
+			last = tm
+		}
+	}
+
+	// The whole window is used
+	if first.Sub(start) > 30*time.Minute || end.Sub(last) > 30*time.Minute {
+		t.Errorf("times between %s and %s, want the window %s to %s", first, last, start, end)
+	}
+}
+
+func TestShouldKillInstance(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, mtbk := range []int{1, 2, 5} {
+		kills := 0
+		trials := 10000
+		for i := 0; i < trials; i++ {
+			if shouldKillInstance(mtbk, r) {
+				kills++
+			}
+		}
+
+		want := float64(trials) / float64(mtbk)
+		if got := float64(kills); got < 0.95*want || got > 1.05*want {
+			t.Errorf("mtbk=%d: got %d kills in %d trials, want about %.0f", mtbk, kills, trials, want)
+		}
+	}
+}
+
+func TestNextWorkingWindow(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	hours := config.BusinessHours{StartHour: 9, EndHour: 15, Location: loc}
+
+	at := func(day, hour, min, sec int) time.Time {
+		return time.Date(2016, time.November, day, hour, min, sec, 0, loc)
+	}
+
+	tests := []struct {
+		now        time.Time
+		ok         bool
+		start, end time.Time
+	}{
+		{at(16, 7, 0, 0), true, at(16, 9, 0, 0), at(16, 15, 0, 0)},    // before the window
+		{at(16, 12, 0, 0), true, at(16, 12, 0, 0), at(16, 15, 0, 0)},  // during the window, only the rest of it
+		{at(16, 12, 0, 30), true, at(16, 12, 1, 0), at(16, 15, 0, 0)}, // from the next whole minute
+		{at(16, 14, 59, 30), false, time.Time{}, time.Time{}},         // less than a minute left
+		{at(16, 16, 0, 0), true, at(17, 9, 0, 0), at(17, 15, 0, 0)},   // after the window
+		{at(18, 16, 0, 0), false, time.Time{}, time.Time{}},           // Friday afternoon
+		{at(20, 7, 0, 0), false, time.Time{}, time.Time{}},            // Sunday, Monday starts in more than a day
+		{at(20, 10, 0, 0), false, time.Time{}, time.Time{}},           // Sunday, Monday is left to Monday's run
+	}
+
+	for _, tt := range tests {
+		start, end, ok := nextWorkingWindow(tt.now, hours, cal.Calendar{})
+		if ok != tt.ok || !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("%s: got [%s, %s), %t, want [%s, %s), %t", tt.now, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+}
+
+// TestNextWorkingWindowInOtherTimeZone verifies that when the scheduler runs
+// during the working window of a group in another time zone, the group is
+// only scheduled for the rest of the window
+func TestNextWorkingWindowInOtherTimeZone(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	hours := config.BusinessHours{StartHour: 9, EndHour: 17, Location: london}
+
+	// 7AM in Los Angeles is 3PM in London
+	now := wednesday(t)
+	start, end, ok := nextWorkingWindow(now, hours, cal.Calendar{})
+	if !ok {
+		t.Fatal("expected a working window")
+	}
+
+	want := time.Date(2016, time.November, 16, 15, 0, 0, 0, london)
+	if !start.Equal(want) || !end.Equal(time.Date(2016, time.November, 16, 17, 0, 0, 0, london)) {
+		t.Errorf("got [%s, %s), want the rest of the window from %s", start, end, want)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		if tm := chooseTerminationTime(start, end, r); tm.Before(now) {
+			t.Fatalf("got %s, before now %s", tm, now)
+		}
+	}
+}
+
+// TestNextWorkingWindowHoliday verifies that a group whose window today is on
+// a holiday is not scheduled for the next work day, which is left to the next
+// run of the scheduler, so that the day is not scheduled twice
+func TestNextWorkingWindowHoliday(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	hours := config.BusinessHours{StartHour: 9, EndHour: 15, Location: loc}
+
+	calendar, err := cal.Parse(strings.NewReader("2016-11-16 holiday\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := wednesday(t)
+	if start, _, ok := nextWorkingWindow(now, hours, calendar); ok {
+		t.Errorf("got a window at %s on a holiday, want none", start)
+	}
+
+	// The next run schedules Thursday
+	start, _, ok := nextWorkingWindow(now.Add(24*time.Hour), hours, calendar)
+	if !ok || start.Day() != 17 {
+		t.Errorf("got %s, %t, want Thursday", start, ok)
+	}
+}
+
+// wednesday returns 7AM on Wednesday November 16 2016 in Los Angeles, the
+// default time zone, when the scheduler runs by default
+func wednesday(t *testing.T) time.Time {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return time.Date(2016, time.November, 16, 7, 0, 0, 0, loc)
+}
+
+// mockAccountConfigGetter implements chaosmonkey.AccountAppConfigGetter
+// It disables apps in one account
+type mockAccountConfigGetter struct {
+	mockConfigGetter
+	disabled string
+}
+
+// GetAccount implements chaosmonkey.AccountAppConfigGetter.GetAccount
+func (g mockAccountConfigGetter) GetAccount(app, account string) (*chaosmonkey.AppConfig, error) {
+	cfg, err := g.Get(app)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Enabled = account != g.disabled
+	return cfg, nil
+}
+
 // mockConfigGetter implements chaosmonkey.Getter
 // returns configs for apps
 type mockConfigGetter struct {
+	// mtbk is the mean time between kills in work days, 1 if zero
+	mtbk int
 }
 
 // Get implements chaosmonkey.Getter.Get
 // Configures each app for app-level grouping
-// configures mean time between work days to 1, which ensures
+// configures mean time between work days to 1 by default, which ensures
 // a kill on each day
 func (g mockConfigGetter) Get(app string) (*chaosmonkey.AppConfig, error) {
 	cfg := chaosmonkey.NewAppConfig(nil)
 	cfg.Grouping = chaosmonkey.App
 	cfg.MeanTimeBetweenKillsInWorkDays = 1
+	if g.mtbk != 0 {
+		cfg.MeanTimeBetweenKillsInWorkDays = g.mtbk
+	}
 	return &cfg, nil
 }
 
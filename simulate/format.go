@@ -0,0 +1,57 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulate
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Netflix/chaosmonkey/history"
+)
+
+// columns are the columns of each group in table and CSV output
+var columns = []string{"app", "account", "region", "stack", "cluster", "mtbk", "min_time",
+	"scheduled", "blocked", "kills", "longest_gap", "sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// row returns the columns of a group
+func (g Group) row() []string {
+	result := []string{g.App, g.Account, g.Region, g.Stack, g.Cluster}
+	for _, n := range []int{g.MeanTimeBetweenKills, g.MinTimeBetweenKills, g.Scheduled, g.Blocked, g.Kills, g.LongestGap} {
+		result = append(result, strconv.Itoa(n))
+	}
+	for _, n := range g.Weekdays {
+		result = append(result, strconv.Itoa(n))
+	}
+	return result
+}
+
+// Write writes a report to w in a format: table, json or csv. Table and CSV
+// output only has the groups. Times are written in loc
+func Write(w io.Writer, format string, report *Report, loc *time.Location) error {
+	if format == history.JSON {
+		local := *report
+		local.Start = local.Start.In(loc)
+		local.End = local.End.In(loc)
+		return history.WriteJSON(w, local)
+	}
+
+	rows := make([][]string, len(report.Groups))
+	for i, g := range report.Groups {
+		rows[i] = g.row()
+	}
+
+	return history.WriteRows(w, format, columns, rows)
+}
@@ -0,0 +1,413 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulate forecasts the terminations of the coming days by running
+// the scheduler against a snapshot of the deployment
+package simulate
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/cal"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/cron"
+	"github.com/Netflix/chaosmonkey/deploy"
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// Options are the options of a simulation
+type Options struct {
+	// Start is the time that the simulation starts at. The scheduler runs
+	// every time chaosmonkey.cron_expression fires after Start
+	Start time.Time
+
+	// Days is the number of runs of the scheduler to simulate
+	Days int
+
+	// Seed is the seed that the seed of each run is drawn from. Given the
+	// same deployment, configs and start, the same seed gives the same report
+	Seed int64
+}
+
+// Group is the forecast for an eligible instance group
+type Group struct {
+	App     string `json:"app"`
+	Account string `json:"account"`
+	Region  string `json:"region"`
+	Stack   string `json:"stack"`
+	Cluster string `json:"cluster"`
+
+	MeanTimeBetweenKills int `json:"meanTimeBetweenKillsInWorkDays"`
+	MinTimeBetweenKills  int `json:"minTimeBetweenKillsInWorkDays"`
+
+	// Scheduled is the number of runs that scheduled a termination
+	Scheduled int `json:"scheduled"`
+
+	// Blocked is the number of scheduled terminations that the min time
+	// between kills check rejects
+	Blocked int `json:"blocked"`
+
+	// Kills is the number of scheduled terminations that are executed
+	Kills int `json:"kills"`
+
+	// LongestGap is the largest number of consecutive runs without a kill
+	LongestGap int `json:"longestGap"`
+
+	// Weekdays is the number of kills on each day of the week, Sunday
+	// first, in the time zone of the group's business hours
+	Weekdays [7]int `json:"weekdays"`
+}
+
+// Report is the result of a simulation
+type Report struct {
+	Seed int64 `json:"seed"`
+	Days int   `json:"days"`
+
+	// Start and End are the times of the first and last runs
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Groups are ordered by app, account, region, stack and cluster
+	Groups []Group `json:"groups"`
+}
+
+// Run simulates opts.Days runs of the scheduler for apps, or for all apps if
+// apps is empty. Each run generates a schedule with Schedule.PopulateAt.
+// Its terminations are then checked against the min time between kills, like
+// the checker does before terminating, assuming that every termination that
+// passed the check earlier in the simulation was executed. Nothing is
+// published or installed.
+//
+// The deployment and the app configs are retrieved once, at the start of the
+// simulation
+func Run(d deploy.Deployment, g chaosmonkey.AppConfigGetter, cfg *config.Monkey, apps []string, opts Options) (*Report, error) {
+	if opts.Days <= 0 {
+		return nil, errors.Errorf("number of days must be positive, got %d", opts.Days)
+	}
+
+	expr, err := cfg.CronExpression()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get cron expression")
+	}
+	runs, err := cron.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := cfg.Location()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get location")
+	}
+
+	calendars, err := cal.NewFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := newSnapshot(d, apps)
+	if err != nil {
+		return nil, err
+	}
+	getter := newConfigCache(g).getter()
+
+	groups := eligibleGroups(snap, getter, cfg)
+
+	report := &Report{Seed: opts.Seed, Days: opts.Days}
+	seeds := rand.New(rand.NewSource(opts.Seed))
+	now := opts.Start
+	for day := 0; day < opts.Days; day++ {
+		now = runs.Next(now.In(loc))
+		if now.IsZero() {
+			return nil, errors.Errorf("cron expression %q does not fire after %s", expr, report.End)
+		}
+		if day == 0 {
+			report.Start = now
+		}
+		report.End = now
+
+		s := schedule.New()
+		err := s.PopulateAt(snap, getter, cfg, snap.names, now, seeds.Int63())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to populate schedule at %s", now)
+		}
+
+		for _, entry := range s.Entries() {
+			// Like the daemon, terminations scheduled before the run are
+			// never executed
+			if !entry.Time.After(now) {
+				continue
+			}
+
+			group, ok := groups[grp.String(entry.Group)]
+			if !ok {
+				return nil, errors.Errorf("%s was scheduled but is not eligible", grp.String(entry.Group))
+			}
+
+			err = group.schedule(day, entry.Time, calendars)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, group := range groups {
+		report.Groups = append(report.Groups, group.result(opts.Days))
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		switch {
+		case a.App != b.App:
+			return a.App < b.App
+		case a.Account != b.Account:
+			return a.Account < b.Account
+		case a.Region != b.Region:
+			return a.Region < b.Region
+		case a.Stack != b.Stack:
+			return a.Stack < b.Stack
+		default:
+			return a.Cluster < b.Cluster
+		}
+	})
+
+	return report, nil
+}
+
+// group tracks the terminations of an instance group during a simulation
+type group struct {
+	Group
+	hours config.BusinessHours
+
+	lastKill    time.Time
+	lastKillDay int // -1 if there was no kill yet
+}
+
+// eligibleGroups returns the groups of the enabled apps in the snapshot,
+// by the string representation of their instance group
+func eligibleGroups(snap *snapshot, getter chaosmonkey.AppConfigGetter, cfg *config.Monkey) map[string]*group {
+	result := make(map[string]*group)
+
+	for _, name := range snap.names {
+		app := snap.apps[name]
+		for _, account := range app.Accounts() {
+			appCfg, err := chaosmonkey.GetAppConfig(getter, app.Name(), account.Name())
+			if err != nil {
+				// The scheduler skips apps whose config cannot be retrieved
+				continue
+			}
+			if !appCfg.Enabled {
+				continue
+			}
+
+			for _, ig := range app.EligibleInstanceGroups(*appCfg) {
+				if ig.Account() != account.Name() {
+					continue
+				}
+
+				region, _ := ig.Region()
+				stack, _ := ig.Stack()
+				cluster, _ := ig.Cluster()
+
+				// Groups whose business hours are invalid are never
+				// scheduled, and are reported without terminations
+				hours, _ := cfg.BusinessHours(ig.Account(), region)
+
+				result[grp.String(ig)] = &group{
+					Group: Group{
+						App:                  ig.App(),
+						Account:              ig.Account(),
+						Region:               region,
+						Stack:                stack,
+						Cluster:              cluster,
+						MeanTimeBetweenKills: appCfg.MeanTimeBetweenKillsInWorkDays,
+						MinTimeBetweenKills:  appCfg.MinTimeBetweenKillsInWorkDays,
+					},
+					hours:       hours,
+					lastKillDay: -1,
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// schedule records a termination of the group scheduled at t by the run of
+// day, which is executed unless the min time between kills rejects it
+func (g *group) schedule(day int, t time.Time, calendars cal.Calendars) error {
+	g.Scheduled++
+
+	if g.lastKillDay >= 0 {
+		calendar := calendars.For(g.Account, g.Region)
+		threshold, err := calendar.NoKillsSince(g.MinTimeBetweenKills, t, g.hours.EndHour, g.hours.Location)
+		if err != nil {
+			return err
+		}
+		if !g.lastKill.Before(threshold) {
+			g.Blocked++
+			return nil
+		}
+	}
+
+	g.Kills++
+	g.Weekdays[t.In(g.hours.Location).Weekday()]++
+	g.recordGap(day)
+	g.lastKill = t
+	g.lastKillDay = day
+	return nil
+}
+
+// recordGap records the runs without a kill before day
+func (g *group) recordGap(day int) {
+	if gap := day - g.lastKillDay - 1; gap > g.LongestGap {
+		g.LongestGap = gap
+	}
+}
+
+// result returns the forecast for the group at the end of a simulation of
+// days runs
+func (g *group) result(days int) Group {
+	g.recordGap(days)
+	return g.Group
+}
+
+// snapshot implements deploy.Deployment with apps retrieved once from
+// another deployment
+type snapshot struct {
+	names []string // sorted
+	apps  map[string]*deploy.App
+}
+
+// newSnapshot retrieves apps from d, or all apps if apps is empty
+func newSnapshot(d deploy.Deployment, apps []string) (*snapshot, error) {
+	if len(apps) == 0 {
+		var err error
+		apps, err = d.AppNames()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not retrieve list of apps")
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range apps {
+		wanted[name] = true
+	}
+
+	s := &snapshot{apps: make(map[string]*deploy.App)}
+	c := make(chan *deploy.App)
+	go d.Apps(c, apps)
+	for app := range c {
+		if wanted[app.Name()] {
+			s.apps[app.Name()] = app
+			s.names = append(s.names, app.Name())
+		}
+	}
+	sort.Strings(s.names)
+
+	return s, nil
+}
+
+// Apps implements deploy.Deployment.Apps
+func (s *snapshot) Apps(c chan<- *deploy.App, names []string) {
+	defer close(c)
+
+	for _, name := range names {
+		if app, ok := s.apps[name]; ok {
+			c <- app
+		}
+	}
+}
+
+// GetApp implements deploy.Deployment.GetApp
+func (s *snapshot) GetApp(name string) (*deploy.App, error) {
+	app, ok := s.apps[name]
+	if !ok {
+		return nil, errors.Errorf("unknown app %s", name)
+	}
+	return app, nil
+}
+
+// AppNames implements deploy.Deployment.AppNames
+func (s *snapshot) AppNames() ([]string, error) {
+	return s.names, nil
+}
+
+// configCache retrieves the config of each app, or of each app and account,
+// once from another getter
+type configCache struct {
+	g       chaosmonkey.AppConfigGetter
+	configs map[appAccount]cachedConfig
+}
+
+type appAccount struct {
+	app, account string
+}
+
+type cachedConfig struct {
+	cfg *chaosmonkey.AppConfig
+	err error
+}
+
+func newConfigCache(g chaosmonkey.AppConfigGetter) *configCache {
+	return &configCache{g: g, configs: make(map[appAccount]cachedConfig)}
+}
+
+// getter returns the cache as a getter that, like the getter it caches,
+// implements chaosmonkey.AccountAppConfigGetter only if that getter does,
+// since the scheduler behaves differently for those
+func (c *configCache) getter() chaosmonkey.AppConfigGetter {
+	if _, ok := c.g.(chaosmonkey.AccountAppConfigGetter); ok {
+		return c
+	}
+	return appConfigCache{c}
+}
+
+// Get implements chaosmonkey.AppConfigGetter.Get
+func (c *configCache) Get(app string) (*chaosmonkey.AppConfig, error) {
+	return c.get(appAccount{app: app}, func() (*chaosmonkey.AppConfig, error) {
+		return c.g.Get(app)
+	})
+}
+
+// GetAccount implements chaosmonkey.AccountAppConfigGetter.GetAccount
+func (c *configCache) GetAccount(app, account string) (*chaosmonkey.AppConfig, error) {
+	return c.get(appAccount{app, account}, func() (*chaosmonkey.AppConfig, error) {
+		return chaosmonkey.GetAppConfig(c.g, app, account)
+	})
+}
+
+func (c *configCache) get(key appAccount, retrieve func() (*chaosmonkey.AppConfig, error)) (*chaosmonkey.AppConfig, error) {
+	cached, ok := c.configs[key]
+	if !ok {
+		cached.cfg, cached.err = retrieve()
+		c.configs[key] = cached
+	}
+	return cached.cfg, cached.err
+}
+
+// appConfigCache hides the GetAccount method of configCache
+type appConfigCache struct {
+	c *configCache
+}
+
+// Get implements chaosmonkey.AppConfigGetter.Get
+func (a appConfigCache) Get(app string) (*chaosmonkey.AppConfig, error) {
+	return a.c.Get(app)
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulate
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+// configGetter configures every app for cluster grouping, with the given mean
+// and min times between kills
+type configGetter struct {
+	mtbk, min int
+}
+
+func (c configGetter) Get(app string) (*chaosmonkey.AppConfig, error) {
+	return &chaosmonkey.AppConfig{
+		Enabled:                        true,
+		RegionsAreIndependent:          true,
+		MeanTimeBetweenKillsInWorkDays: c.mtbk,
+		MinTimeBetweenKillsInWorkDays:  c.min,
+		Grouping:                       chaosmonkey.Cluster,
+	}, nil
+}
+
+// sunday returns the start of Sunday, Nov. 13, 2016 in the default time zone.
+// The scheduler runs on weekdays by default, so the first run is on Monday
+func sunday(t *testing.T) time.Time {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return time.Date(2016, time.November, 13, 0, 0, 0, 0, loc)
+}
+
+func run(t *testing.T, g chaosmonkey.AppConfigGetter, days int, seed int64) *Report {
+	report, err := Run(mock.Deployment(), g, config.Defaults(), nil, Options{Start: sunday(t), Days: days, Seed: seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestRunMinTimeBetweenKills(t *testing.T) {
+	tests := []struct {
+		name       string
+		min        int
+		kills      int
+		blocked    int
+		longestGap int
+		weekdays   [7]int
+	}{
+		{"every day", 1, 10, 0, 0, [7]int{0, 2, 2, 2, 2, 2, 0}},
+		{"every other day", 2, 5, 5, 1, [7]int{0, 1, 1, 1, 1, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		// Every run schedules a termination of every group
+		report := run(t, configGetter{mtbk: 1, min: tt.min}, 10, 1)
+
+		if got, want := len(report.Groups), 4; got != want {
+			t.Fatalf("%s: got %d groups, want %d", tt.name, got, want)
+		}
+
+		for _, g := range report.Groups {
+			if got, want := g.Scheduled, 10; got != want {
+				t.Errorf("%s: %s got scheduled=%d, want %d", tt.name, g.App, got, want)
+			}
+			if got, want := g.Kills, tt.kills; got != want {
+				t.Errorf("%s: %s got kills=%d, want %d", tt.name, g.App, got, want)
+			}
+			if got, want := g.Blocked, tt.blocked; got != want {
+				t.Errorf("%s: %s got blocked=%d, want %d", tt.name, g.App, got, want)
+			}
+			if got, want := g.LongestGap, tt.longestGap; got != want {
+				t.Errorf("%s: %s got longest gap=%d, want %d", tt.name, g.App, got, want)
+			}
+			if got, want := g.Weekdays, tt.weekdays; got != want {
+				t.Errorf("%s: %s got weekdays=%v, want %v", tt.name, g.App, got, want)
+			}
+		}
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	report := run(t, configGetter{mtbk: 3, min: 1}, 20, 42)
+
+	if got, want := report.Start, time.Date(2016, time.November, 14, 7, 0, 0, 0, sunday(t).Location()); !got.Equal(want) {
+		t.Errorf("got start=%s, want %s", got, want)
+	}
+
+	// 20 weekdays later
+	if got, want := report.End, time.Date(2016, time.December, 9, 7, 0, 0, 0, sunday(t).Location()); !got.Equal(want) {
+		t.Errorf("got end=%s, want %s", got, want)
+	}
+
+	var apps []string
+	for _, g := range report.Groups {
+		apps = append(apps, g.App)
+		if g.Kills+g.Blocked != g.Scheduled {
+			t.Errorf("%s: got kills=%d, blocked=%d, scheduled=%d", g.App, g.Kills, g.Blocked, g.Scheduled)
+		}
+		if g.Kills == 0 || g.Kills == 20 {
+			t.Errorf("%s: got kills=%d, want some but not all of 20 days with mtbk=3", g.App, g.Kills)
+		}
+	}
+
+	if got, want := apps, []string{"bar", "baz", "foo", "quux"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got apps %v, want %v", got, want)
+	}
+
+	if got, want := run(t, configGetter{mtbk: 3, min: 1}, 20, 42), report; !reflect.DeepEqual(got, want) {
+		t.Errorf("got different reports with the same seed: %+v and %+v", got, want)
+	}
+
+	if got := run(t, configGetter{mtbk: 3, min: 1}, 20, 43); reflect.DeepEqual(got.Groups, report.Groups) {
+		t.Errorf("got the same groups with different seeds: %+v", got.Groups)
+	}
+}
+
+func TestRunNoKills(t *testing.T) {
+	report := run(t, configGetter{mtbk: 1000000, min: 1}, 5, 1)
+
+	for _, g := range report.Groups {
+		if g.Kills != 0 || g.LongestGap != 5 {
+			t.Errorf("%s: got kills=%d, longest gap=%d, want 0 and 5", g.App, g.Kills, g.LongestGap)
+		}
+	}
+}
+
+func TestRunDays(t *testing.T) {
+	_, err := Run(mock.Deployment(), configGetter{mtbk: 1, min: 1}, config.Defaults(), nil, Options{Start: sunday(t)})
+	if err == nil {
+		t.Error("got no error for 0 days")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	report := &Report{
+		Days: 5,
+		Groups: []Group{
+			{App: "foo", Account: "prod", Region: "us-east-1", Cluster: "foo-prod", MeanTimeBetweenKills: 2, MinTimeBetweenKills: 1,
+				Scheduled: 3, Blocked: 1, Kills: 2, LongestGap: 2, Weekdays: [7]int{0, 1, 0, 0, 1, 0, 0}},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := Write(&buf, history.CSV, report, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"app,account,region,stack,cluster,mtbk,min_time,scheduled,blocked,kills,longest_gap,sun,mon,tue,wed,thu,fri,sat",
+		"foo,prod,us-east-1,,foo-prod,2,1,3,1,2,2,0,1,0,0,1,0,0",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spinnaker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+
+	"github.com/pkg/errors"
+)
+
+// tokenExpiryDelta is how long before it expires that an OAuth2 token is
+// refreshed, so that it does not expire while a request is in flight
+const tokenExpiryDelta = 10 * time.Second
+
+// auth configures how the Spinnaker client authenticates against Spinnaker.
+// The zero value does not authenticate
+type auth struct {
+	// p12 is the path to a PKCS#12 client cert, encrypted with password
+	p12      string
+	password string
+
+	// certFile and keyFile are the paths to a PEM client cert and its key
+	certFile string
+	keyFile  string
+
+	// caFile is the path to a PEM bundle of CA certs that are trusted to
+	// sign Spinnaker's cert, in addition to the system CAs
+	caFile string
+
+	// tokenFile is the path to a file that contains a bearer token
+	tokenFile string
+
+	// tokenURL, clientID, clientSecret and scopes are used to request bearer
+	// tokens with the OAuth2 client credentials grant
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+
+	// proxy is the URL of an HTTP proxy. If empty, the proxy is taken from
+	// the environment
+	proxy string
+}
+
+// newClient returns an http client that authenticates against Spinnaker
+// according to a
+func newClient(a auth) (*http.Client, error) {
+	if a.p12 != "" && (a.certFile != "" || a.keyFile != "") {
+		return nil, errors.New("spinnaker certificate and cert_file cannot both be specified")
+	}
+
+	if a.tokenFile != "" && a.tokenURL != "" {
+		return nil, errors.New("spinnaker token_file and oauth2_token_url cannot both be specified")
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if a.p12 != "" {
+		pfxData, err := ioutil.ReadFile(a.p12)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read file %s", a.p12)
+		}
+
+		cert, err := p12Certificate(pfxData, a.password)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if a.certFile != "" || a.keyFile != "" {
+		if a.certFile == "" || a.keyFile == "" {
+			return nil, errors.New("spinnaker cert_file and key_file must be specified together")
+		}
+
+		cert, err := tls.LoadX509KeyPair(a.certFile, a.keyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load cert %s and key %s", a.certFile, a.keyFile)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if a.caFile != "" {
+		pool, err := caPool(a.caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	if a.proxy != "" {
+		proxy, err := url.Parse(a.proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid spinnaker proxy %s", a.proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	switch {
+	case a.tokenFile != "":
+		return &http.Client{Transport: &bearer{base: transport, tokens: &fileToken{path: a.tokenFile}}}, nil
+	case a.tokenURL != "":
+		tokens := &clientCredentials{
+			tokenURL:     a.tokenURL,
+			clientID:     a.clientID,
+			clientSecret: a.clientSecret,
+			scopes:       a.scopes,
+			client:       &http.Client{Transport: transport},
+			now:          time.Now,
+		}
+		return &http.Client{Transport: &bearer{base: transport, tokens: tokens}}, nil
+	default:
+		return &http.Client{Transport: transport}, nil
+	}
+}
+
+// p12Certificate takes PKCS#12 data (encrypted cert data in .p12 format) and
+// the password for the encrypted cert, and returns the cert for TLS client auth
+func p12Certificate(pfxData []byte, password string) (tls.Certificate, error) {
+	blocks, err := pkcs12.ToPEM(pfxData, password)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, "pkcs.ToPEM failed")
+	}
+
+	// The first block is the cert and the last block is the private key
+	certPEMBlock := pem.EncodeToMemory(blocks[0])
+	keyPEMBlock := pem.EncodeToMemory(blocks[len(blocks)-1])
+
+	cert, err := tls.X509KeyPair(certPEMBlock, keyPEMBlock)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, "tls.X509KeyPair failed")
+	}
+
+	return cert, nil
+}
+
+// caPool returns the system CA certs together with the CA certs in the PEM
+// bundle at path
+func caPool(path string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read file %s", path)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("no certs found in CA bundle %s", path)
+	}
+
+	return pool, nil
+}
+
+// tokenSource provides bearer tokens
+type tokenSource interface {
+	// token returns the current token
+	token(ctx context.Context) (string, error)
+
+	// invalidate discards the current token after Spinnaker rejected it
+	invalidate()
+}
+
+// bearer is an http.RoundTripper that sends a bearer token with each request
+type bearer struct {
+	base   http.RoundTripper
+	tokens tokenSource
+}
+
+// RoundTrip implements http.RoundTripper.RoundTrip
+func (b *bearer) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := b.tokens.token(req.Context())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get bearer token for spinnaker")
+	}
+
+	// A RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := b.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		b.tokens.invalidate()
+	}
+
+	return resp, err
+}
+
+// fileToken is a token read from a file, which is read again whenever it
+// changes so that tokens can be rotated by an external process
+type fileToken struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+// token implements tokenSource.token
+func (f *fileToken) token(ctx context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to stat token file %s", f.path)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.value != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.value, nil
+	}
+
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read token file %s", f.path)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", errors.Errorf("token file %s is empty", f.path)
+	}
+
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	f.value = value
+	return f.value, nil
+}
+
+// invalidate implements tokenSource.invalidate
+func (f *fileToken) invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.value = ""
+}
+
+// clientCredentials requests tokens with the OAuth2 client credentials grant,
+// and caches them until they expire
+type clientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+	now          func() time.Time
+
+	mu     sync.Mutex
+	value  string
+	expiry time.Time // zero if the token does not expire
+}
+
+// tokenResponse is the response of an OAuth2 token endpoint
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// token implements tokenSource.token
+func (c *clientCredentials) token(ctx context.Context) (string, error) {
+	// Holding the lock while requesting a token means that concurrent
+	// requests wait for a single token instead of each requesting one
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.value != "" && (c.expiry.IsZero() || c.now().Before(c.expiry)) {
+		return c.value, nil
+	}
+
+	tok, err := c.request(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if tok.TokenType != "" && !strings.EqualFold(tok.TokenType, "bearer") {
+		return "", errors.Errorf("unsupported token type %s from %s", tok.TokenType, c.tokenURL)
+	}
+
+	c.value = tok.AccessToken
+	c.expiry = time.Time{}
+	if tok.ExpiresIn > 0 {
+		c.expiry = c.now().Add(time.Duration(tok.ExpiresIn)*time.Second - tokenExpiryDelta)
+	}
+
+	return c.value, nil
+}
+
+// request requests a new token from the token endpoint
+func (c *clientCredentials) request(ctx context.Context) (tok tokenResponse, err error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return tok, errors.Wrapf(err, "failed to create token request for %s", c.tokenURL)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return tok, errors.Wrapf(err, "token request to %s failed", c.tokenURL)
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "failed to close response body from %s", c.tokenURL)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return tok, errors.Wrapf(err, "failed to read body from url %s", c.tokenURL)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return tok, errors.Errorf("unexpected response code (%d) from %s: %s", resp.StatusCode, c.tokenURL, body)
+	}
+
+	if err = json.Unmarshal(body, &tok); err != nil {
+		return tok, errors.Wrapf(err, "failed to parse token response from %s", c.tokenURL)
+	}
+
+	if tok.AccessToken == "" {
+		return tok, errors.Errorf("no access_token in response from %s", c.tokenURL)
+	}
+
+	return tok, nil
+}
+
+// invalidate implements tokenSource.invalidate
+func (c *clientCredentials) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value = ""
+}
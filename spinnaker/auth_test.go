@@ -0,0 +1,334 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spinnaker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// providerHandler serves the cloud provider of an account
+func providerHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, `{"cloudProvider": "aws"}`)
+}
+
+// writeFile writes a file to dir and returns its path
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPEMClientCertAndCABundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spinnaker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mu sync.Mutex
+	var clientCN string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		clientCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		mu.Unlock()
+		providerHandler(w, r)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	certFile, keyFile := writeClientCert(t, dir)
+	caFile := writeFile(t, dir, "ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	// Without the CA bundle, the server's cert is not trusted
+	s, err := newWithAuth(srv.URL, auth{certFile: certFile, keyFile: keyFile}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.CloudProvider("prod"); err == nil {
+		t.Error("got nil error for server with untrusted cert")
+	}
+
+	s, err = newWithAuth(srv.URL, auth{certFile: certFile, keyFile: keyFile, caFile: caFile}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := s.CloudProvider("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider != "aws" {
+		t.Errorf("got provider %s, want aws", provider)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if clientCN != "chaosmonkey" {
+		t.Errorf("got client cert for %q, want chaosmonkey", clientCN)
+	}
+}
+
+// writeClientCert writes a self-signed client cert and its key to dir, and
+// returns their paths
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "chaosmonkey"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = writeFile(t, dir, "cert.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyFile = writeFile(t, dir, "key.pem", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certFile, keyFile
+}
+
+// tokenServer accepts requests with a bearer token, and records the tokens
+type tokenServer struct {
+	mu     sync.Mutex
+	valid  string
+	tokens []string
+}
+
+func (ts *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	token := r.Header.Get("Authorization")
+	ts.tokens = append(ts.tokens, token)
+	if token != "Bearer "+ts.valid {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	providerHandler(w, r)
+}
+
+// accept sets the token that is accepted
+func (ts *tokenServer) accept(token string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.valid = token
+}
+
+func TestTokenFileRefreshedOnChange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spinnaker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := &tokenServer{valid: "token-1"}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	tokenFile := writeFile(t, dir, "token", []byte("token-1\n"))
+
+	s, err := newWithAuth(srv.URL, auth{tokenFile: tokenFile}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.CloudProvider("prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate the token. It has the same size, so make sure the modification
+	// time changes
+	ts.accept("token-2")
+	writeFile(t, dir, "token", []byte("token-2\n"))
+	later := time.Now().Add(time.Minute)
+	if err = os.Chtimes(tokenFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.CloudProvider("prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Bearer token-1", "Bearer token-2"}
+	if fmt.Sprint(ts.tokens) != fmt.Sprint(want) {
+		t.Errorf("got tokens %q, want %q", ts.tokens, want)
+	}
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	ts := &tokenServer{valid: "token-1"}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	var mu sync.Mutex
+	issued := 0
+	oauth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "chaosmonkey" || secret != "s3cret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": "invalid_client"}`)
+			return
+		}
+
+		if got := r.FormValue("scope"); got != "spinnaker:read spinnaker:write" {
+			t.Errorf("got scope %q", got)
+		}
+
+		mu.Lock()
+		issued++
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 3600}`, issued)
+		mu.Unlock()
+	}))
+	defer oauth.Close()
+
+	s, err := newWithAuth(srv.URL, auth{
+		tokenURL:     oauth.URL,
+		clientID:     "chaosmonkey",
+		clientSecret: "s3cret",
+		scopes:       []string{"spinnaker:read", "spinnaker:write"},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	tokens := s.client.Transport.(*bearer).tokens.(*clientCredentials)
+	tokens.now = func() time.Time { return now }
+
+	// The token is reused until it expires
+	for i := 0; i < 2; i++ {
+		if _, err = s.CloudProvider("prod"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts.accept("token-2")
+	now = now.Add(time.Hour)
+	if _, err = s.CloudProvider("prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A token that is rejected is requested again
+	ts.accept("token-3")
+	if _, err = s.CloudProvider("prod"); err == nil {
+		t.Error("got nil error for rejected token")
+	}
+	if _, err = s.CloudProvider("prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Bearer token-1", "Bearer token-1", "Bearer token-2", "Bearer token-2", "Bearer token-3"}
+	if fmt.Sprint(ts.tokens) != fmt.Sprint(want) {
+		t.Errorf("got tokens %q, want %q", ts.tokens, want)
+	}
+}
+
+func TestOAuth2InvalidClient(t *testing.T) {
+	srv := httptest.NewServer(&tokenServer{valid: "token-1"})
+	defer srv.Close()
+
+	oauth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": "invalid_client"}`)
+	}))
+	defer oauth.Close()
+
+	s, err := newWithAuth(srv.URL, auth{tokenURL: oauth.URL, clientID: "chaosmonkey"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.CloudProvider("prod"); err == nil {
+		t.Error("got nil error when token request failed")
+	}
+}
+
+func TestProxy(t *testing.T) {
+	var mu sync.Mutex
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hosts = append(hosts, r.URL.Host)
+		mu.Unlock()
+		providerHandler(w, r)
+	}))
+	defer proxy.Close()
+
+	s, err := newWithAuth("http://spinnaker.example.com", auth{proxy: proxy.URL}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.CloudProvider("prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hosts) != 1 || hosts[0] != "spinnaker.example.com" {
+		t.Errorf("got proxied requests to %v, want [spinnaker.example.com]", hosts)
+	}
+}
+
+func TestInvalidAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		a    auth
+	}{
+		{"p12 and pem", auth{p12: "cm.p12", certFile: "cert.pem", keyFile: "key.pem"}},
+		{"cert without key", auth{certFile: "cert.pem"}},
+		{"token file and oauth2", auth{tokenFile: "token", tokenURL: "https://oauth.example.com/token"}},
+		{"missing ca file", auth{caFile: "/does/not/exist.pem"}},
+		{"invalid proxy", auth{proxy: "://proxy"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := newClient(tt.a); err == nil {
+			t.Errorf("%s: got nil error", tt.name)
+		}
+	}
+}
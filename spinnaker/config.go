@@ -15,6 +15,7 @@
 package spinnaker
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 
@@ -24,7 +25,50 @@ import (
 )
 
 // Get implements chaosmonkey.Getter.Get
-func (s Spinnaker) Get(app string) (c *chaosmonkey.AppConfig, err error) {
+func (s Spinnaker) Get(app string) (*chaosmonkey.AppConfig, error) {
+	body, err := s.appBody(app)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromJSON(body)
+}
+
+// AppConfigJSON implements appcfg.Source.AppConfigJSON
+// It returns the "chaosMonkey" attribute of the Spinnaker application
+func (s Spinnaker) AppConfigJSON(app string) ([]byte, error) {
+	body, err := s.appBody(app)
+	if err != nil {
+		return nil, err
+	}
+
+	return ChaosMonkeyAttribute(body)
+}
+
+// OwnerEmail implements email.OwnerGetter.OwnerEmail
+// It returns the "email" attribute of the Spinnaker application
+func (s Spinnaker) OwnerEmail(app string) (string, error) {
+	body, err := s.appBody(app)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Attributes struct {
+			Email string `json:"email"`
+		} `json:"attributes"`
+	}
+
+	err = json.Unmarshal(body, &parsed)
+	if err != nil {
+		return "", errors.Wrap(err, "json unmarshal failed")
+	}
+
+	return parsed.Attributes.Email, nil
+}
+
+// appBody returns the body of the Spinnaker API response for an application
+func (s Spinnaker) appBody(app string) (body []byte, err error) {
 	// avoid expanding the response to avoid unneeded load
 	url := s.appURL(app) + "?expand=false"
 	resp, err := s.client.Get(url)
@@ -43,10 +87,10 @@ func (s Spinnaker) Get(app string) (c *chaosmonkey.AppConfig, err error) {
 		return nil, errors.Errorf("unexpected response code (%d) from %s", resp.StatusCode, url)
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, errors.Wrapf(err, "body read failed at %s", url)
 	}
 
-	return fromJSON(body)
+	return body, nil
 }
@@ -91,7 +91,7 @@ import (
 //  	  ]
 // 	  }
 //
-func fromJSON(js []byte) (*chaosmonkey.AppConfig, error) {
+func FromJSON(js []byte) (*chaosmonkey.AppConfig, error) {
 	parsed := new(parsedJSON)
 	err := json.Unmarshal(js, parsed)
 
@@ -179,6 +179,48 @@ func fromJSON(js []byte) (*chaosmonkey.AppConfig, error) {
 	return &cfg, nil
 }
 
+// AppConfigFromJSON takes the JSON representation of the "chaosMonkey"
+// attribute of a Spinnaker app, e.g. {"enabled": false}, and returns a Chaos
+// Monkey config. It is used by backends that store app configs in the same
+// format outside of Spinnaker
+func AppConfigFromJSON(js []byte) (*chaosmonkey.AppConfig, error) {
+	var cm json.RawMessage
+	err := json.Unmarshal(js, &cm)
+	if err != nil {
+		return nil, errors.Wrap(err, "json unmarshal failed")
+	}
+
+	wrapped, err := json.Marshal(map[string]interface{}{
+		"attributes": map[string]interface{}{"chaosMonkey": cm},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "json marshal failed")
+	}
+
+	return FromJSON(wrapped)
+}
+
+// ChaosMonkeyAttribute takes a Spinnaker JSON representation of an app and
+// returns its "chaosMonkey" attribute, or nil if the app does not have one
+func ChaosMonkeyAttribute(js []byte) ([]byte, error) {
+	var parsed struct {
+		Attributes struct {
+			ChaosMonkey json.RawMessage `json:"chaosMonkey"`
+		} `json:"attributes"`
+	}
+
+	err := json.Unmarshal(js, &parsed)
+	if err != nil {
+		return nil, errors.Wrap(err, "json unmarshal failed")
+	}
+
+	if len(parsed.Attributes.ChaosMonkey) == 0 || string(parsed.Attributes.ChaosMonkey) == "null" {
+		return nil, nil
+	}
+
+	return parsed.Attributes.ChaosMonkey, nil
+}
+
 // parsedJson is the parsed JSON representatino
 type parsedJSON struct {
 	Name       string      `json:"name"`
@@ -49,7 +49,7 @@ func TestFromJSON(t *testing.T) {
 		  }
 	  }
   `
-	actual, err := fromJSON([]byte(input))
+	actual, err := FromJSON([]byte(input))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -124,7 +124,7 @@ func TestFromJSONDisabled(t *testing.T) {
 	}
 	`
 
-	actual, err := fromJSON([]byte(input))
+	actual, err := FromJSON([]byte(input))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -167,7 +167,7 @@ func TestBadJSON(t *testing.T) {
 	}
 
 	for _, input := range tests {
-		_, err := fromJSON([]byte(input))
+		_, err := FromJSON([]byte(input))
 		if err == nil {
 			t.Fatalf("Expected an error given missing config: %s", input)
 		}
@@ -204,7 +204,7 @@ func TestFromJSONEmptyWhitelist(t *testing.T) {
 		  }
 	  }
   `
-	actual, err := fromJSON([]byte(input))
+	actual, err := FromJSON([]byte(input))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -249,7 +249,7 @@ func TestFromJSONPopulatedWhitelist(t *testing.T) {
 		  }
 	  }
   `
-	actual, err := fromJSON([]byte(input))
+	actual, err := FromJSON([]byte(input))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -296,3 +296,40 @@ func TestFromJSONPopulatedWhitelist(t *testing.T) {
 		}
 	}
 }
+
+func TestAppConfigFromJSON(t *testing.T) {
+	input := `{"enabled": true, "meanTimeBetweenKillsInWorkDays": 3, "minTimeBetweenKillsInWorkDays": 1, "grouping": "stack"}`
+
+	actual, err := AppConfigFromJSON([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !actual.Enabled || actual.MeanTimeBetweenKillsInWorkDays != 3 || actual.Grouping != chaosmonkey.Stack {
+		t.Errorf("unexpected config: %+v", actual)
+	}
+
+	if _, err := AppConfigFromJSON([]byte(`{"grouping": "stack"}`)); err == nil {
+		t.Error("expected error when enabled field is missing")
+	}
+}
+
+func TestChaosMonkeyAttribute(t *testing.T) {
+	actual, err := ChaosMonkeyAttribute([]byte(`{"name": "abc", "attributes": {"chaosMonkey": {"grouping": "app"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(actual) != `{"grouping": "app"}` {
+		t.Errorf("got %s", actual)
+	}
+
+	actual, err = ChaosMonkeyAttribute([]byte(`{"name": "abc", "attributes": {}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != nil {
+		t.Errorf("expected nil for app without chaosMonkey attribute, got %s", actual)
+	}
+}
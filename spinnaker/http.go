@@ -0,0 +1,298 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spinnaker
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// errCircuitOpen is returned instead of sending a request while the circuit
+// breaker is open
+var errCircuitOpen = errors.New("not sending request to spinnaker: circuit breaker is open after repeated failures")
+
+// maxBackoff is the longest delay between two attempts of a request
+const maxBackoff = 10 * time.Second
+
+// clientOptions configures how the Spinnaker client sends requests
+type clientOptions struct {
+	// workers is the number of apps that are retrieved concurrently
+	workers int
+
+	// requestsPerSecond limits the rate of requests, including retries. If
+	// zero, the rate is not limited
+	requestsPerSecond float64
+
+	// timeout is the timeout of each attempt of a request, including reading
+	// the response body. If zero, requests do not time out
+	timeout time.Duration
+
+	// retries is the number of times a failed request is retried, and
+	// backoff is the delay before the first retry. The delay doubles with
+	// each retry
+	retries int
+	backoff time.Duration
+
+	// After breakerThreshold consecutive failures, requests fail immediately
+	// for breakerCooldown. If breakerThreshold is zero, there is no breaker
+	breakerThreshold int
+	breakerCooldown  time.Duration
+}
+
+// withOptions returns a copy of s that sends requests according to o
+func (s Spinnaker) withOptions(o clientOptions) Spinnaker {
+	s.workers = o.workers
+	if s.workers < 1 {
+		s.workers = 1
+	}
+
+	base := s.client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t := &transport{
+		base:    base,
+		timeout: o.timeout,
+		retries: o.retries,
+		backoff: o.backoff,
+	}
+	if o.requestsPerSecond > 0 {
+		t.limiter = newLimiter(o.requestsPerSecond)
+	}
+	if o.breakerThreshold > 0 {
+		t.breaker = &breaker{threshold: o.breakerThreshold, cooldown: o.breakerCooldown, now: time.Now}
+	}
+
+	client := *s.client
+	client.Transport = t
+	s.client = &client
+
+	return s
+}
+
+// transport is an http.RoundTripper that rate limits, times out and retries
+// requests to Spinnaker, and stops sending them while Spinnaker is down
+type transport struct {
+	base    http.RoundTripper
+	limiter *limiter // nil if the rate is not limited
+	breaker *breaker // nil if there is no circuit breaker
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.RoundTrip
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if t.breaker != nil {
+			if err := t.breaker.allow(); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.send(attemptReq)
+
+		if t.breaker != nil {
+			if err != nil || resp.StatusCode >= 500 {
+				t.breaker.failure()
+			} else {
+				t.breaker.success()
+			}
+		}
+
+		if attempt >= t.retries || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain the body so that the connection can be reused
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(t.delay(attempt)):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// send sends one attempt of a request
+func (t *transport) send(req *http.Request) (*http.Response, error) {
+	if t.limiter != nil {
+		t.limiter.wait()
+	}
+
+	if t.timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The timeout also applies to reading the body, so it is only cancelled
+	// when the body is closed
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// delay returns how long to wait before retrying after the given attempt.
+// It grows exponentially, with jitter so that concurrent workers do not
+// retry in lockstep
+func (t *transport) delay(attempt int) time.Duration {
+	d := t.backoff << uint(attempt)
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// rewind returns the request to send for an attempt. Retries need a fresh
+// copy of the request body
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errors.Errorf("cannot retry %s %s: request body cannot be rewound", req.Method, req.URL)
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to rewind request body")
+	}
+
+	clone := req.WithContext(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// retryable returns true if a request that failed with err, or that got resp,
+// should be retried. Requests that are not idempotent are only retried if
+// Spinnaker did not process them
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return idempotent(req.Method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+// idempotent returns true if requests with method can safely be sent twice
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancelOnClose cancels the context of a request when its response body is
+// closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.Close
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// breaker is a circuit breaker. It opens after threshold consecutive
+// failures, and then rejects requests for cooldown. After the cooldown, one
+// request is let through: if it succeeds the breaker closes, otherwise it
+// stays open for another cooldown
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow returns errCircuitOpen if a request should not be sent
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+
+	now := b.now()
+	if now.Before(b.openUntil) {
+		return errCircuitOpen
+	}
+
+	// Let this request through to probe Spinnaker, and hold back the others
+	// until it completes
+	b.openUntil = now.Add(b.cooldown)
+	return nil
+}
+
+// success records a request that Spinnaker handled
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+// failure records a request that Spinnaker failed to handle
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spinnaker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+// flakyServer fails the first requests to each path with a status code
+// before it starts serving them with handler. It records the requests to
+// each path, and the body of each request
+type flakyServer struct {
+	failures map[string][]int // path -> status codes of the first requests
+	handler  http.HandlerFunc
+
+	mu       sync.Mutex
+	requests map[string]int
+	bodies   []string
+}
+
+func (f *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	f.mu.Lock()
+	n := f.requests[r.URL.Path]
+	f.requests[r.URL.Path]++
+	f.bodies = append(f.bodies, string(body))
+	f.mu.Unlock()
+
+	if codes := f.failures[r.URL.Path]; n < len(codes) {
+		w.WriteHeader(codes[n])
+		fmt.Fprint(w, `{"error": "injected failure"}`)
+		return
+	}
+
+	f.handler(w, r)
+}
+
+// count returns the number of requests to path
+func (f *flakyServer) count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[path]
+}
+
+func newFlakyServer(failures map[string][]int) *flakyServer {
+	return &flakyServer{
+		failures: failures,
+		requests: make(map[string]int),
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/credentials/"):
+				fmt.Fprint(w, `{"cloudProvider": "aws"}`)
+			case strings.HasSuffix(r.URL.Path, "/clusters"):
+				fmt.Fprint(w, `{"prod": ["foo-main"]}`)
+			case strings.HasSuffix(r.URL.Path, "/serverGroups"):
+				fmt.Fprint(w, `[{"name": "foo-main-v000", "region": "us-east-1", "instances": [{"name": "i-1"}]}]`)
+			case strings.HasPrefix(r.URL.Path, "/instances/"):
+				fmt.Fprint(w, `{"health": []}`)
+			case strings.HasSuffix(r.URL.Path, "/tasks"):
+				fmt.Fprint(w, `{"ref": "/tasks/01BXQ5TDV8ZR2K7SZ5RJGQ1NKG"}`)
+			case strings.HasPrefix(r.URL.Path, "/tasks/"):
+				fmt.Fprint(w, `{"status": "SUCCEEDED"}`)
+			default:
+				fmt.Fprint(w, `{}`)
+			}
+		},
+	}
+}
+
+// newTestSpinnaker returns a Spinnaker that sends requests to srv, and
+// retries quickly
+func newTestSpinnaker(t *testing.T, srv *httptest.Server, o clientOptions) Spinnaker {
+	s, err := New(srv.URL, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.backoff == 0 {
+		o.backoff = time.Millisecond
+	}
+	return s.withOptions(o)
+}
+
+var testTermination = chaosmonkey.Termination{
+	Instance: mock.Instance{App: "foo", Account: "prod", Region: "us-east-1", ASG: "foo-main-v000", InstanceID: "i-1"},
+}
+
+func TestRetryTransientFailures(t *testing.T) {
+	f := newFlakyServer(map[string][]int{
+		"/applications/foo/clusters":                            {http.StatusBadGateway, http.StatusServiceUnavailable},
+		"/applications/foo/clusters/prod/foo-main/serverGroups": {http.StatusGatewayTimeout},
+	})
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s := newTestSpinnaker(t, srv, clientOptions{retries: 3})
+
+	app, err := s.GetApp("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := app.Accounts()[0].Clusters()[0].ASGs()[0].Instances()[0].ID(); got != "i-1" {
+		t.Errorf("got instance %s, want i-1", got)
+	}
+
+	if got, want := f.count("/applications/foo/clusters"), 3; got != want {
+		t.Errorf("got %d requests for clusters, want %d", got, want)
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	f := newFlakyServer(map[string][]int{
+		"/applications/foo/clusters": {502, 502, 502, 502},
+	})
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s := newTestSpinnaker(t, srv, clientOptions{retries: 2})
+
+	_, err := s.GetApp("foo")
+	if err == nil {
+		t.Fatal("expected error after retries are exhausted")
+	}
+
+	if got, want := f.count("/applications/foo/clusters"), 3; got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+}
+
+func TestNoRetryOnFatalStatus(t *testing.T) {
+	f := newFlakyServer(map[string][]int{
+		"/applications/foo/clusters": {http.StatusNotFound},
+	})
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s := newTestSpinnaker(t, srv, clientOptions{retries: 3})
+
+	_, err := s.GetApp("foo")
+	if err == nil {
+		t.Fatal("expected error for 404")
+	}
+
+	if got := f.count("/applications/foo/clusters"); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestExecuteRetries(t *testing.T) {
+	tests := []struct {
+		code     int
+		attempts int
+		ok       bool
+	}{
+		// Spinnaker did not process the task, so it is safe to resend it
+		{http.StatusServiceUnavailable, 2, true},
+		{http.StatusTooManyRequests, 2, true},
+		// The task may have been created, so it is not resent
+		{http.StatusBadGateway, 1, false},
+	}
+
+	for _, tt := range tests {
+		f := newFlakyServer(map[string][]int{
+			"/applications/foo/tasks":       {tt.code},
+			"/instances/prod/us-east-1/i-1": {http.StatusBadGateway},
+		})
+		srv := httptest.NewServer(f)
+
+		s := newTestSpinnaker(t, srv, clientOptions{retries: 3})
+		err := s.Execute(testTermination)
+
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("code=%d: got error %v", tt.code, err)
+		}
+
+		if got := f.count("/applications/foo/tasks"); got != tt.attempts {
+			t.Errorf("code=%d: got %d attempts, want %d", tt.code, got, tt.attempts)
+		}
+
+		// Every attempt must send the complete task
+		tasks := 0
+		for _, body := range f.bodies {
+			if strings.Contains(body, "terminateInstances") {
+				tasks++
+			}
+		}
+		if tasks != tt.attempts {
+			t.Errorf("code=%d: got %d complete task bodies, want %d", tt.code, tasks, tt.attempts)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+
+		// The first attempt hangs
+		if n == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{"cloudProvider": "aws"}`)
+	}))
+	defer srv.Close()
+
+	s := newTestSpinnaker(t, srv, clientOptions{retries: 1, timeout: 50 * time.Millisecond})
+
+	provider, err := s.CloudProvider("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if provider != "aws" {
+		t.Errorf("got provider %s, want aws", provider)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	down := true
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"cloudProvider": "aws"}`)
+	}))
+	defer srv.Close()
+
+	s := newTestSpinnaker(t, srv, clientOptions{retries: 0, breakerThreshold: 3, breakerCooldown: time.Hour})
+
+	now := time.Date(2016, time.November, 16, 9, 0, 0, 0, time.UTC)
+	b := s.client.Transport.(*transport).breaker
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		_, err := s.CloudProvider("prod")
+		if err == nil {
+			t.Fatal("expected error while spinnaker is down")
+		}
+	}
+
+	// The breaker opens after 3 failures, so the last 2 are not sent
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+
+	_, err := s.CloudProvider("prod")
+	if err == nil || !strings.Contains(err.Error(), errCircuitOpen.Error()) {
+		t.Errorf("got error %v, want %v", err, errCircuitOpen)
+	}
+
+	// After the cooldown, a probe is sent, and closes the breaker if it succeeds
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	now = now.Add(time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.CloudProvider("prod"); err != nil {
+			t.Fatalf("expected breaker to close after successful probe: %v", err)
+		}
+	}
+}
+
+func TestBreakerProbeFailure(t *testing.T) {
+	now := time.Date(2016, time.November, 16, 9, 0, 0, 0, time.UTC)
+	b := &breaker{threshold: 1, cooldown: time.Minute, now: func() time.Time { return now }}
+
+	b.failure()
+	if b.allow() != errCircuitOpen {
+		t.Fatal("expected breaker to be open")
+	}
+
+	// Only one probe is let through after the cooldown
+	now = now.Add(time.Minute)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to be allowed: %v", err)
+	}
+	if b.allow() != errCircuitOpen {
+		t.Error("expected second request to wait for the probe")
+	}
+
+	// A failed probe keeps the breaker open for another cooldown
+	b.failure()
+	now = now.Add(30 * time.Second)
+	if b.allow() != errCircuitOpen {
+		t.Error("expected breaker to stay open after failed probe")
+	}
+}
@@ -16,16 +16,14 @@
 package spinnaker
 
 import (
-	"crypto/tls"
 	"encoding/json"
-	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"strings"
-
-	"golang.org/x/crypto/pkcs12"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -42,8 +40,24 @@ type Spinnaker struct {
 	endpoint string
 	client   *http.Client
 	user     string
+
+	// workers is the number of apps that Apps retrieves concurrently
+	workers int
+
+	// taskTimeout is how long Execute waits for a termination task to
+	// complete, polling it every taskPollInterval. If zero, Execute does not
+	// wait
+	taskTimeout      time.Duration
+	taskPollInterval time.Duration
 }
 
+// Defaults for how long Execute waits for termination tasks, if not
+// configured
+const (
+	defaultTaskTimeout      = 5 * time.Minute
+	defaultTaskPollInterval = 5 * time.Second
+)
+
 // spinnakerClusters maps account name (e.g., "prod", "test") to a list
 // of cluster names
 type spinnakerClusters map[string][]string
@@ -62,82 +76,101 @@ type spinnakerInstance struct {
 	Name string
 }
 
-// getClient takes PKCS#12 data (encrypted cert data in .p12 format) and the
-// password for the encrypted cert, and returns an http client that does TLS client auth
-func getClient(pfxData []byte, password string) (*http.Client, error) {
-	blocks, err := pkcs12.ToPEM(pfxData, password)
-	if err != nil {
-		return nil, errors.Wrap(err, "pkcs.ToPEM failed")
-	}
-
-	// The first block is the cert and the last block is the private key
-	certPEMBlock := pem.EncodeToMemory(blocks[0])
-	keyPEMBlock := pem.EncodeToMemory(blocks[len(blocks)-1])
-
-	cert, err := tls.X509KeyPair(certPEMBlock, keyPEMBlock)
-	if err != nil {
-		return nil, errors.Wrap(err, "tls.X509KeyPair failed")
-	}
-
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-	}
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
-	return &http.Client{Transport: transport}, nil
-}
-
 // NewFromConfig returns a Spinnaker based on config
 func NewFromConfig(cfg *config.Monkey) (Spinnaker, error) {
 	spinnakerEndpoint := cfg.SpinnakerEndpoint()
-	certPath := cfg.SpinnakerCertificate()
-	encryptedPassword := cfg.SpinnakerEncryptedPassword()
 	user := cfg.SpinnakerUser()
 
 	if spinnakerEndpoint == "" {
 		return Spinnaker{}, errors.New("FATAL: no spinnaker endpoint specified in config")
 	}
 
-	var password string
-	var err error
+	scopes, err := cfg.SpinnakerOAuth2Scopes()
+	if err != nil {
+		return Spinnaker{}, err
+	}
+
+	a := auth{
+		p12:       cfg.SpinnakerCertificate(),
+		certFile:  cfg.SpinnakerCertFile(),
+		keyFile:   cfg.SpinnakerKeyFile(),
+		caFile:    cfg.SpinnakerCAFile(),
+		tokenFile: cfg.SpinnakerTokenFile(),
+		tokenURL:  cfg.SpinnakerOAuth2TokenURL(),
+		clientID:  cfg.SpinnakerOAuth2ClientID(),
+		scopes:    scopes,
+		proxy:     cfg.SpinnakerProxy(),
+	}
+
 	var decryptor chaosmonkey.Decryptor
 
-	if encryptedPassword != "" {
-		decryptor, err = deps.GetDecryptor(cfg)
-		if err != nil {
-			return Spinnaker{}, err
+	// decrypt decrypts a secret from the config, if it is set
+	decrypt := func(encrypted string) (string, error) {
+		if encrypted == "" {
+			return "", nil
 		}
 
-		password, err = decryptor.Decrypt(encryptedPassword)
-		if err != nil {
-			return Spinnaker{}, err
+		if decryptor == nil {
+			d, err := deps.GetDecryptor(cfg)
+			if err != nil {
+				return "", err
+			}
+			decryptor = d
 		}
+
+		return decryptor.Decrypt(encrypted)
+	}
+
+	a.password, err = decrypt(cfg.SpinnakerEncryptedPassword())
+	if err != nil {
+		return Spinnaker{}, err
 	}
 
-	return New(spinnakerEndpoint, certPath, password, user)
+	a.clientSecret, err = decrypt(cfg.SpinnakerOAuth2EncryptedClientSecret())
+	if err != nil {
+		return Spinnaker{}, err
+	}
 
+	s, err := newWithAuth(spinnakerEndpoint, a, user)
+	if err != nil {
+		return Spinnaker{}, err
+	}
+	s.taskTimeout = cfg.SpinnakerTaskTimeout()
+	s.taskPollInterval = cfg.SpinnakerTaskPollInterval()
+
+	return s.withOptions(clientOptions{
+		workers:           cfg.SpinnakerWorkers(),
+		requestsPerSecond: cfg.SpinnakerRequestsPerSecond(),
+		timeout:           cfg.SpinnakerTimeout(),
+		retries:           cfg.SpinnakerRetries(),
+		backoff:           cfg.SpinnakerRetryBackoff(),
+		breakerThreshold:  cfg.SpinnakerBreakerThreshold(),
+		breakerCooldown:   cfg.SpinnakerBreakerCooldown(),
+	}), nil
 }
 
 // New returns a Spinnaker using a .p12 cert at certPath encrypted with
 // password. The user argument identifies the email address of the user which is
 // sent in the payload of the terminateInstances task API call
 func New(endpoint string, certPath string, password string, user string) (Spinnaker, error) {
-	var client *http.Client
-
-	if certPath != "" {
-		pfxData, err := ioutil.ReadFile(certPath)
-		if err != nil {
-			return Spinnaker{}, errors.Wrapf(err, "failed to read file %s", certPath)
-		}
+	return newWithAuth(endpoint, auth{p12: certPath, password: password}, user)
+}
 
-		client, err = getClient(pfxData, password)
-		if err != nil {
-			return Spinnaker{}, err
-		}
-	} else {
-		client = new(http.Client)
+// newWithAuth returns a Spinnaker that authenticates according to a
+func newWithAuth(endpoint string, a auth, user string) (Spinnaker, error) {
+	client, err := newClient(a)
+	if err != nil {
+		return Spinnaker{}, err
 	}
 
-	return Spinnaker{endpoint: endpoint, client: client, user: user}, nil
+	return Spinnaker{
+		endpoint:         endpoint,
+		client:           client,
+		user:             user,
+		workers:          1,
+		taskTimeout:      defaultTaskTimeout,
+		taskPollInterval: defaultTaskPollInterval,
+	}, nil
 }
 
 // AccountID returns numerical ID associated with an AWS account
@@ -207,27 +240,48 @@ func (s Spinnaker) alternateAccountID(name string) (string, error) {
 }
 
 // Apps implements deploy.Deployment.Apps
+// Apps are retrieved concurrently by the configured number of workers, so
+// they are not necessarily sent in the order of appNames
 func (s Spinnaker) Apps(c chan<- *D.App, appNames []string) {
-	// Close the channel we're done
+	// Close the channel when all the workers are done
 	defer close(c)
 
-	for _, appName := range appNames {
-		app, err := s.GetApp(appName)
-		if err != nil {
-			// If we have a problem with one app, we go to the next one
-			log.Printf("WARNING: GetApp failed for %s: %v", appName, err)
-			continue
-		}
+	names := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for appName := range names {
+				app, err := s.GetApp(appName)
+				if err != nil {
+					// If we have a problem with one app, we go to the next one
+					log.Printf("WARNING: GetApp failed for %s: %v", appName, err)
+					continue
+				}
 
-		c <- app
+				c <- app
+			}
+		}()
 	}
+
+	for _, appName := range appNames {
+		names <- appName
+	}
+	close(names)
+
+	wg.Wait()
 }
 
 // GetApp implements deploy.Deployment.GetApp
 func (s Spinnaker) GetApp(appName string) (*D.App, error) {
 	// data arg is a map like {accountName: {clusterName: {regionName: {asgName: [instanceId]}}}}
 	data := make(D.AppMap)
-	for account, clusters := range s.clusters(appName) {
+	appClusters, err := s.clusters(appName)
+	if err != nil {
+		return nil, err
+	}
+	for account, clusters := range appClusters {
 		cloudProvider, err := s.CloudProvider(account)
 		if err != nil {
 			return nil, errors.Wrap(err, "retrieve cloud provider failed")
@@ -310,27 +364,26 @@ type spinnakerApp struct {
 }
 
 // clusters returns a map from account name to list of cluster names
-func (s Spinnaker) clusters(appName string) spinnakerClusters {
+func (s Spinnaker) clusters(appName string) (result spinnakerClusters, err error) {
 	url := s.clustersURL(appName)
 	resp, err := s.client.Get(url)
 	if err != nil {
-		log.Println("Error connecting to spinnaker clusters endpoint")
-		log.Println(url)
-		log.Fatalln(err)
+		return nil, errors.Wrapf(err, "failed to retrieve clusters url (%s)", url)
 	}
 
 	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Error closing response body of %s: %v", url, err)
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "failed to close response body of %s", url)
 		}
 	}()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("Error retrieving spinnaker clusters for app", appName)
-		log.Println(url)
-		log.Println(string(body))
-		log.Fatalln(err)
+		return nil, errors.Wrapf(err, "failed to read body of clusters url (%s)", url)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code (%d) from %s: body: '%s'", resp.StatusCode, url, string(body))
 	}
 
 	// Example cluster output:
@@ -348,13 +401,10 @@ func (s Spinnaker) clusters(appName string) spinnakerClusters {
 
 	err = json.Unmarshal(body, &m)
 	if err != nil {
-		log.Println("Error parsing body when retrieving cluster info for", appName)
-		log.Println(url)
-		log.Println(string(body))
-		log.Fatalln(err)
+		return nil, errors.Wrapf(err, "failed to parse body of clusters url (%s): body: '%s'", url, string(body))
 	}
 
-	return m
+	return m, nil
 }
 
 // asgs returns a slice of autoscaling groups associated with the given cluster
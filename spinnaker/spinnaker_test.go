@@ -0,0 +1,157 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spinnaker
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	D "github.com/Netflix/chaosmonkey/deploy"
+)
+
+// fakeSpinnaker serves the clusters, server groups and credentials of apps
+// named app0, app1, ..., each with one cluster with one instance. It records
+// the maximum number of requests that were in flight at the same time
+type fakeSpinnaker struct {
+	delay time.Duration
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	requests    int
+}
+
+func (f *fakeSpinnaker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.inFlight++
+	f.requests++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	time.Sleep(f.delay)
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case parts[0] == "credentials":
+		fmt.Fprint(w, `{"cloudProvider": "aws"}`)
+	case len(parts) == 3 && parts[2] == "clusters" && parts[1] == "broken":
+		w.WriteHeader(http.StatusInternalServerError)
+	case len(parts) == 3 && parts[2] == "clusters":
+		fmt.Fprintf(w, `{"prod": ["%s-main"]}`, parts[1])
+	case len(parts) == 6 && parts[5] == "serverGroups":
+		fmt.Fprintf(w, `[{"name": "%s-v000", "region": "us-east-1", "instances": [{"name": "i-%s"}]}]`, parts[4], parts[1])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// appNames returns the names of the first n apps
+func appNames(n int) []string {
+	var result []string
+	for i := 0; i < n; i++ {
+		result = append(result, fmt.Sprintf("app%d", i))
+	}
+	return result
+}
+
+// collect returns the names of the apps sent by Apps, sorted
+func collect(s Spinnaker, names []string) []string {
+	c := make(chan *D.App)
+	go s.Apps(c, names)
+
+	var result []string
+	for app := range c {
+		result = append(result, app.Name())
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestAppsConcurrent(t *testing.T) {
+	f := &fakeSpinnaker{delay: 20 * time.Millisecond}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s, err := New(srv.URL, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = s.withOptions(clientOptions{workers: 4, timeout: time.Second})
+
+	names := appNames(12)
+	got := collect(s, append(names, "broken"))
+
+	// The broken app is skipped
+	sort.Strings(names)
+	if fmt.Sprint(got) != fmt.Sprint(names) {
+		t.Errorf("got apps %v, want %v", got, names)
+	}
+
+	if f.maxInFlight < 2 || f.maxInFlight > 4 {
+		t.Errorf("got %d concurrent requests, want between 2 and 4", f.maxInFlight)
+	}
+}
+
+func TestAppsRateLimited(t *testing.T) {
+	f := &fakeSpinnaker{}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s, err := New(srv.URL, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = s.withOptions(clientOptions{workers: 4, requestsPerSecond: 100, timeout: time.Second})
+
+	start := time.Now()
+	collect(s, appNames(5))
+	elapsed := time.Since(start)
+
+	// Each app takes 3 requests, and the first one is not delayed
+	if min := time.Duration(f.requests-1) * 10 * time.Millisecond; elapsed < min {
+		t.Errorf("%d requests took %s, want at least %s", f.requests, elapsed, min)
+	}
+}
+
+func TestAppsTimeout(t *testing.T) {
+	f := &fakeSpinnaker{delay: 200 * time.Millisecond}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s, err := New(srv.URL, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = s.withOptions(clientOptions{workers: 1, timeout: 20 * time.Millisecond})
+
+	_, err = s.GetApp("app0")
+	if err == nil {
+		t.Error("expected request to time out")
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"path"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -69,19 +71,42 @@ func (t fakeTerminator) Execute(trm chaosmonkey.Termination) error {
 }
 
 // Execute implements term.Terminator.Execute
-func (s Spinnaker) Execute(trm chaosmonkey.Termination) (err error) {
+func (s Spinnaker) Execute(trm chaosmonkey.Termination) error {
+	_, err := s.ExecuteTask(trm)
+	return err
+}
+
+// ExecuteTask implements chaosmonkey.TaskTerminator.ExecuteTask
+// It creates a terminateInstances task, and polls the task until it completes
+func (s Spinnaker) ExecuteTask(trm chaosmonkey.Termination) (taskID string, err error) {
 	ins := trm.Instance
-	url := s.tasksURL(ins.AppName())
 
 	otherID, err := s.OtherID(ins)
 	if err != nil {
-		return errors.Wrap(err, "retrieve other id failed")
+		return "", errors.Wrap(err, "retrieve other id failed")
 	}
 
 	payload := killJSONPayload(ins, otherID, s.user)
+	ref, err := s.createTask(ins.AppName(), payload)
+	if err != nil {
+		return "", err
+	}
+
+	taskID = path.Base(ref)
+
+	if s.taskTimeout <= 0 {
+		return taskID, nil
+	}
+
+	return taskID, s.waitForTask(ref)
+}
+
+// createTask submits a task, and returns its reference, e.g. "/tasks/01BXQ5TDV8ZR2K7SZ5RJGQ1NKG"
+func (s Spinnaker) createTask(appName string, payload []byte) (ref string, err error) {
+	url := s.tasksURL(appName)
 	resp, err := s.client.Post(url, "application/json", bytes.NewReader(payload))
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("POST to %s failed, (body '%s')", url, string(payload)))
+		return "", errors.Wrap(err, fmt.Sprintf("POST to %s failed, (body '%s')", url, string(payload)))
 	}
 
 	defer func() {
@@ -90,16 +115,95 @@ func (s Spinnaker) Execute(trm chaosmonkey.Termination) (err error) {
 		}
 	}()
 
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read response body")
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("Unexpected response: %d", resp.StatusCode)
-		contents, err := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected response code: %d, body: %s", resp.StatusCode, string(contents))
+	}
+
+	var fields struct {
+		Ref string `json:"ref"`
+	}
+
+	err = json.Unmarshal(contents, &fields)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("json unmarshal failed, body: %s", contents))
+	}
+
+	if fields.Ref == "" {
+		return "", fmt.Errorf("no ref field in response body: %s", contents)
+	}
+
+	return fields.Ref, nil
+}
+
+// waitForTask polls a task until it completes or the task timeout expires.
+// It returns an error unless the task succeeded
+func (s Spinnaker) waitForTask(ref string) error {
+	deadline := time.Now().Add(s.taskTimeout)
+	status := ""
+
+	for {
+		current, err := s.taskStatus(ref)
 		if err != nil {
-			return errors.Wrap(err, "failed to read response body")
+			// The task may still complete, so keep polling until the timeout
+			log.Printf("WARNING: could not retrieve status of task %s: %v", ref, err)
+		} else {
+			status = current
+		}
+
+		switch status {
+		case "SUCCEEDED":
+			return nil
+		case "TERMINAL", "FAILED_CONTINUE", "CANCELED", "STOPPED", "SKIPPED":
+			return errors.Errorf("task %s completed with status %s", ref, status)
+		}
+
+		if !time.Now().Before(deadline) {
+			return errors.Errorf("task %s did not complete within %s, last status: %q", ref, s.taskTimeout, status)
 		}
-		return fmt.Errorf("unexpected response code: %d, body: %s", resp.StatusCode, string(contents))
+
+		time.Sleep(s.taskPollInterval)
 	}
+}
 
-	return nil
+// taskStatus returns the status of a task, e.g. "RUNNING" or "SUCCEEDED"
+func (s Spinnaker) taskStatus(ref string) (status string, err error) {
+	url := s.endpoint + ref
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("get failed on %s", url))
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, fmt.Sprintf("failed to close response body from %s", url))
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("body read failed at %s", url))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d. body: %s", resp.StatusCode, body)
+	}
+
+	var fields struct {
+		Status string `json:"status"`
+	}
+
+	err = json.Unmarshal(body, &fields)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("json unmarshal failed, body: %s", body))
+	}
+
+	return fields.Status, nil
 }
 
 // killJsonPayload generates the JSON request body for terminating an instance
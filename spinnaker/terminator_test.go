@@ -16,7 +16,13 @@ package spinnaker
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/Netflix/chaosmonkey/mock"
 )
@@ -168,3 +174,76 @@ func TestKillJSONPayloadWithOtherID(t *testing.T) {
 		t.Errorf("got: %s, want: %s", got, want)
 	}
 }
+
+// taskServer creates termination tasks whose status goes through statuses,
+// one per poll, and then stays at the last one
+type taskServer struct {
+	statuses []string
+
+	mu    sync.Mutex
+	polls int
+}
+
+func (ts *taskServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/instances/"):
+		fmt.Fprint(w, `{"health": []}`)
+	case r.Method == "POST" && r.URL.Path == "/applications/foo/tasks":
+		fmt.Fprint(w, `{"ref": "/tasks/01BXQ5TDV8ZR2K7SZ5RJGQ1NKG"}`)
+	case r.URL.Path == "/tasks/01BXQ5TDV8ZR2K7SZ5RJGQ1NKG":
+		ts.mu.Lock()
+		i := ts.polls
+		ts.polls++
+		ts.mu.Unlock()
+		if i >= len(ts.statuses) {
+			i = len(ts.statuses) - 1
+		}
+		fmt.Fprintf(w, `{"id": "01BXQ5TDV8ZR2K7SZ5RJGQ1NKG", "status": "%s"}`, ts.statuses[i])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestExecuteTask(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		timeout  time.Duration
+		ok       bool
+		polls    int
+	}{
+		{[]string{"NOT_STARTED", "RUNNING", "SUCCEEDED"}, time.Second, true, 3},
+		{[]string{"RUNNING", "TERMINAL"}, time.Second, false, 2},
+		{[]string{"CANCELED"}, time.Second, false, 1},
+		// The task hangs
+		{[]string{"RUNNING"}, 20 * time.Millisecond, false, -1},
+		// Tasks are not verified without a timeout
+		{[]string{"TERMINAL"}, 0, true, 0},
+	}
+
+	for _, tt := range tests {
+		ts := &taskServer{statuses: tt.statuses}
+		srv := httptest.NewServer(ts)
+
+		s, err := New(srv.URL, "", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.taskTimeout = tt.timeout
+		s.taskPollInterval = time.Millisecond
+
+		taskID, err := s.ExecuteTask(testTermination)
+		srv.Close()
+
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("statuses=%v: got error %v", tt.statuses, err)
+		}
+
+		if taskID != "01BXQ5TDV8ZR2K7SZ5RJGQ1NKG" {
+			t.Errorf("statuses=%v: got task id %q", tt.statuses, taskID)
+		}
+
+		if tt.polls >= 0 && ts.polls != tt.polls {
+			t.Errorf("statuses=%v: got %d polls, want %d", tt.statuses, ts.polls, tt.polls)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey/killswitch"
+)
+
+// killSwitchID is the id of the single row of the kill_switch table
+const killSwitchID = 1
+
+// Stop implements killswitch.KillSwitch.Stop
+func (s SQLite) Stop(reason string, stoppedAt time.Time, expiresAt time.Time) error {
+	var expires interface{}
+	if !expiresAt.IsZero() {
+		expires = sqlTime(expiresAt)
+	}
+
+	_, err := s.db.Exec(`INSERT INTO kill_switch (id, stopped, reason, stopped_at, expires_at) VALUES (?, TRUE, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET stopped=TRUE, reason=excluded.reason, stopped_at=excluded.stopped_at, expires_at=excluded.expires_at`,
+		killSwitchID, reason, sqlTime(stoppedAt), expires)
+	if err != nil {
+		return errors.Wrap(err, "failed to stop kill switch")
+	}
+
+	return nil
+}
+
+// Resume implements killswitch.KillSwitch.Resume
+func (s SQLite) Resume() error {
+	_, err := s.db.Exec("UPDATE kill_switch SET stopped=FALSE WHERE id=?", killSwitchID)
+	if err != nil {
+		return errors.Wrap(err, "failed to resume kill switch")
+	}
+
+	return nil
+}
+
+// State implements killswitch.KillSwitch.State
+func (s SQLite) State() (killswitch.State, error) {
+	var state killswitch.State
+	var expiresAt sql.NullTime
+
+	err := s.db.QueryRow("SELECT stopped, reason, stopped_at, expires_at FROM kill_switch WHERE id=?", killSwitchID).
+		Scan(&state.Stopped, &state.Reason, &state.StoppedAt, &expiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return killswitch.State{}, nil
+	case err != nil:
+		return killswitch.State{}, errors.Wrap(err, "failed to retrieve kill switch state")
+	}
+
+	if expiresAt.Valid {
+		state.ExpiresAt = expiresAt.Time
+	}
+
+	return state, nil
+}
@@ -0,0 +1,457 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite provides a store for schedules and terminations in a local
+// SQLite database file, for deployments where Chaos Monkey runs on a single
+// host
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	// Registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"github.com/rubenv/sql-migrate"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/cal"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/migration"
+	"github.com/Netflix/chaosmonkey/schedstore"
+	"github.com/Netflix/chaosmonkey/schedule"
+)
+
+// SQLite represents a store for schedules and terminations in a SQLite
+// database file
+type SQLite struct {
+	db        *sql.DB
+	calendars cal.Calendars
+}
+
+// busyTimeout is how long a transaction waits for another process to release
+// the database
+const busyTimeout = 30 * time.Second
+
+// timeFormat is the format of times in the database. Times are stored as
+// text in UTC, with a fixed number of fractional digits so that they compare
+// in chronological order
+const timeFormat = "2006-01-02 15:04:05.000000"
+
+// ViolatesMinTime returns true if the error violates min time between
+// terminations
+func ViolatesMinTime(err error) bool {
+	_, ok := errors.Cause(err).(chaosmonkey.ErrViolatesMinTime)
+	return ok
+}
+
+// NewFromConfig creates a new SQLite taking config parameters from cfg
+func NewFromConfig(cfg *config.Monkey) (SQLite, error) {
+	path := cfg.DatabasePath()
+	if path == "" {
+		return SQLite{}, errors.Errorf("%s not specified", param.DatabasePath)
+	}
+
+	calendars, err := cal.NewFromConfig(cfg)
+	if err != nil {
+		return SQLite{}, err
+	}
+
+	s, err := New(path)
+	if err != nil {
+		return SQLite{}, err
+	}
+
+	s.calendars = calendars
+	return s, nil
+}
+
+// New creates a new SQLite that stores its data in the file at path. The file
+// is created if it does not exist
+func New(path string) (SQLite, error) {
+	db, err := sql.Open("sqlite3", dsn(path))
+	if err != nil {
+		return SQLite{}, errors.Wrap(err, "sql.Open failed")
+	}
+
+	return SQLite{db: db}, nil
+}
+
+// Close closes the underlying sql.DB
+func (s SQLite) Close() error {
+	return s.db.Close()
+}
+
+// dsn returns a SQLite data source name for the file at path
+// See: https://github.com/mattn/go-sqlite3#connection-string
+func dsn(path string) string {
+	params := url.Values{
+		// Transactions take the write lock when they begin, rather than when
+		// they first write. Otherwise two terminate processes could both read
+		// that there was no recent termination before either of them writes
+		"_txlock": {"immediate"},
+
+		// Wait for other processes to release the lock instead of failing
+		"_busy_timeout": {fmt.Sprint(int64(busyTimeout / time.Millisecond))},
+
+		// Let readers proceed while a transaction is writing
+		"_journal_mode": {"WAL"},
+	}
+
+	u := url.URL{Scheme: "file", Opaque: path, RawQuery: params.Encode()}
+	return u.String()
+}
+
+// sqlTime returns t as it is stored in the database
+func sqlTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// sqlValue converts a value of a history.Condition to the value stored in
+// the database
+func sqlValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return sqlTime(t)
+	}
+	return v
+}
+
+// dateOf returns the date of t in the location of t, as it is stored in the
+// database
+func dateOf(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// Retrieve retrieves the schedule for the given date
+func (s SQLite) Retrieve(date time.Time) (sched *schedule.Schedule, err error) {
+	rows, err := s.db.Query("SELECT time, app, account, region, stack, cluster, seed FROM schedules WHERE date = ? ORDER BY id", dateOf(date))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve schedule for %s", date)
+	}
+
+	sched = schedule.New()
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "rows.Close() failed")
+		}
+	}()
+
+	for rows.Next() {
+		var tm time.Time
+		var app, account, region, stack, cluster string
+		var seed sql.NullInt64
+
+		err = rows.Scan(&tm, &app, &account, &region, &stack, &cluster, &seed)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		if seed.Valid {
+			sched.SetSeed(seed.Int64)
+		}
+
+		sched.Add(tm, grp.New(app, account, region, stack, cluster))
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "rows.Err() errored")
+	}
+
+	return sched, nil
+}
+
+// Publish publishes the schedule for the given date
+func (s SQLite) Publish(date time.Time, sched *schedule.Schedule) error {
+	return s.PublishWithDelay(date, sched, 0)
+}
+
+// PublishWithDelay publishes the schedule with a delay between checking the schedule
+// exists and writing it. The delay is used only for testing race conditions
+func (s SQLite) PublishWithDelay(date time.Time, sched *schedule.Schedule, delay time.Duration) (err error) {
+	// First, we check to see if there is a schedule present
+	tx, err := s.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	// We must either commit or rollback at the end
+	defer func() {
+		switch err {
+		case nil:
+			err = tx.Commit()
+		case schedstore.ErrAlreadyExists:
+			// We want to return ErrAlreadyExists even if the transaction commit
+			// fails
+			_ = tx.Commit()
+		default:
+			_ = tx.Rollback()
+		}
+	}()
+
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM schedules WHERE date = ?", dateOf(date)).Scan(&count)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check if schedule exists for %s", date)
+	}
+
+	if count > 0 {
+		return schedstore.ErrAlreadyExists
+	}
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	query := "INSERT INTO schedules (date, time, app, account, region, stack, cluster, seed) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return errors.Wrapf(err, "failed to prepare sql statement: %s", query)
+	}
+
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	// Schedules published without a seed record it as NULL
+	var seed sql.NullInt64
+	seed.Int64, seed.Valid = sched.Seed()
+
+	for _, entry := range sched.Entries() {
+		var app, account, region, stack, cluster string
+		app = entry.Group.App()
+		account = entry.Group.Account()
+		if val, ok := entry.Group.Region(); ok {
+			region = val
+		}
+		if val, ok := entry.Group.Stack(); ok {
+			stack = val
+		}
+		if val, ok := entry.Group.Cluster(); ok {
+			cluster = val
+		}
+
+		_, err = stmt.Exec(dateOf(date), sqlTime(entry.Time), app, account, region, stack, cluster, seed)
+		if err != nil {
+			return errors.Wrapf(err, "failed to execute prepared query")
+		}
+	}
+
+	return nil
+}
+
+// Check checks if a termination is permitted and, if so, records the
+// termination time on the server
+func (s SQLite) Check(term chaosmonkey.Termination, appCfg chaosmonkey.AppConfig, endHour int, loc *time.Location) error {
+	return s.CheckWithDelay(term, appCfg, endHour, loc, 0)
+}
+
+// CheckWithDelay is the same as Check, but adds a delay between reading and
+// writing to the database (used for testing only)
+func (s SQLite) CheckWithDelay(term chaosmonkey.Termination, appCfg chaosmonkey.AppConfig, endHour int, loc *time.Location, delay time.Duration) (err error) {
+	// The transaction holds the write lock on the whole database until it
+	// completes, so concurrent checks run one after the other
+	tx, err := s.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	defer func() {
+		switch err {
+		case nil:
+			err = tx.Commit()
+		default:
+			_ = tx.Rollback()
+		}
+	}()
+
+	err = respectsMinTimeBetweenKills(tx, term.Time, term, appCfg, s.calendars, endHour, loc)
+	if err != nil {
+		return err
+	}
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	return recordTermination(tx, term)
+}
+
+// respectsMinTimeBetweenKills checks if this termination will respect or
+// violate the min time between kills value. If this termination is too close
+// to the most recent one, this will return an error.
+// If this termination would violate the min time, returns an ErrViolatesMinTime
+func respectsMinTimeBetweenKills(tx *sql.Tx, now time.Time, term chaosmonkey.Termination, appCfg chaosmonkey.AppConfig, calendars cal.Calendars, endHour int, loc *time.Location) error {
+	calendar := calendars.For(term.Instance.AccountName(), term.Instance.RegionName())
+	threshold, err := calendar.NoKillsSince(appCfg.MinTimeBetweenKillsInWorkDays, now, endHour, loc)
+	if err != nil {
+		return err
+	}
+
+	query := "SELECT instance_id, killed_at FROM terminations WHERE app = ? AND account = ? AND killed_at >= ?"
+	args := []interface{}{term.Instance.AppName(), term.Instance.AccountName(), sqlTime(threshold)}
+
+	switch appCfg.Grouping {
+	case chaosmonkey.App:
+		// nothing to do
+	case chaosmonkey.Stack:
+		query += " AND stack = ?"
+		args = append(args, term.Instance.StackName())
+	case chaosmonkey.Cluster:
+		query += " AND cluster = ?"
+		args = append(args, term.Instance.ClusterName())
+	default:
+		return errors.Errorf("unknown group: %v", appCfg.Grouping)
+	}
+
+	if appCfg.RegionsAreIndependent {
+		query += " AND region = ?"
+		args = append(args, term.Instance.RegionName())
+	}
+
+	// For unleashed (real) terminations, we only care about previous
+	// terminations that were also unleashed. That's because a previous
+	// leashed termination wasn't a real one, so that wouldn't violate
+	// the min time between terminations
+	if !term.Leashed {
+		query += " AND leashed = FALSE"
+	}
+
+	// We need at most one entry
+	query += " LIMIT 1"
+
+	var instanceID string
+	var killedAt time.Time
+	err = tx.QueryRow(query, args...).Scan(&instanceID, &killedAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil
+	case err != nil:
+		return errors.Wrap(err, "failed to retrieve previous terminations")
+	}
+
+	return chaosmonkey.ErrViolatesMinTime{InstanceID: instanceID, KilledAt: killedAt, Loc: loc}
+}
+
+func recordTermination(tx *sql.Tx, term chaosmonkey.Termination) error {
+	i := term.Instance
+
+	_, err := tx.Exec("INSERT INTO terminations (app, account, stack, cluster, region, asg, instance_id, killed_at, leashed) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		i.AppName(), i.AccountName(), i.StackName(), i.ClusterName(), i.RegionName(), i.ASGName(), i.ID(), sqlTime(term.Time), term.Leashed)
+
+	return err
+}
+
+// Termination statuses recorded by RecordOutcome
+const (
+	statusSucceeded = "succeeded"
+	statusFailed    = "failed"
+)
+
+// RecordOutcome implements chaosmonkey.OutcomeRecorder.RecordOutcome
+// It stores the status and task id with the termination recorded by Check
+func (s SQLite) RecordOutcome(term chaosmonkey.Termination, outcome chaosmonkey.Outcome) error {
+	status := statusFailed
+	if outcome.Succeeded {
+		status = statusSucceeded
+	}
+
+	i := term.Instance
+	result, err := s.db.Exec("UPDATE terminations SET status = ?, task_id = ? WHERE app = ? AND account = ? AND instance_id = ? AND killed_at = ?",
+		status, outcome.TaskID, i.AppName(), i.AccountName(), i.ID(), sqlTime(term.Time))
+	if err != nil {
+		return errors.Wrap(err, "failed to record termination outcome")
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to record termination outcome")
+	}
+
+	if n == 0 {
+		return errors.Errorf("no termination of %s at %s to record outcome of", i.ID(), term.Time.In(time.UTC))
+	}
+
+	return nil
+}
+
+// Terminations implements history.Store.Terminations
+func (s SQLite) Terminations(f history.Filter) (terms []history.Termination, err error) {
+	query := "SELECT app, account, region, stack, cluster, asg, instance_id, killed_at, leashed, status, task_id FROM terminations"
+
+	var clauses []string
+	var args []interface{}
+	for _, c := range f.Conditions() {
+		clauses = append(clauses, c.Column+" "+c.Op+" ?")
+		args = append(args, sqlValue(c.Value))
+	}
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY killed_at"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve terminations")
+	}
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "rows.Close() failed")
+		}
+	}()
+
+	for rows.Next() {
+		var t history.Termination
+		err = rows.Scan(&t.App, &t.Account, &t.Region, &t.Stack, &t.Cluster, &t.ASG, &t.InstanceID, &t.KilledAt, &t.Leashed, &t.Status, &t.TaskID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		terms = append(terms, t)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "rows.Err() errored")
+	}
+
+	return terms, nil
+}
+
+var migrationSource = &migrate.AssetMigrationSource{
+	Asset:    migration.Asset,
+	AssetDir: migration.AssetDir,
+	Dir:      "migration/sqlite",
+}
+
+var databaseDialect = "sqlite3"
+
+// Migrate upgrades a database to the latest database schema version.
+func Migrate(db SQLite) error {
+	migrationCount, err := migrate.Exec(db.db, databaseDialect, migrationSource, migrate.Up)
+	if err != nil {
+		return errors.Wrap(err, "database migration failed")
+	}
+	log.Println("Successfully applied database migrations. Number of migrations applied: ", migrationCount)
+
+	return nil
+}
@@ -0,0 +1,603 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	c "github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/history"
+	"github.com/Netflix/chaosmonkey/migration"
+	"github.com/Netflix/chaosmonkey/mock"
+	"github.com/Netflix/chaosmonkey/schedstore"
+	"github.com/Netflix/chaosmonkey/schedule"
+	"github.com/Netflix/chaosmonkey/sqlite"
+)
+
+var endHour = 15 // 3PM
+
+// initDB creates a database file with the chaosmonkey schemas in a new
+// directory, and returns its path and a function that removes it
+func initDB(t *testing.T) (path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "chaosmonkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path = filepath.Join(dir, "chaosmonkey.db")
+	s := open(t, path)
+	defer s.Close()
+
+	err = sqlite.Migrate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+// TestMigrationIDs verifies that the SQLite migrations have the same ids as
+// the MySQL migrations, so that a schema version means the same schema
+func TestMigrationIDs(t *testing.T) {
+	want, err := migration.AssetDir("migration/mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := migration.AssetDir("migration/sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(want)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got migrations %v, want %v", got, want)
+	}
+}
+
+func open(t *testing.T, path string) sqlite.SQLite {
+	s, err := sqlite.New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// testSetup returns some values useful for test setup
+func testSetup(t *testing.T) (ins c.Instance, loc *time.Location, appCfg c.AppConfig) {
+	ins = mock.Instance{
+		App:        "myapp",
+		Account:    "prod",
+		Stack:      "mystack",
+		Cluster:    "mycluster",
+		Region:     "us-east-1",
+		ASG:        "myapp-mystack-mycluster-V123",
+		InstanceID: "i-a96a0166",
+	}
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appCfg = c.AppConfig{
+		Enabled:                        true,
+		RegionsAreIndependent:          true,
+		MeanTimeBetweenKillsInWorkDays: 5,
+		MinTimeBetweenKillsInWorkDays:  1,
+		Grouping:                       c.Cluster,
+	}
+
+	return
+}
+
+// TestCheckForbidden verifies check succeeds when there are no previous
+// terminations, and fails if the previous termination is too recent
+func TestCheckForbidden(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	s := open(t, path)
+	defer s.Close()
+
+	ins, loc, appCfg := testSetup(t)
+
+	trm := c.Termination{Instance: ins, Time: time.Now()}
+
+	err := s.Check(trm, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Check(trm, appCfg, endHour, loc)
+	if !sqlite.ViolatesMinTime(err) {
+		t.Fatalf("Expected Err.ViolatesMinTime, got %v", err)
+	}
+}
+
+// When we are going to commit an unleashed termination, we only care
+// about unleashed previous terminations
+func TestCheckLeashed(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	s := open(t, path)
+	defer s.Close()
+
+	ins, loc, appCfg := testSetup(t)
+
+	err := s.Check(c.Termination{Instance: ins, Time: time.Now(), Leashed: true}, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Check(c.Termination{Instance: ins, Time: time.Now()}, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatalf("Should have allowed an unleashed termination after leashed: %v", err)
+	}
+}
+
+// TestCheckMinTimeEnforced verifies the boundary of the min time between
+// kills, which depends on times comparing in chronological order
+func TestCheckMinTimeEnforced(t *testing.T) {
+	ins, loc, appCfg := testSetup(t)
+	appCfg.MinTimeBetweenKillsInWorkDays = 2
+
+	// The most recent kill permitted is Tue Dec 15 15:00:00 2015 -0800
+	now := time.Date(2015, time.December, 17, 11, 35, 0, 0, loc)
+
+	tests := []struct {
+		last    time.Time
+		allowed bool
+	}{
+		{time.Date(2015, time.December, 15, 15, 0, 0, 1000, loc), false},
+		{time.Date(2015, time.December, 15, 14, 59, 59, 999999000, loc), true},
+	}
+
+	for _, tt := range tests {
+		path, cleanup := initDB(t)
+		s := open(t, path)
+
+		err := s.Check(c.Termination{Instance: ins, Time: tt.last}, appCfg, endHour, loc)
+		if err != nil {
+			t.Fatalf("Failed to write the initial termination, should always succeed: %v", err)
+		}
+
+		err = s.Check(c.Termination{Instance: ins, Time: now}, appCfg, endHour, loc)
+		switch {
+		case err == nil && !tt.allowed:
+			t.Errorf("%s termination should have been forbidden, was allowed", tt.last)
+		case err != nil && tt.allowed:
+			t.Errorf("%s termination should have been allowed, got: %v", tt.last, err)
+		case err != nil && !sqlite.ViolatesMinTime(err):
+			t.Errorf("%s termination returned unexpected err: %v", tt.last, err)
+		}
+
+		s.Close()
+		cleanup()
+	}
+}
+
+// TestConcurrentChecks verifies that only one of two processes that check
+// the same termination at the same time is permitted to terminate
+func TestConcurrentChecks(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	// Each process has its own connection to the database file
+	s1 := open(t, path)
+	defer s1.Close()
+	s2 := open(t, path)
+	defer s2.Close()
+
+	ins, loc, appCfg := testSetup(t)
+
+	trm := c.Termination{Instance: ins, Time: time.Now()}
+
+	ch := make(chan error, 2)
+
+	go func() {
+		// The delay between reading from the database and writing to it
+		// makes the two checks overlap
+		ch <- s1.CheckWithDelay(trm, appCfg, endHour, loc, 500*time.Millisecond)
+	}()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ch <- s2.Check(trm, appCfg, endHour, loc)
+	}()
+
+	var success, violatesMinTime int
+	for i := 0; i < 2; i++ {
+		err := <-ch
+		switch {
+		case err == nil:
+			success++
+		case sqlite.ViolatesMinTime(err):
+			violatesMinTime++
+		default:
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}
+
+	if success != 1 || violatesMinTime != 1 {
+		t.Errorf("got %d successes and %d min time violations, want 1 of each", success, violatesMinTime)
+	}
+}
+
+// TestRecordOutcome verifies that the outcome is stored with the termination
+// that Check recorded
+func TestRecordOutcome(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	s := open(t, path)
+	defer s.Close()
+
+	ins, loc, appCfg := testSetup(t)
+
+	trm := c.Termination{Instance: ins, Time: time.Date(2016, time.November, 16, 17, 0, 0, 700000123, time.UTC)}
+
+	err := s.Check(trm, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.RecordOutcome(trm, c.Outcome{Succeeded: true, TaskID: "01BXQ5TD"})
+	if err != nil {
+		t.Fatalf("failed to record outcome: %v", err)
+	}
+
+	other := trm
+	other.Time = trm.Time.Add(time.Hour)
+	err = s.RecordOutcome(other, c.Outcome{Succeeded: false})
+	if err == nil {
+		t.Error("expected error when recording the outcome of a termination that was not checked")
+	}
+}
+
+// Test that holidays in the configured calendar do not count as work days
+// for the min time between kills
+func TestCheckSkipsHolidays(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	holidays := filepath.Join(filepath.Dir(path), "holidays.txt")
+	err := ioutil.WriteFile(holidays, []byte("2016-11-24..2016-11-25 Thanksgiving\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Defaults()
+	cfg.Set(param.DatabasePath, path)
+	cfg.Set(param.CalendarAccounts, map[string]string{"prod": holidays})
+
+	s, err := sqlite.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ins, loc, appCfg := testSetup(t)
+	appCfg.MinTimeBetweenKillsInWorkDays = 2
+
+	// Wednesday before Thanksgiving
+	trm := c.Termination{Instance: ins, Time: time.Date(2016, time.November, 23, 11, 0, 0, 0, loc)}
+	err = s.Check(trm, appCfg, endHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The Monday after is only one work day later
+	trm.Time = time.Date(2016, time.November, 28, 11, 0, 0, 0, loc)
+	err = s.Check(trm, appCfg, endHour, loc)
+	if !sqlite.ViolatesMinTime(err) {
+		t.Errorf("expected min time violation, got %v", err)
+	}
+}
+
+// Test that terminations can be retrieved with filters
+func TestTerminations(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	s := open(t, path)
+	defer s.Close()
+
+	ins, loc, appCfg := testSetup(t)
+	appCfg.MinTimeBetweenKillsInWorkDays = 0
+
+	west := ins.(mock.Instance)
+	west.Region = "us-west-2"
+	west.InstanceID = "i-b96a0166"
+
+	day := func(d int) time.Time {
+		return time.Date(2016, time.November, d, 11, 0, 0, 0, loc)
+	}
+
+	for _, trm := range []c.Termination{
+		{Instance: ins, Time: day(14), Leashed: true},
+		{Instance: ins, Time: day(15)},
+		{Instance: west, Time: day(16)},
+	} {
+		if err := s.Check(trm, appCfg, endHour, loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	unleashed := false
+	tests := []struct {
+		filter history.Filter
+		want   []string
+	}{
+		{history.Filter{}, []string{"i-a96a0166", "i-a96a0166", "i-b96a0166"}},
+		{history.Filter{App: "myapp", Region: "us-west-2"}, []string{"i-b96a0166"}},
+		{history.Filter{App: "otherapp"}, nil},
+		{history.Filter{Since: day(15), Until: day(16)}, []string{"i-a96a0166"}},
+		{history.Filter{Leashed: &unleashed}, []string{"i-a96a0166", "i-b96a0166"}},
+	}
+
+	for _, tt := range tests {
+		terms, err := s.Terminations(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, term := range terms {
+			got = append(got, term.InstanceID)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	terms, err := s.Terminations(history.Filter{Region: "us-west-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := terms[0].KilledAt, day(16); !got.Equal(want) {
+		t.Errorf("got killed at %s, want %s", got, want)
+	}
+}
+
+// Test we can publish and then retrieve a schedule, and that only the
+// schedule of the requested day is retrieved
+func TestPublishRetrieve(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	s := open(t, path)
+	defer s.Close()
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pEntries := []schedule.Entry{
+		{Time: time.Date(2016, time.June, 20, 11, 40, 0, 0, loc), Group: grp.New("doesnotexist", "test", "us-east-1", "", "doesnotexist-foo-bar")},
+		{Time: time.Date(2016, time.June, 20, 18, 35, 0, 0, loc), Group: grp.New("foobar", "other", "us-west-2", "", "foobar-baz-quux")},
+		{Time: time.Date(2016, time.June, 20, 9, 7, 0, 0, loc), Group: grp.New("chaosguineapig", "prod", "us-east-1", "", "chaosguineapig-prod")},
+	}
+
+	psched := schedule.New()
+	for _, v := range pEntries {
+		psched.Add(v.Time, v.Group)
+	}
+
+	date := time.Date(2016, time.June, 20, 0, 0, 0, 0, loc)
+
+	next := schedule.New()
+	next.Add(time.Date(2016, time.June, 21, 11, 0, 0, 0, loc), grp.New("imaginaryproject", "test", "us-west-2", "", ""))
+
+	err = s.Publish(date, psched)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Publish(date.AddDate(0, 0, 1), next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsched, err := s.Retrieve(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rEntries := rsched.Entries()
+	if got, want := len(rEntries), len(pEntries); got != want {
+		t.Fatalf("got len(entries)=%d, want %d", got, want)
+	}
+
+	for i := range pEntries {
+		if got, want := rEntries[i], pEntries[i]; !got.Equal(&want) {
+			t.Errorf("got entry[%d]=%v, want %v", i, got, want)
+		}
+	}
+}
+
+// Test that the seed a schedule was generated with survives a round trip,
+// and that a schedule published without one is retrieved without one
+func TestPublishRetrieveSeed(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	s := open(t, path)
+	defer s.Close()
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Date(2016, time.June, 20, 0, 0, 0, 0, loc)
+
+	seeded := schedule.New()
+	seeded.SetSeed(42)
+	seeded.Add(time.Date(2016, time.June, 20, 11, 40, 0, 0, loc), grp.New("chaosguineapig", "test", "us-east-1", "", "chaosguineapig-test"))
+
+	unseeded := schedule.New()
+	unseeded.Add(time.Date(2016, time.June, 21, 11, 40, 0, 0, loc), grp.New("chaosguineapig", "test", "us-east-1", "", "chaosguineapig-test"))
+
+	if err := s.Publish(date, seeded); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Publish(date.AddDate(0, 0, 1), unseeded); err != nil {
+		t.Fatal(err)
+	}
+
+	sched, err := s.Retrieve(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed, ok := sched.Seed(); !ok || seed != 42 {
+		t.Errorf("got seed=%d, ok=%t, want seed=42, ok=true", seed, ok)
+	}
+
+	sched, err = s.Retrieve(date.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed, ok := sched.Seed(); ok {
+		t.Errorf("got seed=%d, want no seed", seed)
+	}
+}
+
+// TestConcurrentPublish verifies that only one of two processes that publish
+// a schedule for the same day at the same time succeeds
+func TestConcurrentPublish(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	s1 := open(t, path)
+	defer s1.Close()
+	s2 := open(t, path)
+	defer s2.Close()
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sched := schedule.New()
+	sched.Add(time.Date(2016, time.June, 20, 11, 40, 0, 0, loc), grp.New("imaginaryproject", "test", "us-west-2", "", ""))
+
+	date := time.Date(2016, time.June, 20, 0, 0, 0, 0, loc)
+
+	ch := make(chan error, 2)
+
+	go func() {
+		ch <- s1.PublishWithDelay(date, sched, 500*time.Millisecond)
+	}()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ch <- s2.Publish(date, sched)
+	}()
+
+	var success, alreadyExists int
+	for i := 0; i < 2; i++ {
+		err := <-ch
+		switch err {
+		case nil:
+			success++
+		case schedstore.ErrAlreadyExists:
+			alreadyExists++
+		default:
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}
+
+	if success != 1 || alreadyExists != 1 {
+		t.Errorf("got %d successes and %d ErrAlreadyExists, want 1 of each", success, alreadyExists)
+	}
+}
+
+// Test we can stop, retrieve and resume the kill switch
+func TestKillSwitch(t *testing.T) {
+	path, cleanup := initDB(t)
+	defer cleanup()
+
+	s := open(t, path)
+	defer s.Close()
+
+	state, err := s.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Stopped {
+		t.Fatal("expected kill switch to be inactive before it was ever stopped")
+	}
+
+	stoppedAt := time.Date(2016, time.June, 20, 11, 40, 0, 0, time.UTC)
+	expiresAt := stoppedAt.Add(4 * time.Hour)
+
+	err = s.Stop("game day", stoppedAt, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = s.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.Stopped || state.Reason != "game day" || !state.StoppedAt.Equal(stoppedAt) || !state.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("unexpected state after stop: %+v", state)
+	}
+
+	// Stopping again without an expiry replaces the previous one
+	err = s.Stop("", stoppedAt, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = s.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.ExpiresAt.IsZero() {
+		t.Errorf("got ExpiresAt=%s, want zero time", state.ExpiresAt)
+	}
+
+	err = s.Resume()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = s.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Stopped {
+		t.Error("expected kill switch to be inactive after resume")
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"github.com/Netflix/chaosmonkey/deploy"
 	"github.com/Netflix/chaosmonkey/deps"
 	"github.com/Netflix/chaosmonkey/grp"
+	"github.com/Netflix/chaosmonkey/killswitch"
+	"github.com/Netflix/chaosmonkey/metrics"
 )
 
 type leashedKiller struct {
@@ -48,7 +50,22 @@ func (err UnleashedInTestEnv) Error() string {
 // based on the app, account, region, stack, cluster passed
 //
 // region, stack, and cluster may be blank
-func Terminate(d deps.Deps, app string, account string, region string, stack string, cluster string) error {
+//
+// Each stage that is reached is recorded in metrics.TerminateEvents, labelled
+// with the app, account and region passed
+func Terminate(d deps.Deps, app string, account string, region string, stack string, cluster string) (err error) {
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		stage := metrics.Failed
+		if _, ok := errors.Cause(err).(chaosmonkey.ErrViolatesMinTime); ok {
+			stage = metrics.MinTime
+		}
+		metrics.TerminateEvents.Inc(stage, app, account, region)
+	}()
+
 	enabled, err := d.MonkeyCfg.Enabled()
 	if err != nil {
 		return errors.Wrap(err, "not terminating: could not determine if monkey is enabled")
@@ -56,10 +73,22 @@ func Terminate(d deps.Deps, app string, account string, region string, stack str
 
 	if !enabled {
 		log.Printf("not terminating: enabled=false")
+		metrics.TerminateEvents.Inc(metrics.Disabled, app, account, region)
+		return nil
+	}
+
+	state, err := d.KillSwitch.State()
+	if err != nil {
+		return errors.Wrap(err, "not terminating: could not determine if kill switch is active")
+	}
+
+	if state.Active(d.Cl.Now()) {
+		log.Printf("not terminating: stopped by kill switch at %s, reason=%q%s", state.StoppedAt.Format(time.RFC3339), state.Reason, expiry(state))
+		metrics.TerminateEvents.Inc(metrics.Disabled, app, account, region)
 		return nil
 	}
 
-	problem, err := d.Ou.Outage()
+	problem, err := checkOutage(d.Ou, account, region)
 
 	// If the check for ongoing outage fails, we err on the safe side nd don't terminate an instance
 	if err != nil {
@@ -68,6 +97,7 @@ func Terminate(d deps.Deps, app string, account string, region string, stack str
 
 	if problem {
 		log.Printf("not terminating: outage in progress")
+		metrics.TerminateEvents.Inc(metrics.Outage, app, account, region)
 		return nil
 	}
 
@@ -79,6 +109,7 @@ func Terminate(d deps.Deps, app string, account string, region string, stack str
 
 	if !accountEnabled {
 		log.Printf("Not terminating: account=%s is not enabled in Chaos Monkey", account)
+		metrics.TerminateEvents.Inc(metrics.Disabled, app, account, region)
 		return nil
 	}
 
@@ -90,6 +121,25 @@ func Terminate(d deps.Deps, app string, account string, region string, stack str
 
 }
 
+// expiry describes when the kill switch state expires, for logging
+func expiry(state killswitch.State) string {
+	if state.ExpiresAt.IsZero() {
+		return ""
+	}
+
+	return ", expires at " + state.ExpiresAt.Format(time.RFC3339)
+}
+
+// checkOutage checks for an ongoing outage. If the outage checker supports
+// it, only outages that affect account and region are considered
+func checkOutage(ou chaosmonkey.Outage, account, region string) (bool, error) {
+	if scoped, ok := ou.(chaosmonkey.ScopedOutage); ok {
+		return scoped.OutageIn(account, region)
+	}
+
+	return ou.Outage()
+}
+
 // doTerminate does the actual termination
 func doTerminate(d deps.Deps, group grp.InstanceGroup) error {
 	leashed, err := d.MonkeyCfg.Leashed()
@@ -119,7 +169,7 @@ func doTerminate(d deps.Deps, group grp.InstanceGroup) error {
 
 	// get Chaos Monkey config info for this app
 	appName := group.App()
-	appCfg, err := d.ConfGetter.Get(appName)
+	appCfg, err := chaosmonkey.GetAppConfig(d.ConfGetter, appName, group.Account())
 
 	if err != nil {
 		return errors.Wrapf(err, "not terminating: Could not retrieve config for app=%s", appName)
@@ -130,6 +180,7 @@ func doTerminate(d deps.Deps, group grp.InstanceGroup) error {
 	// if we check here and bail out early with a more informative log message
 	if !appCfg.Enabled {
 		log.Printf("not terminating: enabled=false for app=%s", appName)
+		metrics.TerminateEvents.Inc(metrics.Disabled, appName, group.Account(), regionLabel(group))
 		return nil
 	}
 
@@ -145,10 +196,11 @@ func doTerminate(d deps.Deps, group grp.InstanceGroup) error {
 	}
 
 	log.Printf("Picked: %s", instance)
+	metrics.TerminateEvents.Inc(metrics.Picked, appName, group.Account(), regionLabel(group))
 
-	loc, err := d.MonkeyCfg.Location()
+	hours, err := d.MonkeyCfg.BusinessHours(instance.AccountName(), instance.RegionName())
 	if err != nil {
-		return errors.Wrap(err, "not terminating: could not retrieve location")
+		return errors.Wrap(err, "not terminating: could not retrieve business hours")
 	}
 
 	trm := chaosmonkey.Termination{Instance: instance, Time: d.Cl.Now(), Leashed: leashed}
@@ -156,7 +208,7 @@ func doTerminate(d deps.Deps, group grp.InstanceGroup) error {
 	//
 	// Check that we don't violate min time between terminations
 	//
-	err = d.Checker.Check(trm, *appCfg, d.MonkeyCfg.EndHour(), loc)
+	err = d.Checker.Check(trm, *appCfg, hours.EndHour, hours.Location)
 	if err != nil {
 		return errors.Wrap(err, "not terminating: check for min time between terminations failed")
 	}
@@ -174,14 +226,56 @@ func doTerminate(d deps.Deps, group grp.InstanceGroup) error {
 	//
 	// Actual instance termination happens here
 	//
-	err = killer.Execute(trm)
+	start := time.Now()
+	taskID, err := execute(killer, trm)
+	metrics.TerminateDuration.Observe(time.Since(start).Seconds(), appName, group.Account(), regionLabel(group))
+
+	if !leashed {
+		recordOutcome(d.Checker, trm, chaosmonkey.Outcome{Succeeded: err == nil, TaskID: taskID})
+	}
+
 	if err != nil {
 		return errors.Wrap(err, "termination failed")
 	}
 
+	metrics.TerminateEvents.Inc(metrics.Executed, appName, group.Account(), regionLabel(group))
 	return nil
 }
 
+// execute executes a termination. If the terminator runs the termination as a
+// backend task, the id of the task is returned
+func execute(killer chaosmonkey.Terminator, trm chaosmonkey.Termination) (taskID string, err error) {
+	if t, ok := killer.(chaosmonkey.TaskTerminator); ok {
+		return t.ExecuteTask(trm)
+	}
+	return "", killer.Execute(trm)
+}
+
+// recordOutcome records the outcome of an executed termination, if the
+// checker supports it. Failing to record the outcome does not fail the
+// termination, which has already happened
+func recordOutcome(checker chaosmonkey.Checker, trm chaosmonkey.Termination, outcome chaosmonkey.Outcome) {
+	recorder, ok := checker.(chaosmonkey.OutcomeRecorder)
+	if !ok {
+		return
+	}
+
+	err := recorder.RecordOutcome(trm, outcome)
+	if err != nil {
+		log.Printf("WARNING: could not record outcome of termination of %s: %v", trm.Instance.ID(), err)
+	}
+}
+
+// regionLabel returns the region of the group, or blank if the group is not
+// restricted to a region
+func regionLabel(group grp.InstanceGroup) string {
+	region, ok := group.Region()
+	if !ok {
+		return ""
+	}
+	return region
+}
+
 // PickRandomInstance randomly selects an eligible instance from a group
 func PickRandomInstance(group grp.InstanceGroup, cfg chaosmonkey.AppConfig, app *deploy.App) (chaosmonkey.Instance, bool) {
 	instances := EligibleInstances(group, cfg, app)
@@ -16,6 +16,7 @@ package term
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/Netflix/chaosmonkey/config"
 	"github.com/Netflix/chaosmonkey/config/param"
 	"github.com/Netflix/chaosmonkey/deps"
+	"github.com/Netflix/chaosmonkey/metrics"
 	"github.com/Netflix/chaosmonkey/mock"
 )
 
@@ -39,7 +41,8 @@ func mockDeps() deps.Deps {
 	ttor := mock.Terminator{}
 	ou := mock.Outage{}
 	env := mock.Env{IsInTest: false}
-	return deps.Deps{MonkeyCfg: monkeyCfg, Checker: recorder, ConfGetter: confGetter, Cl: cl, Dep: dep, T: &ttor, Ou: ou, Env: env}
+	ks := mock.KillSwitch{}
+	return deps.Deps{MonkeyCfg: monkeyCfg, Checker: recorder, ConfGetter: confGetter, Cl: cl, Dep: dep, T: &ttor, Ou: ou, Env: env, KillSwitch: &ks}
 }
 
 // TestTerminateKills ensure the terminator actually gets invoked
@@ -173,3 +176,151 @@ func TestDoesNotTerminateIfTrackerFails(t *testing.T) {
 	}
 
 }
+
+// TestTerminateMetrics ensures each stage of a termination is counted
+func TestTerminateMetrics(t *testing.T) {
+	tests := []struct {
+		deps  func() deps.Deps
+		stage string
+	}{
+		{mockDeps, metrics.Executed},
+		{func() deps.Deps {
+			d := mockDeps()
+			d.Ou = mock.Outage{IsOutage: true}
+			return d
+		}, metrics.Outage},
+		{func() deps.Deps {
+			d := mockDeps()
+			d.Checker = mock.Checker{Error: chaosmonkey.ErrViolatesMinTime{InstanceID: "i-8703ada6", KilledAt: time.Now()}}
+			return d
+		}, metrics.MinTime},
+		{func() deps.Deps {
+			d := mockDeps()
+			d.Checker = mock.Checker{Error: errors.New("database unavailable")}
+			return d
+		}, metrics.Failed},
+	}
+
+	for _, tt := range tests {
+		before := metrics.TerminateEvents.Value(tt.stage, "foo", "prod", "us-east-1")
+
+		_ = Terminate(tt.deps(), "foo", "prod", "us-east-1", "", "foo-prod")
+
+		after := metrics.TerminateEvents.Value(tt.stage, "foo", "prod", "us-east-1")
+		if got, want := after-before, 1.0; got != want {
+			t.Errorf("stage=%s: got increment of %v, want %v", tt.stage, got, want)
+		}
+	}
+}
+
+// scopedOutage reports an outage only in the listed accounts
+type scopedOutage struct {
+	accounts map[string]bool
+}
+
+func (s scopedOutage) Outage() (bool, error) {
+	return true, nil
+}
+
+func (s scopedOutage) OutageIn(account, region string) (bool, error) {
+	return s.accounts[account], nil
+}
+
+// TestTerminateScopedOutage ensures an outage in another account does not
+// prevent terminations
+func TestTerminateScopedOutage(t *testing.T) {
+	deps := mockDeps()
+	deps.MonkeyCfg.Set(param.Accounts, []string{"prod", "test"})
+	deps.Ou = scopedOutage{accounts: map[string]bool{"test": true}}
+
+	if err := Terminate(deps, "foo", "prod", "us-east-1", "", "foo-prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	ttor := deps.T.(*mock.Terminator)
+	if got, want := ttor.Ncalls, 1; got != want {
+		t.Fatalf("Expected terminator to be called once during outage in other account, got ttor.Ncalls=%d", ttor.Ncalls)
+	}
+}
+
+// TestTerminateKillSwitch ensures nothing is terminated while the kill switch
+// is active, and terminations resume once it expires
+func TestTerminateKillSwitch(t *testing.T) {
+	now := time.Date(2016, time.November, 16, 11, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expiresAt time.Time
+		ncalls    int
+	}{
+		{time.Time{}, 0},
+		{now.Add(time.Hour), 0},
+		{now.Add(-time.Hour), 1},
+	}
+
+	for _, tt := range tests {
+		deps := mockDeps()
+		deps.Cl = mock.Clock{Time: now}
+		if err := deps.KillSwitch.Stop("game day", now.Add(-2*time.Hour), tt.expiresAt); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Terminate(deps, "foo", "prod", "us-east-1", "", "foo-prod"); err != nil {
+			t.Fatal(err)
+		}
+
+		ttor := deps.T.(*mock.Terminator)
+		if got, want := ttor.Ncalls, tt.ncalls; got != want {
+			t.Errorf("expiresAt=%v: got ttor.Ncalls=%d, want %d", tt.expiresAt, got, want)
+		}
+	}
+}
+
+// taskTerminator runs terminations as backend tasks
+type taskTerminator struct {
+	mock.Terminator
+	taskID string
+}
+
+func (t *taskTerminator) ExecuteTask(trm chaosmonkey.Termination) (string, error) {
+	return t.taskID, t.Execute(trm)
+}
+
+// outcomeChecker records the outcomes of terminations
+type outcomeChecker struct {
+	mock.Checker
+	outcomes []chaosmonkey.Outcome
+}
+
+func (c *outcomeChecker) RecordOutcome(trm chaosmonkey.Termination, outcome chaosmonkey.Outcome) error {
+	c.outcomes = append(c.outcomes, outcome)
+	return nil
+}
+
+func TestTerminateRecordsOutcome(t *testing.T) {
+	tests := []struct {
+		err     error
+		leashed bool
+		want    []chaosmonkey.Outcome
+	}{
+		{nil, false, []chaosmonkey.Outcome{{Succeeded: true, TaskID: "01BXQ5TD"}}},
+		{errors.New("task 01BXQ5TD completed with status TERMINAL"), false, []chaosmonkey.Outcome{{Succeeded: false, TaskID: "01BXQ5TD"}}},
+		{nil, true, nil},
+	}
+
+	for _, tt := range tests {
+		deps := mockDeps()
+		deps.MonkeyCfg.Set(param.Leashed, tt.leashed)
+		checker := &outcomeChecker{}
+		deps.Checker = checker
+		deps.T = &taskTerminator{Terminator: mock.Terminator{Error: tt.err}, taskID: "01BXQ5TD"}
+
+		err := Terminate(deps, "foo", "prod", "us-east-1", "", "foo-prod")
+		if (err != nil) != (tt.err != nil) {
+			t.Errorf("err=%v leashed=%t: got error %v", tt.err, tt.leashed, err)
+		}
+
+		if !reflect.DeepEqual(checker.outcomes, tt.want) {
+			t.Errorf("err=%v leashed=%t: got outcomes %+v, want %+v", tt.err, tt.leashed, checker.outcomes, tt.want)
+		}
+	}
+}
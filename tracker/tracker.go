@@ -17,8 +17,14 @@ package tracker
 
 import (
 	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/aws"
 	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
 	"github.com/Netflix/chaosmonkey/deps"
+	"github.com/Netflix/chaosmonkey/email"
+	"github.com/Netflix/chaosmonkey/file"
+	"github.com/Netflix/chaosmonkey/kubernetes"
+	"github.com/Netflix/chaosmonkey/spinnaker"
 	"github.com/pkg/errors"
 )
 
@@ -46,12 +52,35 @@ func getTrackers(cfg *config.Monkey) ([]chaosmonkey.Tracker, error) {
 }
 
 // getTracker returns a tracker by name
-// No trackers have been implemented yet
 func getTracker(kind string, cfg *config.Monkey) (chaosmonkey.Tracker, error) {
 	switch kind {
 	// As trackers are contributed to the open source project, they should
 	// be instantiated here
+	case "webhook":
+		return newWebhook(cfg)
+	case "email":
+		owners, err := getOwnerGetter(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return email.NewFromConfig(cfg, owners)
 	default:
 		return nil, errors.Errorf("unsupported tracker: %s", kind)
 	}
 }
+
+// getOwnerGetter returns the configured backend, which looks up app owners
+func getOwnerGetter(cfg *config.Monkey) (email.OwnerGetter, error) {
+	switch kind := cfg.Backend(); kind {
+	case "spinnaker":
+		return spinnaker.NewFromConfig(cfg)
+	case "kubernetes":
+		return kubernetes.NewFromConfig(cfg)
+	case "aws":
+		return aws.NewFromConfig(cfg)
+	case "file":
+		return file.NewFromConfig(cfg)
+	default:
+		return nil, errors.Errorf("unsupported %s: %s", param.Backend, kind)
+	}
+}
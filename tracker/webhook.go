@@ -0,0 +1,211 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/deps"
+)
+
+// SignatureHeader is the HTTP header that carries the HMAC-SHA256 signature
+// of the request body, formatted as "sha256=<hex digest>"
+const SignatureHeader = "X-Chaos-Monkey-Signature"
+
+// webhook is a tracker that POSTs a JSON document describing each termination
+// to one or more URLs
+type webhook struct {
+	urls           []string
+	headers        map[string]string
+	secret         []byte
+	retries        int
+	retryDelay     time.Duration
+	blockOnFailure bool
+	client         *http.Client
+}
+
+// webhookEvent is the JSON document sent by the webhook tracker
+type webhookEvent struct {
+	App           string    `json:"app"`
+	Account       string    `json:"account"`
+	Region        string    `json:"region"`
+	Stack         string    `json:"stack"`
+	Cluster       string    `json:"cluster"`
+	ASG           string    `json:"asg"`
+	InstanceID    string    `json:"instanceId"`
+	CloudProvider string    `json:"cloudProvider"`
+	Leashed       bool      `json:"leashed"`
+	Time          time.Time `json:"time"`
+}
+
+// newWebhook returns a webhook tracker configured from cfg
+func newWebhook(cfg *config.Monkey) (*webhook, error) {
+	urls, err := cfg.WebhookURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(urls) == 0 {
+		return nil, errors.Errorf("webhook tracker: %s not specified", param.WebhookURLs)
+	}
+
+	var secret []byte
+	if encrypted := cfg.WebhookEncryptedSecret(); encrypted != "" {
+		decryptor, err := deps.GetDecryptor(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		plaintext, err := decryptor.Decrypt(encrypted)
+		if err != nil {
+			return nil, errors.Wrap(err, "webhook tracker: could not decrypt secret")
+		}
+		secret = []byte(plaintext)
+	}
+
+	return &webhook{
+		urls:           urls,
+		headers:        cfg.WebhookHeaders(),
+		secret:         secret,
+		retries:        cfg.WebhookRetries(),
+		retryDelay:     time.Second,
+		blockOnFailure: cfg.WebhookBlockOnFailure(),
+		client:         &http.Client{Timeout: cfg.WebhookTimeout()},
+	}, nil
+}
+
+// Track implements chaosmonkey.Tracker.Track
+// If the webhook is not configured to block on failure, delivery errors are
+// logged and Track returns nil so that the termination proceeds
+func (w *webhook) Track(trm chaosmonkey.Termination) error {
+	body, err := json.Marshal(newWebhookEvent(trm))
+	if err != nil {
+		return errors.Wrap(err, "webhook tracker: json marshal failed")
+	}
+
+	for _, url := range w.urls {
+		err = w.deliver(url, body)
+		if err == nil {
+			continue
+		}
+
+		if w.blockOnFailure {
+			return err
+		}
+
+		log.Printf("WARNING: %v", err)
+	}
+
+	return nil
+}
+
+func newWebhookEvent(trm chaosmonkey.Termination) webhookEvent {
+	ins := trm.Instance
+	return webhookEvent{
+		App:           ins.AppName(),
+		Account:       ins.AccountName(),
+		Region:        ins.RegionName(),
+		Stack:         ins.StackName(),
+		Cluster:       ins.ClusterName(),
+		ASG:           ins.ASGName(),
+		InstanceID:    ins.ID(),
+		CloudProvider: ins.CloudProvider(),
+		Leashed:       trm.Leashed,
+		Time:          trm.Time.UTC(),
+	}
+}
+
+// deliver POSTs body to url, retrying with exponential backoff on network
+// errors and 5xx responses
+func (w *webhook) deliver(url string, body []byte) error {
+	delay := w.retryDelay
+
+	var err error
+	var retryable bool
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		retryable, err = w.post(url, body)
+		if err == nil || !retryable {
+			break
+		}
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "webhook tracker: delivery to %s failed", url)
+	}
+
+	return nil
+}
+
+// post makes a single POST request. It returns whether a failure is worth
+// retrying
+func (w *webhook) post(url string, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "could not create request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	if w.secret != nil {
+		req.Header.Set(SignatureHeader, "sha256="+sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "failed to close response body")
+		}
+	}()
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+
+	contents, _ := ioutil.ReadAll(resp.Body)
+	err = errors.Errorf("unexpected response code: %d, body: %s", resp.StatusCode, contents)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of body using key
+func sign(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
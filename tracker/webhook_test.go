@@ -0,0 +1,158 @@
+// Copyright 2016 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Netflix/chaosmonkey"
+	"github.com/Netflix/chaosmonkey/config"
+	"github.com/Netflix/chaosmonkey/config/param"
+	"github.com/Netflix/chaosmonkey/mock"
+)
+
+func testTermination() chaosmonkey.Termination {
+	return chaosmonkey.Termination{
+		Instance: mock.Instance{
+			App:        "foo",
+			Account:    "prod",
+			Stack:      "staging",
+			Cluster:    "foo-staging",
+			Region:     "us-east-1",
+			ASG:        "foo-staging-v001",
+			InstanceID: "i-a96a0166",
+		},
+		Time:    time.Date(2016, time.November, 8, 14, 3, 0, 0, time.UTC),
+		Leashed: true,
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	var event webhookEvent
+	var header http.Header
+	var body []byte
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("could not parse body %s: %v", body, err)
+		}
+	}))
+	defer srv.Close()
+
+	w := &webhook{
+		urls:           []string{srv.URL},
+		headers:        map[string]string{"X-Team": "chaos"},
+		secret:         []byte("s3cr3t"),
+		blockOnFailure: true,
+		client:         new(http.Client),
+	}
+
+	if err := w.Track(testTermination()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := webhookEvent{
+		App:           "foo",
+		Account:       "prod",
+		Region:        "us-east-1",
+		Stack:         "staging",
+		Cluster:       "foo-staging",
+		ASG:           "foo-staging-v001",
+		InstanceID:    "i-a96a0166",
+		CloudProvider: "aws",
+		Leashed:       true,
+		Time:          time.Date(2016, time.November, 8, 14, 3, 0, 0, time.UTC),
+	}
+
+	if event != want {
+		t.Errorf("got %+v, want %+v", event, want)
+	}
+
+	if got, want := header.Get("X-Team"), "chaos"; got != want {
+		t.Errorf("got X-Team=%s, want %s", got, want)
+	}
+
+	if got, want := header.Get(SignatureHeader), "sha256="+sign([]byte("s3cr3t"), body); got != want {
+		t.Errorf("got signature %s, want %s", got, want)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		status   int
+		retries  int
+		blocking bool
+		calls    int
+		fail     bool
+	}{
+		{http.StatusBadGateway, 2, true, 3, true},
+		{http.StatusBadGateway, 2, false, 3, false},
+		{http.StatusBadRequest, 2, true, 1, true},
+		{http.StatusOK, 2, true, 1, false},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(tt.status)
+		}))
+
+		w := &webhook{
+			urls:           []string{srv.URL},
+			retries:        tt.retries,
+			retryDelay:     time.Millisecond,
+			blockOnFailure: tt.blocking,
+			client:         new(http.Client),
+		}
+
+		err := w.Track(testTermination())
+		srv.Close()
+
+		if got, want := err != nil, tt.fail; got != want {
+			t.Errorf("status=%d blocking=%t: got error=%v, want failure=%t", tt.status, tt.blocking, err, want)
+		}
+
+		if got, want := calls, tt.calls; got != want {
+			t.Errorf("status=%d: got %d calls, want %d", tt.status, got, want)
+		}
+	}
+}
+
+func TestGetWebhookTracker(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Set(param.Trackers, []string{"webhook"})
+
+	if _, err := getTrackers(cfg); err == nil {
+		t.Error("expected error when no webhook urls are configured")
+	}
+
+	cfg.Set(param.WebhookURLs, []string{"http://localhost/hook"})
+	trackers, err := getTrackers(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(trackers), 1; got != want {
+		t.Fatalf("got %d trackers, want %d", got, want)
+	}
+}